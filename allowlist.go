@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// defaultAllowedCommands is the default of -allowed-commands.
+const defaultAllowedCommands = "golangci-lint"
+
+// allowedCommand is an entry of -allowed-commands: an executable, followed by the
+// leading arguments a command must start with, like "go tool golangci-lint".
+type allowedCommand []string
+
+// parseAllowedCommands parses the comma-separated entries of -allowed-commands.
+func parseAllowedCommands(list string) []allowedCommand {
+	var allowed []allowedCommand
+
+	for _, entry := range strings.Split(list, ",") {
+		if fields := strings.Fields(entry); len(fields) > 0 {
+			allowed = append(allowed, fields)
+		}
+	}
+
+	return allowed
+}
+
+// matches reports whether command starts with the entry. An executable given by its
+// basename only matches commands run by that name from PATH, so that a binary of the
+// workspace with the same name does not pass; an absolute path matches the command
+// resolving to it.
+func (a allowedCommand) matches(command []string) bool {
+	if len(command) < len(a) || !slices.Equal(a[1:], command[1:len(a)]) {
+		return false
+	}
+
+	name := command[0]
+	if filepath.IsAbs(a[0]) {
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return false
+		}
+
+		path, err = filepath.Abs(path)
+
+		return err == nil && filepath.Clean(path) == filepath.Clean(a[0])
+	}
+
+	if strings.ContainsAny(name, `/\`) {
+		return false
+	}
+
+	return executableName(name) == executableName(a[0])
+}
+
+// executableName returns the name of an executable without the .exe extension of
+// Windows, which may be left out.
+func executableName(name string) string {
+	if ext := filepath.Ext(name); strings.EqualFold(ext, ".exe") {
+		name = strings.TrimSuffix(name, ext)
+	}
+
+	return name
+}
+
+// checkAllowedCommand fails when the command is outside the -allowed-commands policy.
+// Without a policy, every command is allowed; so is an empty command, which runs nothing.
+func (h *langHandler) checkAllowedCommand(command []string) error {
+	if h.allowedCommands == nil || len(command) == 0 {
+		return nil
+	}
+
+	for _, a := range h.allowedCommands {
+		if a.matches(command) {
+			return nil
+		}
+	}
+
+	entries := make([]string, 0, len(h.allowedCommands))
+	for _, a := range h.allowedCommands {
+		entries = append(entries, strings.Join(a, " "))
+	}
+
+	return fmt.Errorf("command %q is not allowed by the -allowed-commands policy of the server (%s)", strings.Join(command, " "), strings.Join(entries, ", "))
+}
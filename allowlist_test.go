@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseAllowedCommands(t *testing.T) {
+	got := parseAllowedCommands(" golangci-lint, go tool golangci-lint ,,/opt/bin/lint")
+	want := []allowedCommand{{"golangci-lint"}, {"go", "tool", "golangci-lint"}, {"/opt/bin/lint"}}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseAllowedCommands() mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := parseAllowedCommands(""); got != nil {
+		t.Errorf("parseAllowedCommands(\"\") = %v, want no policy", got)
+	}
+}
+
+func TestLangHandler_checkAllowedCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the absolute path entry is a shell script")
+	}
+
+	bin := t.TempDir()
+	lint := filepath.Join(bin, "custom-lint")
+	if err := os.WriteFile(lint, []byte("#!/bin/sh\n"), 0o700); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		name    string
+		allowed string
+		command []string
+		wantErr bool
+	}{
+		{name: "no policy", command: []string{"./evil.sh"}},
+		{name: "default", allowed: defaultAllowedCommands, command: []string{"golangci-lint", "run"}},
+		{name: "default rejects others", allowed: defaultAllowedCommands, command: []string{"sh", "-c", "golangci-lint run"}, wantErr: true},
+		{name: "basename only matches PATH", allowed: defaultAllowedCommands, command: []string{"./golangci-lint", "run"}, wantErr: true},
+		{name: "exe extension", allowed: defaultAllowedCommands, command: []string{"golangci-lint.exe", "run"}},
+		{name: "wrapper", allowed: "go tool golangci-lint", command: []string{"go", "tool", "golangci-lint", "run"}},
+		{name: "wrapper with other arguments", allowed: "go tool golangci-lint", command: []string{"go", "run", "./evil"}, wantErr: true},
+		{name: "absolute path", allowed: lint, command: []string{lint, "run"}},
+		{name: "absolute path resolved from PATH", allowed: lint, command: []string{"custom-lint", "run"}},
+		{name: "other absolute path", allowed: "/usr/local/bin/golangci-lint", command: []string{lint}, wantErr: true},
+		{name: "empty command", allowed: defaultAllowedCommands},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &langHandler{allowedCommands: parseAllowedCommands(tt.allowed)}
+
+			err := h.checkAllowedCommand(tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkAllowedCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), "-allowed-commands") {
+				t.Errorf("checkAllowedCommand() error %q does not name the policy", err)
+			}
+		})
+	}
+}
+
+// TestLangHandler_allowedCommandsInitialize tests that initialize fails for a command
+// outside the policy, and that a later settings change cannot bring one in.
+func TestLangHandler_allowedCommandsInitialize(t *testing.T) {
+	h := newLangHandler(false)
+	h.allowedCommands = parseAllowedCommands(defaultAllowedCommands)
+	client := newTestClient(t, h)
+
+	err := client.conn.Call(context.Background(), "initialize", map[string]any{
+		"initializationOptions": map[string]any{"command": []string{"sh", "-c", "true"}},
+	}, nil)
+	if err == nil || !strings.Contains(err.Error(), "-allowed-commands") {
+		t.Fatalf("initialize error = %v, want the policy error", err)
+	}
+
+	client.call("initialize", map[string]any{
+		"initializationOptions": map[string]any{"command": []string{"golangci-lint", "run"}},
+	}, nil)
+
+	h.settingsMu.Lock()
+	problems := h.applyOptions(InitializationOptions{Command: []string{"sh", "-c", "true"}})
+	command := h.command
+	h.settingsMu.Unlock()
+
+	if len(problems) != 1 || !strings.Contains(problems[0], "-allowed-commands") {
+		t.Errorf("applyOptions() problems = %v, want the policy error", problems)
+	}
+
+	if diff := cmp.Diff([]string{"golangci-lint", "run"}, command); diff != "" {
+		t.Errorf("command mismatch (-want +got):\n%s", diff)
+	}
+}
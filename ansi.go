@@ -0,0 +1,21 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ansiRe matches ANSI escape sequences: CSI sequences such as colors and cursor
+// movements, OSC sequences such as hyperlinks, terminated by BEL or ST, and the
+// remaining two-byte escapes.
+var ansiRe = regexp.MustCompile("\x1b\\[[0-?]*[ -/]*[@-~]|\x1b\\][^\x07\x1b]*(?:\x07|\x1b\\\\)|\x1b[@-Z\\\\-_]")
+
+// stripANSI removes the ANSI escape sequences that wrapper scripts or color settings
+// leave in golangci-lint output, which editors would show as garbage.
+func stripANSI(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+
+	return ansiRe.ReplaceAllString(s, "")
+}
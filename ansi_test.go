@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "colored-line-number issue of --color=always",
+			in:   "\x1b[1mmain.go:3:6\x1b[0m: \x1b[1m\x1b[31mfunc `unused` is unused\x1b[0m (unused)",
+			want: "main.go:3:6: func `unused` is unused (unused)",
+		},
+		{
+			name: "colored log level of --color=always",
+			in:   "\x1b[33mWARN\x1b[0m [runner] The linter 'golint' is deprecated",
+			want: "WARN [runner] The linter 'golint' is deprecated",
+		},
+		{
+			name: "256 colors and erase line",
+			in:   "\x1b[2K\x1b[38;5;196merror\x1b[39m",
+			want: "error",
+		},
+		{
+			name: "hyperlink terminated by ST",
+			in:   "see \x1b]8;;https://golangci-lint.run/\x1b\\the docs\x1b]8;;\x1b\\",
+			want: "see the docs",
+		},
+		{
+			name: "title terminated by BEL",
+			in:   "\x1b]0;golangci-lint\x07done",
+			want: "done",
+		},
+		{
+			name: "plain text",
+			in:   "Error return value of `os.Remove` is not checked [1;2]",
+			want: "Error return value of `os.Remove` is not checked [1;2]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripANSI(tt.in); got != tt.want {
+				t.Errorf("stripANSI() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLangHandler_lintStripsANSI tests that colored issue texts and stderr excerpts
+// reach the diagnostics without escape sequences.
+func TestLangHandler_lintStripsANSI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake linter needs sh")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	var issue Issue
+	issue.FromLinter = "errcheck"
+	issue.Text = "\x1b[31mError return value is not checked\x1b[0m"
+	issue.Pos.Filename = "main.go"
+	issue.Pos.Line = 1
+
+	tests := []struct {
+		name    string
+		command []string
+		want    string
+	}{
+		{
+			name:    "issue text",
+			command: fakeLinter(t, GolangCILintResult{Issues: []Issue{issue}}),
+			want:    "errcheck: Error return value is not checked",
+		},
+		{
+			name:    "stderr excerpt",
+			command: []string{"sh", "-c", `printf '\033[31mERRO\033[0m Running error: context loading failed\n' >&2; exit 3`},
+			want:    "ERRO Running error: context loading failed\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &langHandler{rootDir: dir, command: tt.command}
+
+			results, err := h.lint(context.Background(), pathToURI(path))
+			if err != nil {
+				t.Fatalf("lint() returned unexpected error: %v", err)
+			}
+
+			diagnostics := results[pathToURI(path)]
+
+			if len(diagnostics) != 1 || diagnostics[0].Message != tt.want {
+				t.Errorf("lint() = %+v, want one diagnostic with message %q", diagnostics, tt.want)
+			}
+		})
+	}
+}
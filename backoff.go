@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// backoffThreshold is the number of consecutive identical failures after which runs are skipped.
+	backoffThreshold = 3
+	// backoffBaseDelay is the first delay once the threshold is reached; it doubles with every further failure.
+	backoffBaseDelay = 30 * time.Second
+	// backoffMaxDelay caps the delay between attempts.
+	backoffMaxDelay = 30 * time.Minute
+)
+
+// failureBackoff tracks consecutive failed lint runs per directory and decides when
+// runs should be skipped. A nil *failureBackoff never backs off.
+type failureBackoff struct {
+	threshold int
+	base      time.Duration
+	max       time.Duration
+
+	mu       sync.Mutex
+	failures map[string]*failureState
+}
+
+type failureState struct {
+	// signature identifies the failure; a different failure starts a new count.
+	signature string
+	count     int
+	// retryAt is the time before which runs are skipped, zero if not backing off.
+	retryAt time.Time
+	// diagnostics are published in place of the skipped runs.
+	diagnostics []Diagnostic
+}
+
+func newFailureBackoff() *failureBackoff {
+	return &failureBackoff{
+		threshold: backoffThreshold,
+		base:      backoffBaseDelay,
+		max:       backoffMaxDelay,
+	}
+}
+
+// skip returns the diagnostics of the last failure when runs for key are currently backed off.
+func (b *failureBackoff) skip(key string, now time.Time) ([]Diagnostic, bool) {
+	if b == nil {
+		return nil, false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, ok := b.failures[key]
+	if !ok || s.retryAt.IsZero() || !now.Before(s.retryAt) {
+		return nil, false
+	}
+
+	return s.diagnostics, true
+}
+
+// failure records a failed run for key and returns the diagnostics to publish for it,
+// annotated with the time of the next attempt once the run backs off.
+func (b *failureBackoff) failure(key, signature string, diagnostics []Diagnostic, now time.Time) []Diagnostic {
+	if b == nil {
+		return diagnostics
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures == nil {
+		b.failures = make(map[string]*failureState)
+	}
+
+	s, ok := b.failures[key]
+	if !ok || s.signature != signature {
+		s = &failureState{signature: signature}
+		b.failures[key] = s
+	}
+
+	s.count++
+	s.retryAt = time.Time{}
+	s.diagnostics = diagnostics
+
+	if s.count < b.threshold {
+		return diagnostics
+	}
+
+	s.retryAt = now.Add(b.delay(s.count))
+	s.diagnostics = backoffDiagnostics(diagnostics, s.count, s.retryAt)
+
+	return s.diagnostics
+}
+
+// delay returns how long runs are skipped after count consecutive failures.
+func (b *failureBackoff) delay(count int) time.Duration {
+	d := b.base
+	for i := b.threshold; i < count && d < b.max; i++ {
+		d *= 2
+	}
+
+	return min(d, b.max)
+}
+
+// success forgets the failures of key.
+func (b *failureBackoff) success(key string) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.failures, key)
+}
+
+// reset forgets all failures, for instance after the configuration changed.
+func (b *failureBackoff) reset() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = nil
+}
+
+// failureSignature returns the signature of a failed run, which errToDiagnostics
+// reports as a single error diagnostic without a source.
+func failureSignature(diagnostics []Diagnostic) (string, bool) {
+	if len(diagnostics) != 1 || diagnostics[0].Source != nil || diagnostics[0].Severity != DSError {
+		return "", false
+	}
+
+	return strings.TrimSpace(diagnostics[0].Message), true
+}
+
+func backoffDiagnostics(diagnostics []Diagnostic, count int, retryAt time.Time) []Diagnostic {
+	note := fmt.Sprintf("golangci-lint failed %d times in a row; next attempt after %s", count, retryAt.Format(time.TimeOnly))
+
+	annotated := make([]Diagnostic, len(diagnostics))
+	for i, d := range diagnostics {
+		d.Message = strings.TrimRight(d.Message, "\n") + "\n\n(" + note + ")"
+		annotated[i] = d
+	}
+
+	return annotated
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFailureBackoff tests the transitions of the failure backoff.
+func TestFailureBackoff(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	failed := []Diagnostic{{Severity: DSError, Message: "can't load config"}}
+
+	type step struct {
+		// action is one of "fail", "fail-other", "success" or "reset".
+		action string
+		// after is the time elapsed since now when the action happens.
+		after    time.Duration
+		wantSkip bool
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "below the threshold",
+			steps: []step{
+				{action: "fail"},
+				{action: "fail", wantSkip: false},
+			},
+		},
+		{
+			name: "backs off at the threshold",
+			steps: []step{
+				{action: "fail"},
+				{action: "fail"},
+				{action: "fail", wantSkip: true},
+			},
+		},
+		{
+			name: "retries once the delay elapsed",
+			steps: []step{
+				{action: "fail"},
+				{action: "fail"},
+				{action: "fail", after: 0, wantSkip: true},
+				{action: "fail", after: 31 * time.Second, wantSkip: true},
+			},
+		},
+		{
+			name: "a different failure starts over",
+			steps: []step{
+				{action: "fail"},
+				{action: "fail"},
+				{action: "fail-other", wantSkip: false},
+			},
+		},
+		{
+			name: "success resets",
+			steps: []step{
+				{action: "fail"},
+				{action: "fail"},
+				{action: "success"},
+				{action: "fail", wantSkip: false},
+			},
+		},
+		{
+			name: "reset forgets",
+			steps: []step{
+				{action: "fail"},
+				{action: "fail"},
+				{action: "fail", wantSkip: true},
+				{action: "reset", wantSkip: false},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newFailureBackoff()
+
+			for i, s := range tt.steps {
+				at := now.Add(s.after)
+
+				switch s.action {
+				case "fail":
+					b.failure("dir", "can't load config", failed, at)
+				case "fail-other":
+					b.failure("dir", "no go files", failed, at)
+				case "success":
+					b.success("dir")
+				case "reset":
+					b.reset()
+				}
+
+				if _, got := b.skip("dir", at); got != s.wantSkip {
+					t.Errorf("step %d: skip() = %v, want %v", i, got, s.wantSkip)
+				}
+			}
+		})
+	}
+}
+
+// TestFailureBackoffDelay tests that the delay doubles and is capped.
+func TestFailureBackoffDelay(t *testing.T) {
+	b := newFailureBackoff()
+
+	tests := []struct {
+		count int
+		want  time.Duration
+	}{
+		{count: 3, want: 30 * time.Second},
+		{count: 4, want: time.Minute},
+		{count: 5, want: 2 * time.Minute},
+		{count: 20, want: 30 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := b.delay(tt.count); got != tt.want {
+			t.Errorf("delay(%d) = %v, want %v", tt.count, got, tt.want)
+		}
+	}
+}
+
+// TestFailureBackoffNote tests that backed off diagnostics tell when the next attempt happens.
+func TestFailureBackoffNote(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	failed := []Diagnostic{{Severity: DSError, Message: "can't load config\n"}}
+
+	b := newFailureBackoff()
+	b.failure("dir", "x", failed, now)
+	b.failure("dir", "x", failed, now)
+	got := b.failure("dir", "x", failed, now)
+
+	want := "can't load config\n\n(golangci-lint failed 3 times in a row; next attempt after 12:00:30)"
+	if got[0].Message != want {
+		t.Errorf("message = %q, want %q", got[0].Message, want)
+	}
+
+	skipped, _ := b.skip("dir", now)
+	if skipped[0].Message != want {
+		t.Errorf("skipped message = %q, want %q", skipped[0].Message, want)
+	}
+
+	if failed[0].Message != "can't load config\n" {
+		t.Errorf("input diagnostics were modified: %q", failed[0].Message)
+	}
+}
+
+// TestFailureSignature tests which results count as failed runs.
+func TestFailureSignature(t *testing.T) {
+	tests := []struct {
+		name        string
+		diagnostics []Diagnostic
+		want        bool
+	}{
+		{name: "no issues", diagnostics: []Diagnostic{}, want: false},
+		{name: "run error", diagnostics: []Diagnostic{{Severity: DSError, Message: "boom"}}, want: true},
+		{name: "issue", diagnostics: []Diagnostic{{Severity: DSError, Source: pt("typecheck"), Message: "boom"}}, want: false},
+		{name: "timeout", diagnostics: []Diagnostic{{Severity: DSInformation, Message: "lint timed out after 1m0s"}}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := failureSignature(tt.diagnostics); got != tt.want {
+				t.Errorf("failureSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
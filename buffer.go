@@ -0,0 +1,46 @@
+package main
+
+import "strings"
+
+// applyChange applies a content change event to text. A nil range replaces the whole text.
+func applyChange(text string, r *Range, newText string) string {
+	if r == nil {
+		return newText
+	}
+
+	start := offsetOf(text, r.Start)
+	end := max(offsetOf(text, r.End), start)
+
+	return text[:start] + newText + text[end:]
+}
+
+// offsetOf converts an LSP position (0-based line, UTF-16 character offset) into a byte
+// offset in text. Positions past the end of a line or of the text are clamped.
+func offsetOf(text string, pos Position) int {
+	offset := 0
+	for range pos.Line {
+		i := strings.IndexByte(text[offset:], '\n')
+		if i < 0 {
+			return len(text)
+		}
+
+		offset += i + 1
+	}
+
+	line := text[offset:]
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	line = strings.TrimSuffix(line, "\r")
+
+	units := 0
+	for i, r := range line {
+		if units >= pos.Character {
+			return offset + i
+		}
+
+		units += utf16Len(string(r))
+	}
+
+	return offset + len(line)
+}
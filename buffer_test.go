@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func TestApplyChange(t *testing.T) {
+	rng := func(startLine, startChar, endLine, endChar int) *Range {
+		return &Range{Start: Position{Line: startLine, Character: startChar}, End: Position{Line: endLine, Character: endChar}}
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		r       *Range
+		newText string
+		want    string
+	}{
+		{
+			name:    "full replacement",
+			text:    "package a\n",
+			newText: "package b\n",
+			want:    "package b\n",
+		},
+		{
+			name:    "insertion",
+			text:    "package main\n\nfunc main() {}\n",
+			r:       rng(2, 13, 2, 13),
+			newText: " println() ",
+			want:    "package main\n\nfunc main() { println() }\n",
+		},
+		{
+			name:    "deletion across lines",
+			text:    "a\nb\nc\n",
+			r:       rng(0, 1, 2, 0),
+			newText: "",
+			want:    "ac\n",
+		},
+		{
+			name:    "utf-16 offsets",
+			text:    "s := \"😀x\"\n",
+			r:       rng(0, 8, 0, 9),
+			newText: "y",
+			want:    "s := \"😀y\"\n",
+		},
+		{
+			name:    "past the end",
+			text:    "a\n",
+			r:       rng(5, 0, 5, 0),
+			newText: "b",
+			want:    "a\nb",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyChange(tt.text, tt.r, tt.newText); got != tt.want {
+				t.Errorf("applyChange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
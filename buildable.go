@@ -0,0 +1,76 @@
+package main
+
+import (
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hasGoFiles reports whether dir holds Go files the go tool would consider, ignoring
+// those whose name starts with "_" or ".". Build constraints are not evaluated.
+func hasGoFiles(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		// golangci-lint reports unreadable directories itself.
+		return true
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// nothingToLint reports whether the package in dir is known to have no Go files to
+// lint: there are none, or golangci-lint said so the last time and its Go files did not
+// change since, for instance because build constraints exclude them all.
+func (h *langHandler) nothingToLint(dir string) bool {
+	dir = filepath.Clean(dir)
+
+	h.noGoFilesMu.Lock()
+	stamps, cached := h.noGoFiles[dir]
+	h.noGoFilesMu.Unlock()
+
+	// A file saved without a file watcher telling, such as one whose build constraint
+	// was removed, gets the directory linted again.
+	if cached && !maps.Equal(stamps, goFileStamps(dir)) {
+		h.forgetNoGoFiles(dir)
+		cached = false
+	}
+
+	return cached || !hasGoFiles(dir)
+}
+
+// markNoGoFiles remembers that golangci-lint found no Go files to lint in dir.
+func (h *langHandler) markNoGoFiles(dir string) {
+	dir = filepath.Clean(dir)
+	slog.Debug("no Go files to lint, skipping the directory until its files change", "dir", dir)
+
+	stamps := goFileStamps(dir)
+
+	h.noGoFilesMu.Lock()
+	if h.noGoFiles == nil {
+		h.noGoFiles = make(map[string]map[string]string)
+	}
+	h.noGoFiles[dir] = stamps
+	h.noGoFilesMu.Unlock()
+}
+
+// forgetNoGoFiles lets the directory of a changed file, or the changed directory
+// itself, be linted again.
+func (h *langHandler) forgetNoGoFiles(path string) {
+	path = filepath.Clean(path)
+
+	h.noGoFilesMu.Lock()
+	delete(h.noGoFiles, path)
+	delete(h.noGoFiles, filepath.Dir(path))
+	h.noGoFilesMu.Unlock()
+}
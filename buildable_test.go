@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHasGoFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  bool
+	}{
+		{name: "package", files: []string{"doc.go", "README.md"}, want: true},
+		{name: "tests only", files: []string{"a_test.go"}, want: true},
+		{name: "no Go files", files: []string{"README.md", "go.mod"}},
+		{name: "ignored files", files: []string{"_gen.go", ".#main.go"}},
+		{name: "directory named like a file", files: []string{"sub.go/"}},
+		{name: "empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.files {
+				var err error
+				if dirName, ok := strings.CutSuffix(name, "/"); ok {
+					err = os.Mkdir(filepath.Join(dir, dirName), 0o755)
+				} else {
+					err = os.WriteFile(filepath.Join(dir, name), nil, 0o600)
+				}
+				if err != nil {
+					t.Fatalf("creating %s returned unexpected error: %v", name, err)
+				}
+			}
+
+			if got := hasGoFiles(dir); got != tt.want {
+				t.Errorf("hasGoFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLangHandler_noGoFiles tests that a directory golangci-lint found no Go files in is
+// not linted again until a file of the directory changes.
+func TestLangHandler_noGoFiles(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "doc.go")
+	if err := os.WriteFile(path, []byte("//go:build ignore\n\npackage doc\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	runs := filepath.Join(t.TempDir(), "runs")
+	command := []string{"sh", "-c", `echo run >> "$0"; exit 5`, runs}
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(root)),
+		"initializationOptions": map[string]any{"command": command},
+	}, nil)
+
+	save := func() {
+		t.Helper()
+
+		before := len(client.received("textDocument/publishDiagnostics"))
+		client.notify("textDocument/didSave", map[string]any{"textDocument": map[string]any{"uri": string(pathToURI(path))}})
+
+		client.waitFor("textDocument/publishDiagnostics", func(json.RawMessage) bool {
+			return len(client.received("textDocument/publishDiagnostics")) > before
+		}, 5*time.Second)
+	}
+
+	countRuns := func() int {
+		b, _ := os.ReadFile(runs)
+
+		return strings.Count(string(b), "run")
+	}
+
+	save()
+	save()
+
+	if got := countRuns(); got != 1 {
+		t.Errorf("golangci-lint ran %d times, want once", got)
+	}
+
+	client.notify("workspace/didChangeWatchedFiles", map[string]any{
+		"changes": []map[string]any{{"uri": string(pathToURI(path)), "type": FCTChanged}},
+	})
+	save()
+
+	if got := countRuns(); got != 2 {
+		t.Errorf("golangci-lint ran %d times after the directory changed, want 2", got)
+	}
+
+	// Saving the file without its build constraint lints it again, without a watcher.
+	if err := os.WriteFile(path, []byte("package doc\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+	save()
+
+	if got := countRuns(); got != 3 {
+		t.Errorf("golangci-lint ran %d times after the file was saved, want 3", got)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"container/list"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+const (
+	// defaultCacheMaxEntries is the number of documents whose diagnostics are kept by default.
+	defaultCacheMaxEntries = 10000
+	// defaultCacheMaxBytes is the estimated size of the kept diagnostics by default.
+	defaultCacheMaxBytes = 64 << 20
+)
+
+// diagnosticsCache holds the diagnostics of the last completed lint of each document.
+// Beyond maxEntries documents or maxBytes of diagnostics, the least recently used
+// entries are evicted, except those of documents for which pinned reports true.
+type diagnosticsCache struct {
+	mu      sync.Mutex
+	entries map[DocumentURI]*list.Element
+	// lru orders the entries from the most to the least recently used.
+	lru   list.List
+	bytes int
+
+	// maxEntries and maxBytes bound the cache; zero means no bound.
+	maxEntries int
+	maxBytes   int
+	// pinned reports whether the entry of a document must be kept, such as an open one.
+	pinned func(DocumentURI) bool
+}
+
+type cacheEntry struct {
+	diagnostics []Diagnostic
+	// resultID identifies the inputs of the lint, see langHandler.resultID.
+	resultID string
+}
+
+type cacheItem struct {
+	uri   DocumentURI
+	entry cacheEntry
+	size  int
+}
+
+func (c *diagnosticsCache) get(uri DocumentURI) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[uri]
+	if !ok {
+		return cacheEntry{}, false
+	}
+
+	c.lru.MoveToFront(e)
+
+	return e.Value.(*cacheItem).entry, true
+}
+
+func (c *diagnosticsCache) set(uri DocumentURI, diagnostics []Diagnostic, resultID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[DocumentURI]*list.Element)
+	}
+
+	item := &cacheItem{
+		uri:   uri,
+		entry: cacheEntry{diagnostics: diagnostics, resultID: resultID},
+		size:  entrySize(uri, diagnostics, resultID),
+	}
+
+	if e, ok := c.entries[uri]; ok {
+		c.bytes -= e.Value.(*cacheItem).size
+		e.Value = item
+		c.lru.MoveToFront(e)
+	} else {
+		c.entries[uri] = c.lru.PushFront(item)
+	}
+	c.bytes += item.size
+
+	c.evict()
+}
+
+// clear drops every entry.
+func (c *diagnosticsCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = nil
+	c.lru.Init()
+	c.bytes = 0
+}
+
+// removeFunc drops the entries of the documents for which remove reports true and
+// returns those that had diagnostics.
+func (c *diagnosticsCache) removeFunc(remove func(DocumentURI) bool) []DocumentURI {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var removed []DocumentURI
+	for uri, e := range c.entries {
+		if !remove(uri) {
+			continue
+		}
+
+		item := e.Value.(*cacheItem)
+		if len(item.entry.diagnostics) > 0 {
+			removed = append(removed, uri)
+		}
+
+		c.lru.Remove(e)
+		delete(c.entries, uri)
+		c.bytes -= item.size
+	}
+
+	return removed
+}
+
+// setLimits changes the bounds of the cache, evicting entries beyond the new ones.
+func (c *diagnosticsCache) setLimits(maxEntries, maxBytes int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxEntries = maxEntries
+	c.maxBytes = maxBytes
+
+	c.evict()
+}
+
+// evict drops the least recently used unpinned entries until the cache is within its bounds.
+func (c *diagnosticsCache) evict() {
+	e := c.lru.Back()
+	for e != nil && c.overBudget() {
+		prev := e.Prev()
+
+		item := e.Value.(*cacheItem)
+		if c.pinned == nil || !c.pinned(item.uri) {
+			c.lru.Remove(e)
+			delete(c.entries, item.uri)
+			c.bytes -= item.size
+
+			slog.Debug("evicted cached diagnostics", "uri", item.uri, "entries", len(c.entries), "bytes", c.bytes)
+		}
+
+		e = prev
+	}
+}
+
+func (c *diagnosticsCache) overBudget() bool {
+	return (c.maxEntries > 0 && len(c.entries) > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes)
+}
+
+// entrySize estimates the memory held by a cache entry.
+func entrySize(uri DocumentURI, diagnostics []Diagnostic, resultID string) int {
+	const diagnosticOverhead = 128
+
+	size := len(uri) + len(resultID)
+	for _, d := range diagnostics {
+		if d.Data != nil && len(d.Data.Merged) > 0 {
+			size += entrySize("", d.Data.Merged, "")
+		}
+
+		size += diagnosticOverhead + len(d.Message)
+		if d.Data != nil {
+			size += len(d.Data.FullMessage)
+			if d.Data.UnusedNolint != nil {
+				size += len(d.Data.UnusedNolint.Directive)
+			}
+			for _, hunk := range d.Data.Hunks {
+				for _, l := range slices.Concat(hunk.Old, hunk.New) {
+					size += len(l)
+				}
+			}
+		}
+		for _, r := range d.RelatedInformation {
+			size += diagnosticOverhead + len(r.Location.URI) + len(r.Message)
+		}
+	}
+
+	return size
+}
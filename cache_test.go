@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestDiagnosticsCache_evict tests that the least recently used closed documents are
+// evicted beyond the budgets while open documents are kept.
+func TestDiagnosticsCache_evict(t *testing.T) {
+	uri := func(i int) DocumentURI { return DocumentURI(fmt.Sprintf("file:///project/f%d.go", i)) }
+	diagnostics := []Diagnostic{{Message: "unused: func `f` is unused"}}
+	size := entrySize(uri(0), diagnostics, "id")
+
+	// Documents 0 and 1 are open.
+	open := map[DocumentURI]bool{uri(0): true, uri(1): true}
+
+	tests := []struct {
+		name       string
+		maxEntries int
+		maxBytes   int
+		want       []int
+	}{
+		{name: "entry budget", maxEntries: 4, want: []int{0, 1, 2, 9}},
+		{name: "byte budget", maxBytes: 4 * size, want: []int{0, 1, 2, 9}},
+		{name: "budget below the open documents", maxEntries: 1, want: []int{0, 1}},
+		{name: "no budget", want: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &diagnosticsCache{pinned: func(uri DocumentURI) bool { return open[uri] }}
+			c.setLimits(tt.maxEntries, tt.maxBytes)
+
+			for i := range 10 {
+				c.set(uri(i), diagnostics, "id")
+
+				// Document 2 keeps being pulled, so it stays recently used.
+				c.get(uri(2))
+			}
+
+			var got []int
+			for i := range 10 {
+				if _, ok := c.get(uri(i)); ok {
+					got = append(got, i)
+				}
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("cached documents mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// commandCacheClean runs `golangci-lint cache clean`, for instance after a toolchain
+// upgrade left stale results behind, and lints the open documents again.
+const commandCacheClean = "golangci-lint.cacheClean"
+
+// cacheCleanCommand returns the command cleaning the cache of the golangci-lint run by
+// command: the arguments before its last "run" followed by "cache clean", so that
+// wrappers such as `go run <module>@<version> run` clean the cache of the same binary.
+func cacheCleanCommand(command []string) []string {
+	base, ok := commandBinary(command)
+	if !ok {
+		base = command[:1]
+	}
+
+	return append(slices.Clone(base), "cache", "clean")
+}
+
+// commandBinary returns the arguments of command before its last "run", which invoke
+// the golangci-lint binary itself. It fails when the command has no run subcommand.
+func commandBinary(command []string) ([]string, bool) {
+	for i := len(command) - 1; i > 0; i-- {
+		if command[i] == "run" {
+			return command[:i], true
+		}
+	}
+
+	return nil, false
+}
+
+// executeCacheClean starts cleaning the cache in the background and returns at once:
+// the outcome is reported with window/showMessage.
+func (h *langHandler) executeCacheClean() {
+	go h.cacheClean(h.serverContext())
+}
+
+// cacheClean runs the cache clean command from the workspace root, with the environment
+// of the lint runs, then drops the cached results and lints the open documents again.
+func (h *langHandler) cacheClean(ctx context.Context) {
+	h.settingsMu.RLock()
+	defer h.settingsMu.RUnlock()
+
+	root := h.rootDir
+	command, _ := expandCommand(h.command, root, root, root)
+	command = cacheCleanCommand(command)
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	setProcessGroup(cmd)
+	cmd.Dir = root
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	slog.Info("cleaning the golangci-lint cache", "command", cmd.Args)
+
+	message := ShowMessageParams{Type: MTInfo, Message: "golangci-lint cache cleaned"}
+	if err := h.runLintCommand(cmd); err != nil {
+		message = ShowMessageParams{
+			Type:    MTError,
+			Message: strings.TrimSpace(fmt.Sprintf("golangci-lint cache clean failed: %v\n%s", err, output.String())),
+		}
+	} else {
+		h.results.clear()
+		h.runs.clear()
+		h.backoff.reset()
+	}
+
+	if err := h.conn.Notify(ctx, "window/showMessage", &message); err != nil {
+		slog.Error("failed to show the cache clean result", "error", err)
+	}
+
+	if message.Type != MTError {
+		if err := h.lintOpenDocuments(ctx); err != nil {
+			slog.Error("failed to lint the open documents", "error", err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCacheCleanCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+		want    []string
+	}{
+		{
+			name:    "golangci-lint",
+			command: []string{"golangci-lint", "run", "--output.json.path", "stdout"},
+			want:    []string{"golangci-lint", "cache", "clean"},
+		},
+		{
+			name:    "go run",
+			command: []string{"go", "run", "github.com/golangci/golangci-lint/v2/cmd/golangci-lint@v2.1.0", "run", "--fast-only"},
+			want:    []string{"go", "run", "github.com/golangci/golangci-lint/v2/cmd/golangci-lint@v2.1.0", "cache", "clean"},
+		},
+		{
+			name:    "wrapper without run",
+			command: []string{"./scripts/lint.sh", "--json"},
+			want:    []string{"./scripts/lint.sh", "cache", "clean"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, cacheCleanCommand(tt.command)); diff != "" {
+				t.Errorf("cacheCleanCommand() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestLangHandler_cacheClean tests that the outcome of the cache clean is shown and
+// that a successful clean drops the cached results.
+func TestLangHandler_cacheClean(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake golangci-lint needs sh")
+	}
+
+	tests := []struct {
+		name        string
+		exit        string
+		wantType    MessageType
+		wantMessage string
+		wantCached  bool
+	}{
+		{name: "success", exit: "0", wantType: MTInfo, wantMessage: "golangci-lint cache cleaned"},
+		{name: "failure", exit: "3", wantType: MTError, wantMessage: "permission denied", wantCached: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			record := filepath.Join(t.TempDir(), "args")
+			script := `echo "$@" > "$0"; echo permission denied >&2; exit ` + tt.exit
+
+			h := newLangHandler(false)
+			client := newTestClient(t, h)
+
+			client.call("initialize", map[string]any{
+				"rootUri":               string(pathToURI(root)),
+				"initializationOptions": map[string]any{"command": []string{"sh", "-c", script, record, "run"}},
+			}, nil)
+
+			uri := pathToURI(filepath.Join(root, "main.go"))
+			h.results.set(uri, []Diagnostic{{Message: "stale"}}, "")
+
+			client.call("workspace/executeCommand", map[string]any{"command": commandCacheClean}, nil)
+
+			raw := client.waitFor("window/showMessage", func(json.RawMessage) bool { return true }, 5*time.Second)
+
+			var params ShowMessageParams
+			if err := json.Unmarshal(raw, &params); err != nil {
+				t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+			}
+
+			if params.Type != tt.wantType || !strings.Contains(params.Message, tt.wantMessage) {
+				t.Errorf("showMessage = %+v, want type %d containing %q", params, tt.wantType, tt.wantMessage)
+			}
+
+			if _, cached := h.results.get(uri); cached != tt.wantCached {
+				t.Errorf("results cached = %v, want %v", cached, tt.wantCached)
+			}
+
+			b, err := os.ReadFile(record)
+			if err != nil {
+				t.Fatalf("os.ReadFile() returned unexpected error: %v", err)
+			}
+
+			if got := strings.TrimSpace(string(b)); got != "cache clean" {
+				t.Errorf("cache clean ran with arguments %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// defaultCheckCommand is the command the check subcommand runs when none is given after --.
+var defaultCheckCommand = []string{"golangci-lint", "run", "--output.json.path", "stdout", "--show-stats=false", "--issues-exit-code=1"}
+
+const (
+	// exitIssues is the exit code of the check subcommand when issues were found.
+	exitIssues = 1
+	// exitUsage is the exit code used for invalid arguments and failed checks.
+	exitUsage = 2
+)
+
+// runCheck lints the packages below a directory the way the server does and prints
+// the diagnostics, to try a command or a config without an editor.
+func runCheck(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	noLinterName := fs.Bool("nolintername", false, "don't show a linter name in message")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: golangci-lint-langserver check [flags] [dir] [-- command...]\n\n")
+		fmt.Fprintf(fs.Output(), "Lint the packages below dir (default \".\") the way the server does and print the issues.\n")
+		fmt.Fprintf(fs.Output(), "The golangci-lint command defaults to %q.\n\n", strings.Join(defaultCheckCommand, " "))
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+
+		return exitUsage
+	}
+
+	rest := fs.Args()
+	command := defaultCheckCommand
+	if i := slices.Index(rest, "--"); i >= 0 {
+		rest, command = rest[:i], rest[i+1:]
+	}
+
+	if len(rest) > 1 || len(command) == 0 {
+		fs.Usage()
+
+		return exitUsage
+	}
+
+	dir := "."
+	if len(rest) == 1 {
+		dir = rest[0]
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitUsage
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		fmt.Fprintf(stderr, "golangci-lint-langserver: %s is not a directory\n", dir)
+
+		return exitUsage
+	}
+
+	h := newLangHandler(*noLinterName)
+	defer h.stop()
+
+	h.rootDir = dir
+
+	h.settingsMu.Lock()
+	problems := h.applyOptions(InitializationOptions{Command: command})
+	h.settingsMu.Unlock()
+
+	for _, p := range problems {
+		fmt.Fprintf(stderr, "golangci-lint-langserver: %s\n", p)
+	}
+
+	h.settingsMu.RLock()
+	results, err := h.packageDiagnostics(context.Background(), "./...", dir, true)
+	h.settingsMu.RUnlock()
+
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return exitUsage
+	}
+
+	if printDiagnostics(stdout, dir, results) > 0 {
+		return exitIssues
+	}
+
+	return 0
+}
+
+// printDiagnostics prints the diagnostics by file, relative to dir when below it, in
+// the file:line:column format of compilers, and returns their number.
+func printDiagnostics(w io.Writer, dir string, results map[DocumentURI][]Diagnostic) int {
+	uris := make([]DocumentURI, 0, len(results))
+	for uri := range results {
+		uris = append(uris, uri)
+	}
+	slices.Sort(uris)
+
+	n := 0
+	for _, uri := range uris {
+		path := uriToPath(string(uri))
+		if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+
+		for _, d := range results[uri] {
+			fmt.Fprintf(w, "%s:%d:%d: %s: %s\n", path, d.Range.Start.Line+1, d.Range.Start.Character+1, severityName(d.Severity), d.Message)
+			n++
+		}
+	}
+
+	return n
+}
+
+// severityName returns the name of a severity, as accepted by parseSeverity.
+func severityName(s DiagnosticSeverity) string {
+	switch s {
+	case DSError:
+		return "error"
+	case DSInformation:
+		return "info"
+	case DSHint:
+		return "hint"
+	default:
+		return "warning"
+	}
+}
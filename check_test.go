@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSubcommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		terminal bool
+		want     string
+		wantArgs []string
+	}{
+		{name: "editor without arguments", want: "serve"},
+		{name: "terminal without arguments", terminal: true, want: "help"},
+		{name: "flags only", args: []string{"-debug", "-log-file", "ls.log"}, want: "serve", wantArgs: []string{"-debug", "-log-file", "ls.log"}},
+		{name: "flags on a terminal", args: []string{"-debug"}, terminal: true, want: "serve", wantArgs: []string{"-debug"}},
+		{name: "serve", args: []string{"serve", "-debug"}, want: "serve", wantArgs: []string{"-debug"}},
+		{name: "check", args: []string{"check", "./cmd", "--", "golangci-lint", "run"}, want: "check", wantArgs: []string{"./cmd", "--", "golangci-lint", "run"}},
+		{name: "version", args: []string{"version"}, want: "version", wantArgs: []string{}},
+		{name: "help flag", args: []string{"-h"}, want: "help"},
+		{name: "unknown", args: []string{"lint"}, want: "lint", wantArgs: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotArgs := subcommand(tt.args, tt.terminal)
+			if got != tt.want {
+				t.Errorf("subcommand() = %q, want %q", got, tt.want)
+			}
+
+			if diff := cmp.Diff(tt.wantArgs, gotArgs); diff != "" {
+				t.Errorf("subcommand() args mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunCheck(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {\n\tf()\n}\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	issue := Issue{FromLinter: "errcheck", Text: "Error return value is not checked", Severity: "error"}
+	issue.Pos.Filename, issue.Pos.Line, issue.Pos.Column = "main.go", 4, 2
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+	}{
+		{
+			name:       "issues",
+			args:       append([]string{root, "--"}, fakeLinter(t, GolangCILintResult{Issues: []Issue{issue}})...),
+			wantCode:   exitIssues,
+			wantStdout: "main.go:4:2: error: errcheck: Error return value is not checked\n",
+		},
+		{
+			name: "no issues",
+			args: append([]string{root, "--"}, fakeLinter(t, GolangCILintResult{})...),
+		},
+		{
+			name:     "failure",
+			args:     []string{root, "--", "sh", "-c", "echo broken >&2; exit 3"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "missing directory",
+			args:     []string{filepath.Join(root, "missing")},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "two directories",
+			args:     []string{root, root},
+			wantCode: exitUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := runCheck(tt.args, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("runCheck() = %d, want %d; stderr:\n%s", code, tt.wantCode, stderr.String())
+			}
+
+			if got := stdout.String(); got != tt.wantStdout {
+				t.Errorf("runCheck() printed %q, want %q", got, tt.wantStdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// commandShowDocumentation opens the documentation URL given as argument.
+const commandShowDocumentation = "golangci-lint.showDocumentation"
+
+func (h *langHandler) handleTextDocumentCodeAction(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	var params CodeActionParams
+	if err := decodeParams(req, &params); err != nil {
+		return nil, err
+	}
+
+	if err := validateDocumentURI(params.TextDocument.URI); err != nil {
+		return nil, err
+	}
+
+	// The actions apply to the diagnostics a merged diagnostic stands for.
+	diagnostics := expandMerged(params.Context.Diagnostics)
+
+	actions := []CodeAction{}
+	actions = append(actions, h.formatActions(params.TextDocument.URI, diagnostics, params.Context.Only)...)
+	actions = append(actions, h.nolintActions(params.TextDocument.URI, diagnostics, params.Context.Only)...)
+	actions = append(actions, documentationActions(diagnostics)...)
+	actions = append(actions, recheckActions(params.TextDocument.URI, diagnostics)...)
+	actions = append(actions, dismissActions(params.TextDocument.URI, diagnostics)...)
+
+	return actions, nil
+}
+
+// documentationActions returns an action opening the documentation of each linter or
+// rule reported by the diagnostics.
+func documentationActions(diagnostics []Diagnostic) []CodeAction {
+	var actions []CodeAction
+
+	seen := make(map[string]bool)
+	for _, d := range diagnostics {
+		if d.Source == nil {
+			continue
+		}
+
+		doc := issueDocumentation(*d.Source, strings.TrimPrefix(d.Message, *d.Source+": "))
+		if seen[doc.url+doc.name] {
+			continue
+		}
+		seen[doc.url+doc.name] = true
+
+		title := "Learn more about " + doc.name
+		actions = append(actions, CodeAction{
+			Title:       title,
+			Diagnostics: []Diagnostic{d},
+			Command: &Command{
+				Title:     title,
+				Command:   commandShowDocumentation,
+				Arguments: []any{doc.url},
+			},
+		})
+	}
+
+	return actions
+}
+
+func (h *langHandler) executeShowDocumentation(ctx context.Context, params ExecuteCommandParams) error {
+	var url string
+	if len(params.Arguments) != 1 || json.Unmarshal(params.Arguments[0], &url) != nil || url == "" {
+		return invalidParams("%s: expected a URL argument", commandShowDocumentation)
+	}
+
+	if h.showDocument {
+		// The response is not awaited: it is read by the goroutine running this handler.
+		_, err := h.conn.DispatchCall(ctx, "window/showDocument", &ShowDocumentParams{URI: url, External: true})
+
+		return err
+	}
+
+	slog.Debug("client does not support window/showDocument", "url", url)
+
+	return h.conn.Notify(ctx, "window/showMessage", &ShowMessageParams{
+		Type:    MTInfo,
+		Message: fmt.Sprintf("Documentation: %s", url),
+	})
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// Commands handled by workspace/executeCommand.
+const (
+	// commandPause stops linting in response to document notifications.
+	commandPause = "golangci-lint.pause"
+	// commandResume lints the open documents and restores linting on document notifications.
+	commandResume = "golangci-lint.resume"
+	// commandStatus returns a StatusResult.
+	commandStatus = "golangci-lint.status"
+)
+
+// statusNotification sends a StatusResult to the client once initialized and whenever
+// linting is paused or resumed or diagnostics are dismissed, for statuslines to show.
+const statusNotification = "golangci-lint/status"
+
+// commands lists the commands advertised in the server capabilities.
+var commands = []string{commandPause, commandResume, commandStatus, commandExcludeRule, commandShowDocumentation, commandCacheClean, commandLintPackage, commandRecheckLinter, commandDismiss, commandUndismissAll, commandApplyFixes}
+
+func (h *langHandler) handleWorkspaceExecuteCommand(ctx context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	var params ExecuteCommandParams
+	if err := decodeParams(req, &params); err != nil {
+		return nil, err
+	}
+
+	switch params.Command {
+	case commandPause:
+		h.pause(ctx)
+
+		return nil, nil
+	case commandResume:
+		return nil, h.resume(ctx)
+	case commandStatus:
+		return h.status(), nil
+	case commandExcludeRule:
+		return nil, h.executeExcludeRule(ctx, params)
+	case commandShowDocumentation:
+		return nil, h.executeShowDocumentation(ctx, params)
+	case commandCacheClean:
+		h.executeCacheClean()
+
+		return nil, nil
+	case commandLintPackage:
+		return nil, h.executeLintPackage(ctx, params)
+	case commandRecheckLinter:
+		return nil, h.executeRecheckLinter(ctx, params)
+	case commandDismiss:
+		return nil, h.executeDismiss(ctx, params)
+	case commandUndismissAll:
+		return nil, h.executeUndismissAll(ctx)
+	case commandApplyFixes:
+		return nil, h.executeApplyFixes(ctx, params)
+	}
+
+	return nil, invalidParams("unknown command %q", params.Command)
+}
+
+// pause stops linting and publishing: running lints are cancelled, leaving their
+// previous diagnostics published, and queued ones are dropped.
+func (h *langHandler) pause(ctx context.Context) {
+	if h.paused.Swap(true) {
+		return
+	}
+
+	h.cancelRunningLints()
+	h.notifyStatus(ctx)
+}
+
+// resume publishes what was held back while paused, lints every open document once and
+// lets document notifications lint again.
+func (h *langHandler) resume(ctx context.Context) error {
+	if !h.paused.Swap(false) {
+		return nil
+	}
+
+	h.notifyStatus(ctx)
+	h.publishHeld()
+
+	return h.lintOpenDocuments(ctx)
+}
+
+// holdWhilePaused reports whether linting is paused, in which case the diagnostics
+// of the document are held back until resume.
+func (h *langHandler) holdWhilePaused(uri DocumentURI) bool {
+	if !h.paused.Load() {
+		return false
+	}
+
+	h.heldMu.Lock()
+	defer h.heldMu.Unlock()
+
+	if h.held == nil {
+		h.held = make(map[DocumentURI]bool)
+	}
+	h.held[uri] = true
+
+	return true
+}
+
+// publishHeld publishes the diagnostics held back while paused for the documents
+// that are closed: cleared with clearOnClose, the cached ones otherwise. Open
+// documents are linted again instead.
+func (h *langHandler) publishHeld() {
+	h.heldMu.Lock()
+	held := h.held
+	h.held = nil
+	h.heldMu.Unlock()
+
+	results := make(map[DocumentURI][]Diagnostic)
+
+	for uri := range held {
+		if h.isOpen(uri) {
+			continue
+		}
+
+		if h.clearOnClose {
+			h.publishedMu.Lock()
+			published := h.published[uri]
+			h.publishedMu.Unlock()
+
+			if published {
+				results[uri] = []Diagnostic{}
+			}
+
+			continue
+		}
+
+		if cached, ok := h.results.get(uri); ok {
+			results[uri] = cached.diagnostics
+		}
+	}
+
+	h.publishAll("", results)
+}
+
+// notifyStatus sends the status with statusNotification.
+func (h *langHandler) notifyStatus(ctx context.Context) {
+	if h.conn == nil {
+		return
+	}
+
+	if err := h.conn.Notify(ctx, statusNotification, h.status()); err != nil {
+		slog.Error("failed to send the status", "error", err)
+	}
+}
+
+// lintOpenDocuments queues a lint of every open document.
+func (h *langHandler) lintOpenDocuments(ctx context.Context) error {
+	for _, uri := range h.openDocuments() {
+		if err := h.enqueue(ctx, uri); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isOpen reports whether the document is open in the client.
+func (h *langHandler) isOpen(uri DocumentURI) bool {
+	h.openMu.Lock()
+	defer h.openMu.Unlock()
+
+	_, ok := h.open[uri]
+
+	return ok
+}
+
+// openDocuments returns the documents open in the client, sorted.
+func (h *langHandler) openDocuments() []DocumentURI {
+	h.openMu.Lock()
+	defer h.openMu.Unlock()
+
+	uris := make([]DocumentURI, 0, len(h.open))
+	for uri := range h.open {
+		uris = append(uris, uri)
+	}
+	slices.Sort(uris)
+
+	return uris
+}
+
+func (h *langHandler) status() StatusResult {
+	h.openMu.Lock()
+	defer h.openMu.Unlock()
+
+	return StatusResult{
+		Paused:            h.paused.Load(),
+		OpenDocuments:     len(h.open),
+		QueuedLints:       len(h.request),
+		HiddenDiagnostics: h.hiddenDiagnostics(),
+		Dismissed:         h.dismissed.len(),
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLangHandler_startPaused tests that a paused server only lints after golangci-lint.resume.
+func TestLangHandler_startPaused(t *testing.T) {
+	dir := t.TempDir()
+	uri := pathToURI(filepath.Join(dir, "main.go"))
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(dir)),
+		"initializationOptions": map[string]any{"command": []string{"true"}, "startPaused": true},
+	}, nil)
+
+	client.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": ""},
+	})
+
+	var status StatusResult
+	client.call("workspace/executeCommand", map[string]any{"command": commandStatus}, &status)
+	if !status.Paused || status.OpenDocuments != 1 {
+		t.Errorf("status = %+v, want paused with 1 open document", status)
+	}
+
+	if got := client.received("textDocument/publishDiagnostics"); len(got) != 0 {
+		t.Fatalf("expected no diagnostics while paused, got %d", len(got))
+	}
+
+	client.call("workspace/executeCommand", map[string]any{"command": commandResume}, nil)
+
+	client.waitFor("textDocument/publishDiagnostics", func(params json.RawMessage) bool {
+		var p PublishDiagnosticsParams
+
+		return json.Unmarshal(params, &p) == nil && p.URI == uri
+	}, 5*time.Second)
+
+	client.call("workspace/executeCommand", map[string]any{"command": commandStatus}, &status)
+	if status.Paused {
+		t.Errorf("status = %+v, want not paused after resume", status)
+	}
+}
+
+// TestLangHandler_pause tests that nothing is published while paused, even across a
+// configuration change, and that the paused state is sent with statusNotification.
+func TestLangHandler_pause(t *testing.T) {
+	dir := t.TempDir()
+	uri := pathToURI(filepath.Join(dir, "main.go"))
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(dir)),
+		"initializationOptions": map[string]any{"command": []string{"true"}},
+	}, nil)
+
+	status := func(paused bool) func(json.RawMessage) bool {
+		return func(params json.RawMessage) bool {
+			var s StatusResult
+
+			return json.Unmarshal(params, &s) == nil && s.Paused == paused
+		}
+	}
+	published := func(params json.RawMessage) bool {
+		var p PublishDiagnosticsParams
+
+		return json.Unmarshal(params, &p) == nil && p.URI == uri
+	}
+
+	client.waitFor(statusNotification, status(false), 5*time.Second)
+
+	client.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": ""},
+	})
+	client.waitFor("textDocument/publishDiagnostics", published, 5*time.Second)
+
+	client.call("workspace/executeCommand", map[string]any{"command": commandPause}, nil)
+	client.waitFor(statusNotification, status(true), 5*time.Second)
+
+	before := len(client.received("textDocument/publishDiagnostics"))
+
+	// The invalid option is reported once the settings are applied.
+	client.notify("workspace/didChangeConfiguration", map[string]any{
+		"settings": map[string]any{configurationSection: map[string]any{"minimumSeverity": "severe"}},
+	})
+	client.waitFor("window/showMessage", func(json.RawMessage) bool { return true }, 5*time.Second)
+
+	client.notify("textDocument/didSave", map[string]any{"textDocument": map[string]any{"uri": uri}})
+
+	var s StatusResult
+	client.call("workspace/executeCommand", map[string]any{"command": commandStatus}, &s)
+	if !s.Paused {
+		t.Errorf("status = %+v, want paused after the configuration change", s)
+	}
+
+	if got := len(client.received("textDocument/publishDiagnostics")); got != before {
+		t.Fatalf("got %d diagnostics notifications while paused, want none", got-before)
+	}
+
+	client.call("workspace/executeCommand", map[string]any{"command": commandResume}, nil)
+	client.waitFor(statusNotification, status(false), 5*time.Second)
+
+	client.waitFor("textDocument/publishDiagnostics", func(params json.RawMessage) bool {
+		return published(params) && len(client.received("textDocument/publishDiagnostics")) > before
+	}, 5*time.Second)
+}
+
+// TestLangHandler_pauseHoldsPublishing tests that opening and closing documents and
+// dismissing diagnostics publish nothing while paused, and that resume publishes then.
+func TestLangHandler_pauseHoldsPublishing(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\nfunc main() {\n\tf()\n}\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	issue := Issue{FromLinter: "errcheck", Text: "Error return value is not checked"}
+	issue.Pos.Filename, issue.Pos.Line, issue.Pos.Column = "main.go", 4, 2
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri": string(pathToURI(root)),
+		"initializationOptions": map[string]any{
+			"command":      fakeLinter(t, GolangCILintResult{Issues: []Issue{issue}}),
+			"lintOnOpen":   false,
+			"clearOnClose": true,
+		},
+	}, nil)
+
+	uri := pathToURI(path)
+	diagnostics := func(n int) func(json.RawMessage) bool {
+		return func(raw json.RawMessage) bool {
+			var params PublishDiagnosticsParams
+
+			return json.Unmarshal(raw, &params) == nil && params.URI == uri && len(params.Diagnostics) == n
+		}
+	}
+	open := func() {
+		client.notify("textDocument/didOpen", map[string]any{
+			"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": ""},
+		})
+	}
+	closeDocument := func() {
+		client.notify("textDocument/didClose", map[string]any{"textDocument": map[string]any{"uri": uri}})
+	}
+
+	open()
+	client.notify("textDocument/didSave", map[string]any{"textDocument": map[string]any{"uri": uri}})
+
+	var params PublishDiagnosticsParams
+	if err := json.Unmarshal(client.waitFor("textDocument/publishDiagnostics", diagnostics(1), 5*time.Second), &params); err != nil {
+		t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+	}
+
+	client.call("workspace/executeCommand", map[string]any{"command": commandPause}, nil)
+
+	before := len(client.received("textDocument/publishDiagnostics"))
+
+	client.call("workspace/executeCommand", map[string]any{
+		"command":   commandDismiss,
+		"arguments": []any{DismissArguments{URI: uri, Diagnostic: params.Diagnostics[0]}},
+	}, nil)
+	closeDocument()
+	open()
+	closeDocument()
+
+	var s StatusResult
+	client.call("workspace/executeCommand", map[string]any{"command": commandStatus}, &s)
+
+	if got := len(client.received("textDocument/publishDiagnostics")); got != before {
+		t.Fatalf("got %d diagnostics notifications while paused, want none", got-before)
+	}
+
+	// The document is closed by now, so its diagnostics are cleared.
+	client.call("workspace/executeCommand", map[string]any{"command": commandResume}, nil)
+	client.waitFor("textDocument/publishDiagnostics", func(raw json.RawMessage) bool {
+		return len(client.received("textDocument/publishDiagnostics")) > before && diagnostics(0)(raw)
+	}, 5*time.Second)
+}
+
+// TestLangHandler_executeUnknownCommand tests that unknown commands are rejected.
+func TestLangHandler_executeUnknownCommand(t *testing.T) {
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{}, nil)
+
+	err := client.conn.Call(context.Background(), "workspace/executeCommand", map[string]any{"command": "golangci-lint.nope"}, nil)
+	if err == nil {
+		t.Error("expected an error for an unknown command")
+	}
+}
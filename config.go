@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFileNames are the file names golangci-lint looks for when no config is specified.
+var configFileNames = []string{
+	".golangci.yml",
+	".golangci.yaml",
+	".golangci.toml",
+	".golangci.json",
+}
+
+// golangciConfig is the subset of the golangci-lint configuration the server reads.
+// Both v1 and v2 layouts are covered.
+type golangciConfig struct {
+	Version string `yaml:"version"`
+	Run     struct {
+		Timeout            string   `yaml:"timeout"`
+		RelativePathMode   string   `yaml:"relative-path-mode"`
+		SkipFiles          []string `yaml:"skip-files"`
+		SkipDirs           []string `yaml:"skip-dirs"`
+		SkipDirsUseDefault *bool    `yaml:"skip-dirs-use-default"`
+	} `yaml:"run"`
+	Issues struct {
+		ExcludeFiles          []string `yaml:"exclude-files"`
+		ExcludeDirs           []string `yaml:"exclude-dirs"`
+		ExcludeDirsUseDefault *bool    `yaml:"exclude-dirs-use-default"`
+	} `yaml:"issues"`
+	Linters struct {
+		Exclusions struct {
+			Paths []string `yaml:"paths"`
+		} `yaml:"exclusions"`
+	} `yaml:"linters"`
+}
+
+// defaultExcludeDirs are the directories golangci-lint v1 excludes unless
+// issues.exclude-dirs-use-default is false.
+var defaultExcludeDirs = []string{
+	"(^|/)vendor($|/)",
+	"(^|/)third_party($|/)",
+	"(^|/)testdata($|/)",
+	"(^|/)examples($|/)",
+	"(^|/)Godeps($|/)",
+	"(^|/)builtin($|/)",
+}
+
+// lintConfig holds the settings the server reads from a golangci-lint config file.
+type lintConfig struct {
+	path string
+
+	// timeout is the run.timeout of the config, or zero.
+	timeout time.Duration
+
+	// relativePathMode is the v2 run.relative-path-mode of the config, or "".
+	relativePathMode string
+
+	// excludeFiles are matched against the slash path of a file relative to the config directory.
+	excludeFiles []*regexp.Regexp
+	// excludeDirs are matched against the slash path of a file's directory relative to the config directory.
+	excludeDirs []*regexp.Regexp
+	// excludePaths are the v2 exclusion paths, matched against both the file and its directory.
+	excludePaths []*regexp.Regexp
+}
+
+// findConfigFile walks up from dir looking for a golangci-lint config file.
+func findConfigFile(dir string) string {
+	for {
+		for _, name := range configFileNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
+
+// loadLintConfig reads and compiles the exclude patterns of the config file at path.
+func loadLintConfig(path string) (*lintConfig, error) {
+	if filepath.Ext(path) == ".toml" {
+		return nil, fmt.Errorf("unsupported config format: %s", path)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseLintConfig(path, b)
+}
+
+// parseLintConfig compiles the exclude patterns from the YAML (or JSON) content of a config file.
+func parseLintConfig(path string, b []byte) (*lintConfig, error) {
+	var raw golangciConfig
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	c := &lintConfig{path: path, relativePathMode: raw.Run.RelativePathMode}
+
+	if raw.Run.Timeout != "" {
+		d, err := time.ParseDuration(raw.Run.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid run.timeout %q: %w", raw.Run.Timeout, err)
+		}
+
+		c.timeout = d
+	}
+
+	var err error
+	if c.excludeFiles, err = compilePatterns(raw.Issues.ExcludeFiles, raw.Run.SkipFiles); err != nil {
+		return nil, err
+	}
+
+	// v2 dropped the default directories in favour of presets for linters.exclusions.paths.
+	var defaultDirs []string
+	if raw.Version != "2" && useDefaultExcludeDirs(raw) {
+		defaultDirs = defaultExcludeDirs
+	}
+
+	if c.excludeDirs, err = compilePatterns(raw.Issues.ExcludeDirs, raw.Run.SkipDirs, defaultDirs); err != nil {
+		return nil, err
+	}
+
+	if c.excludePaths, err = compilePatterns(raw.Linters.Exclusions.Paths); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// useDefaultExcludeDirs reports whether a v1 config keeps the default excluded
+// directories. Like golangci-lint, the deprecated run.skip-dirs-use-default turns
+// them off too.
+func useDefaultExcludeDirs(raw golangciConfig) bool {
+	for _, use := range []*bool{raw.Issues.ExcludeDirsUseDefault, raw.Run.SkipDirsUseDefault} {
+		if use != nil && !*use {
+			return false
+		}
+	}
+
+	return true
+}
+
+func compilePatterns(lists ...[]string) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+
+	for _, list := range lists {
+		for _, p := range list {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid exclude pattern %q: %w", p, err)
+			}
+
+			patterns = append(patterns, re)
+		}
+	}
+
+	return patterns, nil
+}
+
+// excludes reports whether golangci-lint would drop every issue of the file at path.
+// Like golangci-lint, patterns are matched against slash-separated paths relative
+// to the directory of the config file.
+func (c *lintConfig) excludes(path string) bool {
+	rel, err := filepath.Rel(filepath.Dir(c.path), path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+
+	relFile := filepath.ToSlash(rel)
+	relDir := filepath.ToSlash(filepath.Dir(rel))
+
+	for _, re := range c.excludeFiles {
+		if re.MatchString(relFile) {
+			return true
+		}
+	}
+
+	for _, re := range c.excludeDirs {
+		if re.MatchString(relDir) {
+			return true
+		}
+	}
+
+	for _, re := range c.excludePaths {
+		if re.MatchString(relFile) || re.MatchString(relDir) {
+			return true
+		}
+	}
+
+	return false
+}
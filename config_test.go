@@ -0,0 +1,309 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLintConfigExcludes tests exclude patterns taken from real-world configs.
+func TestLintConfigExcludes(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		path   string
+		want   bool
+	}{
+		{
+			name: "v1 exclude-files generated suffix",
+			config: `
+issues:
+  exclude-files:
+    - ".*_gen\\.go$"
+`,
+			path: "internal/api/types_gen.go",
+			want: true,
+		},
+		{
+			name: "v1 exclude-files does not match other files",
+			config: `
+issues:
+  exclude-files:
+    - ".*_gen\\.go$"
+`,
+			path: "internal/api/types.go",
+			want: false,
+		},
+		{
+			name: "v1 exclude-files protobuf",
+			config: `
+issues:
+  exclude-files:
+    - ".*\\.pb\\.go$"
+    - "zz_generated.*\\.go$"
+`,
+			path: "pkg/apis/v1/zz_generated.deepcopy.go",
+			want: true,
+		},
+		{
+			name: "v1 exclude-dirs vendor",
+			config: `
+issues:
+  exclude-dirs:
+    - "(^|/)vendor($|/)"
+`,
+			path: "vendor/github.com/foo/bar/bar.go",
+			want: true,
+		},
+		{
+			name: "v1 exclude-dirs anchored at the root",
+			config: `
+issues:
+  exclude-dirs:
+    - "^hack"
+`,
+			path: "cmd/hack/main.go",
+			want: false,
+		},
+		{
+			name: "v1 deprecated run.skip-files",
+			config: `
+run:
+  skip-files:
+    - "mock_.*\\.go$"
+`,
+			path: "store/mock_store.go",
+			want: true,
+		},
+		{
+			name: "v1 default exclude-dirs",
+			config: `
+issues:
+  max-same-issues: 3
+`,
+			path: "pkg/testdata/fixture.go",
+			want: true,
+		},
+		{
+			name: "v1 default exclude-dirs do not match a prefix",
+			config: `
+issues:
+  max-same-issues: 3
+`,
+			path: "pkg/testdatagen/fixture.go",
+			want: false,
+		},
+		{
+			name: "v1 exclude-dirs-use-default off",
+			config: `
+issues:
+  exclude-dirs-use-default: false
+`,
+			path: "vendor/github.com/foo/bar/bar.go",
+			want: false,
+		},
+		{
+			name: "v1 deprecated run.skip-dirs-use-default off",
+			config: `
+run:
+  skip-dirs-use-default: false
+`,
+			path: "third_party/lib.go",
+			want: false,
+		},
+		{
+			name: "v2 has no default exclude-dirs",
+			config: `
+version: "2"
+`,
+			path: "vendor/github.com/foo/bar/bar.go",
+			want: false,
+		},
+		{
+			name: "v1 exclude-dirs matching a dot-dot directory",
+			config: `
+issues:
+  exclude-dirs:
+    - "^\\.\\.gen$"
+`,
+			path: "..gen/gen.go",
+			want: true,
+		},
+		{
+			name: "file outside the config directory",
+			config: `
+issues:
+  exclude-files:
+    - ".*"
+`,
+			path: "../other/main.go",
+			want: false,
+		},
+		{
+			name: "v2 exclusions paths matching a directory",
+			config: `
+version: "2"
+linters:
+  exclusions:
+    paths:
+      - third_party$
+      - builtin$
+      - examples$
+`,
+			path: "third_party/lib.go",
+			want: true,
+		},
+		{
+			name: "v2 exclusions paths matching a file",
+			config: `
+version: "2"
+linters:
+  exclusions:
+    paths:
+      - ".*_test\\.go$"
+`,
+			path: "foo/foo_test.go",
+			want: true,
+		},
+		{
+			name: "v2 formatters exclusions are ignored",
+			config: `
+version: "2"
+formatters:
+  exclusions:
+    paths:
+      - third_party$
+`,
+			path: "third_party/lib.go",
+			want: false,
+		},
+		{
+			name:   "json config",
+			config: `{"issues": {"exclude-files": ["_gen\\.go$"]}}`,
+			path:   "foo_gen.go",
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := filepath.FromSlash("/project")
+
+			c, err := parseLintConfig(filepath.Join(root, ".golangci.yml"), []byte(tt.config))
+			if err != nil {
+				t.Fatalf("parseLintConfig() returned unexpected error: %v", err)
+			}
+
+			got := c.excludes(filepath.Join(root, filepath.FromSlash(tt.path)))
+			if got != tt.want {
+				t.Errorf("excludes(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseLintConfigInvalidPattern tests that invalid regexps are reported.
+func TestParseLintConfigInvalidPattern(t *testing.T) {
+	config := `
+issues:
+  exclude-files:
+    - "**/*_gen.go"
+`
+	if _, err := parseLintConfig(".golangci.yml", []byte(config)); err == nil {
+		t.Error("parseLintConfig() expected an error for a glob pattern")
+	}
+}
+
+// TestFindConfigFile tests the upward config file discovery.
+func TestFindConfigFile(t *testing.T) {
+	dir, err := filepath.Abs("./testdata/monorepo/foo")
+	if err != nil {
+		t.Fatalf("filepath.Abs() returned unexpected error: %v", err)
+	}
+
+	want := filepath.Join(dir, ".golangci.yaml")
+	if got := findConfigFile(dir); got != want {
+		t.Errorf("findConfigFile() = %q, want %q", got, want)
+	}
+}
+
+// TestParseLintConfigTimeout tests reading run.timeout.
+func TestParseLintConfigTimeout(t *testing.T) {
+	c, err := parseLintConfig(".golangci.yml", []byte("run:\n  timeout: 5m\n"))
+	if err != nil {
+		t.Fatalf("parseLintConfig() returned unexpected error: %v", err)
+	}
+
+	if c.timeout != 5*time.Minute {
+		t.Errorf("timeout = %v, want %v", c.timeout, 5*time.Minute)
+	}
+}
+
+// TestLangHandler_configBaseDir tests that relative paths are resolved against the directory of the discovered config.
+func TestLangHandler_configBaseDir(t *testing.T) {
+	root, err := filepath.Abs("./testdata/monorepo")
+	if err != nil {
+		t.Fatalf("filepath.Abs() returned unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		config pathConfig
+		dir    string
+		want   string
+	}{
+		{
+			name: "discovered config in a subproject",
+			dir:  filepath.Join(root, "foo"),
+			want: filepath.Join(root, "foo"),
+		},
+		{
+			name:   "explicit config",
+			config: pathConfig{configFile: "foo/.golangci.yaml", configDir: "foo"},
+			dir:    filepath.Join(root, "bar"),
+			want:   root,
+		},
+		{
+			name:   "no config",
+			config: pathConfig{noConfig: true},
+			dir:    filepath.Join(root, "foo"),
+			want:   root,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &langHandler{rootDir: root, pathConfig: tt.config}
+
+			if got := h.configBaseDir(tt.dir); got != tt.want {
+				t.Errorf("configBaseDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLangHandler_discoverConfigFileCache tests that discovery is cached until the configs are reset.
+func TestLangHandler_discoverConfigFileCache(t *testing.T) {
+	dir := t.TempDir()
+	h := &langHandler{rootDir: dir}
+
+	if got := h.discoverConfigFile(dir); got != "" {
+		t.Fatalf("discoverConfigFile() = %q, want none", got)
+	}
+
+	path := filepath.Join(dir, ".golangci.yml")
+	if err := os.WriteFile(path, []byte("run:\n  timeout: 5m\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	if got := h.discoverConfigFile(dir); got != "" {
+		t.Errorf("discoverConfigFile() = %q before reset, want cached none", got)
+	}
+
+	h.resetLintConfigs()
+
+	if got := h.discoverConfigFile(dir); got != path {
+		t.Errorf("discoverConfigFile() = %q after reset, want %q", got, path)
+	}
+}
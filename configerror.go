@@ -0,0 +1,167 @@
+package main
+
+import (
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// configErrorMarkers are substrings of the errors golangci-lint reports for an invalid config.
+var configErrorMarkers = []string{
+	"can't load config",
+	"can't read config",
+	"failed to load config",
+	"while parsing config",
+	"config verification",
+	"jsonschema:",
+}
+
+// configPositionRes match the positions in config errors, most specific first:
+// "config.yml:12:3", "line 12, column 3", "(line 12)", "yaml: line 12:".
+var configPositionRes = []*regexp.Regexp{
+	regexp.MustCompile(`\.(?:ya?ml|toml|json):(\d+):(\d+)`),
+	regexp.MustCompile(`(?i)\bline (\d+),? column (\d+)`),
+	regexp.MustCompile(`(?i)\bline (\d+)`),
+}
+
+// isConfigError reports whether the message of a failed run is about the config file.
+func isConfigError(message string) bool {
+	lower := strings.ToLower(message)
+	for _, marker := range configErrorMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// configErrorPosition extracts the 1-based line, and column if any, named by a config error.
+func configErrorPosition(message string) (line, column int, ok bool) {
+	for _, re := range configPositionRes {
+		m := re.FindStringSubmatch(message)
+		if m == nil {
+			continue
+		}
+
+		line, _ = strconv.Atoi(m[1])
+		if len(m) > 2 {
+			column, _ = strconv.Atoi(m[2])
+		}
+
+		return line, column, line > 0
+	}
+
+	return 0, 0, false
+}
+
+// configErrorSelection returns the range selected when opening the config at a reported
+// position: the rest of the line from the column, or the whole line. It returns nil when
+// the line is out of the file.
+func configErrorSelection(src sourceLines, line, column int) *Range {
+	if line < 1 || line > len(src.lines) {
+		return nil
+	}
+
+	start := src.position(line, max(column, 1))
+	end := Position{Line: start.Line, Character: utf16Len(src.lines[start.Line])}
+
+	if start.Character >= end.Character {
+		start.Character = 0
+	}
+
+	return &Range{Start: start, End: end}
+}
+
+// configFileFor returns the config file golangci-lint uses when linting dir, or "".
+func (h *langHandler) configFileFor(dir string) string {
+	if h.pathConfig.noConfig {
+		return ""
+	}
+
+	if path := h.pathConfig.configFile; path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(h.projectRoot(dir), path)
+		}
+
+		return path
+	}
+
+	return h.discoverConfigFile(dir)
+}
+
+// offerConfigJump asks the user whether to open the config file when a failed run of
+// dir was caused by it, at the reported line when there is one. Each error is offered once.
+func (h *langHandler) offerConfigJump(dir, message string) {
+	if !h.showDocument || h.conn == nil || !isConfigError(message) {
+		return
+	}
+
+	path := h.configFileFor(dir)
+	if path == "" {
+		return
+	}
+
+	h.configPromptMu.Lock()
+	if h.configPrompted == message {
+		h.configPromptMu.Unlock()
+
+		return
+	}
+	h.configPrompted = message
+	h.configPromptMu.Unlock()
+
+	params := ShowDocumentParams{URI: string(pathToURI(path)), TakeFocus: true}
+	title := "Open " + filepath.Base(path)
+
+	if line, column, ok := configErrorPosition(message); ok {
+		src, err := readSourceLines(path)
+		if err == nil {
+			params.Selection = configErrorSelection(src, line, column)
+		}
+
+		if params.Selection != nil {
+			title += " at line " + strconv.Itoa(line)
+		}
+	}
+
+	// The prompt waits for the user and must not hold up the linter.
+	go func() {
+		ctx := h.lintContext()
+
+		var action *MessageActionItem
+		err := h.conn.Call(ctx, "window/showMessageRequest", &ShowMessageRequestParams{
+			Type:    MTError,
+			Message: "golangci-lint could not load its config: " + firstLine(message),
+			Actions: []MessageActionItem{{Title: title}},
+		}, &action)
+		if err != nil {
+			slog.Debug("failed to offer to open the config", "error", err)
+
+			return
+		}
+
+		if action == nil || action.Title != title {
+			return
+		}
+
+		if err := h.conn.Call(ctx, "window/showDocument", &params, nil); err != nil {
+			slog.Warn("failed to open the config", "path", path, "error", err)
+		}
+	}()
+}
+
+// resetConfigPrompt lets the current config error be offered again.
+func (h *langHandler) resetConfigPrompt() {
+	h.configPromptMu.Lock()
+	h.configPrompted = ""
+	h.configPromptMu.Unlock()
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+
+	return line
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestConfigErrorPosition tests extracting positions from golangci-lint config errors.
+func TestConfigErrorPosition(t *testing.T) {
+	tests := []struct {
+		name       string
+		message    string
+		wantLine   int
+		wantColumn int
+		wantOK     bool
+	}{
+		{
+			name:     "yaml syntax error",
+			message:  `level=error msg="Running error: can't load config: yaml: line 12: mapping values are not allowed in this context"`,
+			wantLine: 12,
+			wantOK:   true,
+		},
+		{
+			name:     "yaml unmarshal error",
+			message:  "Error: can't load config: yaml: unmarshal errors:\n  line 7: cannot unmarshal !!str `foo` into []string",
+			wantLine: 7,
+			wantOK:   true,
+		},
+		{
+			name:       "file position",
+			message:    "Error: can't load config: /project/.golangci.yml:4:3: unknown field \"lintrs\"",
+			wantLine:   4,
+			wantColumn: 3,
+			wantOK:     true,
+		},
+		{
+			name:       "line and column",
+			message:    "While parsing config: toml: line 3, column 9: expected value",
+			wantLine:   3,
+			wantColumn: 9,
+			wantOK:     true,
+		},
+		{
+			name:    "no position",
+			message: "Error: can't load config: jsonschema: '/linters' does not validate: additionalProperties 'enabel' not allowed",
+			wantOK:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !isConfigError(tt.message) {
+				t.Errorf("isConfigError() = false, want true")
+			}
+
+			line, column, ok := configErrorPosition(tt.message)
+			if line != tt.wantLine || column != tt.wantColumn || ok != tt.wantOK {
+				t.Errorf("configErrorPosition() = %d, %d, %v, want %d, %d, %v", line, column, ok, tt.wantLine, tt.wantColumn, tt.wantOK)
+			}
+		})
+	}
+
+	if isConfigError("main.go:3:1: expected 'package', found 'EOF'") {
+		t.Error("isConfigError() = true for a source error")
+	}
+}
+
+// TestConfigErrorSelection tests the range selected in the config.
+func TestConfigErrorSelection(t *testing.T) {
+	src := newSourceLines("linters:\n  enable:\n    - errcheck\n")
+
+	tests := []struct {
+		name   string
+		line   int
+		column int
+		want   *Range
+	}{
+		{name: "whole line", line: 2, want: &Range{Start: Position{Line: 1}, End: Position{Line: 1, Character: 9}}},
+		{name: "from the column", line: 3, column: 7, want: &Range{Start: Position{Line: 2, Character: 6}, End: Position{Line: 2, Character: 14}}},
+		{name: "column past the end", line: 1, column: 40, want: &Range{Start: Position{Line: 0}, End: Position{Line: 0, Character: 8}}},
+		{name: "line past the end", line: 40, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, configErrorSelection(src, tt.line, tt.column)); diff != "" {
+				t.Errorf("configErrorSelection() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestLangHandler_offerConfigJump tests opening the config at the reported line once the user accepts.
+func TestLangHandler_offerConfigJump(t *testing.T) {
+	root := t.TempDir()
+	config := filepath.Join(root, ".golangci.yml")
+	if err := os.WriteFile(config, []byte("linters:\n  enable\n    - errcheck\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+	client.respond("window/showMessageRequest", MessageActionItem{Title: "Open .golangci.yml at line 3"})
+
+	client.call("initialize", map[string]any{
+		"rootUri":      string(pathToURI(root)),
+		"capabilities": map[string]any{"window": map[string]any{"showDocument": map[string]any{"support": true}}},
+	}, nil)
+
+	h.offerConfigJump(root, "Error: can't load config: yaml: line 3: mapping values are not allowed in this context")
+
+	params := client.waitFor("window/showDocument", func(json.RawMessage) bool { return true }, 5*time.Second)
+
+	var got ShowDocumentParams
+	if err := json.Unmarshal(params, &got); err != nil {
+		t.Fatalf("invalid showDocument params: %v", err)
+	}
+
+	want := ShowDocumentParams{
+		URI:       string(pathToURI(config)),
+		TakeFocus: true,
+		Selection: &Range{Start: Position{Line: 2}, End: Position{Line: 2, Character: 14}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("showDocument mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"syscall"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// watchConnection stops the server work once the connection closes, so that a client
+// going away mid-lint neither keeps golangci-lint running nor floods the log with
+// failed publishes.
+func (h *langHandler) watchConnection(conn *jsonrpc2.Conn) {
+	<-conn.DisconnectNotify()
+
+	h.connectionClosed()
+	h.stop()
+}
+
+// connectionClosed cancels the running lints and stops publishing. It may run on the
+// linter goroutine, from a failed publish, so stopping the linter is left to
+// watchConnection.
+func (h *langHandler) connectionClosed() {
+	if !h.disconnected.CompareAndSwap(false, true) {
+		return
+	}
+
+	slog.Info("golangci-lint-langserver: connection closed, stopping")
+	h.lintCancel()
+}
+
+// connectionLost reports whether err means the connection to the client is gone: the
+// connection was closed, or writing to it failed before the read loop noticed.
+func connectionLost(err error) bool {
+	return errors.Is(err, jsonrpc2.ErrClosed) ||
+		errors.Is(err, io.ErrClosedPipe) ||
+		errors.Is(err, os.ErrClosed) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// notifyFailed reports whether a notification failed because the connection is gone,
+// in which case the server stops its work.
+func (h *langHandler) notifyFailed(err error) bool {
+	if !connectionLost(err) {
+		return false
+	}
+
+	h.connectionClosed()
+
+	return true
+}
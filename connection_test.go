@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for the concurrent writes of a logger.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// TestLangHandler_connectionClosed tests that the server stops linting and publishing
+// once the client disconnected, without an error per unpublished document.
+func TestLangHandler_connectionClosed(t *testing.T) {
+	var logs syncBuffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(t.TempDir())),
+		"initializationOptions": map[string]any{"command": []string{"sleep", "60"}},
+	}, nil)
+
+	lintCtx := h.lintContext()
+
+	if err := client.conn.Close(); err != nil {
+		t.Fatalf("Close() returned unexpected error: %v", err)
+	}
+
+	results := make(map[DocumentURI][]Diagnostic)
+	for i := range 1000 {
+		results[DocumentURI(fmt.Sprintf("file:///tmp/%d.go", i))] = []Diagnostic{{Message: "unused"}}
+	}
+
+	start := time.Now()
+	h.publishAll("", results)
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("publishAll() took %v after the connection closed", elapsed)
+	}
+
+	select {
+	case <-lintCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the lint context was not cancelled after the connection closed")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		h.mu.Lock()
+		closed := h.closed
+		h.mu.Unlock()
+
+		if closed {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("the linter did not stop after the connection closed")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if n := strings.Count(logs.String(), "failed to publish diagnostics"); n > 0 {
+		t.Errorf("logged %d publish failures, want none:\n%s", n, logs.String())
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultDebounce is the window within which the lints of the files of a directory,
+// such as those of a "save all", are coalesced into one.
+const defaultDebounce = 200 * time.Millisecond
+
+// stopper is the part of *time.Timer the debouncer uses.
+type stopper interface {
+	Stop() bool
+}
+
+// debouncer coalesces the lints requested for the same key within a window into a
+// single lint, once no request arrived for the window. The zero value lints right away.
+type debouncer struct {
+	// afterFunc is time.AfterFunc, unless replaced by tests.
+	afterFunc func(time.Duration, func()) stopper
+
+	mu      sync.Mutex
+	window  time.Duration
+	pending map[string]*pendingLint
+}
+
+// pendingLint is a coalesced lint waiting for its timer.
+type pendingLint struct {
+	uris  []DocumentURI
+	timer stopper
+	// generation counts the restarts of the window, telling the current timer apart.
+	generation int
+}
+
+func (d *debouncer) setWindow(window time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.window = window
+}
+
+// add requests a lint of uri under key. A request for a key already pending restarts
+// its window. fire is called with the requested documents, the last one last, once
+// the window elapsed, or right away without a window.
+func (d *debouncer) add(key string, uri DocumentURI, fire func([]DocumentURI)) {
+	d.mu.Lock()
+	if d.window <= 0 {
+		d.mu.Unlock()
+		fire([]DocumentURI{uri})
+
+		return
+	}
+	defer d.mu.Unlock()
+
+	p, ok := d.pending[key]
+	if ok {
+		p.timer.Stop()
+		p.uris = append(slices.DeleteFunc(p.uris, func(u DocumentURI) bool { return u == uri }), uri)
+		slog.Debug("coalescing lint requests", "key", key, "count", len(p.uris))
+	} else {
+		p = &pendingLint{uris: []DocumentURI{uri}}
+		if d.pending == nil {
+			d.pending = make(map[string]*pendingLint)
+		}
+		d.pending[key] = p
+	}
+
+	afterFunc := d.afterFunc
+	if afterFunc == nil {
+		afterFunc = func(window time.Duration, f func()) stopper { return time.AfterFunc(window, f) }
+	}
+
+	p.generation++
+	generation := p.generation
+	p.timer = afterFunc(d.window, func() {
+		d.mu.Lock()
+		// A timer stopped too late to prevent its call finds its window restarted.
+		if d.pending[key] != p || p.generation != generation {
+			d.mu.Unlock()
+
+			return
+		}
+		delete(d.pending, key)
+		d.mu.Unlock()
+
+		fire(p.uris)
+	})
+}
+
+// reset drops the pending lints.
+func (d *debouncer) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, p := range d.pending {
+		p.timer.Stop()
+	}
+	d.pending = nil
+}
+
+// queueDebounced hands the document to the linter once the debounce window of its
+// directory elapsed, as golangci-lint lints the whole directory of a document and
+// publishes the diagnostics of its other files. Documents linted alone, in file mode
+// or in an overlay, are debounced by themselves.
+func (h *langHandler) queueDebounced(uri DocumentURI) {
+	h.debounce.add(h.lintKey(uri), uri, func(uris []DocumentURI) {
+		// The lint of the last document covers the others.
+		h.queue(uris[len(uris)-1])
+	})
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeTimer is a timer of fakeClock, fired by the test.
+type fakeTimer struct {
+	window  time.Duration
+	f       func()
+	stopped bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	wasActive := !t.stopped
+	t.stopped = true
+
+	return wasActive
+}
+
+// fakeClock records the timers of a debouncer instead of starting them.
+type fakeClock struct {
+	timers []*fakeTimer
+}
+
+func (c *fakeClock) afterFunc(window time.Duration, f func()) stopper {
+	t := &fakeTimer{window: window, f: f}
+	c.timers = append(c.timers, t)
+
+	return t
+}
+
+// active returns the timers that were not stopped.
+func (c *fakeClock) active() []*fakeTimer {
+	var active []*fakeTimer
+	for _, t := range c.timers {
+		if !t.stopped {
+			active = append(active, t)
+		}
+	}
+
+	return active
+}
+
+func TestDebouncer(t *testing.T) {
+	tests := []struct {
+		name string
+		// adds are the key and document of each request, in order.
+		adds [][2]string
+		// fireStale also calls the stopped timers, as when a timer fires while being stopped.
+		fireStale bool
+		want      [][]DocumentURI
+	}{
+		{
+			name: "single save",
+			adds: [][2]string{{"/p", "file:///p/a.go"}},
+			want: [][]DocumentURI{{"file:///p/a.go"}},
+		},
+		{
+			name: "saves of a directory coalesce",
+			adds: [][2]string{{"/p", "file:///p/a.go"}, {"/p", "file:///p/b.go"}, {"/p", "file:///p/c.go"}},
+			want: [][]DocumentURI{{"file:///p/a.go", "file:///p/b.go", "file:///p/c.go"}},
+		},
+		{
+			name: "repeated save moves last",
+			adds: [][2]string{{"/p", "file:///p/a.go"}, {"/p", "file:///p/b.go"}, {"/p", "file:///p/a.go"}},
+			want: [][]DocumentURI{{"file:///p/b.go", "file:///p/a.go"}},
+		},
+		{
+			name: "directories are separate",
+			adds: [][2]string{{"/p", "file:///p/a.go"}, {"/q", "file:///q/b.go"}},
+			want: [][]DocumentURI{{"file:///p/a.go"}, {"file:///q/b.go"}},
+		},
+		{
+			name:      "restarted window ignores the previous timer",
+			adds:      [][2]string{{"/p", "file:///p/a.go"}, {"/p", "file:///p/b.go"}},
+			fireStale: true,
+			want:      [][]DocumentURI{{"file:///p/a.go", "file:///p/b.go"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{}
+			d := &debouncer{afterFunc: clock.afterFunc}
+			d.setWindow(defaultDebounce)
+
+			var fired [][]DocumentURI
+			for _, add := range tt.adds {
+				d.add(add[0], DocumentURI(add[1]), func(uris []DocumentURI) { fired = append(fired, uris) })
+			}
+
+			if len(fired) != 0 {
+				t.Fatalf("lints fired before the window elapsed: %v", fired)
+			}
+
+			timers := clock.active()
+			if tt.fireStale {
+				timers = clock.timers
+			}
+
+			for _, timer := range timers {
+				if timer.window != defaultDebounce {
+					t.Errorf("timer window = %v, want %v", timer.window, defaultDebounce)
+				}
+				timer.f()
+			}
+
+			if diff := cmp.Diff(tt.want, fired); diff != "" {
+				t.Errorf("fired lints mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDebouncer_pendingAfterFire(t *testing.T) {
+	clock := &fakeClock{}
+	d := &debouncer{afterFunc: clock.afterFunc}
+	d.setWindow(defaultDebounce)
+
+	var fired [][]DocumentURI
+	fire := func(uris []DocumentURI) { fired = append(fired, uris) }
+
+	d.add("/p", "file:///p/a.go", fire)
+	clock.active()[0].f()
+
+	// A save arriving once the lint fired starts a new window rather than being dropped.
+	d.add("/p", "file:///p/b.go", fire)
+	if active := clock.active(); len(active) != 2 {
+		t.Fatalf("got %d timers, want a new one for the second save", len(active))
+	}
+	clock.timers[1].f()
+
+	want := [][]DocumentURI{{"file:///p/a.go"}, {"file:///p/b.go"}}
+	if diff := cmp.Diff(want, fired); diff != "" {
+		t.Errorf("fired lints mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDebouncer_noWindow(t *testing.T) {
+	clock := &fakeClock{}
+	d := &debouncer{afterFunc: clock.afterFunc}
+
+	var fired []DocumentURI
+	d.add("/p", "file:///p/a.go", func(uris []DocumentURI) { fired = append(fired, uris...) })
+
+	if len(fired) != 1 || len(clock.timers) != 0 {
+		t.Errorf("fired %v with %d timers, want the lint right away", fired, len(clock.timers))
+	}
+}
+
+// TestLangHandler_debounceSaves tests that the saves of two files of a directory within
+// the debounce window run golangci-lint once and publish the diagnostics of both.
+func TestLangHandler_debounceSaves(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake linter needs sh")
+	}
+
+	root := t.TempDir()
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("package main\n"), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	runs := filepath.Join(t.TempDir(), "runs")
+	command := []string{"sh", "-c", `echo run >> "$0"`, runs}
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(root)),
+		"initializationOptions": map[string]any{"command": command, "lintOnOpen": false, "debounceMs": 100},
+	}, nil)
+
+	a, b := pathToURI(filepath.Join(root, "a.go")), pathToURI(filepath.Join(root, "b.go"))
+	for _, uri := range []DocumentURI{a, b} {
+		client.notify("textDocument/didOpen", map[string]any{
+			"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": "package main\n"},
+		})
+	}
+	for _, uri := range []DocumentURI{a, b} {
+		client.notify("textDocument/didSave", map[string]any{"textDocument": map[string]any{"uri": uri}})
+	}
+
+	for _, uri := range []DocumentURI{a, b} {
+		client.waitFor("textDocument/publishDiagnostics", func(raw json.RawMessage) bool {
+			var params PublishDiagnosticsParams
+
+			return json.Unmarshal(raw, &params) == nil && params.URI == uri
+		}, 5*time.Second)
+	}
+
+	out, err := os.ReadFile(runs)
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned unexpected error: %v", err)
+	}
+	if n := strings.Count(string(out), "run"); n != 1 {
+		t.Errorf("golangci-lint ran %d times, want once", n)
+	}
+}
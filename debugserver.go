@@ -0,0 +1,52 @@
+package main
+
+import (
+	"expvar"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+)
+
+// debugMux serves the profiles of net/http/pprof under /debug/pprof/, the expvar
+// variables, which include the metrics of the server, on /debug/vars and the metrics
+// in the Prometheus text format on /metrics.
+func debugMux(h *langHandler) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		h.writePrometheus(w)
+	})
+
+	return mux
+}
+
+// serveDebug starts the debug HTTP listener of -pprof-addr in the background. The
+// metrics are published as the "metrics" expvar variable, so it must be called once.
+func serveDebug(addr string, h *langHandler) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	expvar.Publish("metrics", expvar.Func(func() any { return h.metricsSnapshot() }))
+
+	slog.Info("serving profiles and metrics", "addr", l.Addr().String())
+
+	go func() {
+		if err := http.Serve(l, debugMux(h)); err != nil {
+			slog.Error("debug HTTP listener stopped", "error", err)
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// migrationGuideURL documents the flag and config changes of golangci-lint v2.
+const migrationGuideURL = "https://golangci-lint.run/product/migration-guide/"
+
+// deprecatedFlag is a golangci-lint flag that was deprecated or removed.
+type deprecatedFlag struct {
+	// name is the flag without its leading dashes.
+	name        string
+	replacement string
+	// v2 is set for the flags removed by v2 but still valid with v1, which the
+	// command alone cannot tell apart from a v1 setup.
+	v2 bool
+}
+
+// deprecatedFlags are the flags detected in the command and in the golangci-lint stderr.
+var deprecatedFlags = []deprecatedFlag{
+	{name: "deadline", replacement: "--timeout"},
+	{name: "skip-dirs", replacement: "issues.exclude-dirs in the config"},
+	{name: "skip-files", replacement: "issues.exclude-files in the config"},
+	{name: "skip-dirs-use-default", replacement: "issues.exclude-dirs-use-default in the config"},
+	{name: "out-format", replacement: "--output.<format>.path, e.g. --output.json.path stdout", v2: true},
+	{name: "disable-all", replacement: "--default=none", v2: true},
+	{name: "enable-all", replacement: "--default=all", v2: true},
+	{name: "fast", replacement: "--fast-only", v2: true},
+	{name: "presets", replacement: "explicit linters in the config", v2: true},
+	{name: "exclude", replacement: "linters.exclusions.rules in the config", v2: true},
+	{name: "exclude-use-default", replacement: "linters.exclusions.presets in the config", v2: true},
+	{name: "print-issued-lines", replacement: "--output.text.print-issued-lines", v2: true},
+	{name: "print-linter-name", replacement: "--output.text.print-linter-name", v2: true},
+	{name: "sort-results", replacement: "output.sort-order in the config", v2: true},
+	{name: "uniq-by-line", replacement: "issues.uniq-by-line in the config", v2: true},
+}
+
+// commandDeprecatedFlag returns the first flag of command deprecated in every golangci-lint version.
+func commandDeprecatedFlag(command []string) (deprecatedFlag, bool) {
+	for _, arg := range command {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+
+		for _, f := range deprecatedFlags {
+			if !f.v2 && f.name == name {
+				return f, true
+			}
+		}
+	}
+
+	return deprecatedFlag{}, false
+}
+
+// stderrDeprecatedFlag returns the flag a golangci-lint stderr line complains about,
+// such as "Error: unknown flag: --out-format" or a deprecation warning.
+func stderrDeprecatedFlag(line string) (deprecatedFlag, bool) {
+	lower := strings.ToLower(line)
+	if !strings.Contains(lower, "unknown flag") && !strings.Contains(lower, "deprecated") {
+		return deprecatedFlag{}, false
+	}
+
+	for _, f := range deprecatedFlags {
+		for _, quoted := range []string{"--" + f.name, "'" + f.name + "'", `"` + f.name + `"`} {
+			i := strings.Index(line, quoted)
+			if i < 0 {
+				continue
+			}
+
+			// --exclude must not match --exclude-use-default.
+			if end := i + len(quoted); end < len(line) && (line[end] == '-' || isWordByte(line[end])) {
+				continue
+			}
+
+			return f, true
+		}
+	}
+
+	return deprecatedFlag{}, false
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// warnDeprecatedFlag tells the user once per session about a deprecated flag.
+func (h *langHandler) warnDeprecatedFlag(f deprecatedFlag) {
+	if h.conn == nil || h.deprecatedFlagWarned.Swap(true) {
+		return
+	}
+
+	slog.Warn("golangci-lint flag is deprecated", "flag", f.name, "replacement", f.replacement)
+
+	if err := h.conn.Notify(context.Background(), "window/showMessage", &ShowMessageParams{
+		Type:    MTWarning,
+		Message: fmt.Sprintf("golangci-lint-langserver: the --%s flag is deprecated or removed, use %s instead; see %s", f.name, f.replacement, migrationGuideURL),
+	}); err != nil {
+		slog.Error("failed to show message", "error", err)
+	}
+}
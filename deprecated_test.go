@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandDeprecatedFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+		want    string
+	}{
+		{name: "deadline", command: []string{"golangci-lint", "run", "--deadline=5m"}, want: "deadline"},
+		{name: "skip-dirs", command: []string{"golangci-lint", "run", "--skip-dirs", "gen"}, want: "skip-dirs"},
+		{name: "v1 output flag", command: []string{"golangci-lint", "run", "--out-format", "json"}},
+		{name: "argument", command: []string{"golangci-lint", "run", "deadline"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := commandDeprecatedFlag(tt.command)
+			if ok != (tt.want != "") || f.name != tt.want {
+				t.Errorf("commandDeprecatedFlag() = (%q, %v), want %q", f.name, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestStderrDeprecatedFlag(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{name: "unknown flag", line: "Error: unknown flag: --out-format", want: "out-format"},
+		{name: "prefix of another flag", line: "Error: unknown flag: --exclude-use-default", want: "exclude-use-default"},
+		{name: "deprecation warning", line: `level=warning msg="[config_reader] The flag 'deadline' is deprecated, use 'timeout' instead"`, want: "deadline"},
+		{name: "unrelated warning", line: `level=warning msg="[runner] Can't run linter goanalysis_metalinter: buildir: failed to load package"`},
+		{name: "unknown flag not in the table", line: "Error: unknown flag: --frobnicate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := stderrDeprecatedFlag(tt.line)
+			if ok != (tt.want != "") || f.name != tt.want {
+				t.Errorf("stderrDeprecatedFlag(%q) = (%q, %v), want %q", tt.line, f.name, ok, tt.want)
+			}
+		})
+	}
+}
+
+// TestLangHandler_warnDeprecatedFlag tests that deprecated flags are reported once per session.
+func TestLangHandler_warnDeprecatedFlag(t *testing.T) {
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"initializationOptions": map[string]any{"command": []string{"golangci-lint", "run", "--deadline=5m"}},
+	}, nil)
+
+	h.logStderrLine("Error: unknown flag: --out-format")
+
+	raw := client.waitFor("window/showMessage", func(json.RawMessage) bool { return true }, 5*time.Second)
+
+	var params ShowMessageParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"--deadline", "--timeout", migrationGuideURL} {
+		if !strings.Contains(params.Message, want) {
+			t.Errorf("showMessage %q does not contain %q", params.Message, want)
+		}
+	}
+
+	// The status request is answered after the notifications sent before it.
+	client.call("workspace/executeCommand", map[string]any{"command": commandStatus}, nil)
+
+	if n := len(client.received("window/showMessage")); n != 1 {
+		t.Errorf("got %d showMessage notifications, want 1", n)
+	}
+}
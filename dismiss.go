@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	// commandDismiss hides a diagnostic for the rest of the session.
+	commandDismiss = "golangci-lint.dismiss"
+	// commandUndismissAll brings back the dismissed diagnostics.
+	commandUndismissAll = "golangci-lint.undismissAll"
+)
+
+// dismissLineSlack is the number of lines a dismissed diagnostic may move, as lines
+// are added or removed above it, and still be recognized.
+const dismissLineSlack = 3
+
+// dismissDigitsRe matches the numbers of a message, which are ignored when matching
+// dismissals so that counts such as a cyclomatic complexity may change.
+var dismissDigitsRe = regexp.MustCompile(`\d+`)
+
+// DismissArguments is the argument of the golangci-lint.dismiss command.
+type DismissArguments struct {
+	URI        DocumentURI `json:"uri"`
+	Diagnostic Diagnostic  `json:"diagnostic"`
+}
+
+// dismissal identifies a dismissed diagnostic by its document, linter and normalized
+// message. line follows the diagnostic as it moves.
+type dismissal struct {
+	uri     DocumentURI
+	linter  string
+	message string
+	line    int
+}
+
+// dismissals is the set of diagnostics dismissed during the session.
+type dismissals struct {
+	mu      sync.Mutex
+	entries []*dismissal
+	// generation counts the changes of the set, which change the results of the documents.
+	generation uint64
+}
+
+func newDismissal(uri DocumentURI, d Diagnostic) *dismissal {
+	var linter string
+	if d.Source != nil {
+		linter = *d.Source
+	}
+
+	return &dismissal{uri: uri, linter: linter, message: normalizeDismissedMessage(d.Message), line: d.Range.Start.Line}
+}
+
+// normalizeDismissedMessage returns the message without the numbers and the
+// differences in spacing that small edits may change.
+func normalizeDismissedMessage(message string) string {
+	return strings.Join(strings.Fields(dismissDigitsRe.ReplaceAllString(message, "0")), " ")
+}
+
+// matches reports whether d is the dismissed diagnostic.
+func (e *dismissal) matches(d *dismissal) bool {
+	return e.uri == d.uri && e.linter == d.linter && e.message == d.message && abs(e.line-d.line) <= dismissLineSlack
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+func (s *dismissals) add(uri DocumentURI, d Diagnostic) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = append(s.entries, newDismissal(uri, d))
+	s.generation++
+}
+
+// filter returns the diagnostics of the document that were not dismissed. Each
+// dismissal hides the closest matching diagnostic, so that identical issues on
+// neighbouring lines are dismissed one by one, and moves to its line.
+func (s *dismissals) filter(uri DocumentURI, diagnostics []Diagnostic) []Diagnostic {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.entries) == 0 {
+		return diagnostics
+	}
+
+	candidates := make([]*dismissal, len(diagnostics))
+	for i, d := range diagnostics {
+		candidates[i] = newDismissal(uri, d)
+	}
+
+	hidden := make([]bool, len(diagnostics))
+	for _, e := range s.entries {
+		closest := -1
+		for i, c := range candidates {
+			if hidden[i] || !e.matches(c) {
+				continue
+			}
+
+			if closest < 0 || abs(c.line-e.line) < abs(candidates[closest].line-e.line) {
+				closest = i
+			}
+		}
+
+		if closest >= 0 {
+			hidden[closest] = true
+			e.line = candidates[closest].line
+		}
+	}
+
+	kept := make([]Diagnostic, 0, len(diagnostics))
+	for i, d := range diagnostics {
+		if !hidden[i] {
+			kept = append(kept, d)
+		}
+	}
+
+	if n := len(diagnostics) - len(kept); n > 0 {
+		slog.Debug("hiding dismissed diagnostics", "uri", uri, "count", n)
+	}
+
+	return kept
+}
+
+// clear empties the set and returns the number of dismissals it held.
+func (s *dismissals) clear() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.entries)
+	s.entries = nil
+	s.generation++
+
+	return n
+}
+
+// version returns the generation of the set.
+func (s *dismissals) version() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.generation
+}
+
+func (s *dismissals) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.entries)
+}
+
+// dismissActions returns an action dismissing each diagnostic for the session.
+func dismissActions(uri DocumentURI, diagnostics []Diagnostic) []CodeAction {
+	var actions []CodeAction
+
+	for _, d := range diagnostics {
+		if d.Source == nil {
+			continue
+		}
+
+		title := "Dismiss " + *d.Source + " issue for this session"
+		actions = append(actions, CodeAction{
+			Title:       title,
+			Diagnostics: []Diagnostic{d},
+			Command: &Command{
+				Title:     title,
+				Command:   commandDismiss,
+				Arguments: []any{DismissArguments{URI: uri, Diagnostic: d}},
+			},
+		})
+	}
+
+	return actions
+}
+
+// executeDismiss records the dismissal and publishes the last diagnostics of the
+// document without the dismissed one.
+func (h *langHandler) executeDismiss(ctx context.Context, params ExecuteCommandParams) error {
+	if len(params.Arguments) != 1 {
+		return invalidParams("%s: expected one argument", commandDismiss)
+	}
+
+	var args DismissArguments
+	if err := json.Unmarshal(params.Arguments[0], &args); err != nil {
+		return invalidParams("%s: invalid argument: %v", commandDismiss, err)
+	}
+
+	if err := validateDocumentURI(args.URI); err != nil {
+		return err
+	}
+
+	// The dismissal changes the resultID: a result that was current stays so under the new one.
+	current := h.resultID(args.URI)
+
+	h.dismissed.add(args.URI, args.Diagnostic)
+	slog.Info("dismissed diagnostic", "uri", args.URI, "message", args.Diagnostic.Message)
+
+	if cached, ok := h.results.get(args.URI); ok {
+		resultID := cached.resultID
+		if resultID == current {
+			resultID = h.resultID(args.URI)
+		}
+
+		diagnostics := h.mergeSameRange(h.dismissed.filter(args.URI, expandMerged(cached.diagnostics)))
+		h.results.set(args.URI, diagnostics, resultID)
+		if !h.holdWhilePaused(args.URI) {
+			h.publishAll("", map[DocumentURI][]Diagnostic{args.URI: diagnostics})
+		}
+	}
+
+	h.notifyStatus(ctx)
+
+	return nil
+}
+
+// executeUndismissAll clears the dismissals and lints the open documents again, as
+// the dismissed diagnostics were dropped from the cached results.
+func (h *langHandler) executeUndismissAll(ctx context.Context) error {
+	if h.dismissed.clear() == 0 {
+		return nil
+	}
+
+	h.results.clear()
+	h.notifyStatus(ctx)
+
+	return h.lintOpenDocuments(ctx)
+}
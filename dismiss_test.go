@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDismissals_filter(t *testing.T) {
+	const uri = DocumentURI("file:///project/main.go")
+
+	at := func(linter string, line int, message string) Diagnostic {
+		return Diagnostic{
+			Range:   Range{Start: Position{Line: line}, End: Position{Line: line}},
+			Source:  pt(linter),
+			Message: message,
+		}
+	}
+
+	dismissed := at("gocyclo", 10, "gocyclo: cyclomatic complexity 31 of func `run` is high (> 30)")
+
+	tests := []struct {
+		name     string
+		uri      DocumentURI
+		d        Diagnostic
+		wantKept bool
+	}{
+		{name: "same diagnostic", uri: uri, d: dismissed},
+		{name: "moved a few lines", uri: uri, d: at("gocyclo", 13, dismissed.Message)},
+		{name: "number changed", uri: uri, d: at("gocyclo", 10, "gocyclo: cyclomatic complexity 32 of func `run` is high (> 30)")},
+		{name: "spacing changed", uri: uri, d: at("gocyclo", 10, "gocyclo:  cyclomatic complexity 31 of func `run`\nis high (> 30)")},
+		{name: "moved far away", uri: uri, d: at("gocyclo", 14, dismissed.Message), wantKept: true},
+		{name: "other message", uri: uri, d: at("gocyclo", 10, "gocyclo: cyclomatic complexity 31 of func `main` is high (> 30)"), wantKept: true},
+		{name: "other linter", uri: uri, d: at("cyclop", 10, dismissed.Message), wantKept: true},
+		{name: "other document", uri: "file:///project/util.go", d: dismissed, wantKept: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s dismissals
+			s.add(uri, dismissed)
+
+			got := s.filter(tt.uri, []Diagnostic{tt.d})
+			if kept := len(got) == 1; kept != tt.wantKept {
+				t.Errorf("filter() kept = %v, want %v", kept, tt.wantKept)
+			}
+		})
+	}
+}
+
+// TestDismissals_filterFollowsMoves tests that a dismissal moving a few lines at a time
+// keeps hiding its diagnostic.
+func TestDismissals_filterFollowsMoves(t *testing.T) {
+	const uri = DocumentURI("file:///project/main.go")
+
+	d := Diagnostic{Source: pt("errcheck"), Message: "errcheck: Error return value is not checked"}
+
+	var s dismissals
+	s.add(uri, d)
+
+	for _, line := range []int{2, 4, 6, 8} {
+		d.Range = Range{Start: Position{Line: line}, End: Position{Line: line}}
+		if got := s.filter(uri, []Diagnostic{d}); len(got) != 0 {
+			t.Fatalf("filter() kept the diagnostic moved to line %d", line)
+		}
+	}
+}
+
+// TestDismissals_filterIdenticalIssues tests that a dismissal hides only the closest of
+// identical diagnostics.
+func TestDismissals_filterIdenticalIssues(t *testing.T) {
+	const uri = DocumentURI("file:///project/main.go")
+
+	at := func(line int) Diagnostic {
+		return Diagnostic{
+			Range:   Range{Start: Position{Line: line}, End: Position{Line: line}},
+			Source:  pt("errcheck"),
+			Message: "errcheck: Error return value is not checked",
+		}
+	}
+
+	var s dismissals
+	s.add(uri, at(4))
+
+	got := s.filter(uri, []Diagnostic{at(3), at(4), at(5)})
+	if len(got) != 2 || got[0].Range.Start.Line != 3 || got[1].Range.Start.Line != 5 {
+		t.Errorf("filter() = %+v, want the diagnostics of lines 3 and 5", got)
+	}
+}
+
+// TestLangHandler_dismiss tests that a dismissed diagnostic stays hidden across lints
+// until golangci-lint.undismissAll.
+func TestLangHandler_dismiss(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	text := "package main\n\nfunc main() {\n\tf()\n\tg()\n}\n"
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	issue := func(line int) Issue {
+		i := Issue{FromLinter: "errcheck", Text: "Error return value is not checked"}
+		i.Pos.Filename, i.Pos.Line, i.Pos.Column = "main.go", line, 2
+
+		return i
+	}
+
+	command := fakeLinter(t, GolangCILintResult{Issues: []Issue{issue(4), issue(5)}})
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(root)),
+		"initializationOptions": map[string]any{"command": command},
+	}, nil)
+
+	uri := pathToURI(path)
+	lines := func(want ...int) func(json.RawMessage) bool {
+		return func(raw json.RawMessage) bool {
+			var params PublishDiagnosticsParams
+			if err := json.Unmarshal(raw, &params); err != nil || len(params.Diagnostics) != len(want) {
+				return false
+			}
+
+			for i, d := range params.Diagnostics {
+				if d.Range.Start.Line != want[i] {
+					return false
+				}
+			}
+
+			return true
+		}
+	}
+
+	client.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": text},
+	})
+
+	var params PublishDiagnosticsParams
+	if err := json.Unmarshal(client.waitFor("textDocument/publishDiagnostics", lines(3, 4), 5*time.Second), &params); err != nil {
+		t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+	}
+
+	client.call("workspace/executeCommand", map[string]any{
+		"command":   commandDismiss,
+		"arguments": []any{DismissArguments{URI: uri, Diagnostic: params.Diagnostics[0]}},
+	}, nil)
+	client.waitFor("textDocument/publishDiagnostics", lines(4), 5*time.Second)
+
+	var status StatusResult
+	client.call("workspace/executeCommand", map[string]any{"command": commandStatus}, &status)
+	if status.Dismissed != 1 {
+		t.Errorf("status = %+v, want 1 dismissed diagnostic", status)
+	}
+
+	before := len(client.received("textDocument/publishDiagnostics"))
+	client.notify("textDocument/didSave", map[string]any{"textDocument": map[string]any{"uri": uri}})
+	client.waitFor("textDocument/publishDiagnostics", func(raw json.RawMessage) bool {
+		return len(client.received("textDocument/publishDiagnostics")) > before
+	}, 5*time.Second)
+
+	if got := client.received("textDocument/publishDiagnostics"); !lines(4)(got[len(got)-1]) {
+		t.Errorf("lint after the dismissal published %s, want only line 4", got[len(got)-1])
+	}
+
+	client.call("workspace/executeCommand", map[string]any{"command": commandUndismissAll}, nil)
+	client.waitFor("textDocument/publishDiagnostics", func(raw json.RawMessage) bool {
+		return len(client.received("textDocument/publishDiagnostics")) > before+1 && lines(3, 4)(raw)
+	}, 5*time.Second)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// lintersIndexURL lists the linters supported by golangci-lint.
+const lintersIndexURL = "https://golangci-lint.run/usage/linters/"
+
+var (
+	// staticcheckCodeRe matches the check code leading staticcheck messages, such as "SA4006: ...".
+	staticcheckCodeRe = regexp.MustCompile(`^((?:SA|S|ST|QF)\d+):`)
+	// gosecCodeRe matches the rule leading gosec messages, such as "G104: ...".
+	gosecCodeRe = regexp.MustCompile(`^(G\d+)(?::|\s)`)
+	// analyzerRe matches the analyzer or rule name leading govet and revive messages, such as "printf: ...".
+	analyzerRe = regexp.MustCompile(`^([a-z][a-z0-9-]*): `)
+)
+
+// lintDocumentation describes where an issue is documented.
+type lintDocumentation struct {
+	// name is the linter and, when known, the rule, such as "staticcheck SA4006".
+	name string
+	// rule is the check code or rule name found in the message, if any.
+	rule string
+	url  string
+}
+
+// issueDocumentation returns the documentation of the linter, and of its rule when the
+// message names one. text is the message of the issue without the linter prefix.
+func issueDocumentation(linter, text string) lintDocumentation {
+	if linter == "" {
+		return lintDocumentation{name: "golangci-lint linters", url: lintersIndexURL}
+	}
+
+	switch linter {
+	case "staticcheck", "gosimple", "stylecheck":
+		if m := staticcheckCodeRe.FindStringSubmatch(text); m != nil {
+			return lintDocumentation{name: linter + " " + m[1], rule: m[1], url: "https://staticcheck.dev/docs/checks/#" + m[1]}
+		}
+	case "gosec":
+		if m := gosecCodeRe.FindStringSubmatch(text); m != nil {
+			return lintDocumentation{name: "gosec " + m[1], rule: m[1], url: "https://github.com/securego/gosec#available-rules"}
+		}
+	case "govet":
+		if m := analyzerRe.FindStringSubmatch(text); m != nil {
+			return lintDocumentation{name: "govet " + m[1], rule: m[1], url: "https://pkg.go.dev/golang.org/x/tools/go/analysis/passes/" + url.PathEscape(m[1])}
+		}
+	case "revive":
+		if m := analyzerRe.FindStringSubmatch(text); m != nil {
+			return lintDocumentation{name: "revive " + m[1], rule: m[1], url: "https://github.com/mgechev/revive/blob/master/RULES_DESCRIPTIONS.md#" + m[1]}
+		}
+	case "errcheck":
+		return lintDocumentation{name: linter, url: "https://github.com/kisielk/errcheck"}
+	}
+
+	return lintDocumentation{name: linter, url: lintersIndexURL + "#" + strings.ToLower(linter)}
+}
+
+// describeCode sets the code of the diagnostic of an issue, its rule or else its linter,
+// and links it to the documentation when the client supports codeDescription.
+func (h *langHandler) describeCode(d *Diagnostic, linter, text string) {
+	if !h.codeDescription || linter == "" {
+		return
+	}
+
+	doc := issueDocumentation(linter, text)
+
+	code := doc.rule
+	if code == "" {
+		code = linter
+	}
+
+	d.Code = &code
+	d.CodeDescription = &CodeDescription{Href: doc.url}
+}
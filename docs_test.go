@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestIssueDocumentation tests the documentation URL mapping.
+func TestIssueDocumentation(t *testing.T) {
+	tests := []struct {
+		linter   string
+		text     string
+		wantName string
+		wantURL  string
+	}{
+		{
+			linter:   "staticcheck",
+			text:     "SA4006: this value of err is never used",
+			wantName: "staticcheck SA4006",
+			wantURL:  "https://staticcheck.dev/docs/checks/#SA4006",
+		},
+		{
+			linter:   "stylecheck",
+			text:     "ST1003: should not use underscores in Go names",
+			wantName: "stylecheck ST1003",
+			wantURL:  "https://staticcheck.dev/docs/checks/#ST1003",
+		},
+		{
+			linter:   "gosec",
+			text:     "G104: Errors unhandled.",
+			wantName: "gosec G104",
+			wantURL:  "https://github.com/securego/gosec#available-rules",
+		},
+		{
+			linter:   "govet",
+			text:     "printf: fmt.Sprintf format %d has arg s of wrong type string",
+			wantName: "govet printf",
+			wantURL:  "https://pkg.go.dev/golang.org/x/tools/go/analysis/passes/printf",
+		},
+		{
+			linter:   "revive",
+			text:     "exported: exported function Foo should have comment or be unexported",
+			wantName: "revive exported",
+			wantURL:  "https://github.com/mgechev/revive/blob/master/RULES_DESCRIPTIONS.md#exported",
+		},
+		{
+			linter:   "errcheck",
+			text:     "Error return value of `f.Close` is not checked",
+			wantName: "errcheck",
+			wantURL:  "https://github.com/kisielk/errcheck",
+		},
+		{
+			linter:   "gocritic",
+			text:     "ifElseChain: rewrite if-else to switch statement",
+			wantName: "gocritic",
+			wantURL:  "https://golangci-lint.run/usage/linters/#gocritic",
+		},
+		{
+			linter:   "staticcheck",
+			text:     "no code here",
+			wantName: "staticcheck",
+			wantURL:  "https://golangci-lint.run/usage/linters/#staticcheck",
+		},
+	}
+
+	for _, tt := range tests {
+		got := issueDocumentation(tt.linter, tt.text)
+		if got.name != tt.wantName || got.url != tt.wantURL {
+			t.Errorf("issueDocumentation(%q, %q) = %+v, want {%s %s}", tt.linter, tt.text, got, tt.wantName, tt.wantURL)
+		}
+	}
+}
+
+func TestLangHandler_describeCode(t *testing.T) {
+	tests := []struct {
+		name            string
+		codeDescription bool
+		linter          string
+		text            string
+		want            Diagnostic
+	}{
+		{
+			name:   "unsupported",
+			linter: "staticcheck",
+			text:   "SA4006: this value of err is never used",
+		},
+		{
+			name:            "rule",
+			codeDescription: true,
+			linter:          "staticcheck",
+			text:            "SA4006: this value of err is never used",
+			want:            Diagnostic{Code: pt("SA4006"), CodeDescription: &CodeDescription{Href: "https://staticcheck.dev/docs/checks/#SA4006"}},
+		},
+		{
+			name:            "linter",
+			codeDescription: true,
+			linter:          "errcheck",
+			text:            "Error return value of `f.Close` is not checked",
+			want:            Diagnostic{Code: pt("errcheck"), CodeDescription: &CodeDescription{Href: "https://github.com/kisielk/errcheck"}},
+		},
+		{
+			name:            "no linter",
+			codeDescription: true,
+			text:            "could not run golangci-lint",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &langHandler{codeDescription: tt.codeDescription}
+
+			var got Diagnostic
+			h.describeCode(&got, tt.linter, tt.text)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("describeCode() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestLangHandler_showDocumentation tests the documentation action with and without window/showDocument.
+func TestLangHandler_showDocumentation(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities map[string]any
+		wantMethod   string
+	}{
+		{
+			name:         "showDocument",
+			capabilities: map[string]any{"window": map[string]any{"showDocument": map[string]any{"support": true}}},
+			wantMethod:   "window/showDocument",
+		},
+		{
+			name:         "showMessage fallback",
+			capabilities: map[string]any{},
+			wantMethod:   "window/showMessage",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newLangHandler(false)
+			client := newTestClient(t, h)
+
+			client.call("initialize", map[string]any{"capabilities": tt.capabilities}, nil)
+
+			var actions []CodeAction
+			client.call("textDocument/codeAction", map[string]any{
+				"textDocument": map[string]any{"uri": "file:///project/main.go"},
+				"context": map[string]any{"diagnostics": []Diagnostic{
+					{Source: pt("staticcheck"), Message: "staticcheck: SA4006: this value of err is never used"},
+					{Message: "can't load config"},
+				}},
+			}, &actions)
+
+			// The documentation action comes before those re-running and dismissing.
+			if len(actions) != 3 || actions[0].Title != "Learn more about staticcheck SA4006" {
+				t.Fatalf("codeAction returned %+v, want a documentation action first", actions)
+			}
+
+			client.call("workspace/executeCommand", map[string]any{
+				"command":   actions[0].Command.Command,
+				"arguments": actions[0].Command.Arguments,
+			}, nil)
+
+			client.waitFor(tt.wantMethod, func(params json.RawMessage) bool { return true }, 5*time.Second)
+		})
+	}
+}
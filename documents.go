@@ -0,0 +1,141 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// documentStore holds the content of the open documents, kept up to date by the
+// content changes of didChange. Documents are kept as lines, each with its line
+// ending, so that a change only rewrites the lines it spans. Positions are in UTF-16
+// code units, the only position encoding the server supports.
+type documentStore struct {
+	mu   sync.Mutex
+	docs map[DocumentURI][]string
+}
+
+// splitLines splits text after each "\n". The last line is what follows the last line
+// ending, empty when text ends with one.
+func splitLines(text string) []string {
+	return strings.SplitAfter(text, "\n")
+}
+
+// open starts tracking a document with its content from didOpen.
+func (s *documentStore) open(uri DocumentURI, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.docs == nil {
+		s.docs = make(map[DocumentURI][]string)
+	}
+	s.docs[uri] = splitLines(text)
+}
+
+// change applies content changes, in order, to an open document. A change without
+// a range replaces the whole content, as sent by clients that only support full sync.
+// It reports whether the document is open.
+func (s *documentStore) change(uri DocumentURI, changes []TextDocumentContentChangeEvent) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lines, ok := s.docs[uri]
+	if !ok {
+		return false
+	}
+
+	for _, change := range changes {
+		if change.Range == nil {
+			lines = splitLines(change.Text)
+
+			continue
+		}
+
+		lines = applyLineChange(lines, *change.Range, change.Text)
+	}
+	s.docs[uri] = lines
+
+	return true
+}
+
+// applyLineChange replaces the range r of lines with text. Only the lines spanned by r
+// are joined and split again. Positions past the end of the document are clamped.
+func applyLineChange(lines []string, r Range, text string) []string {
+	last := len(lines) - 1
+	start := min(r.Start.Line, last)
+	end := min(max(r.End.Line, start), last)
+
+	// Positions are made relative to the first line of the span; those past the last
+	// line point at the end of the document.
+	local := func(pos Position) Position {
+		if pos.Line > last {
+			return Position{Line: last - start, Character: math.MaxInt}
+		}
+
+		return Position{Line: max(pos.Line-start, 0), Character: pos.Character}
+	}
+	rng := Range{Start: local(r.Start), End: local(r.End)}
+
+	replaced := splitLines(applyChange(strings.Join(lines[start:end+1], ""), &rng, text))
+	if end < last {
+		// The span ends with the line ending of its last line, which the change
+		// cannot reach: the empty remainder after it is not a line of its own.
+		replaced = replaced[:len(replaced)-1]
+	}
+
+	return slices.Replace(lines, start, end+1, replaced...)
+}
+
+// close stops tracking a document.
+func (s *documentStore) close(uri DocumentURI) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.docs, uri)
+}
+
+// reset stops tracking every document.
+func (s *documentStore) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.docs = nil
+}
+
+// isOpen reports whether the document is open.
+func (s *documentStore) isOpen(uri DocumentURI) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.docs[uri]
+
+	return ok
+}
+
+// GetText returns the current content of an open document.
+func (s *documentStore) GetText(uri DocumentURI) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lines, ok := s.docs[uri]
+	if !ok {
+		return "", false
+	}
+
+	return strings.Join(lines, ""), true
+}
+
+// GetLine returns the 0-based line n of an open document, without its line ending.
+// It fails when the document is not open or has no such line.
+func (s *documentStore) GetLine(uri DocumentURI, n int) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lines, ok := s.docs[uri]
+	if !ok || n < 0 || n >= len(lines) {
+		return "", false
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(lines[n], "\n"), "\r"), true
+}
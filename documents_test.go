@@ -0,0 +1,165 @@
+package main
+
+import "testing"
+
+func TestDocumentStore_change(t *testing.T) {
+	rng := func(startLine, startChar, endLine, endChar int) *Range {
+		return &Range{Start: Position{Line: startLine, Character: startChar}, End: Position{Line: endLine, Character: endChar}}
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		changes []TextDocumentContentChangeEvent
+		want    string
+	}{
+		{
+			name: "multiple ranges in one event",
+			text: "package main\n\nfunc main() {\n}\n",
+			changes: []TextDocumentContentChangeEvent{
+				{Range: rng(2, 13, 2, 13), Text: "\n\tprintln()"},
+				{Range: rng(0, 8, 0, 12), Text: "app"},
+				{Range: rng(4, 0, 4, 1), Text: "}\n\nfunc f() {}"},
+			},
+			want: "package app\n\nfunc main() {\n\tprintln()\n}\n\nfunc f() {}\n",
+		},
+		{
+			name: "deletion across lines",
+			text: "a\nb\nc\nd\n",
+			changes: []TextDocumentContentChangeEvent{
+				{Range: rng(0, 1, 2, 0), Text: ""},
+			},
+			want: "ac\nd\n",
+		},
+		{
+			name: "line ending replaced",
+			text: "a\nb\nc\n",
+			changes: []TextDocumentContentChangeEvent{
+				{Range: rng(0, 1, 1, 0), Text: " "},
+			},
+			want: "a b\nc\n",
+		},
+		{
+			name: "insert at EOF",
+			text: "package main\n",
+			changes: []TextDocumentContentChangeEvent{
+				{Range: rng(1, 0, 1, 0), Text: "\nfunc main() {}\n"},
+			},
+			want: "package main\n\nfunc main() {}\n",
+		},
+		{
+			name: "insert at EOF without a final line ending",
+			text: "package main",
+			changes: []TextDocumentContentChangeEvent{
+				{Range: rng(0, 12, 0, 12), Text: "\n"},
+			},
+			want: "package main\n",
+		},
+		{
+			name: "past the end",
+			text: "a\n",
+			changes: []TextDocumentContentChangeEvent{
+				{Range: rng(5, 0, 7, 3), Text: "b"},
+			},
+			want: "a\nb",
+		},
+		{
+			name: "CRLF document",
+			text: "package main\r\n\r\nfunc main() {\r\n}\r\n",
+			changes: []TextDocumentContentChangeEvent{
+				{Range: rng(2, 13, 2, 13), Text: "\r\n\tprintln()"},
+				{Range: rng(0, 8, 0, 100), Text: "app"},
+			},
+			want: "package app\r\n\r\nfunc main() {\r\n\tprintln()\r\n}\r\n",
+		},
+		{
+			name: "CRLF lines joined",
+			text: "a\r\nb\r\n",
+			changes: []TextDocumentContentChangeEvent{
+				{Range: rng(0, 1, 1, 0), Text: ""},
+			},
+			want: "ab\r\n",
+		},
+		{
+			name: "utf-16 offsets",
+			text: "x\ns := \"😀x\"\n",
+			changes: []TextDocumentContentChangeEvent{
+				{Range: rng(1, 8, 1, 9), Text: "y"},
+			},
+			want: "x\ns := \"😀y\"\n",
+		},
+		{
+			name: "full sync",
+			text: "package a\n",
+			changes: []TextDocumentContentChangeEvent{
+				{Range: rng(0, 8, 0, 9), Text: "b"},
+				{Text: "package c\n"},
+				{Range: rng(0, 8, 0, 9), Text: "d"},
+			},
+			want: "package d\n",
+		},
+	}
+
+	uri := DocumentURI("file:///tmp/main.go")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s documentStore
+			s.open(uri, tt.text)
+
+			if !s.change(uri, tt.changes) {
+				t.Fatal("change() reported the document as not open")
+			}
+
+			got, _ := s.GetText(uri)
+			if got != tt.want {
+				t.Errorf("GetText() = %q, want %q", got, tt.want)
+			}
+
+			// Applying the changes to the whole text gives the same content.
+			text := tt.text
+			for _, change := range tt.changes {
+				text = applyChange(text, change.Range, change.Text)
+			}
+			if got != text {
+				t.Errorf("GetText() = %q, applyChange() = %q", got, text)
+			}
+		})
+	}
+}
+
+func TestDocumentStore_GetLine(t *testing.T) {
+	uri := DocumentURI("file:///tmp/main.go")
+
+	var s documentStore
+	s.open(uri, "package main\r\n\nfunc main() {}")
+
+	tests := []struct {
+		n      int
+		want   string
+		wantOK bool
+	}{
+		{n: 0, want: "package main", wantOK: true},
+		{n: 1, want: "", wantOK: true},
+		{n: 2, want: "func main() {}", wantOK: true},
+		{n: 3},
+		{n: -1},
+	}
+
+	for _, tt := range tests {
+		got, ok := s.GetLine(uri, tt.n)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("GetLine(%d) = %q, %v, want %q, %v", tt.n, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	s.close(uri)
+
+	if _, ok := s.GetText(uri); ok {
+		t.Error("GetText() returned the content of a closed document")
+	}
+
+	if s.change(uri, []TextDocumentContentChangeEvent{{Text: "package main\n"}}) {
+		t.Error("change() applied changes to a closed document")
+	}
+}
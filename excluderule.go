@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// commandExcludeRule adds an exclusion rule for a diagnostic to the golangci-lint config.
+const commandExcludeRule = "golangci-lint.excludeRule"
+
+// ExcludeRuleArguments is the argument of the golangci-lint.excludeRule command.
+type ExcludeRuleArguments struct {
+	Linter  string `json:"linter"`
+	Message string `json:"message"`
+	// Path is the file of the diagnostic, as a path or a file URI.
+	Path string `json:"path"`
+}
+
+// excludeRule is an issues.exclude-rules (v1) or linters.exclusions.rules (v2) entry.
+type excludeRule struct {
+	path   string
+	linter string
+	text   string
+}
+
+func (h *langHandler) executeExcludeRule(ctx context.Context, params ExecuteCommandParams) error {
+	if len(params.Arguments) != 1 {
+		return invalidParams("%s: expected one argument", commandExcludeRule)
+	}
+
+	var args ExcludeRuleArguments
+	if err := json.Unmarshal(params.Arguments[0], &args); err != nil {
+		return invalidParams("%s: invalid argument: %v", commandExcludeRule, err)
+	}
+
+	if args.Linter == "" || args.Path == "" {
+		return invalidParams("%s: linter and path are required", commandExcludeRule)
+	}
+
+	path := args.Path
+	if strings.HasPrefix(path, "file://") {
+		path = uriToPath(path)
+	}
+
+	configPath, err := h.excludeRuleConfigFile()
+	if err != nil {
+		return err
+	}
+
+	rel, err := filepath.Rel(filepath.Dir(configPath), path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return invalidParams("%s: %s is outside the directory of %s", commandExcludeRule, path, configPath)
+	}
+
+	// The message may carry the "linter: " prefix added by the server.
+	text := strings.TrimPrefix(args.Message, args.Linter+": ")
+
+	// An open config goes through the editor so that the change can be reviewed, and
+	// is edited from its content there, which may have unsaved changes.
+	configURI := h.documentURI(configPath)
+	inEditor := h.applyEdit && h.isOpen(configURI)
+
+	var content []byte
+	if text, ok := h.documents.GetText(configURI); inEditor && ok {
+		content = []byte(text)
+	} else if content, err = os.ReadFile(configPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	updated, err := addExcludeRule(content, excludeRule{
+		path:   "^" + regexp.QuoteMeta(filepath.ToSlash(rel)) + "$",
+		linter: args.Linter,
+		text:   regexp.QuoteMeta(text),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Saving the edited config re-lints the open documents.
+	if inEditor {
+		edit := ApplyWorkspaceEditParams{
+			Label: "Exclude " + args.Linter + " issue",
+			Edit: WorkspaceEdit{
+				Changes: map[DocumentURI][]TextEdit{
+					configURI: {{Range: newSourceLines(string(content)).fullRange(), NewText: string(updated)}},
+				},
+			},
+		}
+
+		// The response is not awaited: it is read by the goroutine running this handler.
+		if _, err := h.conn.DispatchCall(ctx, "workspace/applyEdit", &edit); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	if err := os.WriteFile(configPath, updated, 0o644); err != nil {
+		return err
+	}
+
+	slog.Info("added exclude rule", "config", configPath, "linter", args.Linter, "path", rel)
+	h.resetLintConfigs()
+
+	return h.lintOpenDocuments(ctx)
+}
+
+// excludeRuleConfigFile returns the config file exclusion rules are added to: the one
+// passed with --config, the one found for the workspace root, or a new .golangci.yml there.
+func (h *langHandler) excludeRuleConfigFile() (string, error) {
+	if h.pathConfig.noConfig {
+		return "", invalidParams("%s: the command runs with --no-config", commandExcludeRule)
+	}
+
+	path := h.pathConfig.configFile
+	switch {
+	case path != "" && !filepath.IsAbs(path):
+		path = filepath.Join(h.rootDir, path)
+	case path == "":
+		path = h.discoverConfigFile(h.rootDir)
+		if path == "" || !h.inWorkspace(path) {
+			path = filepath.Join(h.rootDir, configFileNames[0])
+		}
+	}
+
+	if ext := filepath.Ext(path); ext != ".yml" && ext != ".yaml" {
+		return "", invalidParams("%s: only YAML configs can be edited: %s", commandExcludeRule, path)
+	}
+
+	return path, nil
+}
+
+// addExcludeRule appends the rule to the YAML config content, keeping its comments.
+func addExcludeRule(content []byte, rule excludeRule) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.New("config is not a mapping")
+	}
+
+	keys := []string{"issues", "exclude-rules"}
+	if version := mappingValue(root, "version"); version != nil && version.Value == "2" {
+		keys = []string{"linters", "exclusions", "rules"}
+	}
+
+	node := root
+	for i, key := range keys {
+		kind := yaml.MappingNode
+		if i == len(keys)-1 {
+			kind = yaml.SequenceNode
+		}
+
+		value := mappingValue(node, key)
+		if value == nil {
+			value = &yaml.Node{Kind: kind}
+			node.Content = append(node.Content, scalarNode(key), value)
+		} else if value.Kind != kind {
+			// An empty key such as "issues:" decodes as a null scalar.
+			if value.Tag != "!!null" {
+				return nil, fmt.Errorf("%s is not a %s", strings.Join(keys[:i+1], "."), kindName(kind))
+			}
+
+			*value = yaml.Node{Kind: kind}
+		}
+
+		node = value
+	}
+
+	node.Content = append(node.Content, &yaml.Node{
+		Kind: yaml.MappingNode,
+		Content: []*yaml.Node{
+			scalarNode("path"), scalarNode(rule.path),
+			scalarNode("linters"), {Kind: yaml.SequenceNode, Content: []*yaml.Node{scalarNode(rule.linter)}},
+			scalarNode("text"), scalarNode(rule.text),
+		},
+	})
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// mappingValue returns the value of key in the mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func kindName(kind yaml.Kind) string {
+	if kind == yaml.SequenceNode {
+		return "list"
+	}
+
+	return "mapping"
+}
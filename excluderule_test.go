@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestAddExcludeRule tests adding exclusion rules to v1 and v2 configs.
+func TestAddExcludeRule(t *testing.T) {
+	rule := excludeRule{path: `^foo/bar\.go$`, linter: "errcheck", text: `unchecked error`}
+
+	tests := []struct {
+		name    string
+		config  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "new config",
+			want: `issues:
+  exclude-rules:
+    - path: ^foo/bar\.go$
+      linters:
+        - errcheck
+      text: unchecked error
+`,
+		},
+		{
+			name: "v1 keeps comments and existing rules",
+			config: `# lint settings
+issues:
+  # generated code
+  exclude-rules:
+    - path: _test\.go
+      linters: [errcheck]
+linters:
+  enable:
+    - gosec
+`,
+			want: `# lint settings
+issues:
+  # generated code
+  exclude-rules:
+    - path: _test\.go
+      linters: [errcheck]
+    - path: ^foo/bar\.go$
+      linters:
+        - errcheck
+      text: unchecked error
+linters:
+  enable:
+    - gosec
+`,
+		},
+		{
+			name: "v2",
+			config: `version: "2"
+linters:
+  default: standard
+`,
+			want: `version: "2"
+linters:
+  default: standard
+  exclusions:
+    rules:
+      - path: ^foo/bar\.go$
+        linters:
+          - errcheck
+        text: unchecked error
+`,
+		},
+		{
+			name:   "empty key",
+			config: "issues:\n",
+			want: `issues:
+  exclude-rules:
+    - path: ^foo/bar\.go$
+      linters:
+        - errcheck
+      text: unchecked error
+`,
+		},
+		{
+			name:    "not a mapping",
+			config:  "- a\n",
+			wantErr: true,
+		},
+		{
+			name:    "exclude-rules of the wrong type",
+			config:  "issues:\n  exclude-rules: yes\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := addExcludeRule([]byte(tt.config), rule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("addExcludeRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.want, string(got)); !tt.wantErr && diff != "" {
+				t.Errorf("addExcludeRule() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestLangHandler_executeExcludeRule tests that the rule is written to the workspace config
+// when the config is not open in the editor.
+func TestLangHandler_executeExcludeRule(t *testing.T) {
+	root := t.TempDir()
+
+	arg, err := json.Marshal(ExcludeRuleArguments{
+		Linter:  "errcheck",
+		Message: "errcheck: Error return value is not checked",
+		Path:    string(pathToURI(filepath.Join(root, "pkg", "main.go"))),
+	})
+	if err != nil {
+		t.Fatalf("json.Marshal() returned unexpected error: %v", err)
+	}
+
+	h := &langHandler{rootDir: root}
+	if err := h.executeExcludeRule(context.Background(), ExecuteCommandParams{Command: commandExcludeRule, Arguments: []json.RawMessage{arg}}); err != nil {
+		t.Fatalf("executeExcludeRule() returned unexpected error: %v", err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(root, ".golangci.yml"))
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned unexpected error: %v", err)
+	}
+
+	want := `issues:
+  exclude-rules:
+    - path: ^pkg/main\.go$
+      linters:
+        - errcheck
+      text: Error return value is not checked
+`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("config mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestLangHandler_executeExcludeRuleOpenConfig tests that the rule is added to the content
+// of an open config in the editor, unsaved edits included, and replaces all of it.
+func TestLangHandler_executeExcludeRuleOpenConfig(t *testing.T) {
+	root := t.TempDir()
+	config := filepath.Join(root, ".golangci.yml")
+	if err := os.WriteFile(config, []byte("issues:\n  max-same-issues: 3\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(root)),
+		"capabilities":          map[string]any{"workspace": map[string]any{"applyEdit": true}},
+		"initializationOptions": map[string]any{"lintOnOpen": false},
+	}, nil)
+
+	// The buffer is longer than the file, with edits that were not saved.
+	buffer := "# unsaved\nissues:\n  max-same-issues: 3\nrun:\n  timeout: 5m\n"
+	client.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": pathToURI(config), "languageId": "yaml", "version": 1, "text": buffer},
+	})
+
+	client.call("workspace/executeCommand", map[string]any{
+		"command": commandExcludeRule,
+		"arguments": []any{ExcludeRuleArguments{
+			Linter:  "errcheck",
+			Message: "Error return value is not checked",
+			Path:    filepath.Join(root, "main.go"),
+		}},
+	}, nil)
+
+	client.waitFor("workspace/applyEdit", func(json.RawMessage) bool { return true }, 5*time.Second)
+
+	var params ApplyWorkspaceEditParams
+	if err := json.Unmarshal(client.received("workspace/applyEdit")[0], &params); err != nil {
+		t.Fatalf("invalid applyEdit params: %v", err)
+	}
+
+	edits := params.Edit.Changes[pathToURI(config)]
+	if len(edits) != 1 {
+		t.Fatalf("expected one edit of the config, got %+v", params.Edit.Changes)
+	}
+
+	if want := newSourceLines(buffer).fullRange(); edits[0].Range != want {
+		t.Errorf("edit range = %+v, want the whole buffer %+v", edits[0].Range, want)
+	}
+
+	for _, kept := range []string{"# unsaved", "timeout: 5m", "errcheck"} {
+		if !strings.Contains(edits[0].NewText, kept) {
+			t.Errorf("edited config is missing %q:\n%s", kept, edits[0].NewText)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// commandApplyFixes runs golangci-lint with --fix on the package of the document given
+// as argument, then lints the open documents again.
+const commandApplyFixes = "golangci-lint.applyFixes"
+
+func (h *langHandler) executeApplyFixes(ctx context.Context, params ExecuteCommandParams) error {
+	var uri DocumentURI
+	if len(params.Arguments) != 1 || json.Unmarshal(params.Arguments[0], &uri) != nil || uri == "" {
+		return invalidParams("%s: expected a document URI argument", commandApplyFixes)
+	}
+
+	if err := validateDocumentURI(uri); err != nil {
+		return err
+	}
+
+	if isUntitled(uri) {
+		return invalidParams("%s: untitled documents have no file to fix", commandApplyFixes)
+	}
+
+	if path, err := filepath.Abs(uriToPath(string(uri))); err != nil || !h.inWorkspace(path) {
+		h.showMessage(ctx, MTError, fmt.Sprintf("golangci-lint-langserver: cannot fix %s: the document is outside the workspace", uri))
+
+		return nil
+	}
+
+	// The run may take long, its outcome is shown when it completes.
+	go h.applyFixes(h.lintContext(), uri)
+
+	return nil
+}
+
+// applyFixes runs golangci-lint with --fix on the package of the document, which
+// rewrites its files on disk, and lints the open documents again. The user is told which
+// files changed, for editors that do not reload them by themselves. Nothing runs while a
+// document of the package has unsaved changes, which the fixes would conflict with.
+func (h *langHandler) applyFixes(ctx context.Context, uri DocumentURI) {
+	h.settingsMu.RLock()
+	defer h.settingsMu.RUnlock()
+
+	path, _ := filepath.Abs(uriToPath(string(uri)))
+	dir := filepath.Dir(path)
+	target := h.workspaceRelative(dir)
+
+	if unsaved := h.unsavedDocuments(dir); len(unsaved) > 0 {
+		h.showMessage(ctx, MTWarning, fmt.Sprintf("golangci-lint-langserver: save %s before applying fixes to %s", strings.Join(unsaved, ", "), target))
+
+		return
+	}
+
+	before := goFileStamps(dir)
+
+	if err := h.runFix(ctx, path, dir); err != nil {
+		if ctx.Err() != nil {
+			slog.Info("fixes cancelled", "dir", dir)
+
+			return
+		}
+
+		h.showMessage(ctx, MTError, fmt.Sprintf("golangci-lint-langserver: cannot apply fixes to %s: %v", target, err))
+
+		return
+	}
+
+	after := goFileStamps(dir)
+
+	var changed []string
+	for name, stamp := range after {
+		if before[name] != stamp {
+			changed = append(changed, name)
+		}
+	}
+	slices.Sort(changed)
+
+	slog.Info("fixes applied", "dir", dir, "changed", changed)
+
+	if err := h.lintOpenDocuments(ctx); err != nil {
+		slog.Error("failed to lint open documents", "error", err)
+	}
+
+	if len(changed) == 0 {
+		h.showMessage(ctx, MTInfo, fmt.Sprintf("golangci-lint fixed nothing in %s", target))
+
+		return
+	}
+
+	h.showMessage(ctx, MTInfo, fmt.Sprintf("golangci-lint fixed %s in %s, reload them if your editor does not", strings.Join(changed, ", "), target))
+}
+
+// runFix runs the command with --fix on the package in dir from its module root.
+// settingsMu must be held.
+func (h *langHandler) runFix(ctx context.Context, path, dir string) error {
+	if len(h.command) == 0 {
+		return errors.New("no golangci-lint command is configured")
+	}
+
+	root := moduleRoot(dir)
+
+	target := "."
+	if rel, err := filepath.Rel(root, dir); err == nil && rel != "." {
+		target = "./" + filepath.ToSlash(rel)
+	}
+
+	command, expanded := expandCommand(h.command, path, dir, root)
+	args := append(slices.Clone(command[1:]), "--fix")
+	if !expanded {
+		args = append(args, target)
+	}
+
+	cmd := exec.CommandContext(ctx, command[0], args...)
+	setProcessGroup(cmd)
+	cmd.Dir = root
+
+	// Only the files matter, the issues left are reported by the lints that follow.
+	stdout := &limitedBuffer{limit: h.maxOutputBytes}
+	cmd.Stdout = stdout
+	stderr := &lineWriter{onLine: h.logStderrLine}
+	cmd.Stderr = stderr
+
+	slog.Info("applying fixes", "command", cmd.Args, "dir", cmd.Dir)
+
+	err := h.runLintCommand(cmd)
+	stderr.Flush()
+
+	// The remaining issues make golangci-lint exit with an error, after printing them.
+	if err != nil && stdout.Len() == 0 && !stdout.Exceeded() {
+		return fmt.Errorf("%w\n%s", err, strings.TrimSpace(stripANSI(string(stderr.Bytes()))))
+	}
+
+	return nil
+}
+
+// unsavedDocuments returns the names of the open documents of dir whose content differs
+// from their file.
+func (h *langHandler) unsavedDocuments(dir string) []string {
+	var unsaved []string
+	for _, uri := range h.openDocuments() {
+		path := uriToPath(string(uri))
+		if !samePath(filepath.Dir(path), dir) {
+			continue
+		}
+
+		text, ok := h.documents.GetText(uri)
+		if b, err := os.ReadFile(path); ok && (err != nil || string(b) != text) {
+			unsaved = append(unsaved, filepath.Base(path))
+		}
+	}
+
+	return unsaved
+}
+
+// goFileStamps returns the modification time and size of each Go file of dir, by name.
+func goFileStamps(dir string) map[string]string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	stamps := make(map[string]string)
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".go" {
+			continue
+		}
+
+		if info, err := e.Info(); err == nil {
+			stamps[e.Name()] = info.ModTime().Format(time.RFC3339Nano) + " " + fmt.Sprint(info.Size())
+		}
+	}
+
+	return stamps
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// TestLangHandler_applyFixes tests that golangci-lint.applyFixes runs the command with
+// --fix on the package of the document and reports the files it changed, unless a
+// document of the package has unsaved changes.
+func TestLangHandler_applyFixes(t *testing.T) {
+	tests := []struct {
+		name        string
+		unsaved     bool
+		wantType    MessageType
+		wantMessage string
+		wantArgs    string
+	}{
+		{
+			name:        "fixed",
+			wantType:    MTInfo,
+			wantMessage: "golangci-lint fixed a.go in ./pkg",
+			wantArgs:    "run --fix ./pkg",
+		},
+		{
+			name:        "unsaved document",
+			unsaved:     true,
+			wantType:    MTWarning,
+			wantMessage: "save a.go before applying fixes to ./pkg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/fix\n"), 0o600); err != nil {
+				t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+			}
+			if err := os.Mkdir(filepath.Join(root, "pkg"), 0o755); err != nil {
+				t.Fatalf("os.Mkdir() returned unexpected error: %v", err)
+			}
+
+			path := filepath.Join(root, "pkg", "a.go")
+			if err := os.WriteFile(path, []byte("package pkg\n"), 0o600); err != nil {
+				t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+			}
+
+			// The fake linter reports no issue, and with --fix appends to a.go and records its arguments.
+			args := filepath.Join(t.TempDir(), "args")
+			script := `case " $* " in *" --fix "*) echo "$@" > "$0"; echo "// fixed" >> pkg/a.go;; esac; echo '{"Issues":[]}'`
+
+			h := newLangHandler(false)
+			client := newTestClient(t, h)
+
+			client.call("initialize", map[string]any{
+				"rootUri":               string(pathToURI(root)),
+				"initializationOptions": map[string]any{"command": []string{"sh", "-c", script, args, "run"}},
+			}, nil)
+
+			uri := pathToURI(path)
+			client.notify("textDocument/didOpen", map[string]any{
+				"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": "package pkg\n"},
+			})
+			if tt.unsaved {
+				client.notify("textDocument/didChange", map[string]any{
+					"textDocument":   map[string]any{"uri": uri, "version": 2},
+					"contentChanges": []any{map[string]any{"text": "package pkg\n\nvar x = 1\n"}},
+				})
+			}
+
+			client.call("workspace/executeCommand", map[string]any{"command": commandApplyFixes, "arguments": []any{uri}}, nil)
+
+			raw := client.waitFor("window/showMessage", func(json.RawMessage) bool { return true }, 5*time.Second)
+
+			var message ShowMessageParams
+			if err := json.Unmarshal(raw, &message); err != nil {
+				t.Fatalf("invalid window/showMessage params: %v", err)
+			}
+
+			if message.Type != tt.wantType || !strings.Contains(message.Message, tt.wantMessage) {
+				t.Errorf("window/showMessage = %+v, want type %v containing %q", message, tt.wantType, tt.wantMessage)
+			}
+
+			got, _ := os.ReadFile(args)
+			if strings.TrimSpace(string(got)) != tt.wantArgs {
+				t.Errorf("fix arguments = %q, want %q", got, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestLangHandler_executeApplyFixesInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments []json.RawMessage
+	}{
+		{name: "no argument"},
+		{name: "not a string", arguments: []json.RawMessage{json.RawMessage(`42`)}},
+		{name: "untitled", arguments: []json.RawMessage{json.RawMessage(`"untitled:Untitled-1"`)}},
+		{name: "unsupported scheme", arguments: []json.RawMessage{json.RawMessage(`"https://example.com/a.go"`)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &langHandler{}
+
+			err := h.executeApplyFixes(context.Background(), ExecuteCommandParams{Command: commandApplyFixes, Arguments: tt.arguments})
+
+			var rpcErr *jsonrpc2.Error
+			if !errors.As(err, &rpcErr) || rpcErr.Code != jsonrpc2.CodeInvalidParams {
+				t.Errorf("executeApplyFixes() = %v, want an InvalidParams error", err)
+			}
+		})
+	}
+}
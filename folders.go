@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// isWithin reports whether path is dir or below it.
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// folderDirs returns the directories of the workspace folders with a file URI.
+func folderDirs(folders []WorkspaceFolder) []string {
+	dirs := make([]string, 0, len(folders))
+	for _, f := range folders {
+		if validateDocumentURI(DocumentURI(f.URI)) != nil {
+			slog.Warn("ignoring workspace folder", "uri", f.URI)
+
+			continue
+		}
+
+		if dir, err := filepath.Abs(uriToPath(f.URI)); err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs
+}
+
+// setFolders replaces the workspace folders.
+func (h *langHandler) setFolders(dirs []string) {
+	h.foldersMu.Lock()
+	defer h.foldersMu.Unlock()
+
+	h.folders = dirs
+}
+
+// workspaceRoot returns the root of the workspace the file or directory at path belongs
+// to: the workspace folder with the longest path containing it, or rootDir.
+func (h *langHandler) workspaceRoot(path string) string {
+	h.foldersMu.Lock()
+	defer h.foldersMu.Unlock()
+
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	root := ""
+	for _, dir := range h.folders {
+		if len(dir) > len(root) && isWithin(dir, path) {
+			root = dir
+		}
+	}
+
+	if root == "" {
+		return h.rootDir
+	}
+
+	return root
+}
+
+// workspaceRoots returns the roots of the workspace: the workspace folders, or rootDir.
+func (h *langHandler) workspaceRoots() []string {
+	h.foldersMu.Lock()
+	defer h.foldersMu.Unlock()
+
+	if len(h.folders) > 0 {
+		return slices.Clone(h.folders)
+	}
+
+	if h.rootDir == "" {
+		return nil
+	}
+
+	return []string{h.rootDir}
+}
+
+// handleWorkspaceDidChangeWorkspaceFolders updates the workspace folders and clears the
+// diagnostics of the files that are no longer in the workspace.
+func (h *langHandler) handleWorkspaceDidChangeWorkspaceFolders(ctx context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	var params DidChangeWorkspaceFoldersParams
+	if err := decodeParams(req, &params); err != nil {
+		return nil, err
+	}
+
+	removed := folderDirs(params.Event.Removed)
+
+	h.foldersMu.Lock()
+	folders := slices.DeleteFunc(slices.Clone(h.folders), func(dir string) bool { return slices.Contains(removed, dir) })
+	for _, dir := range folderDirs(params.Event.Added) {
+		if !slices.Contains(folders, dir) {
+			folders = append(folders, dir)
+		}
+	}
+	h.folders = folders
+	h.foldersMu.Unlock()
+
+	slog.Info("workspace folders changed", "folders", folders)
+
+	// A file of a removed folder may still be in a remaining one, when they are nested.
+	cleared := h.results.removeFunc(func(uri DocumentURI) bool {
+		path := uriToPath(string(uri))
+		within := func(dir string) bool { return isWithin(dir, path) }
+
+		return slices.ContainsFunc(removed, within) && !slices.ContainsFunc(folders, within)
+	})
+
+	for _, uri := range cleared {
+		if err := h.publishDiagnostics(ctx, uri, []Diagnostic{}); err != nil {
+			slog.Error("failed to publish diagnostics", "error", err)
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLangHandler_workspaceRoot(t *testing.T) {
+	root := t.TempDir()
+	a, nested, b := filepath.Join(root, "a"), filepath.Join(root, "a", "nested"), filepath.Join(root, "b")
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "first folder", path: filepath.Join(a, "main.go"), want: a},
+		{name: "second folder", path: filepath.Join(b, "pkg", "main.go"), want: b},
+		{name: "nested folder wins", path: filepath.Join(nested, "main.go"), want: nested},
+		{name: "folder itself", path: b, want: b},
+		{name: "sibling with a common prefix", path: filepath.Join(root, "bb", "main.go"), want: a},
+		{name: "outside the folders", path: filepath.Join(root, "main.go"), want: a},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &langHandler{rootDir: a, folders: []string{a, b, nested}}
+
+			if got := h.workspaceRoot(tt.path); got != tt.want {
+				t.Errorf("workspaceRoot() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLangHandler_workspaceFolders tests that a file of the second folder of a multi-root
+// workspace is linted from that folder, and that its diagnostics are cleared once the
+// folder is removed.
+func TestLangHandler_workspaceFolders(t *testing.T) {
+	root := t.TempDir()
+	a, b := filepath.Join(root, "a"), filepath.Join(root, "b")
+	for name, text := range map[string]string{
+		"a/go.mod":      "module example.com/a\n",
+		"a/main.go":     "package main\n",
+		"b/go.mod":      "module example.com/b\n",
+		"b/pkg/main.go": "package pkg\n",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	issue := Issue{FromLinter: "errcheck", Text: "Error return value is not checked"}
+	issue.Pos.Filename, issue.Pos.Line, issue.Pos.Column = "pkg/main.go", 1, 1
+
+	// The linter records the directory it runs in.
+	command := fakeLinter(t, GolangCILintResult{Issues: []Issue{issue}})
+	wd := filepath.Join(t.TempDir(), "wd")
+	command = []string{"sh", "-c", `pwd > "$1"; cat "$0"; exit 1`, command[3], wd}
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	var result InitializeResult
+	client.call("initialize", map[string]any{
+		"workspaceFolders": []map[string]any{
+			{"uri": pathToURI(a), "name": "a"},
+			{"uri": pathToURI(b), "name": "b"},
+		},
+		"initializationOptions": map[string]any{"command": command},
+	}, &result)
+
+	if w := result.Capabilities.Workspace; w == nil || w.WorkspaceFolders == nil || !w.WorkspaceFolders.ChangeNotifications {
+		t.Errorf("workspace capabilities = %+v, want workspace folder changes", w)
+	}
+
+	uri := pathToURI(filepath.Join(b, "pkg", "main.go"))
+	published := func(n int) func(json.RawMessage) bool {
+		return func(raw json.RawMessage) bool {
+			var params PublishDiagnosticsParams
+
+			return json.Unmarshal(raw, &params) == nil && params.URI == uri && len(params.Diagnostics) == n
+		}
+	}
+
+	h.lintDocument(uri)
+	client.waitFor("textDocument/publishDiagnostics", published(1), 5*time.Second)
+
+	out, err := os.ReadFile(wd)
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned unexpected error: %v", err)
+	}
+	if got, _ := filepath.EvalSymlinks(strings.TrimSpace(string(out))); got != mustEvalSymlinks(t, b) {
+		t.Errorf("golangci-lint ran in %s, want the second folder %s", got, b)
+	}
+
+	client.notify("workspace/didChangeWorkspaceFolders", map[string]any{
+		"event": map[string]any{
+			"added":   []any{},
+			"removed": []map[string]any{{"uri": pathToURI(b), "name": "b"}},
+		},
+	})
+	client.waitFor("textDocument/publishDiagnostics", published(0), 5*time.Second)
+
+	if h.inWorkspace(filepath.Join(b, "pkg", "main.go")) {
+		t.Errorf("the file of the removed folder is still in the workspace")
+	}
+}
+
+func mustEvalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatalf("filepath.EvalSymlinks() returned unexpected error: %v", err)
+	}
+
+	return resolved
+}
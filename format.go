@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// formatLinters are the linters whose issues carry the changes fixing them.
+var formatLinters = []string{"gofmt", "gofumpt", "goimports"}
+
+// codeActionKindFixAll is the kind of the action applying every formatting fix of a document.
+const codeActionKindFixAll = "source.fixAll"
+
+// hunkHeaderRe matches the header of a unified diff hunk, capturing the start and
+// optional length of the old lines.
+var hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+\d+(?:,\d+)? @@`)
+
+// parseUnifiedDiff parses the hunks of a unified diff. Lines before the first hunk,
+// such as the file headers, are skipped.
+func parseUnifiedDiff(diff string) ([]DiffHunk, error) {
+	var hunks []DiffHunk
+	var hunk *DiffHunk
+
+	for _, line := range strings.Split(diff, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+
+		if m := hunkHeaderRe.FindStringSubmatch(line); m != nil {
+			start, _ := strconv.Atoi(m[1])
+			count := 1
+			if m[2] != "" {
+				count, _ = strconv.Atoi(m[2])
+			}
+
+			// An empty old side starts after the given line rather than at it.
+			if count > 0 {
+				start--
+			}
+
+			hunks = append(hunks, DiffHunk{Line: start})
+			hunk = &hunks[len(hunks)-1]
+
+			continue
+		}
+
+		if hunk == nil || line == "" {
+			continue
+		}
+
+		switch line[0] {
+		case ' ':
+			hunk.Old = append(hunk.Old, line[1:])
+			hunk.New = append(hunk.New, line[1:])
+		case '-':
+			hunk.Old = append(hunk.Old, line[1:])
+		case '+':
+			hunk.New = append(hunk.New, line[1:])
+		case '\\':
+			// "\ No newline at end of file"
+		default:
+			return nil, fmt.Errorf("unexpected line in diff hunk: %q", line)
+		}
+	}
+
+	return hunks, nil
+}
+
+// issueHunks returns the changes fixing an issue: the diff embedded in the text of an
+// issue of a formatting linter, or the Replacement of any linter.
+func issueHunks(issue *Issue) []DiffHunk {
+	if i := strings.Index(issue.Text, "\n@@ "); i >= 0 && slices.Contains(formatLinters, issue.FromLinter) {
+		hunks, err := parseUnifiedDiff(issue.Text[i+1:])
+		if err != nil {
+			return nil
+		}
+
+		return hunks
+	}
+
+	r := issue.Replacement
+	if r == nil || len(issue.SourceLines) == 0 {
+		return nil
+	}
+
+	line := issue.LineRange.From
+	if line == 0 {
+		line = issue.Pos.Line
+	}
+
+	if r.Inline != nil {
+		return inlineHunks(issue, line, r.Inline)
+	}
+
+	hunk := DiffHunk{Line: line - 1, Old: issue.SourceLines}
+	if !r.NeedOnlyDelete {
+		hunk.New = r.NewLines
+	}
+
+	return []DiffHunk{hunk}
+}
+
+// inlineHunks returns the change of the line of the issue made by an inline fix, as
+// misspell reports them. The SourceLines of the issue start at the line first.
+func inlineHunks(issue *Issue, first int, fix *InlineFix) []DiffHunk {
+	i := issue.Pos.Line - first
+	if i < 0 || i >= len(issue.SourceLines) {
+		return nil
+	}
+
+	old := issue.SourceLines[i]
+	if fix.StartCol < 0 || fix.Length < 0 || fix.StartCol+fix.Length > len(old) {
+		return nil
+	}
+
+	return []DiffHunk{{
+		Line: issue.Pos.Line - 1,
+		Old:  []string{old},
+		New:  []string{old[:fix.StartCol] + fix.NewString + old[fix.StartCol+fix.Length:]},
+	}}
+}
+
+// hunkEdits converts hunks into edits of text. It fails when the old lines of a hunk,
+// context included, no longer match the text or when hunks overlap.
+func hunkEdits(text string, hunks []DiffHunk) ([]TextEdit, bool) {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	hunks = slices.Clone(hunks)
+	slices.SortFunc(hunks, func(a, b DiffHunk) int { return a.Line - b.Line })
+
+	edits := make([]TextEdit, 0, len(hunks))
+	next := 0
+	for _, hunk := range hunks {
+		end := hunk.Line + len(hunk.Old)
+		if hunk.Line < next || end > len(lines) {
+			return nil, false
+		}
+
+		for i, old := range hunk.Old {
+			if strings.TrimRight(lines[hunk.Line+i], "\r\n") != old {
+				return nil, false
+			}
+		}
+
+		var newText strings.Builder
+		for _, l := range hunk.New {
+			newText.WriteString(l + "\n")
+		}
+
+		edits = append(edits, TextEdit{
+			Range: Range{
+				Start: Position{Line: hunk.Line},
+				End:   Position{Line: end},
+			},
+			NewText: newText.String(),
+		})
+		next = end
+	}
+
+	return edits, true
+}
+
+// documentText returns the current content of the document: the editor buffer when it
+// is open, or the file otherwise.
+func (h *langHandler) documentText(uri DocumentURI) (string, bool) {
+	if text, ok := h.documents.GetText(uri); ok {
+		return text, true
+	}
+
+	b, err := os.ReadFile(uriToPath(string(uri)))
+	if err != nil {
+		return "", false
+	}
+
+	return string(b), true
+}
+
+// formatActions returns a quick fix for each diagnostic carrying the changes fixing it
+// and, when requested, a source.fixAll action applying the fixes of every formatting
+// diagnostic of the document.
+func (h *langHandler) formatActions(uri DocumentURI, diagnostics []Diagnostic, only []string) []CodeAction {
+	text, ok := h.documentText(uri)
+	if !ok {
+		return nil
+	}
+
+	var actions []CodeAction
+
+	if codeActionKindRequested(only, "quickfix") {
+		for _, d := range diagnostics {
+			if d.Data == nil || len(d.Data.Hunks) == 0 || d.Source == nil {
+				continue
+			}
+
+			edits, ok := hunkEdits(text, d.Data.Hunks)
+			if !ok {
+				continue
+			}
+
+			title := "Fix " + *d.Source + " issue"
+			if slices.Contains(formatLinters, *d.Source) {
+				title = "Format with " + *d.Source
+			}
+
+			actions = append(actions, CodeAction{
+				Title:       title,
+				Kind:        "quickfix",
+				Diagnostics: []Diagnostic{d},
+				Edit:        &WorkspaceEdit{Changes: map[DocumentURI][]TextEdit{uri: edits}},
+			})
+		}
+	}
+
+	if len(only) > 0 && codeActionKindRequested(only, codeActionKindFixAll) {
+		entry, _ := h.results.get(uri)
+
+		var hunks []DiffHunk
+		for _, d := range expandMerged(entry.diagnostics) {
+			if d.Data == nil || d.Source == nil || !slices.Contains(formatLinters, *d.Source) {
+				continue
+			}
+
+			for _, hunk := range d.Data.Hunks {
+				if !slices.ContainsFunc(hunks, func(h DiffHunk) bool { return h.Line == hunk.Line }) {
+					hunks = append(hunks, hunk)
+				}
+			}
+		}
+
+		if edits, ok := hunkEdits(text, hunks); ok && len(edits) > 0 {
+			actions = append(actions, CodeAction{
+				Title: "Fix all formatting issues",
+				Kind:  codeActionKindFixAll,
+				Edit:  &WorkspaceEdit{Changes: map[DocumentURI][]TextEdit{uri: edits}},
+			})
+		}
+	}
+
+	return actions
+}
+
+// codeActionKindRequested reports whether actions of kind are requested by only, where
+// a requested kind includes its sub-kinds and no kinds request every kind.
+func codeActionKindRequested(only []string, kind string) bool {
+	if len(only) == 0 {
+		return true
+	}
+
+	for _, k := range only {
+		if k == kind || strings.HasPrefix(kind, k+".") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// goimportsIssue is a goimports issue whose text embeds a diff with two hunks.
+const goimportsIssue = `{
+  "FromLinter": "goimports",
+  "Text": "File is not ` + "`goimports`" + `-ed\n--- a/main.go\n+++ b/main.go\n@@ -1,8 +1,8 @@\n package main\n \n import (\n-\t\"os\"\n \t\"fmt\"\n+\t\"os\"\n )\n \n func main() {\n@@ -9,3 +9,2 @@\n \tfmt.Println(os.Args)\n-\n }\n",
+  "Severity": "",
+  "SourceLines": ["\t\"os\""],
+  "Replacement": null,
+  "LineRange": {"From": 4, "To": 4},
+  "Pos": {"Filename": "main.go", "Offset": 0, "Line": 4, "Column": 0},
+  "ExpectNoLint": false,
+  "ExpectedNoLintLinter": ""
+}`
+
+// gofumptIssue is a gofumpt issue replacing the lines of its LineRange.
+const gofumptIssue = `{
+  "FromLinter": "gofumpt",
+  "Text": "File is not ` + "`gofumpt`" + `-ed",
+  "Severity": "",
+  "SourceLines": ["\tx := []int{ 1, 2 }", ""],
+  "Replacement": {"NeedOnlyDelete": false, "NewLines": ["\tx := []int{1, 2}"], "Inline": null},
+  "LineRange": {"From": 10, "To": 11},
+  "Pos": {"Filename": "main.go", "Offset": 0, "Line": 10, "Column": 0},
+  "ExpectNoLint": false,
+  "ExpectedNoLintLinter": ""
+}`
+
+const unformattedSource = `package main
+
+import (
+	"os"
+	"fmt"
+)
+
+func main() {
+	fmt.Println(os.Args)
+
+}
+`
+
+const unformattedValues = `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println(values())
+}
+
+func values() []int {
+	x := []int{ 1, 2 }
+
+	return x
+}
+`
+
+func TestIssueHunks(t *testing.T) {
+	tests := []struct {
+		name  string
+		issue string
+		want  []DiffHunk
+	}{
+		{
+			name:  "goimports diff",
+			issue: goimportsIssue,
+			want: []DiffHunk{
+				{
+					Line: 0,
+					Old:  []string{"package main", "", "import (", "\t\"os\"", "\t\"fmt\"", ")", "", "func main() {"},
+					New:  []string{"package main", "", "import (", "\t\"fmt\"", "\t\"os\"", ")", "", "func main() {"},
+				},
+				{
+					Line: 8,
+					Old:  []string{"\tfmt.Println(os.Args)", "", "}"},
+					New:  []string{"\tfmt.Println(os.Args)", "}"},
+				},
+			},
+		},
+		{
+			name:  "gofumpt replacement",
+			issue: gofumptIssue,
+			want: []DiffHunk{
+				{Line: 9, Old: []string{"\tx := []int{ 1, 2 }", ""}, New: []string{"\tx := []int{1, 2}"}},
+			},
+		},
+		{
+			name:  "whole line deletion",
+			issue: `{"FromLinter": "whitespace", "Text": "unnecessary trailing newline", "SourceLines": [""], "Replacement": {"NeedOnlyDelete": true}, "LineRange": {"From": 3, "To": 3}, "Pos": {"Line": 3}}`,
+			want:  []DiffHunk{{Line: 2, Old: []string{""}}},
+		},
+		{
+			name:  "multi-line replacement",
+			issue: `{"FromLinter": "whitespace", "Text": "multi-line if should be followed by a newline", "SourceLines": ["\tif a &&", "\t\tb {"], "Replacement": {"NewLines": ["\tif a &&", "\t\tb {", ""]}, "LineRange": {"From": 5, "To": 6}, "Pos": {"Line": 5}}`,
+			want:  []DiffHunk{{Line: 4, Old: []string{"\tif a &&", "\t\tb {"}, New: []string{"\tif a &&", "\t\tb {", ""}}},
+		},
+		{
+			name:  "inline",
+			issue: `{"FromLinter": "misspell", "Text": "` + "`recieve`" + ` is a misspelling of ` + "`receive`" + `", "SourceLines": ["\t// recieve the value"], "Replacement": {"Inline": {"StartCol": 4, "Length": 7, "NewString": "receive"}}, "Pos": {"Line": 7, "Column": 5}}`,
+			want:  []DiffHunk{{Line: 6, Old: []string{"\t// recieve the value"}, New: []string{"\t// receive the value"}}},
+		},
+		{
+			name:  "inline on a later line of the range",
+			issue: `{"FromLinter": "misspell", "Text": "misspelling", "SourceLines": ["// a", "// teh b"], "Replacement": {"Inline": {"StartCol": 3, "Length": 3, "NewString": "the"}}, "LineRange": {"From": 2, "To": 3}, "Pos": {"Line": 3, "Column": 4}}`,
+			want:  []DiffHunk{{Line: 2, Old: []string{"// teh b"}, New: []string{"// the b"}}},
+		},
+		{
+			name:  "inline past the end of the line",
+			issue: `{"FromLinter": "misspell", "Text": "misspelling", "SourceLines": ["// teh"], "Replacement": {"Inline": {"StartCol": 3, "Length": 10, "NewString": "the"}}, "Pos": {"Line": 1, "Column": 4}}`,
+		},
+		{
+			name:  "diff of another linter",
+			issue: `{"FromLinter": "dupl", "Text": "lines\n@@ -1 +1 @@\n-a\n+b", "SourceLines": ["a"], "Pos": {"Line": 1}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var issue Issue
+			if err := json.Unmarshal([]byte(tt.issue), &issue); err != nil {
+				t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, issueHunks(&issue)); diff != "" {
+				t.Errorf("issueHunks() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseUnifiedDiff_insertion(t *testing.T) {
+	hunks, err := parseUnifiedDiff("@@ -2,0 +3,1 @@\n+\"fmt\"\n")
+	if err != nil {
+		t.Fatalf("parseUnifiedDiff() returned unexpected error: %v", err)
+	}
+
+	want := []DiffHunk{{Line: 2, New: []string{`"fmt"`}}}
+	if diff := cmp.Diff(want, hunks); diff != "" {
+		t.Errorf("parseUnifiedDiff() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := parseUnifiedDiff("@@ -1 +1 @@\n?garbage\n"); err == nil {
+		t.Error("parseUnifiedDiff() returned no error for an invalid hunk line")
+	}
+}
+
+func TestHunkEdits(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		issue  string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "multi-hunk diff",
+			text:   unformattedSource,
+			issue:  goimportsIssue,
+			want:   "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() {\n\tfmt.Println(os.Args)\n}\n",
+			wantOK: true,
+		},
+		{
+			name:   "replacement",
+			text:   unformattedValues,
+			issue:  gofumptIssue,
+			want:   "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(values())\n}\n\nfunc values() []int {\n\tx := []int{1, 2}\n\treturn x\n}\n",
+			wantOK: true,
+		},
+		{
+			name:  "document changed since the lint",
+			text:  "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n",
+			issue: goimportsIssue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var issue Issue
+			if err := json.Unmarshal([]byte(tt.issue), &issue); err != nil {
+				t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+			}
+
+			edits, ok := hunkEdits(tt.text, issueHunks(&issue))
+			if ok != tt.wantOK {
+				t.Fatalf("hunkEdits() ok = %v, want %v", ok, tt.wantOK)
+			}
+
+			if !ok {
+				return
+			}
+
+			// Edits are applied from the end so that earlier ranges stay valid.
+			got := tt.text
+			for i := len(edits) - 1; i >= 0; i-- {
+				got = applyChange(got, &edits[i].Range, edits[i].NewText)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("edited text mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHunkEdits_overlap(t *testing.T) {
+	hunks := []DiffHunk{
+		{Line: 0, Old: []string{"a", "b"}, New: []string{"a"}},
+		{Line: 1, Old: []string{"b"}, New: []string{"c"}},
+	}
+
+	if edits, ok := hunkEdits("a\nb\n", hunks); ok {
+		t.Errorf("hunkEdits() = %+v, want overlapping hunks declined", edits)
+	}
+}
+
+// TestLangHandler_formatActions tests the quick fix of a formatting diagnostic and the
+// source.fixAll action built from the last lint of the document.
+func TestLangHandler_formatActions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte(unformattedSource), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	var issue Issue
+	if err := json.Unmarshal([]byte(goimportsIssue), &issue); err != nil {
+		t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+	}
+
+	uri := pathToURI(path)
+	d := Diagnostic{
+		Range:   Range{Start: Position{Line: 3}, End: Position{Line: 3}},
+		Source:  pt("goimports"),
+		Message: "goimports: File is not `goimports`-ed",
+		Data:    &DiagnosticData{Hunks: issueHunks(&issue)},
+	}
+	other := Diagnostic{Source: pt("errcheck"), Message: "errcheck: Error return value is not checked"}
+
+	h := &langHandler{}
+	h.results.set(uri, []Diagnostic{d, other}, "")
+
+	tests := []struct {
+		name      string
+		context   []Diagnostic
+		only      []string
+		wantKinds []string
+	}{
+		{name: "quick fix", context: []Diagnostic{d, other}, wantKinds: []string{"quickfix"}},
+		{name: "fix all", only: []string{"source.fixAll"}, wantKinds: []string{"source.fixAll"}},
+		{name: "source", only: []string{"source"}, wantKinds: []string{"source.fixAll"}},
+		{name: "other kinds", context: []Diagnostic{d}, only: []string{"refactor"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var kinds []string
+			for _, a := range h.formatActions(uri, tt.context, tt.only) {
+				kinds = append(kinds, a.Kind)
+
+				if edits := a.Edit.Changes[uri]; len(edits) != 2 {
+					t.Errorf("%s action has edits %+v, want one per hunk", a.Kind, edits)
+				}
+			}
+
+			if diff := cmp.Diff(tt.wantKinds, kinds); diff != "" {
+				t.Errorf("formatActions() kinds mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestLangHandler_formatActions_replacement tests the quick fix of a misspell diagnostic
+// from its inline Replacement, which source.fixAll leaves out.
+func TestLangHandler_formatActions_replacement(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\n// recieve the value\nfunc main() {}\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	var issue Issue
+	if err := json.Unmarshal([]byte(`{"FromLinter": "misspell", "Text": "misspelling", "SourceLines": ["// recieve the value"], "Replacement": {"Inline": {"StartCol": 3, "Length": 7, "NewString": "receive"}}, "Pos": {"Line": 3, "Column": 4}}`), &issue); err != nil {
+		t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+	}
+
+	uri := pathToURI(path)
+	d := Diagnostic{
+		Range:   Range{Start: Position{Line: 2, Character: 3}, End: Position{Line: 2, Character: 3}},
+		Source:  pt("misspell"),
+		Message: "misspell: misspelling",
+		Data:    &DiagnosticData{Hunks: issueHunks(&issue)},
+	}
+
+	h := &langHandler{}
+	h.results.set(uri, []Diagnostic{d}, "")
+
+	actions := h.formatActions(uri, []Diagnostic{d}, nil)
+	if len(actions) != 1 || actions[0].Title != "Fix misspell issue" {
+		t.Fatalf("formatActions() = %+v, want a misspell quick fix", actions)
+	}
+
+	want := []TextEdit{{Range: Range{Start: Position{Line: 2}, End: Position{Line: 3}}, NewText: "// receive the value\n"}}
+	if diff := cmp.Diff(want, actions[0].Edit.Changes[uri]); diff != "" {
+		t.Errorf("quick fix edits mismatch (-want +got):\n%s", diff)
+	}
+
+	if actions := h.formatActions(uri, nil, []string{codeActionKindFixAll}); len(actions) != 0 {
+		t.Errorf("formatActions() for source.fixAll = %+v, want none", actions)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// formattingRegistrationID identifies the dynamic registration of textDocument/formatting.
+const formattingRegistrationID = "golangci-lint-langserver.formatting"
+
+// fmtMajorVersion is the first major version of golangci-lint with the fmt subcommand.
+const fmtMajorVersion = 2
+
+// fmtCommand returns the command formatting standard input with the golangci-lint run
+// by command, like cacheCleanCommand, and with the config it was given if any. It fails
+// when the command has no run subcommand, as with custom scripts, whose binary is unknown.
+func fmtCommand(command []string) ([]string, bool) {
+	base, ok := commandBinary(command)
+	if !ok {
+		return nil, false
+	}
+
+	args := append(slices.Clone(base), "fmt", "--stdin")
+	if config := parseCommandFlags(command).configFile; config != "" {
+		args = append(args, "--config", config)
+	}
+
+	return args, true
+}
+
+// registerFormatting registers textDocument/formatting with the client once golangci-lint
+// is known to have the fmt subcommand. Detecting the version may run a command the user
+// has to approve, so it happens in the background after initialized.
+func (h *langHandler) registerFormatting(ctx context.Context) {
+	h.settingsMu.RLock()
+	command, root := h.command, h.rootDir
+	h.settingsMu.RUnlock()
+
+	args, ok := versionCommand(command)
+	if !ok {
+		slog.Info("not registering formatting: the golangci-lint binary of the command is unknown")
+
+		return
+	}
+
+	v, ok := h.binaryVersion(ctx, root, args)
+	if !ok || v.major < fmtMajorVersion {
+		slog.Info("not registering formatting: golangci-lint fmt needs golangci-lint v2", "version", v.full)
+
+		return
+	}
+
+	err := h.conn.Call(ctx, "client/registerCapability", &RegistrationParams{
+		Registrations: []Registration{{
+			ID:     formattingRegistrationID,
+			Method: "textDocument/formatting",
+			RegisterOptions: TextDocumentRegistrationOptions{
+				DocumentSelector: []DocumentFilter{{Language: "go"}},
+			},
+		}},
+	}, nil)
+	if err != nil {
+		slog.Error("failed to register formatting", "error", err)
+	}
+}
+
+func (h *langHandler) handleTextDocumentFormatting(ctx context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	var params DocumentFormattingParams
+	if err := decodeParams(req, &params); err != nil {
+		return nil, err
+	}
+
+	uri := params.TextDocument.URI
+	if err := validateDocumentURI(uri); err != nil {
+		return nil, err
+	}
+
+	text, ok := h.documentText(uri)
+	if !ok {
+		return nil, invalidParams("textDocument/formatting: cannot read %s", uri)
+	}
+
+	formatted, err := h.formatText(ctx, uri, text)
+	if err != nil {
+		return nil, err
+	}
+
+	return formattingEdits(text, formatted), nil
+}
+
+// formatText runs golangci-lint fmt on the text of the document from its directory, so
+// that the formatters find the config and module of the file. settingsMu must be held.
+func (h *langHandler) formatText(ctx context.Context, uri DocumentURI, text string) (string, error) {
+	args, ok := fmtCommand(h.command)
+	if !ok {
+		return "", errors.New("formatting needs a golangci-lint command with a run subcommand")
+	}
+
+	dir := h.rootDir
+	if !isUntitled(uri) {
+		dir = filepath.Dir(uriToPath(string(uri)))
+	}
+
+	// Clients advertised the capability statically cannot tell older versions apart.
+	if version, ok := versionCommand(h.command); ok {
+		if v, ok := h.binaryVersion(ctx, dir, version); ok && v.major < fmtMajorVersion {
+			return "", fmt.Errorf("golangci-lint %s has no fmt command, formatting needs golangci-lint v2", v.full)
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	setProcessGroup(cmd)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(text)
+
+	stdout := &limitedBuffer{limit: h.maxOutputBytes}
+	cmd.Stdout = stdout
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	slog.Debug("formatting", "uri", uri, "command", cmd.Args, "dir", cmd.Dir)
+
+	if err := h.runLintCommand(cmd); err != nil {
+		return "", fmt.Errorf("golangci-lint fmt failed: %w\n%s", err, strings.TrimSpace(stripANSI(stderr.String())))
+	}
+
+	if stdout.Exceeded() {
+		return "", errors.New("golangci-lint fmt output exceeds maxOutputBytes")
+	}
+
+	// An empty output means the text had nothing to format, or nothing at all.
+	if stdout.Len() == 0 {
+		return text, nil
+	}
+
+	return string(stdout.Bytes()), nil
+}
+
+// formattingEdits returns the edit replacing the lines between the common prefix and
+// suffix of text and formatted, or no edit when they are equal.
+func formattingEdits(text, formatted string) []TextEdit {
+	if text == formatted {
+		return []TextEdit{}
+	}
+
+	before := splitLines(text)
+	after := splitLines(formatted)
+
+	prefix := 0
+	for prefix < len(before)-1 && prefix < len(after)-1 && before[prefix] == after[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	// The last line has no line ending, an edit reaching it ends at its end.
+	end := Position{Line: len(before) - suffix}
+	if suffix == 0 {
+		end = Position{Line: len(before) - 1, Character: utf16Len(before[len(before)-1])}
+	}
+
+	return []TextEdit{{
+		Range:   Range{Start: Position{Line: prefix}, End: end},
+		NewText: strings.Join(after[prefix:len(after)-suffix], ""),
+	}}
+}
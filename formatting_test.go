@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormattingEdits(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		formatted string
+		want      []TextEdit
+	}{
+		{name: "unchanged", text: "package a\n", formatted: "package a\n", want: []TextEdit{}},
+		{
+			name:      "changed line",
+			text:      "package a\n\nvar x=1\n\nfunc f() {}\n",
+			formatted: "package a\n\nvar x = 1\n\nfunc f() {}\n",
+			want: []TextEdit{{
+				Range:   Range{Start: Position{Line: 2}, End: Position{Line: 3}},
+				NewText: "var x = 1\n",
+			}},
+		},
+		{
+			name:      "removed lines",
+			text:      "package a\n\n\n\nvar x = 1\n",
+			formatted: "package a\n\nvar x = 1\n",
+			want: []TextEdit{{
+				Range:   Range{Start: Position{Line: 2}, End: Position{Line: 4}},
+				NewText: "",
+			}},
+		},
+		{
+			name:      "missing final newline",
+			text:      "package a\n\nvar x = 1",
+			formatted: "package a\n\nvar x = 1\n",
+			want: []TextEdit{{
+				Range:   Range{Start: Position{Line: 2}, End: Position{Line: 2, Character: 9}},
+				NewText: "var x = 1\n",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, formattingEdits(tt.text, tt.formatted)); diff != "" {
+				t.Errorf("formattingEdits() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestLangHandler_formatting tests that textDocument/formatting formats the unsaved
+// content of the document with golangci-lint fmt, and that clients registering it
+// dynamically only get it from golangci-lint v2.
+func TestLangHandler_formatting(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake golangci-lint needs sh")
+	}
+
+	tests := []struct {
+		name         string
+		version      string
+		wantRegister bool
+		wantEdits    []TextEdit
+		wantErr      string
+	}{
+		{
+			name:         "v2",
+			version:      "2.1.6",
+			wantRegister: true,
+			wantEdits: []TextEdit{{
+				Range:   Range{Start: Position{Line: 2}, End: Position{Line: 3}},
+				NewText: "var x = 1\n",
+			}},
+		},
+		{
+			name:    "v1",
+			version: "1.64.8",
+			wantErr: "golangci-lint 1.64.8 has no fmt command",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			path := filepath.Join(root, "a.go")
+			if err := os.WriteFile(path, []byte("package a\n"), 0o600); err != nil {
+				t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+			}
+
+			// The fake golangci-lint prints its version, and formats `var x=1` from stdin.
+			script := `case "$1" in version) echo "golangci-lint has version ` + tt.version + ` built with go1.24.3";; fmt) sed 's/^var x=1$/var x = 1/';; run) echo '{"Issues":[]}';; esac`
+
+			h := newLangHandler(false)
+			client := newTestClient(t, h)
+
+			client.call("initialize", map[string]any{
+				"rootUri":               string(pathToURI(root)),
+				"capabilities":          map[string]any{"textDocument": map[string]any{"formatting": map[string]any{"dynamicRegistration": true}}},
+				"initializationOptions": map[string]any{"command": []string{"sh", "-c", script, "sh", "run"}},
+			}, nil)
+
+			if tt.wantRegister {
+				client.waitFor("client/registerCapability", func(params json.RawMessage) bool {
+					return strings.Contains(string(params), `"textDocument/formatting"`)
+				}, 5*time.Second)
+			}
+
+			uri := pathToURI(path)
+			client.notify("textDocument/didOpen", map[string]any{
+				"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": "package a\n\nvar x=1\n"},
+			})
+
+			var edits []TextEdit
+			err := client.conn.Call(context.Background(), "textDocument/formatting", map[string]any{
+				"textDocument": map[string]any{"uri": uri},
+				"options":      map[string]any{"tabSize": 4, "insertSpaces": false},
+			}, &edits)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("textDocument/formatting error = %v, want one containing %q", err, tt.wantErr)
+				}
+
+				if got := client.received("client/registerCapability"); len(got) != 0 {
+					t.Errorf("client/registerCapability was called with %s, want no registration", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("textDocument/formatting returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.wantEdits, edits); diff != "" {
+				t.Errorf("textDocument/formatting mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
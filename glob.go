@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// compileGlob converts a doublestar glob into a regexp matching slash-separated paths.
+// A single star matches within a path segment and a double star, which must be a
+// whole segment, matches any number of segments. "?", character classes ("[abc]",
+// negated with "!" or "^") and alternatives ("{a,b}") are supported as well.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+
+	braces := 0
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				startsSegment := i == 0 || pattern[i-1] == '/'
+				endsSegment := i+2 == len(pattern) || pattern[i+2] == '/'
+				if !startsSegment || !endsSegment {
+					return nil, fmt.Errorf("invalid glob %q: ** must be a whole path segment", pattern)
+				}
+
+				switch {
+				case i+2 == len(pattern):
+					// Trailing "**" matches everything below, including nothing after "dir/".
+					b.WriteString(".*")
+				default:
+					// "**/" matches zero or more leading segments.
+					b.WriteString("(?:.*/)?")
+					i++
+				}
+				i++
+
+				continue
+			}
+
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid glob %q: unterminated character class", pattern)
+			}
+
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case '{':
+			braces++
+			b.WriteString("(?:")
+		case '}':
+			if braces == 0 {
+				return nil, fmt.Errorf("invalid glob %q: unmatched }", pattern)
+			}
+
+			braces--
+			b.WriteString(")")
+		case ',':
+			if braces > 0 {
+				b.WriteString("|")
+			} else {
+				b.WriteString(",")
+			}
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	if braces > 0 {
+		return nil, fmt.Errorf("invalid glob %q: unterminated {", pattern)
+	}
+
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+
+	return re, nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+// TestCompileGlob tests the doublestar glob semantics.
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		glob string
+		path string
+		want bool
+	}{
+		{glob: "internal/experimental/**", path: "internal/experimental/foo.go", want: true},
+		{glob: "internal/experimental/**", path: "internal/experimental/a/b/foo.go", want: true},
+		{glob: "internal/experimental/**", path: "internal/experimentalfoo.go", want: false},
+		{glob: "internal/experimental/**", path: "internal/other/foo.go", want: false},
+		{glob: "**/*_test.go", path: "foo_test.go", want: true},
+		{glob: "**/*_test.go", path: "a/b/foo_test.go", want: true},
+		{glob: "**/*_test.go", path: "a/b/foo.go", want: false},
+		{glob: "*.go", path: "main.go", want: true},
+		{glob: "*.go", path: "cmd/main.go", want: false},
+		{glob: "cmd/**/main.go", path: "cmd/main.go", want: true},
+		{glob: "cmd/**/main.go", path: "cmd/a/b/main.go", want: true},
+		{glob: "pkg/?.go", path: "pkg/a.go", want: true},
+		{glob: "pkg/?.go", path: "pkg/ab.go", want: false},
+		{glob: "pkg/[ab].go", path: "pkg/b.go", want: true},
+		{glob: "pkg/[!ab].go", path: "pkg/b.go", want: false},
+		{glob: "{cmd,tools}/**", path: "tools/gen/main.go", want: true},
+		{glob: "{cmd,tools}/**", path: "internal/gen/main.go", want: false},
+		{glob: "zz_generated.*.go", path: "zz_generated.deepcopy.go", want: true},
+	}
+
+	for _, tt := range tests {
+		re, err := compileGlob(tt.glob)
+		if err != nil {
+			t.Fatalf("compileGlob(%q) returned unexpected error: %v", tt.glob, err)
+		}
+
+		if got := re.MatchString(tt.path); got != tt.want {
+			t.Errorf("glob %q match %q = %v, want %v", tt.glob, tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestCompileGlobInvalid tests that malformed globs are rejected.
+func TestCompileGlobInvalid(t *testing.T) {
+	for _, glob := range []string{"a**/b", "pkg/[ab.go", "{a,b", "a}"} {
+		if _, err := compileGlob(glob); err == nil {
+			t.Errorf("compileGlob(%q) expected an error", glob)
+		}
+	}
+}
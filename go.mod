@@ -5,3 +5,5 @@ go 1.23.4
 require github.com/sourcegraph/jsonrpc2 v0.2.0
 
 require github.com/google/go-cmp v0.6.0
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -3,11 +3,11 @@ package main
 import "strings"
 
 type Issue struct {
-	FromLinter  string   `json:"FromLinter"`
-	Text        string   `json:"Text"`
-	Severity    string   `json:"Severity"`
-	SourceLines []string `json:"SourceLines"`
-	Replacement any      `json:"Replacement"`
+	FromLinter  string       `json:"FromLinter"`
+	Text        string       `json:"Text"`
+	Severity    string       `json:"Severity"`
+	SourceLines []string     `json:"SourceLines"`
+	Replacement *Replacement `json:"Replacement"`
 	Pos         struct {
 		Filename string `json:"Filename"`
 		Offset   int    `json:"Offset"`
@@ -22,23 +22,50 @@ type Issue struct {
 	} `json:"LineRange,omitempty"`
 }
 
+// Replacement is the change fixing an issue: the lines of its LineRange replaced by
+// NewLines, or deleted, or a part of the line of the issue replaced by Inline.
+type Replacement struct {
+	NeedOnlyDelete bool       `json:"NeedOnlyDelete"`
+	NewLines       []string   `json:"NewLines"`
+	Inline         *InlineFix `json:"Inline"`
+}
+
+// InlineFix replaces the Length bytes at the 0-based byte column StartCol of a line.
+type InlineFix struct {
+	StartCol  int    `json:"StartCol"`
+	Length    int    `json:"Length"`
+	NewString string `json:"NewString"`
+}
+
+// DiagSeverity maps the severity golangci-lint reports for the issue, as set by the
+// severity section of its config, onto an LSP severity. The -severity default applies
+// when the issue has none or one that is not recognized.
 func (i Issue) DiagSeverity() DiagnosticSeverity {
-	if i.Severity == "" {
-		// TODO: How to get default-severity from .golangci.yml, if available?
-		i.Severity = defaultSeverity
+	if s, ok := parseSeverity(i.Severity); ok {
+		return s
 	}
 
-	switch strings.ToLower(i.Severity) {
+	// TODO: How to get default-severity from .golangci.yml, if available?
+	if s, ok := parseSeverity(defaultSeverity); ok {
+		return s
+	}
+
+	return DSWarning
+}
+
+// parseSeverity parses the severity names accepted by -severity.
+func parseSeverity(name string) (DiagnosticSeverity, bool) {
+	switch strings.ToLower(name) {
 	case "err", "error":
-		return DSError
+		return DSError, true
 	case "warn", "warning":
-		return DSWarning
+		return DSWarning, true
 	case "info", "information":
-		return DSInformation
+		return DSInformation, true
 	case "hint":
-		return DSHint
+		return DSHint, true
 	default:
-		return DSWarning
+		return 0, false
 	}
 }
 
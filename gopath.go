@@ -0,0 +1,69 @@
+package main
+
+import (
+	"go/build"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// inModule reports whether dir belongs to a module: it or one of its parents holds a go.mod.
+func inModule(dir string) bool {
+	_, err := os.Stat(filepath.Join(moduleRoot(dir), "go.mod"))
+
+	return err == nil
+}
+
+// inGOPATH reports whether dir is below the src directory of a GOPATH entry.
+func inGOPATH(dir string) bool {
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		gopath = build.Default.GOPATH
+	}
+
+	for _, entry := range filepath.SplitList(gopath) {
+		if entry == "" {
+			continue
+		}
+
+		rel, err := filepath.Rel(filepath.Join(entry, "src"), dir)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// gopathEnv sets GO111MODULE for cmd when it runs outside any module, where the default
+// module mode fails to resolve imports: off below a GOPATH, so that packages load the
+// GOPATH way, and auto elsewhere. It leaves cmd alone when its environment sets
+// GO111MODULE already.
+func (h *langHandler) gopathEnv(cmd *exec.Cmd) {
+	if inModule(cmd.Dir) {
+		return
+	}
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "GO111MODULE=") {
+			slog.Debug("no go.mod, keeping GO111MODULE of the environment", "dir", cmd.Dir, "setting", kv)
+
+			return
+		}
+	}
+
+	mode := "auto"
+	if inGOPATH(cmd.Dir) {
+		mode = "off"
+	}
+
+	slog.Debug("no go.mod, setting GO111MODULE", "dir", cmd.Dir, "mode", mode)
+	cmd.Env = append(env, "GO111MODULE="+mode)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// gopathFixture copies the GOPATH project of testdata outside of this module and
+// points GOPATH at it, returning the directory of the project.
+func gopathFixture(t *testing.T) string {
+	t.Helper()
+
+	gopath := t.TempDir()
+	if err := os.CopyFS(gopath, os.DirFS(filepath.Join("testdata", "gopath"))); err != nil {
+		t.Fatalf("os.CopyFS() returned unexpected error: %v", err)
+	}
+
+	t.Setenv("GOPATH", gopath)
+	t.Setenv("GO111MODULE", "")
+	os.Unsetenv("GO111MODULE")
+
+	return filepath.Join(gopath, "src", "example.com", "legacy")
+}
+
+func TestLangHandler_gopathEnv(t *testing.T) {
+	project := gopathFixture(t)
+
+	module := t.TempDir()
+	if err := os.WriteFile(filepath.Join(module, "go.mod"), []byte("module example.com/app\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		dir  string
+		env  []string
+		want string
+	}{
+		{name: "GOPATH package", dir: filepath.Join(project, "util"), want: "off"},
+		{name: "outside GOPATH", dir: t.TempDir(), want: "auto"},
+		{name: "module", dir: module},
+		{name: "set by the environment", dir: project, env: []string{"GO111MODULE=on"}, want: "on"},
+	}
+
+	h := &langHandler{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("golangci-lint", "run")
+			cmd.Dir = tt.dir
+			cmd.Env = tt.env
+
+			h.gopathEnv(cmd)
+
+			got := ""
+			for _, kv := range cmd.Env {
+				if v, ok := strings.CutPrefix(kv, "GO111MODULE="); ok {
+					got = v
+				}
+			}
+
+			if got != tt.want {
+				t.Errorf("GO111MODULE = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLangHandler_lintGOPATH tests that a package of a GOPATH project is linted from
+// its directory with GOPATH mode, and that the issues it reports relative to it are
+// attached to the file.
+func TestLangHandler_lintGOPATH(t *testing.T) {
+	project := gopathFixture(t)
+	path := filepath.Join(project, "util", "util.go")
+
+	var issue Issue
+	issue.FromLinter = "errcheck"
+	issue.Text = "Error return value of `f.Close` is not checked"
+	issue.Pos.Filename, issue.Pos.Line, issue.Pos.Column = "util.go", 7, 9
+
+	command := fakeLinter(t, GolangCILintResult{Issues: []Issue{issue}})
+	// The fake linter only reports the issue when run the GOPATH way.
+	command[2] = `[ "$GO111MODULE" = off ] && [ "$(pwd -P)" = "$(cd "$1" && pwd -P)" ] && cat "$0"; exit 1`
+	command = append(command, filepath.Join(project, "util"))
+
+	h := &langHandler{rootDir: project, command: command}
+
+	results, err := h.lint(context.Background(), pathToURI(path))
+	if err != nil {
+		t.Fatalf("lint() returned unexpected error: %v", err)
+	}
+
+	diagnostics := results[pathToURI(path)]
+
+	want := []Diagnostic{{
+		Range:    Range{Start: Position{Line: 6, Character: 8}, End: Position{Line: 6, Character: 9}},
+		Severity: DSWarning,
+		Source:   pt("errcheck"),
+		Message:  "errcheck: Error return value of `f.Close` is not checked",
+	}}
+	if diff := cmp.Diff(want, diagnostics); diff != "" {
+		t.Errorf("lint() mismatch (-want +got):\n%s", diff)
+	}
+}
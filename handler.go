@@ -1,32 +1,64 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/sourcegraph/jsonrpc2"
 )
 
 func NewHandler(noLinterName bool) jsonrpc2.Handler {
+	return newLangHandler(noLinterName)
+}
+
+// defaultPublishBatchSize is the number of documents published between yields by default.
+const defaultPublishBatchSize = 50
+
+// requestQueueSize is the number of lint requests that can wait for the linter
+// without blocking the handling of further messages, such as cancellations.
+const requestQueueSize = 64
+
+func newLangHandler(noLinterName bool) *langHandler {
 	handler := &langHandler{
-		request:      make(chan DocumentURI),
+		initialized:  make(chan struct{}),
 		noLinterName: noLinterName,
+		backoff:      newFailureBackoff(),
+		metrics:      newLintMetrics(),
 	}
-	go handler.linter()
+	// Open documents keep their diagnostics whatever the bounds of the cache.
+	handler.results.pinned = handler.isOpen
+	handler.startupConcurrency = defaultConcurrency
+	handler.concurrency.Store(defaultConcurrency)
+	handler.start()
 
-	return jsonrpc2.HandlerWithError(handler.handle)
+	return handler
 }
 
 // pathConfig stores parsed golangci-lint command flags related to path handling.
 type pathConfig struct {
-	pathMode  string
-	configDir string
-	noConfig  bool
+	pathMode   string
+	pathPrefix string
+	configFile string
+	configDir  string
+	noConfig   bool
+	// relativePathMode is the value of the v2 --relative-path-mode flag.
+	relativePathMode string
+	// outFormat is the output format written to stdout: the value of the v1 --out-format
+	// flag, or the format of a v2 --output.<format>.path flag set to stdout.
+	outFormat string
 }
 
 // parseCommandFlags extracts path-related flags from the golangci-lint command.
@@ -42,21 +74,76 @@ func parseCommandFlags(command []string) pathConfig {
 			config.pathMode = command[i+1]
 		}
 
+		if after, ok := strings.CutPrefix(arg, "path-prefix="); ok {
+			config.pathPrefix = after
+		} else if arg == "path-prefix" && i+1 < len(command) {
+			config.pathPrefix = command[i+1]
+		}
+
 		if after, ok := strings.CutPrefix(arg, "config="); ok {
-			configPath := after
-			config.configDir = filepath.Dir(configPath)
+			config.configFile = after
+			config.configDir = filepath.Dir(after)
 		} else if arg == "config" && i+1 < len(command) {
+			config.configFile = command[i+1]
 			config.configDir = filepath.Dir(command[i+1])
 		}
 
+		if after, ok := strings.CutPrefix(arg, "relative-path-mode="); ok {
+			config.relativePathMode = after
+		} else if arg == "relative-path-mode" && i+1 < len(command) {
+			config.relativePathMode = command[i+1]
+		}
+
 		if arg == "no-config" {
 			config.noConfig = true
 		}
+
+		if after, ok := strings.CutPrefix(arg, "out-format="); ok {
+			config.outFormat = after
+		} else if arg == "out-format" && i+1 < len(command) {
+			config.outFormat = command[i+1]
+		}
+
+		if format, path, ok := outputPathFlag(arg, command[i+1:]); ok && path == "stdout" && config.outFormat != "json" {
+			config.outFormat = format
+		}
 	}
 
 	return config
 }
 
+// expandCommand substitutes the {path}, {dir} and {root} placeholders in the command.
+// It reports whether any placeholder was found, in which case the target is not appended.
+func expandCommand(command []string, path, dir, root string) ([]string, bool) {
+	replacer := strings.NewReplacer("{path}", path, "{dir}", dir, "{root}", root)
+
+	expanded := make([]string, len(command))
+	found := false
+	for i, arg := range command {
+		expanded[i] = replacer.Replace(arg)
+		if expanded[i] != arg {
+			found = true
+		}
+	}
+
+	return expanded, found
+}
+
+// stripPathPrefix removes the --path-prefix golangci-lint adds to relative issue paths,
+// so that they can be resolved against the base directory.
+func (pc pathConfig) stripPathPrefix(issuePath string) string {
+	prefix := filepath.Clean(filepath.FromSlash(pc.pathPrefix))
+	if pc.pathPrefix == "" || prefix == "." {
+		return issuePath
+	}
+
+	if rest, ok := strings.CutPrefix(filepath.Clean(issuePath), prefix+string(filepath.Separator)); ok {
+		return rest
+	}
+
+	return issuePath
+}
+
 // getBaseDir returns the base directory for resolving relative paths.
 func (pc pathConfig) getBaseDir(cmdDir, rootDir string) string {
 	if pc.pathMode == "abs" {
@@ -74,20 +161,398 @@ func (pc pathConfig) getBaseDir(cmdDir, rootDir string) string {
 	return rootDir
 }
 
+// relativePathBase returns the directory relative issue paths are resolved against
+// under the relative path mode of the --relative-path-mode flag or, failing that, of
+// the run.relative-path-mode of the config: the module root for gomod, the repository
+// root for gitroot, the config directory for cfg and the working directory, workDir,
+// for wd. It returns "" when no mode is set or its directory cannot be found.
+func (h *langHandler) relativePathBase(dir, workDir string) string {
+	config := h.lintConfigFor(dir)
+
+	mode := h.pathConfig.relativePathMode
+	if mode == "" && config != nil {
+		mode = config.relativePathMode
+	}
+
+	switch mode {
+	case "gomod":
+		return moduleRoot(dir)
+	case "gitroot":
+		return gitRoot(dir)
+	case "cfg":
+		if config != nil {
+			return filepath.Dir(config.path)
+		}
+	case "wd":
+		return workDir
+	}
+
+	return ""
+}
+
 type langHandler struct {
 	conn         *jsonrpc2.Conn
 	request      chan DocumentURI
 	command      []string
 	noLinterName bool
 	pathConfig   pathConfig
+	lintTarget   string
+
+	// retryOnTimeout reruns a lint with a doubled timeout when golangci-lint times out.
+	retryOnTimeout bool
+
+	// typecheckOnly withholds the other issues of a run that reports typecheck errors.
+	typecheckOnly bool
+
+	// lowPriority runs the lint processes at reduced CPU and I/O priority.
+	lowPriority bool
+
+	// allowExternalPaths keeps documents and issues outside the workspace.
+	allowExternalPaths bool
+
+	// alsoRunGoVet runs `go vet -json` next to golangci-lint and merges its diagnostics.
+	alsoRunGoVet bool
+
+	// lintByImportPath passes the import path of the package, resolved with go list,
+	// instead of its directory.
+	lintByImportPath bool
+	// importPathsMu guards importPaths, the import paths resolved per directory.
+	importPathsMu sync.Mutex
+	importPaths   map[string]string
+
+	// noGoFilesMu guards noGoFiles, the directories golangci-lint found no Go files
+	// to lint in, with the stamps of their Go files then, until a file of theirs changes.
+	noGoFilesMu sync.Mutex
+	noGoFiles   map[string]map[string]string
+
+	// workspaceLock serializes lint runs with other instances on the same workspace, if enabled.
+	workspaceLock *workspaceLock
+
+	// onlyTouchedLines drops diagnostics outside the lines edited since the document was opened.
+	onlyTouchedLines bool
+	touchedMu        sync.Mutex
+	touched          map[DocumentURI]*touchedLines
+
+	// mergeSamePosition combines the diagnostics sharing a range into one.
+	mergeSamePosition bool
+
+	// debounce coalesces the lints requested for a directory in quick succession.
+	debounce debouncer
+	// running are the lints of the linter goroutine a newer request cancels.
+	running runningLints
+
+	// showDocument is set when the client accepts window/showDocument.
+	showDocument bool
+
+	// relatedDocuments is set when the client accepts the reports of other documents
+	// in the response to textDocument/diagnostic.
+	relatedDocuments bool
+
+	// relatedInformation is set when the client shows the secondary locations of
+	// diagnostics.
+	relatedInformation bool
+
+	// codeDescription is set when the client renders links to the documentation of
+	// diagnostic codes.
+	codeDescription bool
+
+	// tagSupport are the diagnostic tags the client supports.
+	tagSupport []DiagnosticTag
+
+	// formattingRegistration is set when the client registers textDocument/formatting
+	// dynamically, which happens once golangci-lint is known to have the fmt subcommand.
+	formattingRegistration bool
+
+	// configPrompted is the config error the user was last offered to open the config for.
+	configPromptMu sync.Mutex
+	configPrompted string
+
+	// applyEdit is set when the client accepts workspace/applyEdit.
+	applyEdit bool
+
+	// workDoneProgress is set when the client accepts server initiated progress.
+	workDoneProgress bool
+	progressMu       sync.Mutex
+	progressCancels  map[ProgressToken]context.CancelFunc
+	nextProgressID   int
+
+	// stderrPattern selects the golangci-lint stderr lines forwarded as they are
+	// written, or nil to forward none.
+	stderrPattern *regexp.Regexp
+
+	// publishBatchSize is the number of documents published between yields.
+	publishBatchSize int
+
+	// deprecatedFlagWarned is set once the user was told about a deprecated flag.
+	deprecatedFlagWarned atomic.Bool
+
+	// disconnected is set once the connection to the client closed.
+	disconnected atomic.Bool
+
+	// trust decides whether the lint command may run; nil runs it without asking.
+	trust *trustStore
+
+	// allowedCommands restrict the commands that may be configured; nil allows any.
+	allowedCommands []allowedCommand
+
+	// outputFormatWarned is set once the user was told golangci-lint does not write JSON.
+	outputFormatWarned atomic.Bool
+
+	// binaryVersions caches the golangci-lint version of each version command.
+	binaryVersions sync.Map
+	// versionWarned is the last config version check, so that a mismatch is reported
+	// once until it changes.
+	versionWarnedMu sync.Mutex
+	versionWarned   string
+
+	// paused is set while document notifications must not trigger lints.
+	paused atomic.Bool
+
+	// open holds the documents currently open in the client, with their languageId.
+	openMu sync.Mutex
+	open   map[DocumentURI]string
+
+	// documents holds the content of the open documents.
+	documents documentStore
+
+	// languages are the languageIds of the documents that are linted.
+	languages []string
+
+	// mu guards closed, which is set on shutdown, and request and stopping, which are
+	// replaced when the server is initialized again. stopping is closed on shutdown,
+	// which releases the senders waiting for room in request. mu is never held while
+	// sending, as the linter may need it to make room.
+	mu       sync.Mutex
+	closed   bool
+	stopping chan struct{}
+	// linterDone is closed once the linter goroutine reading request returned.
+	linterDone chan struct{}
 
 	rootURI string
 	rootDir string
+
+	// singleFile is set when the client opened a file without a workspace. rootDir is
+	// then the module of that file, or empty when the client sent no rootUri at all.
+	singleFile bool
+
+	// folders are the directories of the workspace folders of a multi-root workspace,
+	// which change with workspace/didChangeWorkspaceFolders. rootDir is the first one
+	// when the client sent no rootUri.
+	foldersMu sync.Mutex
+	folders   []string
+
+	// ctx is cancelled to abort all lint work. Lints run under runCtx, a child of ctx
+	// replaced whenever the running lints must be aborted, for instance when the settings change.
+	ctxMu     sync.Mutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	runCtx    context.Context
+	runCancel context.CancelFunc
+
+	// settingsMu guards the settings applied from the options. Handlers and lints hold
+	// it for reading; new settings are applied holding it for writing.
+	settingsMu sync.RWMutex
+	// initOptions are the initializationOptions and effectiveOptions the options in
+	// effect after merging the client configuration into them.
+	initOptions      json.RawMessage
+	effectiveOptions json.RawMessage
+
+	// configuration is set when the client answers workspace/configuration requests.
+	configuration bool
+	// awaitingConfiguration is set from initialize until the first workspace/configuration
+	// request completes, when the initializationOptions set no command. No lint runs
+	// meanwhile, as the command to run is expected from the client configuration.
+	awaitingConfiguration atomic.Bool
+
+	// concurrency is the number of directories linted at the same time, set by the
+	// concurrency option or startupConcurrency, the value of -concurrency. The linter
+	// reads it without settingsMu, which the lints it waits for hold.
+	concurrency        atomic.Int32
+	startupConcurrency int
+
+	// lifecycle is the state of the server in the LSP lifecycle, and pending the document
+	// notifications held back until the initialized notification. pending is only used
+	// by the goroutine reading messages.
+	lifecycle atomic.Int32
+	pending   []*jsonrpc2.Request
+
+	// processes counts the running golangci-lint and go processes, which exit waits for.
+	processes sync.WaitGroup
+
+	// exit is os.Exit, unless replaced by tests.
+	exit func(code int)
+
+	// initialized is closed once the first initialize request arrived.
+	initialized     chan struct{}
+	initializedOnce sync.Once
+
+	// maxMessageLength is the number of characters messages are truncated to, or zero.
+	maxMessageLength int
+
+	// maxOutputBytes is the amount of golangci-lint output read before the run is
+	// killed; zero reads everything.
+	maxOutputBytes int
+
+	// excludeMessages drop the issues whose text matches one of them.
+	excludeMessages []*regexp.Regexp
+
+	// messageRewrites rewrite the text of issues before it is formatted.
+	messageRewrites []messageRewrite
+
+	// subprojects match the workspace-relative directories that are linted as independent roots.
+	subprojects []*regexp.Regexp
+
+	// pathSeverities override the severity of issues in matching files.
+	pathSeverities []pathSeverity
+
+	// severityGrades map severity grades like "HIGH" onto LSP severities.
+	severityGrades map[string]DiagnosticSeverity
+
+	// linterSeverities override the severity of the issues of a linter, by lowercase name.
+	linterSeverities map[string]DiagnosticSeverity
+
+	// diagnosticTags map linters and rules onto the tags of their diagnostics.
+	diagnosticTags map[string][]DiagnosticTag
+
+	// minimumSeverity hides the diagnostics below it; zero shows every severity.
+	minimumSeverity DiagnosticSeverity
+
+	// logLevel is the level of the logger, set by the logLevel option; nil when the
+	// logger is not the server's own. startupLogLevel is the level given by -debug,
+	// used when the option is unset.
+	logLevel        *slog.LevelVar
+	startupLogLevel slog.Level
+
+	// hidden holds the number of diagnostics below minimumSeverity in the last lint
+	// of each document.
+	hiddenMu sync.Mutex
+	hidden   map[DocumentURI]int
+
+	// lintOnOpen lints documents when they are opened, not only when they are saved.
+	lintOnOpen bool
+
+	// clearOnClose clears the diagnostics of documents when they are closed.
+	clearOnClose bool
+
+	// published holds the documents whose last published diagnostics were not empty,
+	// whichever lint published them.
+	publishedMu sync.Mutex
+	published   map[DocumentURI]bool
+
+	// held holds the documents whose diagnostics were not published while paused,
+	// published again on resume.
+	heldMu sync.Mutex
+	held   map[DocumentURI]bool
+
+	// results caches the last lint results, published on open when lintOnOpen is off.
+	results diagnosticsCache
+
+	// runs caches the results of golangci-lint by target, served while its inputs are unchanged.
+	runs runCache
+
+	// dismissed are the diagnostics hidden for the session with golangci-lint.dismiss.
+	dismissed dismissals
+
+	// backoff skips runs in directories where golangci-lint keeps failing the same way.
+	backoff *failureBackoff
+
+	// metrics count the lint runs and cache lookups for the debug HTTP listener.
+	metrics *lintMetrics
+
+	// vendorLogged holds the module roots whose -mod=vendor decision was logged.
+	vendorLogged sync.Map
+
+	// packageLints are the documents with diagnostics from the last lintPackage command
+	// of each pattern, cleared when a later run of the pattern no longer reports them.
+	packageLintsMu sync.Mutex
+	packageLints   map[string][]DocumentURI
+
+	// configMu guards lintConfigs and configFiles, which are used by both the
+	// message handlers and the linter.
+	configMu sync.Mutex
+	// lintConfigs caches parsed golangci-lint config files by path.
+	// A nil entry records a config that could not be loaded.
+	lintConfigs map[string]*lintConfig
+	// configFiles caches the config file discovered for a directory, "" if none.
+	configFiles map[string]string
 }
 
+// Values of the lintTarget initialization option.
+const (
+	// lintTargetPackage lints the directory of the document.
+	lintTargetPackage = "package"
+	// lintTargetFile lints only the document itself, at the cost of cross-file analyses.
+	lintTargetFile = "file"
+)
+
 // As defined in the `golangci-lint` source code:
 // https://github.com/golangci/golangci-lint/blob/main/pkg/exitcodes/exitcodes.go#L24
-const GoNoFilesExitCode = 5
+const (
+	TimeoutExitCode   = 4
+	GoNoFilesExitCode = 5
+)
+
+// defaultCommand is the command run when the options set none.
+var defaultCommand = []string{"golangci-lint", "run", "--output.json.path", "stdout", "--show-stats=false", "--issues-exit-code=1"}
+
+// defaultLintTimeout is assumed when neither the command nor the config sets run.timeout.
+const defaultLintTimeout = time.Minute
+
+// timeoutError is returned by lint when golangci-lint's own run.timeout fired.
+type timeoutError struct {
+	timeout time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("lint timed out after %s", e.timeout)
+}
+
+// commandTimeout returns the value of the last --timeout flag of the command, if any.
+func commandTimeout(command []string) (timeout time.Duration, found bool) {
+	for i, arg := range command {
+		arg = strings.TrimPrefix(arg, "--")
+
+		value, ok := strings.CutPrefix(arg, "timeout=")
+		if !ok && arg == "timeout" && i+1 < len(command) {
+			value, ok = command[i+1], true
+		}
+
+		if !ok {
+			continue
+		}
+
+		if d, err := time.ParseDuration(value); err == nil {
+			timeout, found = d, true
+		}
+	}
+
+	return timeout, found
+}
+
+// lintTimeout returns the run.timeout golangci-lint applies when linting dir.
+func (h *langHandler) lintTimeout(dir string) time.Duration {
+	if d, ok := commandTimeout(h.command); ok && d > 0 {
+		return d
+	}
+
+	if c := h.lintConfigFor(dir); c != nil && c.timeout > 0 {
+		return c.timeout
+	}
+
+	return defaultLintTimeout
+}
+
+// timeoutDiagnostics returns the informational diagnostic published in place of a timed out lint.
+func timeoutDiagnostics(err *timeoutError, retrying bool) []Diagnostic {
+	message := err.Error()
+	if retrying {
+		message += "; retrying with a longer budget"
+	}
+
+	return []Diagnostic{
+		{Severity: DSInformation, Message: message},
+	}
+}
 
 func (h *langHandler) errToDiagnostics(err error) []Diagnostic {
 	var message string
@@ -96,7 +561,7 @@ func (h *langHandler) errToDiagnostics(err error) []Diagnostic {
 		if e.ExitCode() == GoNoFilesExitCode {
 			return []Diagnostic{}
 		}
-		message = string(e.Stderr)
+		message = stripANSI(string(e.Stderr))
 	default:
 		slog.Debug("error converting to diagnostics", "message", message)
 		message = e.Error()
@@ -106,229 +571,1533 @@ func (h *langHandler) errToDiagnostics(err error) []Diagnostic {
 	}
 }
 
-func (h *langHandler) lint(uri DocumentURI) ([]Diagnostic, error) {
-	diagnostics := make([]Diagnostic, 0)
+// lintContext returns the context lint commands run under.
+func (h *langHandler) lintContext() context.Context {
+	h.ctxMu.Lock()
+	defer h.ctxMu.Unlock()
+
+	if h.runCtx == nil {
+		return context.Background()
+	}
+
+	return h.runCtx
+}
+
+// lint runs golangci-lint for the document. extraArgs are inserted before the target argument.
+// Cancelling ctx kills golangci-lint along with the processes it started.
+// The results hold the diagnostics of the document, possibly none, and those of the other
+// files of its directory that golangci-lint reported.
+func (h *langHandler) lint(ctx context.Context, uri DocumentURI, extraArgs ...string) (results map[DocumentURI][]Diagnostic, err error) {
+	start := time.Now()
+	h.metrics.runStarted()
+	defer func() { h.metrics.runFinished(ctx, results[uri], err, time.Since(start)) }()
+
+	if len(h.command) == 0 {
+		return nil, errors.New("no golangci-lint command is configured")
+	}
+
+	if h.needsOverlay(uri) {
+		diagnostics, err := h.lintOverlay(ctx, uri, extraArgs...)
+		if err != nil {
+			return nil, err
+		}
+
+		return map[DocumentURI][]Diagnostic{uri: diagnostics}, nil
+	}
 
 	path := uriToPath(string(uri))
+
+	byPath, err := h.lintPath(ctx, path, extraArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	results = map[DocumentURI][]Diagnostic{uri: byPath[path]}
+	for p, diagnostics := range byPath {
+		if p != path {
+			results[h.documentURI(p)] = diagnostics
+		}
+	}
+
+	return results, nil
+}
+
+// documentURI returns the URI the file at path was opened with, so that the diagnostics
+// of the other files of a package reach their documents whatever the escaping or drive
+// letter case of their URI, or the URI of path when it is not open.
+func (h *langHandler) documentURI(path string) DocumentURI {
+	h.openMu.Lock()
+	defer h.openMu.Unlock()
+
+	for uri := range h.open {
+		if samePath(uriToPath(string(uri)), path) {
+			return uri
+		}
+	}
+
+	return pathToURI(path)
+}
+
+// lintPath runs golangci-lint for the file at path and returns the diagnostics by file:
+// those of path, always present, and those of the other files of its directory.
+func (h *langHandler) lintPath(ctx context.Context, path string, extraArgs ...string) (map[string][]Diagnostic, error) {
+	diagnostics := make([]Diagnostic, 0)
+	only := func(diagnostics []Diagnostic) map[string][]Diagnostic {
+		return map[string][]Diagnostic{path: diagnostics}
+	}
+
 	dir, _ := filepath.Split(path)
 
-	args := make([]string, 0, len(h.command))
-	args = append(args, h.command[1:]...)
-	args = append(args, dir)
-	cmd := exec.Command(h.command[0], args...)
-	if strings.HasPrefix(path, h.rootDir) {
-		cmd.Dir = h.rootDir
-	} else {
-		cmd.Dir = dir
+	root := h.projectRoot(filepath.Dir(path))
+	if root == "" {
+		// Without a workspace, the module of the file stands in for it.
+		root = moduleRoot(filepath.Dir(path))
+	}
+
+	command, expanded := expandCommand(h.command, path, filepath.Dir(path), root)
+
+	// Linting a package without Go files only gets golangci-lint to exit with GoNoFilesExitCode.
+	lintsDir := !expanded && h.lintTarget != lintTargetFile
+	if lintsDir && h.nothingToLint(dir) {
+		slog.Debug("no Go files to lint, not running golangci-lint", "dir", dir)
+
+		return only(diagnostics), nil
+	}
+
+	// Outside modules, packages load relative to their own directory.
+	gopath := !inModule(dir)
+
+	workDir := dir
+	if isWithin(root, path) && !gopath {
+		workDir = root
+	}
+
+	args := make([]string, 0, len(command)+len(extraArgs))
+	args = append(args, command[1:]...)
+	args = append(args, extraArgs...)
+	if !expanded {
+		switch {
+		case h.lintTarget == lintTargetFile:
+			args = append(args, path)
+		case h.lintByImportPath:
+			if p, ok := h.importPath(ctx, dir, workDir); ok {
+				args = append(args, p)
+			} else {
+				args = append(args, dir)
+			}
+		default:
+			args = append(args, dir)
+		}
 	}
+	// Output over maxOutputBytes kills the run without cancelling the lint.
+	runCtx, kill := context.WithCancel(ctx)
+	defer kill()
+
+	cmd := exec.CommandContext(runCtx, command[0], args...)
+	setProcessGroup(cmd)
+	cmd.Dir = workDir
+
+	// stderr is streamed to the client while golangci-lint runs and kept for error diagnostics.
+	stderr := &lineWriter{onLine: h.logStderrLine}
+	cmd.Stderr = stderr
 
 	slog.Debug("running golangci-lint", "command", cmd.Args)
 
-	b, err := cmd.Output()
+	stdout := &limitedBuffer{limit: h.maxOutputBytes, onExceed: kill}
+	cmd.Stdout = stdout
+	err := h.runLintCommand(cmd)
+	b := stdout.Bytes()
+	stderr.Flush()
+	if stdout.Exceeded() && ctx.Err() == nil {
+		slog.Error("golangci-lint output exceeded maxOutputBytes, the run was killed", "path", path, "limit", h.maxOutputBytes)
+
+		return only([]Diagnostic{{Severity: DSError, Message: outputLimitMessage(h.maxOutputBytes)}}), nil
+	}
+	if e, ok := err.(*exec.ExitError); ok {
+		e.Stderr = stderr.Bytes()
+	}
+	if e, ok := err.(*exec.ExitError); ok && e.ExitCode() == TimeoutExitCode {
+		timeout := h.lintTimeout(dir)
+		if d, ok := commandTimeout(extraArgs); ok {
+			timeout = d
+		}
+
+		return nil, &timeoutError{timeout: timeout}
+	}
+	if lintsDir && exitCode(err) == GoNoFilesExitCode {
+		h.markNoGoFiles(dir)
+	}
+
 	if err == nil {
-		return diagnostics, nil
+		return only(diagnostics), nil
 	} else if len(b) == 0 {
 		// golangci-lint would output critical error to stderr rather than stdout
 		// https://github.com/nametake/golangci-lint-langserver/issues/24
-		return h.errToDiagnostics(err), nil
+		return only(h.errToDiagnostics(err)), nil
 	}
 
-	var result GolangCILintResult
-	if err := json.Unmarshal(b, &result); err != nil {
-		return h.errToDiagnostics(err), nil
+	// SARIF, set in the command or the config, is valid JSON and must be told apart first.
+	result, sarif := parseSARIFOutput(b)
+	if sarif {
+		slog.Debug("reading golangci-lint output as SARIF")
+	} else if jsonErr := json.Unmarshal(b, &result); jsonErr != nil {
+		// A format set in the config or by a wrapper script cannot be overridden;
+		// the issues of the line-number format can still be read.
+		lineResult, ok := parseLineNumberOutput(b)
+		if !ok {
+			return only(unparseableOutputDiagnostics(jsonErr, exitCode(err), b)), nil
+		}
+
+		h.warnOutputFormat("golangci-lint output is not JSON, reading it as the line-number format")
+		result = lineResult
 	}
 
 	slog.Debug("lint result", "result", result)
 
+	if h.typecheckOnly {
+		var withheld int
+		if result.Issues, withheld = withholdUntilCompiles(result.Issues); withheld > 0 {
+			slog.Info("package does not compile, withholding the other issues", "path", path, "withheld", withheld)
+		}
+	}
+
 	// Get absolute path of the target file for comparison.
 	absPath, err := filepath.Abs(path)
 	if err != nil {
-		return h.errToDiagnostics(err), nil
+		return only(h.errToDiagnostics(err)), nil
 	}
 
 	// Clean the path to ensure consistent comparison.
 	absPath = filepath.Clean(absPath)
 
-	// Determine base directory for resolving relative paths.
-	baseDir := h.pathConfig.getBaseDir(cmd.Dir, h.rootDir)
+	// Determine base directory for resolving relative paths. Like golangci-lint,
+	// an implicit config is discovered from the linted directory upward.
+	baseDir := cmp.Or(h.relativePathBase(dir, cmd.Dir), h.pathConfig.getBaseDir(cmd.Dir, cmp.Or(h.configBaseDir(dir), root)))
+
+	// In file mode golangci-lint may echo the file back relative to the
+	// working directory or to the file's own directory rather than the base directory.
+	baseDirs := []string{baseDir}
+	if h.lintTarget == lintTargetFile || gopath {
+		baseDirs = append(baseDirs, cmd.Dir, dir)
+	}
+
+	// Without the source, positions fall back to golangci-lint's byte columns.
+	src, err := readSourceLines(absPath)
+	if err != nil {
+		slog.Debug("failed to read source for position conversion", "path", absPath, "error", err)
+	}
+
+	// Secondary locations only need to exist, so every candidate base is tried.
+	relatedBaseDirs := []string{baseDir, cmd.Dir, dir}
+
+	// The issues of the other files of the directory are kept for them. Files outside
+	// it are skipped: they belong to other packages.
+	results := make(map[string][]Diagnostic)
+	sources := make(map[string]sourceLines)
+
+	excludedMessages := 0
 
 	for _, issue := range result.Issues {
-		issuePath := issue.Pos.Filename
-
-		// Convert issue path to absolute path for comparison.
-		if !filepath.IsAbs(issuePath) {
-			// Join with base directory and convert to absolute.
-			candidatePath := filepath.Join(baseDir, issuePath)
-			absIssuePath, err := filepath.Abs(candidatePath)
-			if err != nil {
-				continue
-			}
+		issue.Pos.Filename = h.pathConfig.stripPathPrefix(issue.Pos.Filename)
+		issue.Text = stripANSI(issue.Text)
 
-			absIssuePath = filepath.Clean(absIssuePath)
+		if h.excludesMessage(issue.Text) {
+			excludedMessages++
 
-			// If direct join doesn't match, try fallback suffix matching.
-			// This handles cases where a global config exists but wasn't explicitly specified.
-			if absIssuePath != absPath {
-				issueBase := filepath.Base(issuePath)
-				targetBase := filepath.Base(absPath)
+			continue
+		}
 
-				if issueBase != targetBase {
-					continue
+		if !issueMatchesPath(issue.Pos.Filename, absPath, baseDirs) {
+			if sibling, ok := resolveIssuePath(issue.Pos.Filename, relatedBaseDirs); ok && samePath(filepath.Dir(sibling), filepath.Clean(dir)) {
+				siblingSrc, ok := sources[sibling]
+				if !ok {
+					siblingSrc, _ = readSourceLines(sibling)
+					sources[sibling] = siblingSrc
 				}
 
-				if !strings.HasSuffix(absPath, issuePath) {
-					continue
-				}
-			}
-		} else {
-			// Path is already absolute, clean it for comparison.
-			absIssuePath := filepath.Clean(issuePath)
-			if absIssuePath != absPath {
+				results[sibling] = append(results[sibling], h.issueDiagnostic(&issue, sibling, siblingSrc, relatedBaseDirs))
+
 				continue
 			}
-		}
 
-		d := Diagnostic{
-			Range: Range{
-				Start: Position{
-					Line:      max(issue.Pos.Line-1, 0),
-					Character: max(issue.Pos.Column-1, 0),
-				},
-				End: Position{
-					Line:      max(issue.Pos.Line-1, 0),
-					Character: max(issue.Pos.Column-1, 0),
-				},
-			},
-			Severity: issue.DiagSeverity(),
-			Source:   &issue.FromLinter,
-			Message:  h.diagnosticMessage(&issue),
+			if d, ok := h.externalDiagnostic(&issue, relatedBaseDirs); ok {
+				h.limitMessage(&d)
+				diagnostics = append(diagnostics, d)
+			}
+
+			continue
 		}
-		diagnostics = append(diagnostics, d)
+
+		diagnostics = append(diagnostics, h.issueDiagnostic(&issue, absPath, src, relatedBaseDirs))
+	}
+
+	if excludedMessages > 0 {
+		slog.Debug("dropped issues matching excludeMessages", "path", path, "count", excludedMessages)
 	}
 
-	return diagnostics, nil
+	results[path] = diagnostics
+
+	return results, nil
 }
 
-func (h *langHandler) diagnosticMessage(issue *Issue) string {
-	if h.noLinterName {
-		return issue.Text
+// issueDiagnostic converts an issue in the file at absPath, whose source is src, into a diagnostic.
+func (h *langHandler) issueDiagnostic(issue *Issue, absPath string, src sourceLines, relatedBaseDirs []string) Diagnostic {
+	pos := src.position(issue.Pos.Line, issue.Pos.Column)
+
+	d := Diagnostic{
+		Range: Range{
+			Start: pos,
+			End:   src.tokenEnd(issue.Pos.Line, issue.Pos.Column),
+		},
+		Severity: h.issueSeverity(issue),
+		Source:   &issue.FromLinter,
+		Message:  h.diagnosticMessage(issue),
 	}
+	if h.relatedInformation {
+		d.RelatedInformation = relatedInformation(issue, absPath, relatedBaseDirs)
+	}
+	h.describeCode(&d, issue.FromLinter, issue.Text)
+	h.tagDiagnostic(&d, issue.FromLinter, issue.Text)
+	// Issues of linters like funlen or dupl cover a block of lines.
+	if r, ok := src.lineRange(issue.LineRange.From, issue.LineRange.To); ok {
+		d.Range = r
+	}
+	if s, ok := h.pathSeverity(absPath); ok {
+		d.Severity = s
+	}
+	if h.lintTarget == lintTargetFile && isSingleFileNoise(issue) {
+		d.Severity = DSHint
+	}
+	if hunks := issueHunks(issue); len(hunks) > 0 {
+		d.Data = &DiagnosticData{Hunks: hunks}
+	}
+	if unused := unusedNolint(issue); unused != nil {
+		d.Data = &DiagnosticData{UnusedNolint: unused}
+	}
+	h.limitMessage(&d)
 
-	return fmt.Sprintf("%s: %s", issue.FromLinter, issue.Text)
+	return d
 }
 
-func (h *langHandler) linter() {
-	for {
-		uri, ok := <-h.request
-		if !ok {
-			break
-		}
+// issueMatchesPath reports whether the issue path refers to absPath, trying each of
+// baseDirs to resolve relative paths.
+func issueMatchesPath(issuePath, absPath string, baseDirs []string) bool {
+	if filepath.IsAbs(issuePath) {
+		// Path is already absolute, clean it for comparison.
+		return samePath(filepath.Clean(issuePath), absPath)
+	}
 
-		diagnostics, err := h.lint(uri)
+	for _, baseDir := range baseDirs {
+		// Join with base directory and convert to absolute.
+		absIssuePath, err := filepath.Abs(filepath.Join(baseDir, issuePath))
 		if err != nil {
-			slog.Error("lint error", "error", err)
-
 			continue
 		}
 
-		if err := h.conn.Notify(
-			context.Background(),
-			"textDocument/publishDiagnostics",
-			&PublishDiagnosticsParams{
-				URI:         uri,
-				Diagnostics: diagnostics,
-			}); err != nil {
-			slog.Error("failed to publish diagnostics", "error", err)
+		if samePath(filepath.Clean(absIssuePath), absPath) {
+			return true
 		}
 	}
+
+	// If direct join doesn't match, try fallback suffix matching.
+	// This handles cases where a global config exists but wasn't explicitly specified.
+	// The issues of a package never belong to a testdata file the suffix happens to match.
+	if testdataBelowBase(issuePath, absPath, baseDirs) {
+		return false
+	}
+
+	return hasPathSuffix(absPath, issuePath)
 }
 
-func (h *langHandler) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
-	slog.Debug("handling request", "method", req.Method)
+// excludesMessage reports whether the issue text matches one of the excludeMessages.
+func (h *langHandler) excludesMessage(text string) bool {
+	for _, re := range h.excludeMessages {
+		if re.MatchString(text) {
+			return true
+		}
+	}
 
-	switch req.Method {
-	case "initialize":
-		return h.handleInitialize(ctx, conn, req)
-	case "initialized":
-		return
-	case "shutdown":
-		return h.handleShutdown(ctx, conn, req)
-	case "textDocument/didOpen":
-		return h.handleTextDocumentDidOpen(ctx, conn, req)
-	case "textDocument/didClose":
-		return h.handleTextDocumentDidClose(ctx, conn, req)
-	case "textDocument/didChange":
+	return false
+}
+
+// withholdUntilCompiles keeps only the typecheck issues when there are any: linters
+// working on a package that does not compile report findings that bury the real error.
+// It returns the kept issues and the number of withheld ones.
+func withholdUntilCompiles(issues []Issue) ([]Issue, int) {
+	if !slices.ContainsFunc(issues, func(issue Issue) bool { return issue.FromLinter == "typecheck" }) {
+		return issues, 0
+	}
+
+	kept := slices.DeleteFunc(slices.Clone(issues), func(issue Issue) bool { return issue.FromLinter != "typecheck" })
+
+	return kept, len(issues) - len(kept)
+}
+
+// isSingleFileNoise reports whether the issue is a typecheck error caused by
+// linting a file without the rest of its package.
+func isSingleFileNoise(issue *Issue) bool {
+	if issue.FromLinter != "typecheck" {
+		return false
+	}
+
+	return strings.HasPrefix(issue.Text, "undefined: ") ||
+		strings.Contains(issue.Text, "has no field or method")
+}
+
+func (h *langHandler) diagnosticMessage(issue *Issue) string {
+	text := rewriteMessage(h.messageRewrites, issue.FromLinter, issue.Text)
+
+	if h.noLinterName {
+		return text
+	}
+
+	return fmt.Sprintf("%s: %s", issue.FromLinter, text)
+}
+
+// lintConfigFor returns the parsed golangci-lint config that applies to files in dir, or nil.
+func (h *langHandler) lintConfigFor(dir string) *lintConfig {
+	if h.pathConfig.noConfig {
+		return nil
+	}
+
+	configPath := h.pathConfig.configFile
+	if configPath == "" {
+		configPath = h.discoverConfigFile(dir)
+	} else if !filepath.IsAbs(configPath) {
+		configPath = filepath.Join(h.projectRoot(dir), configPath)
+	}
+
+	if configPath == "" {
+		return nil
+	}
+
+	h.configMu.Lock()
+	defer h.configMu.Unlock()
+
+	if c, ok := h.lintConfigs[configPath]; ok {
+		return c
+	}
+
+	c, err := loadLintConfig(configPath)
+	if err != nil {
+		slog.Warn("failed to load golangci-lint config", "path", configPath, "error", err)
+	}
+
+	if h.lintConfigs == nil {
+		h.lintConfigs = make(map[string]*lintConfig)
+	}
+	h.lintConfigs[configPath] = c
+
+	return c
+}
+
+// discoverConfigFile returns the config file golangci-lint picks up when linting dir
+// without --config, or "" if there is none. Results are cached per directory.
+func (h *langHandler) discoverConfigFile(dir string) string {
+	dir = filepath.Clean(dir)
+
+	h.configMu.Lock()
+	defer h.configMu.Unlock()
+
+	if path, ok := h.configFiles[dir]; ok {
+		return path
+	}
+
+	path := findConfigFile(dir)
+
+	if h.configFiles == nil {
+		h.configFiles = make(map[string]string)
+	}
+	h.configFiles[dir] = path
+
+	return path
+}
+
+// configBaseDir returns the directory golangci-lint resolves relative issue paths
+// against when linting dir: the directory of the discovered config, or the root.
+func (h *langHandler) configBaseDir(dir string) string {
+	if h.pathConfig.noConfig || h.pathConfig.configFile != "" {
+		return h.projectRoot(dir)
+	}
+
+	if path := h.discoverConfigFile(dir); path != "" {
+		return filepath.Dir(path)
+	}
+
+	return h.projectRoot(dir)
+}
+
+// isConfigFile reports whether path has the name of a golangci-lint config file.
+func isConfigFile(path string) bool {
+	return slices.Contains(configFileNames, filepath.Base(path))
+}
+
+// resetLintConfigs drops the parsed configs, discovery results and failure backoff,
+// reloads the config of the workspace root and checks its version.
+func (h *langHandler) resetLintConfigs() {
+	h.configMu.Lock()
+	h.lintConfigs = nil
+	h.configFiles = nil
+	h.configMu.Unlock()
+
+	h.runs.clear()
+
+	h.backoff.reset()
+	h.resetConfigPrompt()
+
+	if h.rootDir != "" {
+		h.lintConfigFor(h.rootDir)
+
+		// The check may run golangci-lint and must not hold up the caller.
+		if config := h.configFileFor(h.rootDir); config != "" {
+			go h.checkConfigVersion(h.serverContext(), h.rootDir, config)
+		}
+	}
+}
+
+// excluded reports whether the config's exclude rules drop every issue in the document.
+func (h *langHandler) excluded(uri DocumentURI) bool {
+	path, err := filepath.Abs(uriToPath(string(uri)))
+	if err != nil {
+		return false
+	}
+
+	c := h.lintConfigFor(filepath.Dir(path))
+
+	return c != nil && c.excludes(path)
+}
+
+// inWorkspace reports whether path is inside the workspace, in any of its folders.
+// Every path is considered inside when there is no workspace root.
+func (h *langHandler) inWorkspace(path string) bool {
+	roots := h.workspaceRoots()
+	if len(roots) == 0 {
+		return true
+	}
+
+	for _, root := range roots {
+		root, err := filepath.Abs(root)
+		if err != nil {
+			return true
+		}
+
+		if isWithin(root, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// externalDiagnostic turns a typecheck issue located outside the workspace, such as in the
+// module cache or GOROOT, into a diagnostic at the top of the linted document, so that the
+// reason a package fails to build is not lost. Other issues outside the workspace are dropped.
+func (h *langHandler) externalDiagnostic(issue *Issue, baseDirs []string) (Diagnostic, bool) {
+	if h.allowExternalPaths {
+		return Diagnostic{}, false
+	}
+
+	path, ok := resolveIssuePath(issue.Pos.Filename, baseDirs)
+	if !ok || h.inWorkspace(path) {
+		return Diagnostic{}, false
+	}
+
+	if issue.FromLinter != "typecheck" {
+		slog.Debug("dropping issue outside the workspace", "path", path, "linter", issue.FromLinter, "text", issue.Text)
+
+		return Diagnostic{}, false
+	}
+
+	external := *issue
+	external.Text = fmt.Sprintf("%s:%d:%d: %s", path, issue.Pos.Line, issue.Pos.Column, issue.Text)
+
+	return Diagnostic{
+		Severity: h.issueSeverity(&external),
+		Source:   &external.FromLinter,
+		Message:  h.diagnosticMessage(&external),
+	}, true
+}
+
+// enqueue schedules a lint of the document, or clears its diagnostics right away
+// when golangci-lint would exclude or ignore it anyway or it lies outside the workspace.
+func (h *langHandler) enqueue(ctx context.Context, uri DocumentURI) error {
+	if h.paused.Load() {
+		slog.Debug("linting is paused", "uri", uri)
+
+		return nil
+	}
+
+	if h.awaitingConfiguration.Load() {
+		slog.Debug("waiting for the client configuration", "uri", uri)
+
+		return nil
+	}
+
+	if !h.lintable(uri) {
+		slog.Debug("skipping document of another language", "uri", uri)
+
+		return nil
+	}
+
+	if isUntitled(uri) {
+		// Untitled documents have no path to check against the workspace or exclusions.
+		h.queue(uri)
+
+		return nil
+	}
+
+	if !h.allowExternalPaths {
+		if path, err := filepath.Abs(uriToPath(string(uri))); err == nil && !h.inWorkspace(path) {
+			slog.Debug("skipping document outside the workspace", "uri", uri)
+
+			return h.publishDiagnostics(ctx, uri, []Diagnostic{})
+		}
+	}
+
+	if h.excluded(uri) {
+		slog.Debug("skipping excluded document", "uri", uri)
+
+		return h.publishDiagnostics(ctx, uri, []Diagnostic{})
+	}
+
+	if path, err := filepath.Abs(uriToPath(string(uri))); err == nil && h.inTestdata(path) {
+		slog.Debug("skipping document under testdata", "uri", uri)
+
+		return h.publishDiagnostics(ctx, uri, []Diagnostic{})
+	}
+
+	h.queueDebounced(uri)
+
+	return nil
+}
+
+// queue hands the document to the linter, unless the server has shut down. A running
+// lint of the same target is cancelled, as the queued one replaces its results.
+func (h *langHandler) queue(uri DocumentURI) {
+	h.supersedeRunningLint(uri)
+	h.sendRequest(uri)
+}
+
+// sendRequest sends the document to the linter, unless the server shuts down first.
+func (h *langHandler) sendRequest(uri DocumentURI) {
+	h.mu.Lock()
+	closed, request, stopping := h.closed, h.request, h.stopping
+	h.mu.Unlock()
+
+	if closed {
+		slog.Debug("server is shut down, not linting", "uri", uri)
+
+		return
+	}
+
+	select {
+	case request <- uri:
+	case <-stopping:
+		slog.Debug("server is shut down, not linting", "uri", uri)
+	}
+}
+
+// publishAll publishes the diagnostics of every document in batches of publishBatchSize
+// documents, open documents first. The linter yields between batches and reports
+// the publishing as progress under token, so that a large result neither holds up
+// other work nor floods the client in one go.
+func (h *langHandler) publishAll(token ProgressToken, results map[DocumentURI][]Diagnostic) {
+	uris := make([]DocumentURI, 0, len(results))
+	for uri := range results {
+		uris = append(uris, uri)
+	}
+
+	h.openMu.Lock()
+	slices.SortFunc(uris, func(a, b DocumentURI) int {
+		_, aOpen := h.open[a]
+		_, bOpen := h.open[b]
+		if aOpen != bOpen {
+			if aOpen {
+				return -1
+			}
+
+			return 1
+		}
+
+		return strings.Compare(string(a), string(b))
+	})
+	h.openMu.Unlock()
+
+	batchSize := h.publishBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultPublishBatchSize
+	}
+
+	for start := 0; start < len(uris); start += batchSize {
+		if len(uris) > batchSize {
+			h.reportProgress(token, fmt.Sprintf("publishing diagnostics (%d/%d files)", start, len(uris)), start*100/len(uris))
+		}
+
+		for _, uri := range uris[start:min(start+batchSize, len(uris))] {
+			if h.disconnected.Load() {
+				return
+			}
+
+			if err := h.publishDiagnostics(context.Background(), uri, h.clampToDocument(uri, results[uri])); err != nil {
+				slog.Error("failed to publish diagnostics", "error", err)
+			}
+		}
+
+		runtime.Gosched()
+	}
+}
+
+// clampToDocument clamps the diagnostics to the bounds of the current content of the
+// document, which a stale run may exceed after the end of the file was deleted.
+// The document is read once per publish, only when there are diagnostics to check.
+func (h *langHandler) clampToDocument(uri DocumentURI, diagnostics []Diagnostic) []Diagnostic {
+	if len(diagnostics) == 0 {
+		return diagnostics
+	}
+
+	text, ok := h.documentText(uri)
+	if !ok {
+		return diagnostics
+	}
+
+	diagnostics, clamped := newSourceLines(text).clampDiagnostics(diagnostics)
+	if clamped > 0 {
+		slog.Info("clamped diagnostics to the document bounds", "uri", uri, "count", clamped)
+	}
+
+	return diagnostics
+}
+
+// publishDiagnostics sends the diagnostics of the document. Once the connection has
+// closed, nothing is sent and no error is returned: the server is stopping.
+func (h *langHandler) publishDiagnostics(ctx context.Context, uri DocumentURI, diagnostics []Diagnostic) error {
+	if h.disconnected.Load() {
+		return nil
+	}
+
+	err := h.conn.Notify(
+		ctx,
+		"textDocument/publishDiagnostics",
+		&PublishDiagnosticsParams{
+			URI:         uri,
+			Diagnostics: diagnostics,
+		})
+	if h.notifyFailed(err) {
+		return nil
+	}
+
+	if err == nil {
+		h.publishedMu.Lock()
+		if len(diagnostics) > 0 {
+			if h.published == nil {
+				h.published = make(map[DocumentURI]bool)
+			}
+			h.published[uri] = true
+		} else {
+			delete(h.published, uri)
+		}
+		h.publishedMu.Unlock()
+	}
+
+	return err
+}
+
+// lintWithRetry lints the document, retrying once with a doubled --timeout
+// when golangci-lint's own timeout fired.
+func (h *langHandler) lintWithRetry(ctx context.Context, uri DocumentURI) (map[DocumentURI][]Diagnostic, error) {
+	results, err := h.lint(ctx, uri)
+
+	var te *timeoutError
+	if !errors.As(err, &te) {
+		return results, err
+	}
+
+	if !h.retryOnTimeout {
+		slog.Warn("golangci-lint timed out", "uri", uri, "timeout", te.timeout)
+
+		return map[DocumentURI][]Diagnostic{uri: timeoutDiagnostics(te, false)}, nil
+	}
+
+	if err := h.publishDiagnostics(context.Background(), uri, timeoutDiagnostics(te, true)); err != nil {
+		slog.Error("failed to publish diagnostics", "error", err)
+	}
+
+	results, err = h.lint(ctx, uri, "--timeout="+(2*te.timeout).String())
+	if !errors.As(err, &te) {
+		return results, err
+	}
+
+	slog.Warn("golangci-lint timed out again; increase run.timeout in the golangci-lint config or pass --timeout in the command",
+		"uri", uri, "timeout", te.timeout)
+
+	return map[DocumentURI][]Diagnostic{uri: timeoutDiagnostics(te, false)}, nil
+}
+
+// requeue puts the document back in the lint queue after delay, unless the server has shut down.
+func (h *langHandler) requeue(uri DocumentURI, delay time.Duration) {
+	time.AfterFunc(delay, func() { h.sendRequest(uri) })
+}
+
+// lockWorkspace takes the workspace lock, if enabled, before linting the document.
+// It reports whether the lock was taken and whether the lint should proceed;
+// a lint that waited too long is re-queued instead.
+//
+// The lints of this instance running in parallel take the lock too, so that with
+// lockWorkspace every lint of the workspace runs alone.
+func (h *langHandler) lockWorkspace(uri DocumentURI) (locked, proceed bool) {
+	if h.workspaceLock == nil {
+		return false, true
+	}
+
+	locked, err := h.workspaceLock.lock(lockWaitTimeout)
+	if err != nil {
+		slog.Warn("failed to take the workspace lock, linting anyway", "error", err)
+
+		return false, true
+	}
+
+	if !locked {
+		slog.Info("workspace is being linted by another lint or instance, re-queueing", "uri", uri)
+		h.requeue(uri, lockRequeueDelay)
+
+		return false, false
+	}
+
+	return true, true
+}
+
+// linter lints the requested documents in a lintPool until stopping is closed, then
+// waits for the running lints. The lints still queued then are dropped.
+func (h *langHandler) linter(requests <-chan DocumentURI, stopping <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	pool := newLintPool(func(uri DocumentURI) {
+		select {
+		case <-stopping:
+			slog.Debug("server is shut down, dropping queued lint", "uri", uri)
+		default:
+			h.lintDocument(uri)
+		}
+	})
+
+	for {
+		select {
+		case uri := <-requests:
+			pool.submit(uri, int(h.concurrency.Load()))
+		case <-stopping:
+			pool.wait()
+
+			return
+		}
+	}
+}
+
+// lintDocument lints the document and publishes its diagnostics.
+func (h *langHandler) lintDocument(uri DocumentURI) {
+	if h.paused.Load() {
+		// Lints queued before the pause are dropped: resuming lints the open documents.
+		slog.Debug("linting is paused, dropping queued lint", "uri", uri)
+
+		return
+	}
+
+	h.settingsMu.RLock()
+	defer h.settingsMu.RUnlock()
+
+	dir := filepath.Dir(uriToPath(string(uri)))
+	if diagnostics, ok := h.backoff.skip(dir, time.Now()); ok {
+		slog.Info("skipping lint after repeated failures", "uri", uri)
+
+		if err := h.publishDiagnostics(context.Background(), uri, diagnostics); err != nil {
+			slog.Error("failed to publish diagnostics", "error", err)
+		}
+
+		return
+	}
+
+	locked, ok := h.lockWorkspace(uri)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(h.lintContext())
+	token := h.beginProgress(ctx, uri, cancel)
+
+	key := h.lintKey(uri)
+	run := h.running.start(key, cancel)
+
+	// The inputs are identified before the run, so that edits made meanwhile invalidate the result.
+	resultID := h.resultID(uri)
+
+	results, err := h.lintCached(ctx, uri, resultID)
+	diagnostics := results[uri]
+
+	if locked {
+		if err := h.workspaceLock.unlock(); err != nil {
+			slog.Warn("failed to release the workspace lock", "error", err)
+		}
+	}
+
+	defer cancel()
+
+	// A superseded run may have completed before it was cancelled: its results are
+	// dropped all the same, as those of the newer run replace them.
+	if h.running.finish(key, run) {
+		slog.Info("lint superseded", "uri", uri)
+		h.endProgress(token, "superseded")
+
+		return
+	}
+
+	// A cancelled run leaves the previously published diagnostics in place.
+	if ctx.Err() != nil {
+		slog.Info("lint cancelled", "uri", uri)
+		h.endProgress(token, "cancelled")
+
+		return
+	}
+
+	if err != nil {
+		slog.Error("lint error", "error", err)
+		h.endProgress(token, "failed")
+
+		return
+	}
+
+	h.reportProgress(token, fmt.Sprintf("%s: %s", h.progressTarget(uri), issueCount(results)), 0)
+
+	signature, failed := failureSignature(diagnostics)
+	if failed {
+		h.offerConfigJump(dir, signature)
+		diagnostics = h.backoff.failure(dir, signature, diagnostics, time.Now())
+	} else {
+		h.backoff.success(dir)
+	}
+
+	if h.onlyTouchedLines {
+		diagnostics = h.filterTouched(uri, diagnostics)
+	}
+
+	diagnostics = h.dismissed.filter(uri, diagnostics)
+	diagnostics, hidden := h.filterSeverity(uri, diagnostics)
+	diagnostics = h.mergeSameRange(diagnostics)
+
+	h.results.set(uri, diagnostics, resultID)
+
+	published := map[DocumentURI][]Diagnostic{uri: diagnostics}
+	if !failed {
+		h.addSiblingResults(uri, resultID, results, published)
+	}
+
+	h.publishAll(token, published)
+	h.endProgress(token, hiddenMessage(hidden))
+}
+
+// filterTouched keeps the diagnostics on lines edited since the document was opened.
+// Typecheck errors and errors not tied to an issue are always kept.
+func (h *langHandler) filterTouched(uri DocumentURI, diagnostics []Diagnostic) []Diagnostic {
+	h.touchedMu.Lock()
+	defer h.touchedMu.Unlock()
+
+	t, ok := h.touched[uri]
+	if !ok {
+		t = &touchedLines{}
+	}
+
+	filtered := make([]Diagnostic, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		if d.Source == nil || *d.Source == "typecheck" || t.overlaps(d.Range.Start.Line, d.Range.End.Line) {
+			filtered = append(filtered, d)
+		}
+	}
+
+	return filtered
+}
+
+// asyncMethods are the requests answered from their own goroutine, because they may run
+// golangci-lint and the connection must keep reading messages, such as cancellations, meanwhile.
+var asyncMethods = map[string]bool{
+	"textDocument/diagnostic": true,
+	"workspace/diagnostic":    true,
+	"textDocument/formatting": true,
+}
+
+// Handle implements jsonrpc2.Handler. Messages are handled in order, except for asyncMethods.
+func (h *langHandler) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	if !h.admit(ctx, conn, req) {
+		return
+	}
+
+	handler := jsonrpc2.HandlerWithError(h.handle)
+
+	if asyncMethods[req.Method] && !req.Notif {
+		go func() {
+			h.settingsMu.RLock()
+			defer h.settingsMu.RUnlock()
+
+			handler.Handle(ctx, conn, req)
+		}()
+
+		return
+	}
+
+	// initialize applies the settings itself.
+	if req.Method != "initialize" {
+		h.settingsMu.RLock()
+		defer h.settingsMu.RUnlock()
+	}
+
+	handler.Handle(ctx, conn, req)
+}
+
+func (h *langHandler) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	slog.Debug("handling request", "method", req.Method)
+
+	result, err = h.dispatch(ctx, conn, req)
+	if err != nil && req.Notif {
+		// Notifications have no response to carry the error.
+		slog.Warn("dropping notification", "method", req.Method, "error", err)
+
+		return nil, nil
+	}
+
+	return result, err
+}
+
+func (h *langHandler) dispatch(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	switch req.Method {
+	case "initialize":
+		return h.handleInitialize(ctx, conn, req)
+	case "initialized":
+		h.handleInitialized(ctx, conn)
+
+		return
+	case "shutdown":
+		return h.handleShutdown(ctx, conn, req)
+	case "exit":
+		h.handleExit()
+
+		return
+	case "textDocument/didOpen":
+		return h.handleTextDocumentDidOpen(ctx, conn, req)
+	case "textDocument/didClose":
+		return h.handleTextDocumentDidClose(ctx, conn, req)
+	case "textDocument/didChange":
 		return h.handleTextDocumentDidChange(ctx, conn, req)
 	case "textDocument/didSave":
 		return h.handleTextDocumentDidSave(ctx, conn, req)
+	case "window/workDoneProgress/cancel":
+		return h.handleWorkDoneProgressCancel(ctx, conn, req)
 	case "workspace/didChangeConfiguration":
 		return h.handlerWorkspaceDidChangeConfiguration(ctx, conn, req)
+	case "textDocument/diagnostic":
+		return h.handleTextDocumentDiagnostic(ctx, conn, req)
+	case "workspace/diagnostic":
+		return h.handleWorkspaceDiagnostic(ctx, conn, req)
+	case "textDocument/codeAction":
+		return h.handleTextDocumentCodeAction(ctx, conn, req)
+	case "textDocument/formatting":
+		return h.handleTextDocumentFormatting(ctx, conn, req)
+	case "workspace/executeCommand":
+		return h.handleWorkspaceExecuteCommand(ctx, conn, req)
+	case "workspace/didChangeWatchedFiles":
+		return h.handleWorkspaceDidChangeWatchedFiles(ctx, conn, req)
+	case "workspace/didChangeWorkspaceFolders":
+		return h.handleWorkspaceDidChangeWorkspaceFolders(ctx, conn, req)
 	}
 
 	return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: fmt.Sprintf("method not supported: %s", req.Method)}
 }
 
-func (h *langHandler) handleInitialize(_ context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+func (h *langHandler) handleInitialize(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
 	var params InitializeParams
-	if err := json.Unmarshal(*req.Params, &params); err != nil {
+	if err := decodeParams(req, &params); err != nil {
 		return nil, err
 	}
 
+	// A command outside the policy fails initialize before anything changes.
+	requested, _ := parseInitializationOptions(params.InitializationOptions)
+	if err := h.checkAllowedCommand(requested.Command); err != nil {
+		return nil, invalidParams("%v", err)
+	}
+
+	h.initializedOnce.Do(func() { close(h.initialized) })
+
+	// A client may initialize the server again after shutting it down.
+	h.mu.Lock()
+	restart := h.closed
+	h.mu.Unlock()
+	if restart {
+		slog.Info("initialized again after shutdown")
+		h.start()
+	}
+
+	// A null processId means the client does not want its process to be watched.
+	if params.ProcessID != nil {
+		go watchProcess(h.serverContext(), *params.ProcessID, parentPollInterval, func() {
+			slog.Error("golangci-lint-langserver: client process exited, exiting", "pid", *params.ProcessID)
+			h.lintCancel()
+			h.exitProcess(exitClientGone)
+		})
+	}
+
+	h.workDoneProgress = params.Capabilities.Window.WorkDoneProgress
+	h.applyEdit = params.Capabilities.Workspace.ApplyEdit
+	h.showDocument = params.Capabilities.Window.ShowDocument != nil && params.Capabilities.Window.ShowDocument.Support
+	h.configuration = params.Capabilities.Workspace.Configuration
+	h.relatedInformation = params.Capabilities.TextDocument.PublishDiagnostics.RelatedInformation
+	h.codeDescription = params.Capabilities.TextDocument.PublishDiagnostics.CodeDescriptionSupport
+	h.tagSupport = nil
+	if tagSupport := params.Capabilities.TextDocument.PublishDiagnostics.TagSupport; tagSupport != nil {
+		h.tagSupport = tagSupport.ValueSet
+	}
+	formatting := params.Capabilities.TextDocument.Formatting
+	h.formattingRegistration = formatting != nil && formatting.DynamicRegistration
+	pullDiagnostics := params.Capabilities.TextDocument.Diagnostic
+	h.relatedDocuments = pullDiagnostics != nil && pullDiagnostics.RelatedDocumentSupport
+
 	h.rootURI = params.RootURI
-	h.rootDir = uriToPath(params.RootURI)
+	folders := folderDirs(params.WorkspaceFolders)
+	if h.rootURI == "" && len(folders) > 0 {
+		h.rootURI = string(pathToURI(folders[0]))
+	}
+	h.setFolders(folders)
+
+	h.rootDir, h.singleFile = resolveRoot(h.rootURI)
+	if h.singleFile {
+		slog.Info("no workspace, linting single files", "rootUri", params.RootURI, "root", h.rootDir)
+	}
+	if h.conn != conn {
+		h.disconnected.Store(false)
+		go h.watchConnection(conn)
+	}
 	h.conn = conn
-	h.command = params.InitializationOptions.Command
 
-	// Parse path-related flags from the command.
-	h.pathConfig = parseCommandFlags(h.command)
+	// Invalid options are reported but do not prevent initialization with the valid ones.
+	opts, problems := parseInitializationOptions(params.InitializationOptions)
+
+	h.settingsMu.Lock()
+	h.initOptions = params.InitializationOptions
+	h.effectiveOptions, _ = mergeOptions(params.InitializationOptions, nil)
+	problems = append(problems, h.applyOptions(opts)...)
+	h.settingsMu.Unlock()
+
+	h.showOptionProblems(ctx, problems)
+
+	h.touched = make(map[DocumentURI]*touchedLines)
+	h.paused.Store(opts.StartPaused)
+	h.awaitingConfiguration.Store(h.configuration && len(opts.Command) == 0)
+
+	h.resetLintConfigs()
 
-	return InitializeResult{
+	h.lifecycle.Store(stateInitializing)
+
+	initResult := InitializeResult{
 		Capabilities: ServerCapabilities{
 			TextDocumentSync: TextDocumentSyncOptions{
-				Change:    TDSKNone,
+				// Incremental changes keep the documents and touched lines up to date.
+				// Clients that only support full sync send changes without a range.
+				Change:    TDSKIncremental,
 				OpenClose: true,
 				Save:      true,
 			},
+			CodeActionProvider:     true,
+			ExecuteCommandProvider: &ExecuteCommandOptions{Commands: commands},
+			// Clients registering it dynamically only get it from a golangci-lint with fmt.
+			DocumentFormattingProvider: !h.formattingRegistration,
+			Workspace: &WorkspaceServerCapabilities{
+				WorkspaceFolders: &WorkspaceFoldersServerCapabilities{Supported: true, ChangeNotifications: true},
+			},
 		},
-	}, nil
+	}
+
+	// Diagnostics are published to every client; those supporting the pull model may
+	// also request them.
+	if pullDiagnostics != nil {
+		initResult.Capabilities.DiagnosticProvider = &DiagnosticOptions{InterFileDependencies: true, WorkspaceDiagnostics: true}
+	}
+
+	return initResult, nil
+}
+
+// applyOptions sets the settings of the server from the options, whether they come from
+// the initialize request or from the client configuration. It returns the problems found,
+// which do not prevent the valid options from applying. settingsMu must be held.
+func (h *langHandler) applyOptions(opts InitializationOptions) []string {
+	var problems []string
+
+	// Options such as excludeMessages shape the results of golangci-lint.
+	h.runs.clear()
+
+	excludeMessages, err := compileExcludeMessages(opts.ExcludeMessages)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	messageRewrites, err := compileMessageRewrites(opts.MessageRewrites)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	// Without a command, as when the client configuration is null, the default one runs.
+	command := opts.Command
+	if len(command) == 0 {
+		command = slices.Clone(defaultCommand)
+	}
+
+	// Settings changed after initialize cannot fail it: a command outside the
+	// policy is reported and the previous one kept.
+	if err := h.checkAllowedCommand(command); err != nil {
+		problems = append(problems, err.Error())
+	} else {
+		h.command = command
+	}
+
+	if f, ok := commandDeprecatedFlag(h.command); ok {
+		h.warnDeprecatedFlag(f)
+	}
+
+	if command, replaced := jsonOutputCommand(h.command); replaced {
+		h.warnOutputFormat(fmt.Sprintf("the command sets the %q output format, JSON is used instead", parseCommandFlags(h.command).outFormat))
+		h.command = command
+	}
+
+	h.publishBatchSize = opts.PublishBatchSize
+	h.allowExternalPaths = opts.AllowExternalPaths
+	h.alsoRunGoVet = opts.AlsoRunGoVet
+	h.lintByImportPath = opts.LintByImportPath
+	h.lowPriority = opts.LowPriority
+	h.onlyTouchedLines = opts.OnlyTouchedLines
+	h.mergeSamePosition = opts.MergeSamePosition
+
+	debounce := defaultDebounce
+	if opts.DebounceMs != nil {
+		debounce = time.Duration(*opts.DebounceMs) * time.Millisecond
+	}
+	h.debounce.setWindow(debounce)
+	if h.stderrPattern, err = compileStderrPattern(opts.StderrPattern); err != nil {
+		problems = append(problems, err.Error())
+		h.stderrPattern = regexp.MustCompile(defaultStderrPattern)
+	}
+
+	if h.pathSeverities, err = compilePathSeverities(opts.PathSeverities); err != nil {
+		problems = append(problems, err.Error())
+	}
+	h.severityGrades = compileSeverityGrades(opts.SeverityGrades)
+	if h.linterSeverities, err = compileLinterSeverities(opts.Severities); err != nil {
+		problems = append(problems, err.Error())
+	}
+	h.diagnosticTags = compileDiagnosticTags(opts.DiagnosticTags)
+
+	h.minimumSeverity = 0
+	if opts.MinimumSeverity != "" {
+		if s, ok := parseSeverity(opts.MinimumSeverity); ok {
+			h.minimumSeverity = s
+		} else {
+			problems = append(problems, fmt.Sprintf("%q must be one of error, warning, info or hint, got %q", "minimumSeverity", opts.MinimumSeverity))
+		}
+	}
+	if h.logLevel != nil {
+		level := h.startupLogLevel
+		if opts.LogLevel != "" {
+			if l, ok := parseLogLevel(opts.LogLevel); ok {
+				level = l
+			} else {
+				problems = append(problems, fmt.Sprintf("%q must be one of debug, info, warn or error, got %q", "logLevel", opts.LogLevel))
+			}
+		}
+		h.logLevel.Set(level)
+	}
+	if h.subprojects, err = compileSubprojects(opts.Subprojects); err != nil {
+		problems = append(problems, err.Error())
+	}
+	h.excludeMessages = excludeMessages
+	h.messageRewrites = messageRewrites
+
+	h.maxMessageLength = defaultMaxMessageLength
+	if opts.MaxMessageLength != nil {
+		h.maxMessageLength = *opts.MaxMessageLength
+	}
+
+	h.maxOutputBytes = defaultMaxOutputBytes
+	if opts.MaxOutputBytes != nil {
+		h.maxOutputBytes = *opts.MaxOutputBytes
+	}
+
+	concurrency := h.startupConcurrency
+	if opts.Concurrency != nil {
+		if *opts.Concurrency >= 1 {
+			concurrency = *opts.Concurrency
+		} else {
+			problems = append(problems, fmt.Sprintf("%q must be at least 1, got %d", "concurrency", *opts.Concurrency))
+		}
+	}
+	h.concurrency.Store(int32(concurrency))
+
+	cacheMaxEntries, cacheMaxBytes := defaultCacheMaxEntries, defaultCacheMaxBytes
+	if opts.CacheMaxEntries != nil {
+		cacheMaxEntries = *opts.CacheMaxEntries
+	}
+	if opts.CacheMaxBytes != nil {
+		cacheMaxBytes = *opts.CacheMaxBytes
+	}
+	h.results.setLimits(cacheMaxEntries, cacheMaxBytes)
+
+	h.lintOnOpen = true
+	if opts.LintOnOpen != nil {
+		h.lintOnOpen = *opts.LintOnOpen
+	}
+
+	h.clearOnClose = true
+	if opts.ClearOnClose != nil {
+		h.clearOnClose = *opts.ClearOnClose
+	}
+
+	h.languages = []string{languageGo}
+	if opts.Languages != nil {
+		h.languages = make([]string, len(opts.Languages))
+		for i, id := range opts.Languages {
+			h.languages[i] = normalizeLanguageID(id)
+		}
+	}
+
+	h.retryOnTimeout = true
+	if opts.RetryOnTimeout != nil {
+		h.retryOnTimeout = *opts.RetryOnTimeout
+	}
+
+	h.typecheckOnly = true
+	if opts.TypecheckOnly != nil {
+		h.typecheckOnly = *opts.TypecheckOnly
+	}
+
+	switch {
+	case !opts.LockWorkspace:
+		h.workspaceLock = nil
+	case h.singleFile:
+		slog.Warn("lockWorkspace needs a workspace, not locking")
+	case h.workspaceLock == nil:
+		if h.workspaceLock, err = newWorkspaceLock(h.rootDir); err != nil {
+			slog.Warn("failed to set up the workspace lock", "error", err)
+		}
+	}
+
+	switch opts.LintTarget {
+	case "", lintTargetPackage:
+		h.lintTarget = lintTargetPackage
+	case lintTargetFile:
+		h.lintTarget = lintTargetFile
+	default:
+		slog.Warn("unknown lintTarget, using package", "lintTarget", opts.LintTarget)
+		h.lintTarget = lintTargetPackage
+	}
+
+	// Parse path-related flags from the command.
+	h.pathConfig = parseCommandFlags(h.command)
+
+	return problems
 }
 
 func (h *langHandler) handleShutdown(_ context.Context, _ *jsonrpc2.Conn, _ *jsonrpc2.Request) (result any, err error) {
-	close(h.request)
+	h.stop()
+	h.lifecycle.Store(stateShutdown)
 
 	return nil, nil
 }
 
-func (h *langHandler) handleTextDocumentDidOpen(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+func (h *langHandler) handleTextDocumentDidOpen(ctx context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
 	var params DidOpenTextDocumentParams
-	if err := json.Unmarshal(*req.Params, &params); err != nil {
+	if err := decodeParams(req, &params); err != nil {
 		return nil, err
 	}
 
-	h.request <- params.TextDocument.URI
+	if err := validateDocumentURI(params.TextDocument.URI); err != nil {
+		return nil, err
+	}
 
-	return nil, nil
+	h.openMu.Lock()
+	if h.open == nil {
+		h.open = make(map[DocumentURI]string)
+	}
+	h.open[params.TextDocument.URI] = normalizeLanguageID(params.TextDocument.LanguageID)
+	h.openMu.Unlock()
+
+	h.documents.open(params.TextDocument.URI, params.TextDocument.Text)
+
+	if h.onlyTouchedLines {
+		h.touchedMu.Lock()
+		h.touched[params.TextDocument.URI] = &touchedLines{}
+		h.touchedMu.Unlock()
+	}
+
+	if !h.lintOnOpen {
+		// Open documents are linted on resume.
+		if h.holdWhilePaused(params.TextDocument.URI) {
+			return nil, nil
+		}
+
+		// Show what the last lint found rather than a blank document.
+		cached, ok := h.results.get(params.TextDocument.URI)
+		h.metrics.cacheLookup(ok)
+		if ok {
+			return nil, h.publishDiagnostics(ctx, params.TextDocument.URI, cached.diagnostics)
+		}
+
+		return nil, nil
+	}
+
+	return nil, h.enqueue(ctx, params.TextDocument.URI)
 }
 
-func (h *langHandler) handleTextDocumentDidClose(_ context.Context, _ *jsonrpc2.Conn, _ *jsonrpc2.Request) (result any, err error) {
+func (h *langHandler) handleTextDocumentDidClose(ctx context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	var params DidCloseTextDocumentParams
+	if err := decodeParams(req, &params); err != nil {
+		return nil, err
+	}
+
+	if err := validateDocumentURI(params.TextDocument.URI); err != nil {
+		return nil, err
+	}
+
+	h.openMu.Lock()
+	delete(h.open, params.TextDocument.URI)
+	h.openMu.Unlock()
+
+	h.touchedMu.Lock()
+	delete(h.touched, params.TextDocument.URI)
+	h.touchedMu.Unlock()
+
+	h.documents.close(params.TextDocument.URI)
+
+	if !h.clearOnClose || h.holdWhilePaused(params.TextDocument.URI) {
+		return nil, nil
+	}
+
+	h.publishedMu.Lock()
+	published := h.published[params.TextDocument.URI]
+	h.publishedMu.Unlock()
+
+	// The diagnostics stay cached for pull requests and for reopening with lintOnOpen off.
+	if published {
+		if err := h.publishDiagnostics(ctx, params.TextDocument.URI, []Diagnostic{}); err != nil {
+			slog.Error("failed to publish diagnostics", "error", err)
+		}
+	}
+
 	return nil, nil
 }
 
-func (h *langHandler) handleTextDocumentDidChange(_ context.Context, _ *jsonrpc2.Conn, _ *jsonrpc2.Request) (result any, err error) {
+func (h *langHandler) handleTextDocumentDidChange(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	var params DidChangeTextDocumentParams
+	if err := decodeParams(req, &params); err != nil {
+		return nil, err
+	}
+
+	if err := validateDocumentURI(params.TextDocument.URI); err != nil {
+		return nil, err
+	}
+
+	h.documents.change(params.TextDocument.URI, params.ContentChanges)
+
+	if !h.onlyTouchedLines {
+		return nil, nil
+	}
+
+	h.touchedMu.Lock()
+	defer h.touchedMu.Unlock()
+
+	t, ok := h.touched[params.TextDocument.URI]
+	if !ok {
+		t = &touchedLines{}
+		h.touched[params.TextDocument.URI] = t
+	}
+
+	for _, change := range params.ContentChanges {
+		t.apply(change.Range, change.Text)
+	}
+
 	return nil, nil
 }
 
-func (h *langHandler) handleTextDocumentDidSave(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+func (h *langHandler) handleTextDocumentDidSave(ctx context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
 	var params DidSaveTextDocumentParams
-	if err := json.Unmarshal(*req.Params, &params); err != nil {
+	if err := decodeParams(req, &params); err != nil {
+		return nil, err
+	}
+
+	if err := validateDocumentURI(params.TextDocument.URI); err != nil {
 		return nil, err
 	}
 
-	h.request <- params.TextDocument.URI
+	if isConfigFile(uriToPath(string(params.TextDocument.URI))) {
+		h.resetLintConfigs()
+
+		return nil, h.lintOpenDocuments(ctx)
+	}
+
+	if isGoModFile(uriToPath(string(params.TextDocument.URI))) {
+		h.resetImportPaths()
+	}
+
+	// The modification time may not tell a save apart on file systems with a coarse one.
+	h.runs.invalidate(h.lintKey(params.TextDocument.URI))
+
+	return nil, h.enqueue(ctx, params.TextDocument.URI)
+}
+
+func (h *langHandler) handleWorkspaceDidChangeWatchedFiles(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	var params DidChangeWatchedFilesParams
+	if err := decodeParams(req, &params); err != nil {
+		return nil, err
+	}
+
+	for _, change := range params.Changes {
+		if isGoModFile(uriToPath(string(change.URI))) {
+			h.resetImportPaths()
+		}
+
+		h.forgetNoGoFiles(uriToPath(string(change.URI)))
+	}
+
+	for _, change := range params.Changes {
+		if isConfigFile(uriToPath(string(change.URI))) {
+			h.resetLintConfigs()
+
+			break
+		}
+	}
 
 	return nil, nil
 }
 
-func (h *langHandler) handlerWorkspaceDidChangeConfiguration(_ context.Context, _ *jsonrpc2.Conn, _ *jsonrpc2.Request) (result any, err error) {
+func (h *langHandler) handlerWorkspaceDidChangeConfiguration(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	h.resetLintConfigs()
+
+	// Clients such as VS Code send no settings and expect the server to pull them.
+	var params DidChangeConfigurationParams
+	if req.Params != nil {
+		if err := json.Unmarshal(*req.Params, &params); err != nil {
+			slog.Debug("ignoring unexpected configuration change", "error", err)
+		}
+	}
+
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal(params.Settings, &settings); err == nil {
+		if section, ok := settings[configurationSection]; ok {
+			go h.updateSettings(h.serverContext(), section)
+
+			return nil, nil
+		}
+
+		// Some clients send the options as they are, in the shape of initializationOptions.
+		if isOptions(settings) {
+			go h.updateSettings(h.serverContext(), params.Settings)
+
+			return nil, nil
+		}
+	}
+
+	h.pullConfiguration()
+
 	return nil, nil
 }
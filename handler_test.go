@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -251,11 +255,13 @@ func TestLangHandler_lint_Integration(t *testing.T) {
 				t.Fatalf("filepath.Abs() returned unexpected error: %v", err)
 			}
 			testURI := DocumentURI("file://" + testFilePath)
-			diagnostics, err := tt.h.lint(testURI)
+			results, err := tt.h.lint(context.Background(), testURI)
 			if err != nil {
 				t.Fatalf("lint() returned unexpected error: %v", err)
 			}
 
+			diagnostics := results[testURI]
+
 			// Filter diagnostics to only include expected ones (ignore extras from global config).
 			expectedSources := make(map[string]bool)
 			for _, d := range tt.want {
@@ -276,3 +282,321 @@ func TestLangHandler_lint_Integration(t *testing.T) {
 		})
 	}
 }
+
+func TestCommandTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+		want    time.Duration
+		wantOK  bool
+	}{
+		{
+			name:    "no timeout",
+			command: []string{"golangci-lint", "run"},
+		},
+		{
+			name:    "timeout with equals",
+			command: []string{"golangci-lint", "run", "--timeout=30s"},
+			want:    30 * time.Second,
+			wantOK:  true,
+		},
+		{
+			name:    "timeout separate",
+			command: []string{"golangci-lint", "run", "--timeout", "2m"},
+			want:    2 * time.Minute,
+			wantOK:  true,
+		},
+		{
+			name:    "last timeout wins",
+			command: []string{"golangci-lint", "run", "--timeout=1m", "--timeout=2m0s"},
+			want:    2 * time.Minute,
+			wantOK:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := commandTimeout(tt.command)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("commandTimeout() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLangHandler_externalDiagnostic(t *testing.T) {
+	root := t.TempDir()
+	external := t.TempDir()
+
+	for _, dir := range []string{root, external} {
+		if err := os.WriteFile(filepath.Join(dir, "dep.go"), []byte("package dep\n"), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name               string
+		issue              Issue
+		allowExternalPaths bool
+		want               *Diagnostic
+	}{
+		{
+			name:  "typecheck issue outside the workspace",
+			issue: Issue{FromLinter: "typecheck", Text: "undefined: foo"},
+			want: &Diagnostic{
+				Severity: DSWarning,
+				Source:   pt("typecheck"),
+				Message:  "typecheck: " + filepath.Join(external, "dep.go") + ":3:5: undefined: foo",
+			},
+		},
+		{
+			name:  "other issue outside the workspace",
+			issue: Issue{FromLinter: "unused", Text: "var foo is unused"},
+		},
+		{
+			name:               "external paths allowed",
+			issue:              Issue{FromLinter: "typecheck", Text: "undefined: foo"},
+			allowExternalPaths: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &langHandler{rootDir: root, allowExternalPaths: tt.allowExternalPaths}
+
+			tt.issue.Pos.Filename = filepath.Join(external, "dep.go")
+			tt.issue.Pos.Line = 3
+			tt.issue.Pos.Column = 5
+
+			d, ok := h.externalDiagnostic(&tt.issue, []string{root})
+			if ok != (tt.want != nil) {
+				t.Fatalf("externalDiagnostic() ok = %v, want %v", ok, tt.want != nil)
+			}
+
+			if tt.want != nil {
+				if diff := cmp.Diff(*tt.want, d); diff != "" {
+					t.Errorf("externalDiagnostic() mismatch (-want +got):\n%s", diff)
+				}
+			}
+
+			// Issues inside the workspace are never handled as external.
+			tt.issue.Pos.Filename = "dep.go"
+			if _, ok := h.externalDiagnostic(&tt.issue, []string{root}); ok {
+				t.Error("externalDiagnostic() converted an issue inside the workspace")
+			}
+		})
+	}
+}
+
+// TestLangHandler_lintOnOpen tests that with lintOnOpen disabled only saves lint and
+// opening a document shows its cached diagnostics.
+func TestLangHandler_lintOnOpen(t *testing.T) {
+	dir := t.TempDir()
+	uri := pathToURI(filepath.Join(dir, "main.go"))
+	document := map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": ""}
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(dir)),
+		"initializationOptions": map[string]any{"command": []string{"true"}, "lintOnOpen": false},
+	}, nil)
+
+	client.notify("textDocument/didOpen", map[string]any{"textDocument": document})
+	client.call("workspace/executeCommand", map[string]any{"command": commandStatus}, nil)
+
+	if got := len(client.received("textDocument/publishDiagnostics")); got != 0 {
+		t.Fatalf("expected no lint on open, got %d publishes", got)
+	}
+
+	client.notify("textDocument/didSave", map[string]any{"textDocument": map[string]any{"uri": uri}})
+	client.waitFor("textDocument/publishDiagnostics", func(json.RawMessage) bool { return true }, 5*time.Second)
+
+	client.notify("textDocument/didClose", map[string]any{"textDocument": map[string]any{"uri": uri}})
+	client.notify("textDocument/didOpen", map[string]any{"textDocument": document})
+	client.waitFor("textDocument/publishDiagnostics", func(json.RawMessage) bool {
+		return len(client.received("textDocument/publishDiagnostics")) == 2
+	}, 5*time.Second)
+}
+
+// TestLangHandler_clearOnClose tests that closing a document clears the diagnostics
+// published for it, including those published by the lint of another file of its
+// package, unless clearOnClose is off.
+func TestLangHandler_clearOnClose(t *testing.T) {
+	tests := []struct {
+		name string
+		// clearOnClose is the option, left unset when nil.
+		clearOnClose any
+		wantCleared  bool
+	}{
+		{name: "default", wantCleared: true},
+		{name: "disabled", clearOnClose: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for _, name := range []string{"a.go", "b.go"} {
+				if err := os.WriteFile(filepath.Join(root, name), []byte("package main\n"), 0o600); err != nil {
+					t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+				}
+			}
+
+			issue := Issue{FromLinter: "errcheck", Text: "Error return value is not checked"}
+			issue.Pos.Filename, issue.Pos.Line, issue.Pos.Column = "b.go", 1, 1
+
+			options := map[string]any{"command": fakeLinter(t, GolangCILintResult{Issues: []Issue{issue}}), "lintOnOpen": false}
+			if tt.clearOnClose != nil {
+				options["clearOnClose"] = tt.clearOnClose
+			}
+
+			h := newLangHandler(false)
+			client := newTestClient(t, h)
+
+			client.call("initialize", map[string]any{"rootUri": string(pathToURI(root)), "initializationOptions": options}, nil)
+
+			a, b := pathToURI(filepath.Join(root, "a.go")), pathToURI(filepath.Join(root, "b.go"))
+
+			// The diagnostics of b.go are published by the lint of a.go.
+			h.lintDocument(a)
+			client.waitFor("textDocument/publishDiagnostics", func(raw json.RawMessage) bool {
+				var params PublishDiagnosticsParams
+
+				return json.Unmarshal(raw, &params) == nil && params.URI == b && len(params.Diagnostics) == 1
+			}, 5*time.Second)
+
+			before := len(client.received("textDocument/publishDiagnostics"))
+
+			// a.go has no diagnostics to clear.
+			for _, uri := range []DocumentURI{a, b} {
+				client.notify("textDocument/didClose", map[string]any{"textDocument": map[string]any{"uri": uri}})
+			}
+			client.call("workspace/executeCommand", map[string]any{"command": commandStatus}, nil)
+
+			published := client.received("textDocument/publishDiagnostics")[before:]
+			if !tt.wantCleared {
+				if len(published) != 0 {
+					t.Errorf("published %d times on close, want none", len(published))
+				}
+
+				return
+			}
+
+			var params PublishDiagnosticsParams
+			if len(published) != 1 || json.Unmarshal(published[0], &params) != nil || params.URI != b || len(params.Diagnostics) != 0 {
+				t.Errorf("published %s on close, want the diagnostics of %s cleared", published, b)
+			}
+		})
+	}
+}
+
+// TestLangHandler_excludeMessages tests that issues matching excludeMessages are dropped.
+func TestLangHandler_excludeMessages(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	issue := func(linter, text string) Issue {
+		i := Issue{FromLinter: linter, Text: text}
+		i.Pos.Filename = "main.go"
+		i.Pos.Line = 1
+
+		return i
+	}
+
+	excludeMessages, err := compileExcludeMessages([]string{`should have comment or be unexported$`})
+	if err != nil {
+		t.Fatalf("compileExcludeMessages() returned unexpected error: %v", err)
+	}
+
+	h := &langHandler{
+		rootDir: root,
+		command: fakeLinter(t, GolangCILintResult{Issues: []Issue{
+			issue("revive", "exported: exported function Foo should have comment or be unexported"),
+			issue("errcheck", "Error return value is not checked"),
+		}}),
+		excludeMessages: excludeMessages,
+	}
+
+	results, err := h.lint(context.Background(), pathToURI(path))
+	if err != nil {
+		t.Fatalf("lint() returned unexpected error: %v", err)
+	}
+
+	diagnostics := results[pathToURI(path)]
+
+	if len(diagnostics) != 1 || *diagnostics[0].Source != "errcheck" {
+		t.Errorf("lint() = %+v, want only the errcheck diagnostic", diagnostics)
+	}
+}
+
+// TestLangHandler_typecheckOnly tests that the other issues are withheld while the package does not compile.
+func TestLangHandler_typecheckOnly(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	issue := func(linter, text string) Issue {
+		i := Issue{FromLinter: linter, Text: text}
+		i.Pos.Filename = "main.go"
+		i.Pos.Line = 1
+
+		return i
+	}
+
+	tests := []struct {
+		name          string
+		typecheckOnly bool
+		issues        []Issue
+		wantSources   []string
+	}{
+		{
+			name:          "compile error",
+			typecheckOnly: true,
+			issues:        []Issue{issue("unused", "func `f` is unused"), issue("typecheck", "undefined: x"), issue("errcheck", "Error return value is not checked")},
+			wantSources:   []string{"typecheck"},
+		},
+		{
+			name:          "compiles",
+			typecheckOnly: true,
+			issues:        []Issue{issue("unused", "func `f` is unused"), issue("errcheck", "Error return value is not checked")},
+			wantSources:   []string{"unused", "errcheck"},
+		},
+		{
+			name:        "disabled",
+			issues:      []Issue{issue("unused", "func `f` is unused"), issue("typecheck", "undefined: x")},
+			wantSources: []string{"unused", "typecheck"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &langHandler{
+				rootDir:       root,
+				command:       fakeLinter(t, GolangCILintResult{Issues: tt.issues}),
+				typecheckOnly: tt.typecheckOnly,
+			}
+
+			results, err := h.lint(context.Background(), pathToURI(path))
+			if err != nil {
+				t.Fatalf("lint() returned unexpected error: %v", err)
+			}
+
+			diagnostics := results[pathToURI(path)]
+
+			var sources []string
+			for _, d := range diagnostics {
+				sources = append(sources, *d.Source)
+			}
+
+			if diff := cmp.Diff(tt.wantSources, sources); diff != "" {
+				t.Errorf("lint() sources mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
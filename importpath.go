@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// importPath returns the import path of the package in dir, resolved with go list
+// from workDir, where golangci-lint runs. It fails for directories outside the
+// module of workDir, where golangci-lint could not resolve the import path.
+// Resolved paths are cached per directory until a go.mod changes.
+func (h *langHandler) importPath(ctx context.Context, dir, workDir string) (string, bool) {
+	dir = filepath.Clean(dir)
+	if moduleRoot(dir) != moduleRoot(workDir) {
+		return "", false
+	}
+
+	h.importPathsMu.Lock()
+	p, ok := h.importPaths[dir]
+	h.importPathsMu.Unlock()
+	if ok {
+		return p, true
+	}
+
+	cmd := exec.CommandContext(ctx, "go", "list", "-find", "-f", "{{.ImportPath}}", ".")
+	cmd.Dir = dir
+	h.vendorEnv(cmd)
+	h.gopathEnv(cmd)
+
+	b, err := cmd.Output()
+	if err != nil {
+		// Failures are not cached: a syntax error fixed by the next save must not stick.
+		slog.Debug("go list failed, linting the directory", "dir", dir, "error", err)
+
+		return "", false
+	}
+
+	p = strings.TrimSpace(string(b))
+	if p == "" || p == "command-line-arguments" || strings.HasPrefix(p, "_") {
+		// Packages outside modules and GOPATH have no import path to lint.
+		return "", false
+	}
+
+	h.importPathsMu.Lock()
+	if h.importPaths == nil {
+		h.importPaths = make(map[string]string)
+	}
+	h.importPaths[dir] = p
+	h.importPathsMu.Unlock()
+
+	return p, true
+}
+
+// resetImportPaths drops the resolved import paths, for instance after a go.mod changed.
+func (h *langHandler) resetImportPaths() {
+	h.importPathsMu.Lock()
+	h.importPaths = nil
+	h.importPathsMu.Unlock()
+}
+
+// isGoModFile reports whether path is a go.mod file, whose changes move import paths.
+func isGoModFile(path string) bool {
+	return filepath.Base(path) == "go.mod"
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLangHandler_importPath(t *testing.T) {
+	module := t.TempDir()
+	pkg := filepath.Join(module, "internal", "pkg")
+	if err := os.MkdirAll(pkg, 0o755); err != nil {
+		t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+	}
+
+	outside := t.TempDir()
+	for path, content := range map[string]string{
+		filepath.Join(module, "go.mod"):   "module example.com/app\n\ngo 1.21\n",
+		filepath.Join(pkg, "pkg.go"):      "package pkg\n",
+		filepath.Join(outside, "main.go"): "package main\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		dir     string
+		workDir string
+		want    string
+		wantOK  bool
+	}{
+		{name: "package in the module", dir: pkg, workDir: module, want: "example.com/app/internal/pkg", wantOK: true},
+		{name: "outside a module", dir: outside, workDir: outside},
+		{name: "other module", dir: pkg, workDir: outside},
+	}
+
+	h := &langHandler{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := h.importPath(context.Background(), tt.dir, tt.workDir)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("importPath(%q) = (%q, %v), want (%q, %v)", tt.dir, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	if got := h.importPaths[pkg]; got != "example.com/app/internal/pkg" {
+		t.Errorf("import path of %q cached as %q", pkg, got)
+	}
+
+	h.resetImportPaths()
+	if len(h.importPaths) != 0 {
+		t.Errorf("resetImportPaths() kept %v", h.importPaths)
+	}
+}
+
+// TestLangHandler_lintByImportPath tests that the import path replaces the directory
+// and that the directory is linted when it cannot be resolved.
+func TestLangHandler_lintByImportPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake linter needs sh")
+	}
+
+	module := t.TempDir()
+	if err := os.WriteFile(filepath.Join(module, "go.mod"), []byte("module example.com/app\n\ngo 1.21\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(module, "main.go"), []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "main.go"), []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "resolved", path: filepath.Join(module, "main.go"), want: "example.com/app"},
+		{name: "fallback", path: filepath.Join(outside, "main.go"), want: outside + string(filepath.Separator)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := filepath.Join(t.TempDir(), "args")
+			h := &langHandler{
+				rootDir:          module,
+				command:          []string{"sh", "-c", `echo "$1" > "$0"`, record},
+				lintByImportPath: true,
+			}
+
+			if _, err := h.lintPath(context.Background(), tt.path); err != nil {
+				t.Fatalf("lintPath() returned unexpected error: %v", err)
+			}
+
+			b, err := os.ReadFile(record)
+			if err != nil {
+				t.Fatalf("os.ReadFile() returned unexpected error: %v", err)
+			}
+
+			if got := strings.TrimSpace(string(b)); got != tt.want {
+				t.Errorf("lint target = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
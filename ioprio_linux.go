@@ -0,0 +1,32 @@
+package main
+
+import (
+	"log/slog"
+	"syscall"
+)
+
+// I/O priority constants of ioprio_set(2).
+const (
+	ioprioWhoProcess = 1
+	ioprioWhoPgrp    = 2
+	ioprioClassBE    = 2
+	ioprioClassShift = 13
+	// ioprioLowestBE is the lowest priority of the best-effort class.
+	ioprioLowestBE = ioprioClassBE<<ioprioClassShift | 7
+)
+
+// lowerIOPriority moves the process, or its process group, to the lowest best-effort I/O priority.
+func lowerIOPriority(group bool, pid int) {
+	who := ioprioWhoProcess
+	if group {
+		who = ioprioWhoPgrp
+	}
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, uintptr(who), uintptr(pid), ioprioLowestBE); errno != 0 {
+		slog.Debug("failed to lower the I/O priority", "pid", pid, "error", errno)
+
+		return
+	}
+
+	slog.Debug("lowered the I/O priority", "pid", pid)
+}
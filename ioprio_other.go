@@ -0,0 +1,6 @@
+//go:build !linux && !windows
+
+package main
+
+// lowerIOPriority does nothing: only Linux lets a process lower the I/O priority of another.
+func lowerIOPriority(_ bool, _ int) {}
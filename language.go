@@ -0,0 +1,57 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+)
+
+// Language identifiers of the documents the server can lint.
+const (
+	languageGo    = "go"
+	languageGoMod = "go.mod"
+	languageGoSum = "go.sum"
+)
+
+// languageAliases maps the languageIds used by some clients to the ones above.
+var languageAliases = map[string]string{
+	"gomod": languageGoMod,
+	"gosum": languageGoSum,
+}
+
+// normalizeLanguageID returns the canonical form of a languageId.
+func normalizeLanguageID(id string) string {
+	if alias, ok := languageAliases[id]; ok {
+		return alias
+	}
+
+	return id
+}
+
+// languageFromPath guesses the languageId of a document from its file name.
+func languageFromPath(path string) string {
+	switch base := filepath.Base(path); {
+	case base == "go.mod":
+		return languageGoMod
+	case base == "go.sum":
+		return languageGoSum
+	case filepath.Ext(base) == ".go":
+		return languageGo
+	}
+
+	return ""
+}
+
+// lintable reports whether the document is in one of the linted languages. The languageId
+// sent on didOpen is authoritative; documents that were never opened, such as files
+// saved by another tool, fall back to their extension.
+func (h *langHandler) lintable(uri DocumentURI) bool {
+	h.openMu.Lock()
+	id := h.open[uri]
+	h.openMu.Unlock()
+
+	if id == "" {
+		id = languageFromPath(uriToPath(string(uri)))
+	}
+
+	return slices.Contains(h.languages, id)
+}
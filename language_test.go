@@ -0,0 +1,71 @@
+package main
+
+import "testing"
+
+// TestLangHandler_lintable tests the languageId filtering of documents.
+func TestLangHandler_lintable(t *testing.T) {
+	tests := []struct {
+		name      string
+		languages []string
+		open      map[DocumentURI]string
+		uri       DocumentURI
+		want      bool
+	}{
+		{
+			name:      "open go document",
+			languages: []string{languageGo},
+			open:      map[DocumentURI]string{"file:///project/main.go": languageGo},
+			uri:       "file:///project/main.go",
+			want:      true,
+		},
+		{
+			name:      "open templ document",
+			languages: []string{languageGo},
+			open:      map[DocumentURI]string{"file:///project/page.templ": "templ"},
+			uri:       "file:///project/page.templ",
+			want:      false,
+		},
+		{
+			name:      "languageId wins over the extension",
+			languages: []string{languageGo},
+			open:      map[DocumentURI]string{"file:///project/page_templ.go": "templ"},
+			uri:       "file:///project/page_templ.go",
+			want:      false,
+		},
+		{
+			name:      "saved without being opened",
+			languages: []string{languageGo},
+			uri:       "file:///project/main.go",
+			want:      true,
+		},
+		{
+			name:      "go.mod not linted by default",
+			languages: []string{languageGo},
+			uri:       "file:///project/go.mod",
+			want:      false,
+		},
+		{
+			name:      "go.mod by alias",
+			languages: []string{languageGo, normalizeLanguageID("gomod")},
+			open:      map[DocumentURI]string{"file:///project/go.mod": normalizeLanguageID("gomod")},
+			uri:       "file:///project/go.mod",
+			want:      true,
+		},
+		{
+			name:      "go.sum by file name",
+			languages: []string{languageGoSum},
+			uri:       "file:///project/go.sum",
+			want:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &langHandler{languages: tt.languages, open: tt.open}
+
+			if got := h.lintable(tt.uri); got != tt.want {
+				t.Errorf("lintable(%q) = %v, want %v", tt.uri, got, tt.want)
+			}
+		})
+	}
+}
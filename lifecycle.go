@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// shutdownTimeout bounds how long shutdown waits for the running lint to stop.
+const shutdownTimeout = 5 * time.Second
+
+// States of the server lifecycle defined by the LSP specification.
+const (
+	// stateUninitialized is the state before the initialize request.
+	stateUninitialized int32 = iota
+	// stateInitializing is the state between the initialize request and the initialized notification.
+	stateInitializing
+	// stateInitialized is the state once the initialized notification arrived.
+	stateInitialized
+	// stateShutdown is the state after the shutdown request, until the server is initialized again.
+	stateShutdown
+)
+
+// admit enforces the server lifecycle on an incoming message and reports whether it
+// must be handled now. Before initialize, requests are answered with ServerNotInitialized
+// and notifications dropped; after shutdown, requests are answered with InvalidRequest.
+// Document notifications sent between initialize and initialized are held back until
+// initialized arrives. It runs on the goroutine reading messages.
+func (h *langHandler) admit(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) bool {
+	state := h.lifecycle.Load()
+
+	switch {
+	case req.Method == "initialize" || req.Method == "exit":
+		return true
+	case state == stateUninitialized || state == stateShutdown:
+		if req.Notif {
+			slog.Debug("dropping notification outside of a session", "method", req.Method)
+
+			return false
+		}
+
+		e := &jsonrpc2.Error{Code: codeServerNotInitialized, Message: "server not initialized"}
+		if state == stateShutdown {
+			e = &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidRequest, Message: "server is shut down"}
+		}
+
+		if err := conn.ReplyWithError(ctx, req.ID, e); err != nil {
+			slog.Error("failed to reply", "method", req.Method, "error", err)
+		}
+
+		return false
+	case state == stateInitializing && req.Notif && strings.HasPrefix(req.Method, "textDocument/"):
+		slog.Debug("holding back document notification until initialized", "method", req.Method)
+		h.pending = append(h.pending, req)
+
+		return false
+	}
+
+	return true
+}
+
+// handleInitialized completes the initialization: the held back document notifications
+// are handled, the client configuration is pulled and formatting is registered.
+func (h *langHandler) handleInitialized(ctx context.Context, conn *jsonrpc2.Conn) {
+	if !h.lifecycle.CompareAndSwap(stateInitializing, stateInitialized) {
+		slog.Debug("ignoring unexpected initialized notification")
+
+		return
+	}
+
+	pending := h.pending
+	h.pending = nil
+	h.debounce.reset()
+	for _, req := range pending {
+		_, _ = h.handle(ctx, conn, req)
+	}
+
+	h.notifyStatus(ctx)
+	h.pullConfiguration()
+
+	if h.formattingRegistration {
+		go h.registerFormatting(h.serverContext())
+	}
+}
+
+// start creates the context lints run under and starts the linter goroutine.
+func (h *langHandler) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	h.ctxMu.Lock()
+	h.ctx, h.cancel = ctx, cancel
+	h.runCtx, h.runCancel = context.WithCancel(ctx)
+	h.ctxMu.Unlock()
+
+	request := make(chan DocumentURI, requestQueueSize)
+	stopping := make(chan struct{})
+	done := make(chan struct{})
+
+	h.mu.Lock()
+	h.request, h.stopping, h.linterDone, h.closed = request, stopping, done, false
+	h.mu.Unlock()
+
+	go h.linter(request, stopping, done)
+}
+
+// stop cancels the running lint, drops the queued ones and waits for the linter to
+// return. The state of the session is then forgotten, so that a later initialize
+// starts afresh.
+func (h *langHandler) stop() {
+	h.mu.Lock()
+	if h.closed || h.request == nil {
+		h.mu.Unlock()
+
+		return
+	}
+	h.closed = true
+	close(h.stopping)
+	done := h.linterDone
+	h.mu.Unlock()
+
+	// Queued lints are dropped by the linter.
+	h.lintCancel()
+
+	if done != nil {
+		select {
+		case <-done:
+		case <-time.After(shutdownTimeout):
+			slog.Warn("the running lint did not stop before shutdown")
+		}
+	}
+
+	h.resetState()
+}
+
+// handleExit stops the server and exits the process once the golangci-lint processes
+// were reaped, with code 0 after a shutdown request and 1 otherwise.
+func (h *langHandler) handleExit() {
+	code := 1
+	if h.lifecycle.Load() == stateShutdown {
+		code = 0
+	}
+
+	slog.Info("golangci-lint-langserver: exiting", "code", code)
+
+	h.lintCancel()
+	h.stop()
+
+	reaped := make(chan struct{})
+	go func() {
+		h.processes.Wait()
+		close(reaped)
+	}()
+
+	select {
+	case <-reaped:
+	case <-time.After(shutdownTimeout):
+		slog.Warn("golangci-lint processes did not exit before the server")
+	}
+
+	h.exitProcess(code)
+}
+
+// exitProcess exits the process with code, through the exit hook of tests if set.
+func (h *langHandler) exitProcess(code int) {
+	exit := h.exit
+	if exit == nil {
+		exit = os.Exit
+	}
+
+	exit(code)
+}
+
+// lintCancel aborts all lint work.
+func (h *langHandler) lintCancel() {
+	h.ctxMu.Lock()
+	defer h.ctxMu.Unlock()
+
+	if h.cancel != nil {
+		h.cancel()
+	}
+}
+
+// resetState forgets the documents, results and failures of the session.
+// Settings are replaced by the next initialize.
+func (h *langHandler) resetState() {
+	h.openMu.Lock()
+	h.open = nil
+	h.openMu.Unlock()
+
+	h.documents.reset()
+
+	h.touchedMu.Lock()
+	h.touched = nil
+	h.touchedMu.Unlock()
+
+	h.pending = nil
+	h.debounce.reset()
+
+	h.progressMu.Lock()
+	h.progressCancels = nil
+	h.progressMu.Unlock()
+
+	h.configMu.Lock()
+	h.lintConfigs = nil
+	h.configFiles = nil
+	h.configMu.Unlock()
+
+	h.resetImportPaths()
+
+	h.noGoFilesMu.Lock()
+	h.noGoFiles = nil
+	h.noGoFilesMu.Unlock()
+	h.vendorLogged.Clear()
+	h.binaryVersions.Clear()
+
+	h.versionWarnedMu.Lock()
+	h.versionWarned = ""
+	h.versionWarnedMu.Unlock()
+
+	h.packageLintsMu.Lock()
+	h.packageLints = nil
+	h.packageLintsMu.Unlock()
+
+	h.hiddenMu.Lock()
+	h.hidden = nil
+	h.hiddenMu.Unlock()
+
+	h.publishedMu.Lock()
+	h.published = nil
+	h.publishedMu.Unlock()
+
+	h.heldMu.Lock()
+	h.held = nil
+	h.heldMu.Unlock()
+
+	h.results.clear()
+	h.runs.clear()
+	h.dismissed.clear()
+	h.backoff.reset()
+	h.resetConfigPrompt()
+	h.outputFormatWarned.Store(false)
+	h.deprecatedFlagWarned.Store(false)
+	h.paused.Store(false)
+	h.workspaceLock = nil
+}
+
+// cancelRunningLints aborts the lints running under the current lint context and
+// replaces it, so that later lints run normally.
+func (h *langHandler) cancelRunningLints() {
+	h.ctxMu.Lock()
+	defer h.ctxMu.Unlock()
+
+	if h.runCancel == nil {
+		return
+	}
+
+	h.runCancel()
+	h.runCtx, h.runCancel = context.WithCancel(h.ctx)
+}
+
+// serverContext returns the context that lives until the server shuts down.
+func (h *langHandler) serverContext() context.Context {
+	h.ctxMu.Lock()
+	defer h.ctxMu.Unlock()
+
+	if h.ctx == nil {
+		return context.Background()
+	}
+
+	return h.ctx
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// TestLangHandler_reinitialize tests that the server can be initialized again after
+// a shutdown on the same connection, starting from a fresh state.
+func TestLangHandler_reinitialize(t *testing.T) {
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	for cycle, text := range []string{"first", "second"} {
+		root := t.TempDir()
+		path := filepath.Join(root, "main.go")
+		if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+
+		issue := Issue{FromLinter: "revive", Text: text}
+		issue.Pos.Filename = "main.go"
+		issue.Pos.Line = 1
+
+		client.call("initialize", map[string]any{
+			"rootUri":               string(pathToURI(root)),
+			"initializationOptions": map[string]any{"command": fakeLinter(t, GolangCILintResult{Issues: []Issue{issue}})},
+		}, nil)
+
+		var status StatusResult
+		client.call("workspace/executeCommand", map[string]any{"command": commandStatus}, &status)
+		if status.OpenDocuments != 0 {
+			t.Errorf("cycle %d: %d documents open after initialize, want 0", cycle, status.OpenDocuments)
+		}
+
+		uri := pathToURI(path)
+		client.notify("textDocument/didOpen", map[string]any{
+			"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": "package main\n"},
+		})
+
+		client.waitFor("textDocument/publishDiagnostics", func(raw json.RawMessage) bool {
+			var params PublishDiagnosticsParams
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return false
+			}
+
+			return params.URI == uri && len(params.Diagnostics) == 1 && params.Diagnostics[0].Message == "revive: "+text
+		}, 5*time.Second)
+
+		client.call("shutdown", nil, nil)
+
+		if _, ok := h.results.get(uri); ok {
+			t.Errorf("cycle %d: diagnostics still cached after shutdown", cycle)
+		}
+	}
+}
+
+// TestLangHandler_lifecycle tests messages sent out of the order of the LSP lifecycle.
+func TestLangHandler_lifecycle(t *testing.T) {
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	ctx := context.Background()
+	open := func(name string) {
+		client.notify("textDocument/didOpen", map[string]any{
+			"textDocument": map[string]any{"uri": "file:///project/" + name, "languageId": "go", "version": 1, "text": "package main\n"},
+		})
+	}
+	openDocuments := func() int {
+		t.Helper()
+
+		var status StatusResult
+		client.call("workspace/executeCommand", map[string]any{"command": commandStatus}, &status)
+
+		return status.OpenDocuments
+	}
+	wantError := func(err error, code int64) {
+		t.Helper()
+
+		var e *jsonrpc2.Error
+		if !errors.As(err, &e) || e.Code != code {
+			t.Errorf("got error %v, want code %d", err, code)
+		}
+	}
+
+	// Before initialize, requests are rejected and notifications dropped.
+	open("dropped.go")
+	wantError(client.conn.Call(ctx, "workspace/executeCommand", map[string]any{"command": commandStatus}, nil), codeServerNotInitialized)
+
+	// Between initialize and initialized, document notifications are held back.
+	options := map[string]any{"initializationOptions": map[string]any{"command": []string{"true"}, "lintOnOpen": false}}
+	if err := client.conn.Call(ctx, "initialize", options, nil); err != nil {
+		t.Fatalf("initialize returned unexpected error: %v", err)
+	}
+
+	open("held.go")
+	if n := openDocuments(); n != 0 {
+		t.Errorf("%d documents open before initialized, want 0", n)
+	}
+
+	client.notify("initialized", map[string]any{})
+	if n := openDocuments(); n != 1 {
+		t.Errorf("%d documents open after initialized, want the held back one", n)
+	}
+
+	// After shutdown, requests are invalid until the server is initialized again.
+	client.call("shutdown", nil, nil)
+	wantError(client.conn.Call(ctx, "workspace/executeCommand", map[string]any{"command": commandStatus}, nil), jsonrpc2.CodeInvalidRequest)
+
+	client.call("initialize", options, nil)
+	if n := openDocuments(); n != 0 {
+		t.Errorf("%d documents open after initializing again, want 0", n)
+	}
+}
+
+// TestLangHandler_exit tests the exit code of the exit notification, and that
+// notifications sent between shutdown and exit are dropped.
+func TestLangHandler_exit(t *testing.T) {
+	tests := []struct {
+		name     string
+		shutdown bool
+		want     int
+	}{
+		{name: "after shutdown", shutdown: true, want: 0},
+		{name: "without shutdown", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			path := filepath.Join(root, "main.go")
+			if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+				t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+			}
+
+			h := newLangHandler(false)
+			exited := make(chan int, 1)
+			h.exit = func(code int) { exited <- code }
+			client := newTestClient(t, h)
+
+			client.call("initialize", map[string]any{
+				"rootUri":               string(pathToURI(root)),
+				"initializationOptions": map[string]any{"command": []string{"true"}, "debounceMs": 0},
+			}, nil)
+			client.notify("initialized", map[string]any{})
+
+			uri := pathToURI(path)
+			client.notify("textDocument/didOpen", map[string]any{
+				"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": "package main\n"},
+			})
+
+			if tt.shutdown {
+				client.call("shutdown", nil, nil)
+				client.notify("textDocument/didSave", map[string]any{"textDocument": map[string]any{"uri": uri}})
+			}
+
+			client.notify("exit", nil)
+
+			select {
+			case code := <-exited:
+				if code != tt.want {
+					t.Errorf("exit code = %d, want %d", code, tt.want)
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("the server did not exit")
+			}
+
+			// The linter is stopped, a lint requested afterwards is dropped.
+			h.queue(uri)
+		})
+	}
+}
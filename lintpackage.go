@@ -0,0 +1,255 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// commandLintPackage lints the packages matching a pattern relative to the workspace
+// root, such as "./internal/auth/...", and publishes the diagnostics of every file.
+const commandLintPackage = "golangci-lint.lintPackage"
+
+func (h *langHandler) executeLintPackage(ctx context.Context, params ExecuteCommandParams) error {
+	var pattern string
+	if len(params.Arguments) != 1 || json.Unmarshal(params.Arguments[0], &pattern) != nil || pattern == "" {
+		return invalidParams("%s: expected a package pattern argument", commandLintPackage)
+	}
+
+	dir, recursive, err := h.packagePatternDir(pattern)
+	if err != nil {
+		h.showMessage(ctx, MTError, fmt.Sprintf("golangci-lint-langserver: cannot lint %q: %v", pattern, err))
+
+		return nil
+	}
+
+	// The lint may take long, its outcome is published when it completes.
+	go h.lintPackage(h.lintContext(), pattern, dir, recursive)
+
+	return nil
+}
+
+// packagePatternDir returns the directory of a package pattern relative to the workspace
+// root and whether the pattern matches the packages below it as well.
+func (h *langHandler) packagePatternDir(pattern string) (string, bool, error) {
+	if h.rootDir == "" {
+		return "", false, fmt.Errorf("there is no workspace")
+	}
+
+	rest, recursive := strings.CutSuffix(pattern, "...")
+	if recursive {
+		rest = strings.TrimSuffix(rest, "/")
+	}
+
+	if rest != "." && !strings.HasPrefix(rest, "./") {
+		return "", false, fmt.Errorf("the pattern must be relative to the workspace, like ./internal/... or ./cmd/app")
+	}
+
+	if strings.ContainsAny(rest, "*?[") {
+		return "", false, fmt.Errorf("only ... is supported as wildcard, at the end of the pattern")
+	}
+
+	dir := filepath.Join(h.rootDir, filepath.FromSlash(rest))
+	if !h.inWorkspace(dir) {
+		return "", false, fmt.Errorf("the pattern is outside the workspace")
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", false, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	return dir, recursive, nil
+}
+
+// lintPackage runs golangci-lint on the packages in dir from their module root and
+// publishes the diagnostics of every reported file. Files reported by the previous
+// lint of the same pattern but not by this one are cleared.
+func (h *langHandler) lintPackage(ctx context.Context, pattern, dir string, recursive bool) {
+	h.settingsMu.RLock()
+	defer h.settingsMu.RUnlock()
+
+	results, err := h.packageDiagnostics(ctx, pattern, dir, recursive)
+	if ctx.Err() != nil {
+		slog.Info("package lint cancelled", "pattern", pattern)
+
+		return
+	}
+
+	if err != nil {
+		h.showMessage(ctx, MTError, err.Error())
+
+		return
+	}
+
+	found := len(results)
+	h.recordPackageLint(pattern, results)
+
+	for uri, diagnostics := range results {
+		h.results.set(uri, diagnostics, h.resultID(uri))
+	}
+	h.publishAll("", results)
+
+	if found == 0 {
+		h.showMessage(ctx, MTInfo, fmt.Sprintf("golangci-lint found no issues in %s", pattern))
+
+		return
+	}
+
+	slog.Info("package lint completed", "pattern", pattern, "files", found)
+}
+
+// recordPackageLint adds to results the documents with diagnostics from the previous
+// lint of pattern that it no longer reports, without diagnostics so that they are
+// cleared, and records the documents with diagnostics for the next lint of pattern.
+func (h *langHandler) recordPackageLint(pattern string, results map[DocumentURI][]Diagnostic) {
+	h.packageLintsMu.Lock()
+	defer h.packageLintsMu.Unlock()
+
+	for _, uri := range h.packageLints[pattern] {
+		if _, ok := results[uri]; !ok {
+			results[uri] = []Diagnostic{}
+		}
+	}
+
+	if h.packageLints == nil {
+		h.packageLints = make(map[string][]DocumentURI)
+	}
+
+	h.packageLints[pattern] = nil
+	for uri, diagnostics := range results {
+		if len(diagnostics) > 0 {
+			h.packageLints[pattern] = append(h.packageLints[pattern], uri)
+		}
+	}
+}
+
+// packageDiagnostics runs golangci-lint on the packages in dir from their module root
+// and returns the diagnostics of every reported file. settingsMu must be held.
+func (h *langHandler) packageDiagnostics(ctx context.Context, pattern, dir string, recursive bool) (map[DocumentURI][]Diagnostic, error) {
+	root := moduleRoot(dir)
+
+	target := "."
+	if rel, err := filepath.Rel(root, dir); err == nil && rel != "." {
+		target = "./" + filepath.ToSlash(rel)
+	}
+	if recursive {
+		target += "/..."
+	}
+
+	command, expanded := expandCommand(h.command, dir, dir, root)
+	args := slices.Clone(command[1:])
+	if !expanded {
+		args = append(args, target)
+	}
+
+	// Output over maxOutputBytes kills the run without cancelling the lint.
+	runCtx, kill := context.WithCancel(ctx)
+	defer kill()
+
+	cmd := exec.CommandContext(runCtx, command[0], args...)
+	setProcessGroup(cmd)
+	cmd.Dir = root
+
+	stdout := &limitedBuffer{limit: h.maxOutputBytes, onExceed: kill}
+	cmd.Stdout = stdout
+	stderr := &lineWriter{onLine: h.logStderrLine}
+	cmd.Stderr = stderr
+
+	slog.Info("linting packages", "pattern", pattern, "command", cmd.Args, "dir", cmd.Dir)
+
+	err := h.runLintCommand(cmd)
+	stderr.Flush()
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	if stdout.Exceeded() {
+		slog.Error("golangci-lint output exceeded maxOutputBytes, the run was killed", "pattern", pattern, "limit", h.maxOutputBytes)
+
+		return nil, errors.New(outputLimitMessage(h.maxOutputBytes))
+	}
+
+	var result GolangCILintResult
+	if err != nil {
+		if stdout.Len() == 0 {
+			return nil, fmt.Errorf("golangci-lint failed on %s: %w\n%s", pattern, err, strings.TrimSpace(stripANSI(string(stderr.Bytes()))))
+		}
+
+		if sarif, ok := parseSARIFOutput(stdout.Bytes()); ok {
+			result = sarif
+		} else if jsonErr := json.Unmarshal(stdout.Bytes(), &result); jsonErr != nil {
+			lineResult, ok := parseLineNumberOutput(stdout.Bytes())
+			if !ok {
+				return nil, errors.New(unparseableOutputDiagnostics(jsonErr, exitCode(err), stdout.Bytes())[0].Message)
+			}
+
+			result = lineResult
+		}
+	}
+
+	if h.typecheckOnly {
+		result.Issues, _ = withholdUntilCompiles(result.Issues)
+	}
+
+	baseDirs := []string{cmp.Or(h.relativePathBase(dir, root), h.pathConfig.getBaseDir(root, cmp.Or(h.configBaseDir(dir), root))), root, dir}
+	results := make(map[DocumentURI][]Diagnostic)
+	sources := make(map[string]sourceLines)
+
+	for _, issue := range result.Issues {
+		issue.Pos.Filename = h.pathConfig.stripPathPrefix(issue.Pos.Filename)
+		issue.Text = stripANSI(issue.Text)
+
+		if h.excludesMessage(issue.Text) {
+			continue
+		}
+
+		path, ok := resolveIssuePath(issue.Pos.Filename, baseDirs)
+		if !ok || (!h.allowExternalPaths && !h.inWorkspace(path)) {
+			slog.Debug("dropping issue of a file that is not in the workspace", "path", issue.Pos.Filename)
+
+			continue
+		}
+
+		if h.inTestdata(path) {
+			slog.Debug("dropping issue of a testdata file", "path", path)
+
+			continue
+		}
+
+		src, ok := sources[path]
+		if !ok {
+			src, _ = readSourceLines(path)
+			sources[path] = src
+		}
+
+		uri := pathToURI(path)
+		results[uri] = append(results[uri], h.issueDiagnostic(&issue, path, src, baseDirs))
+	}
+
+	for uri, diagnostics := range results {
+		diagnostics, _ = h.filterSeverity(uri, h.dismissed.filter(uri, diagnostics))
+		results[uri] = h.mergeSameRange(diagnostics)
+	}
+
+	return results, nil
+}
+
+// showMessage shows a message to the user with window/showMessage.
+func (h *langHandler) showMessage(ctx context.Context, typ MessageType, message string) {
+	if h.conn == nil {
+		return
+	}
+
+	if err := h.conn.Notify(ctx, "window/showMessage", &ShowMessageParams{Type: typ, Message: message}); err != nil {
+		slog.Error("failed to show message", "error", err)
+	}
+}
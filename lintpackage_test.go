@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLangHandler_packagePatternDir(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "internal", "auth"), 0o755); err != nil {
+		t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		pattern       string
+		wantDir       string
+		wantRecursive bool
+		wantErr       bool
+	}{
+		{name: "package", pattern: "./internal/auth", wantDir: filepath.Join(root, "internal", "auth")},
+		{name: "recursive", pattern: "./internal/...", wantDir: filepath.Join(root, "internal"), wantRecursive: true},
+		{name: "workspace", pattern: "./...", wantDir: root, wantRecursive: true},
+		{name: "import path", pattern: "example.com/app/internal", wantErr: true},
+		{name: "absolute", pattern: root, wantErr: true},
+		{name: "outside the workspace", pattern: "./../...", wantErr: true},
+		{name: "missing directory", pattern: "./cmd/...", wantErr: true},
+		{name: "wildcard", pattern: "./internal/a*", wantErr: true},
+	}
+
+	h := &langHandler{rootDir: root}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, recursive, err := h.packagePatternDir(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("packagePatternDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if dir != tt.wantDir || recursive != tt.wantRecursive {
+				t.Errorf("packagePatternDir() = %q, %v, want %q, %v", dir, recursive, tt.wantDir, tt.wantRecursive)
+			}
+		})
+	}
+}
+
+// TestLangHandler_lintPackage tests that the diagnostics of every file reported by a
+// package lint are published, and cleared when a later lint no longer reports them.
+func TestLangHandler_lintPackage(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("package main\n"), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	issue := func(file string) Issue {
+		i := Issue{FromLinter: "unused", Text: "func f is unused"}
+		i.Pos.Filename, i.Pos.Line, i.Pos.Column = file, 1, 1
+
+		return i
+	}
+
+	command := fakeLinter(t, GolangCILintResult{Issues: []Issue{issue("a.go"), issue("b.go")}})
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(root)),
+		"initializationOptions": map[string]any{"command": command},
+	}, nil)
+
+	published := func(path string, count int) func(json.RawMessage) bool {
+		return func(raw json.RawMessage) bool {
+			var params PublishDiagnosticsParams
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return false
+			}
+
+			return params.URI == pathToURI(path) && len(params.Diagnostics) == count
+		}
+	}
+
+	client.call("workspace/executeCommand", map[string]any{"command": commandLintPackage, "arguments": []string{"./..."}}, nil)
+
+	client.waitFor("textDocument/publishDiagnostics", published(filepath.Join(root, "a.go"), 1), 5*time.Second)
+	client.waitFor("textDocument/publishDiagnostics", published(filepath.Join(root, "b.go"), 1), 5*time.Second)
+
+	b, err := json.Marshal(GolangCILintResult{Issues: []Issue{issue("a.go")}})
+	if err != nil {
+		t.Fatalf("json.Marshal() returned unexpected error: %v", err)
+	}
+	if err := os.WriteFile(command[len(command)-1], b, 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	client.call("workspace/executeCommand", map[string]any{"command": commandLintPackage, "arguments": []string{"./..."}}, nil)
+
+	client.waitFor("textDocument/publishDiagnostics", published(filepath.Join(root, "b.go"), 0), 5*time.Second)
+}
+
+// TestLangHandler_lintPackageMessages tests that invalid patterns and lints without
+// issues are reported with window/showMessage.
+func TestLangHandler_lintPackageMessages(t *testing.T) {
+	tests := []struct {
+		name        string
+		pattern     string
+		wantType    MessageType
+		wantMessage string
+	}{
+		{name: "invalid pattern", pattern: "/etc/...", wantType: MTError, wantMessage: "cannot lint"},
+		{name: "no issues", pattern: "./...", wantType: MTInfo, wantMessage: "found no issues in ./..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newLangHandler(false)
+			client := newTestClient(t, h)
+
+			client.call("initialize", map[string]any{
+				"rootUri":               string(pathToURI(t.TempDir())),
+				"initializationOptions": map[string]any{"command": fakeLinter(t, GolangCILintResult{})},
+			}, nil)
+
+			client.call("workspace/executeCommand", map[string]any{"command": commandLintPackage, "arguments": []string{tt.pattern}}, nil)
+
+			raw := client.waitFor("window/showMessage", func(json.RawMessage) bool { return true }, 5*time.Second)
+
+			var params ShowMessageParams
+			if err := json.Unmarshal(raw, &params); err != nil {
+				t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+			}
+
+			if params.Type != tt.wantType || !strings.Contains(params.Message, tt.wantMessage) {
+				t.Errorf("showMessage = %+v, want type %d containing %q", params, tt.wantType, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+// defaultConcurrency is the number of directories linted at the same time when neither
+// -concurrency nor the concurrency option set it.
+const defaultConcurrency = 2
+
+// lintPool runs the lints of different directories in parallel, up to a limit, and those
+// of a directory one after the other in the order they were requested: golangci-lint
+// runs on the same package would only contend for the locks of its cache. It also keeps
+// the guarantee of runningLints that a newer lint of a target publishes after an older one.
+type lintPool struct {
+	lint func(DocumentURI)
+
+	mu    sync.Mutex
+	limit int
+	// queued holds the lints not started yet, by directory. A directory is present while
+	// it is being linted or waits for a worker.
+	queued map[string][]DocumentURI
+	// waiting are the directories waiting for a worker, in the order they were requested.
+	waiting []string
+	running int
+	wg      sync.WaitGroup
+}
+
+func newLintPool(lint func(DocumentURI)) *lintPool {
+	return &lintPool{lint: lint, queued: make(map[string][]DocumentURI)}
+}
+
+// submit queues the lint of the document after the lints of its directory requested
+// before, and starts a worker if fewer than limit are running. It never waits, so that
+// the linter keeps reading requests.
+func (p *lintPool) submit(uri DocumentURI, limit int) {
+	dir := filepath.Dir(uriToPath(string(uri)))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.limit = max(limit, 1)
+
+	queued, ok := p.queued[dir]
+	p.queued[dir] = append(queued, uri)
+	if !ok {
+		p.waiting = append(p.waiting, dir)
+	}
+
+	for p.running < p.limit && len(p.waiting) > 0 {
+		p.running++
+		p.wg.Add(1)
+
+		go p.work(p.next())
+	}
+}
+
+// next takes the first directory waiting for a worker. p.mu must be held.
+func (p *lintPool) next() string {
+	dir := p.waiting[0]
+	p.waiting = p.waiting[1:]
+
+	return dir
+}
+
+// work lints the documents queued for the directory, then moves on to the directories
+// waiting for a worker, until there are none or the limit was lowered.
+func (p *lintPool) work(dir string) {
+	defer p.wg.Done()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for {
+		if queued := p.queued[dir]; len(queued) > 0 {
+			uri := queued[0]
+			p.queued[dir] = queued[1:]
+
+			p.mu.Unlock()
+			p.lint(uri)
+			p.mu.Lock()
+
+			continue
+		}
+
+		delete(p.queued, dir)
+
+		if len(p.waiting) == 0 || p.running > p.limit {
+			p.running--
+
+			return
+		}
+
+		dir = p.next()
+	}
+}
+
+// wait waits for the workers to be done.
+func (p *lintPool) wait() {
+	p.wg.Wait()
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestLangHandler_lintConcurrency tests that the documents of different directories are
+// linted at the same time, while those of a directory are linted one after the other.
+func TestLangHandler_lintConcurrency(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake linter needs sh")
+	}
+
+	root := t.TempDir()
+	files := []string{
+		filepath.Join(root, "one", "a.go"),
+		filepath.Join(root, "one", "b.go"),
+		filepath.Join(root, "two", "c.go"),
+	}
+	for _, path := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("package p\n"), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	// The fake linter records when it starts and ends linting the file it is given.
+	log := filepath.Join(t.TempDir(), "runs")
+	script := `echo "start $1" >> "$0"; sleep 0.3; echo "end $1" >> "$0"; echo '{"Issues":[]}'`
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri": string(pathToURI(root)),
+		"initializationOptions": map[string]any{
+			"command":     []string{"sh", "-c", script, log, "{path}"},
+			"lintTarget":  "file",
+			"debounceMs":  0,
+			"concurrency": 2,
+		},
+	}, nil)
+
+	for _, path := range files {
+		client.notify("textDocument/didOpen", map[string]any{
+			"textDocument": map[string]any{"uri": pathToURI(path), "languageId": "go", "version": 1, "text": "package p\n"},
+		})
+	}
+
+	var events []string
+	deadline := time.Now().Add(10 * time.Second)
+	for len(events) < 2*len(files) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for the lints, got %q", events)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+
+		b, _ := os.ReadFile(log)
+		events = strings.Split(strings.TrimSpace(string(b)), "\n")
+	}
+
+	running := 0
+	maxRunning := 0
+	byDir := make(map[string]int)
+	for _, event := range events {
+		kind, path, _ := strings.Cut(event, " ")
+		dir := filepath.Base(filepath.Dir(path))
+
+		switch kind {
+		case "start":
+			running++
+			byDir[dir]++
+			if byDir[dir] > 1 {
+				t.Errorf("directory %s was linted twice at the same time: %q", dir, events)
+			}
+		case "end":
+			running--
+			byDir[dir]--
+		}
+
+		maxRunning = max(maxRunning, running)
+	}
+
+	if maxRunning != 2 {
+		t.Errorf("at most %d lints ran at the same time, want 2: %q", maxRunning, events)
+	}
+}
+
+// TestLintPool tests that submitting never waits for the workers, however many lints are
+// queued, and that every lint runs with at most limit at the same time.
+func TestLintPool(t *testing.T) {
+	release := make(chan struct{})
+
+	var mu sync.Mutex
+	running, maxRunning, linted := 0, 0, 0
+
+	p := newLintPool(func(DocumentURI) {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+
+		<-release
+
+		mu.Lock()
+		running--
+		linted++
+		mu.Unlock()
+	})
+
+	submitted := make(chan struct{})
+	go func() {
+		defer close(submitted)
+
+		for i := range 2 * requestQueueSize {
+			p.submit(pathToURI(filepath.Join("/src", fmt.Sprint(i%(requestQueueSize+1)), "a.go")), 2)
+		}
+	}()
+
+	select {
+	case <-submitted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("submit waited for the running lints")
+	}
+
+	// The lints are held until the workers have started.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		started := running
+		mu.Unlock()
+
+		if started == 2 || time.Now().After(deadline) {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(release)
+	p.wait()
+
+	if maxRunning != 2 {
+		t.Errorf("at most %d lints ran at the same time, want 2", maxRunning)
+	}
+
+	if linted != 2*requestQueueSize {
+		t.Errorf("%d lints ran, want %d", linted, 2*requestQueueSize)
+	}
+}
+
+// TestLangHandler_lintQueueFull tests that the server keeps answering requests while
+// more directories are queued than requests fit in the queue of the linter.
+func TestLangHandler_lintQueueFull(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake linter needs sh")
+	}
+
+	root := t.TempDir()
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri": string(pathToURI(root)),
+		"initializationOptions": map[string]any{
+			"command":     []string{"sh", "-c", `sleep 1; echo '{"Issues":[]}'`, "sh", "{path}"},
+			"debounceMs":  0,
+			"concurrency": 1,
+		},
+	}, nil)
+
+	for i := range 2 * requestQueueSize {
+		path := filepath.Join(root, fmt.Sprint(i), "a.go")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("package p\n"), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+
+		client.notify("textDocument/didOpen", map[string]any{
+			"textDocument": map[string]any{"uri": pathToURI(path), "languageId": "go", "version": 1, "text": "package p\n"},
+		})
+	}
+
+	answered := make(chan struct{})
+	go func() {
+		defer close(answered)
+
+		var status StatusResult
+		client.call("workspace/executeCommand", map[string]any{"command": commandStatus}, &status)
+	}()
+
+	select {
+	case <-answered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server stopped answering while lints were queued")
+	}
+
+	// The queued lints are dropped on shutdown.
+	client.call("shutdown", nil, nil)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// lockWaitTimeout bounds how long a lint waits for another instance to release the workspace lock.
+	lockWaitTimeout = 30 * time.Second
+	// lockPollInterval is how often a waiting lint retries the workspace lock.
+	lockPollInterval = 100 * time.Millisecond
+	// lockRequeueDelay is how long a lint that gave up waiting stays out of the queue.
+	lockRequeueDelay = time.Second
+)
+
+// workspaceLock is an advisory lock file shared by every server instance linting the same workspace.
+// The file holds the pid of its owner so that the lock of a crashed instance can be stolen.
+type workspaceLock struct {
+	path string
+
+	// held is locked with the file, as the lints of this instance run in parallel.
+	held sync.Mutex
+}
+
+// newWorkspaceLock returns the lock of the workspace rooted at rootDir,
+// stored under the user cache directory.
+func newWorkspaceLock(rootDir string) (*workspaceLock, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(cacheDir, "golangci-lint-langserver", "locks")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(filepath.Clean(rootDir)))
+
+	return &workspaceLock{path: filepath.Join(dir, hex.EncodeToString(sum[:8])+".lock")}, nil
+}
+
+// lock waits up to timeout for the lock. It reports false if another instance, or
+// another lint of this instance, still holds it.
+func (l *workspaceLock) lock(timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		// held is polled like the file, so that lints of this instance give up in time too.
+		if l.held.TryLock() {
+			ok, err := l.tryLock()
+			if ok {
+				return true, nil
+			}
+
+			l.held.Unlock()
+
+			if err != nil {
+				return false, err
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return false, nil
+		}
+
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// tryLock takes the lock if it is free or held by a process that no longer exists.
+func (l *workspaceLock) tryLock() (bool, error) {
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err == nil {
+		_, err = fmt.Fprintf(f, "%d\n", os.Getpid())
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+
+		return err == nil, err
+	}
+
+	if !errors.Is(err, fs.ErrExist) {
+		return false, err
+	}
+
+	if !l.stale() {
+		return false, nil
+	}
+
+	// The lock is taken on the next poll, once stolen.
+	return false, l.steal()
+}
+
+// steal removes the lock file of an owner that has exited. Instances stealing it at the
+// same time take turns with a guard file, created exclusively like the lock, and check
+// the owner again under it: otherwise one could remove the lock another one just took
+// after removing the stale file itself.
+func (l *workspaceLock) steal() error {
+	guard := l.path + ".steal"
+
+	f, err := os.OpenFile(guard, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		if !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+
+		// Only an instance that exited while stealing leaves an old guard behind.
+		if info, err := os.Stat(guard); err == nil && time.Since(info.ModTime()) > lockWaitTimeout {
+			if err := os.Remove(guard); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+
+		return nil
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	defer os.Remove(guard)
+
+	if !l.stale() {
+		return nil
+	}
+
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// stale reports whether the lock file belongs to a process that has exited.
+func (l *workspaceLock) stale() bool {
+	b, err := os.ReadFile(l.path)
+	if err != nil {
+		return false
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		// The owner may be writing its pid right now; only give up on old files.
+		info, err := os.Stat(l.path)
+
+		return err == nil && time.Since(info.ModTime()) > lockWaitTimeout
+	}
+
+	// A lock carrying our own pid is a leftover: held keeps this instance from trying
+	// to take a lock it already holds.
+	return pid == os.Getpid() || !processExists(pid)
+}
+
+// unlock releases the lock.
+func (l *workspaceLock) unlock() error {
+	defer l.held.Unlock()
+
+	return os.Remove(l.path)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWorkspaceLock(t *testing.T) {
+	// A process that has already exited provides a dead pid.
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to run a child process: %v", err)
+	}
+	deadPid := cmd.ProcessState.Pid()
+
+	tests := []struct {
+		name   string
+		holder string
+		want   bool
+	}{
+		{
+			name: "free lock",
+			want: true,
+		},
+		{
+			name:   "held by a live process",
+			holder: strconv.Itoa(os.Getppid()),
+			want:   false,
+		},
+		{
+			name:   "held by a dead process",
+			holder: strconv.Itoa(deadPid),
+			want:   true,
+		},
+		{
+			name:   "leftover of this process",
+			holder: strconv.Itoa(os.Getpid()),
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &workspaceLock{path: filepath.Join(t.TempDir(), "workspace.lock")}
+
+			if tt.holder != "" {
+				if err := os.WriteFile(l.path, []byte(tt.holder+"\n"), 0o600); err != nil {
+					t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+				}
+			}
+
+			got, err := l.lock(time.Second)
+			if err != nil {
+				t.Fatalf("lock() returned unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("lock() = %v, want %v", got, tt.want)
+			}
+
+			if !got {
+				return
+			}
+
+			if err := l.unlock(); err != nil {
+				t.Errorf("unlock() returned unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestWorkspaceLock_sameInstance tests that a lint of this instance waiting for another
+// one gives up after the timeout, like when another instance holds the lock.
+func TestWorkspaceLock_sameInstance(t *testing.T) {
+	l := &workspaceLock{path: filepath.Join(t.TempDir(), "workspace.lock")}
+
+	if ok, err := l.lock(time.Second); !ok || err != nil {
+		t.Fatalf("lock() = %v, %v, want true", ok, err)
+	}
+
+	start := time.Now()
+	if ok, err := l.lock(200 * time.Millisecond); ok || err != nil {
+		t.Errorf("second lock() = %v, %v, want false", ok, err)
+	}
+	if waited := time.Since(start); waited > 5*time.Second {
+		t.Errorf("second lock() waited %v, want about the timeout", waited)
+	}
+
+	if err := l.unlock(); err != nil {
+		t.Fatalf("unlock() returned unexpected error: %v", err)
+	}
+
+	if ok, err := l.lock(time.Second); !ok || err != nil {
+		t.Errorf("lock() after unlock() = %v, %v, want true", ok, err)
+	}
+}
+
+// TestWorkspaceLock_steal tests that a stale lock is only removed by the instance holding
+// the guard, and that the owner is checked again under it.
+func TestWorkspaceLock_steal(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to run a child process: %v", err)
+	}
+	deadPid := strconv.Itoa(cmd.ProcessState.Pid())
+
+	l := &workspaceLock{path: filepath.Join(t.TempDir(), "workspace.lock")}
+	if err := os.WriteFile(l.path, []byte(deadPid+"\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	// Another instance is stealing the lock.
+	if err := os.WriteFile(l.path+".steal", nil, 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	if ok, err := l.tryLock(); ok || err != nil {
+		t.Fatalf("tryLock() while another instance steals = %v, %v, want false", ok, err)
+	}
+	if _, err := os.Stat(l.path); err != nil {
+		t.Fatalf("the lock was removed while another instance steals it: %v", err)
+	}
+
+	// It took the lock meanwhile: the lock is no longer stale once the guard is free.
+	if err := os.Remove(l.path + ".steal"); err != nil {
+		t.Fatalf("os.Remove() returned unexpected error: %v", err)
+	}
+	if err := os.WriteFile(l.path, []byte(strconv.Itoa(os.Getppid())+"\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	if err := l.steal(); err != nil {
+		t.Fatalf("steal() returned unexpected error: %v", err)
+	}
+	if _, err := os.Stat(l.path); err != nil {
+		t.Errorf("steal() removed the lock of a live process: %v", err)
+	}
+	if _, err := os.Stat(l.path + ".steal"); !os.IsNotExist(err) {
+		t.Errorf("steal() left its guard behind: %v", err)
+	}
+}
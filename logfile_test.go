@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+
+	// Each line is 8 bytes, so a 20 byte limit fits two lines per file.
+	r, err := newRotatingFile(path, 20, 2)
+	if err != nil {
+		t.Fatalf("newRotatingFile() returned unexpected error: %v", err)
+	}
+	defer r.Close()
+
+	for i := range 7 {
+		if _, err := fmt.Fprintf(r, "line %02d\n", i); err != nil {
+			t.Fatalf("Write() returned unexpected error: %v", err)
+		}
+	}
+
+	want := map[string]string{
+		path:        "line 06\n",
+		path + ".1": "line 04\nline 05\n",
+		path + ".2": "line 02\nline 03\n",
+	}
+	for p, content := range want {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("os.ReadFile() returned unexpected error: %v", err)
+		}
+
+		if string(b) != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(p), b, content)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups, got %s.3", filepath.Base(path))
+	}
+}
+
+func TestRotatingFileOversizedWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+
+	r, err := newRotatingFile(path, 4, 1)
+	if err != nil {
+		t.Fatalf("newRotatingFile() returned unexpected error: %v", err)
+	}
+	defer r.Close()
+
+	// A record larger than the limit is written whole rather than split.
+	long := strings.Repeat("x", 10) + "\n"
+	for range 2 {
+		if _, err := r.Write([]byte(long)); err != nil {
+			t.Fatalf("Write() returned unexpected error: %v", err)
+		}
+	}
+
+	for _, p := range []string{path, path + ".1"} {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("os.ReadFile() returned unexpected error: %v", err)
+		}
+
+		if string(b) != long {
+			t.Errorf("%s = %q, want %q", filepath.Base(p), b, long)
+		}
+	}
+}
@@ -1,14 +1,194 @@
 package main
 
+import "encoding/json"
+
 type DocumentURI string
 
 type InitializeParams struct {
-	RootURI               string                `json:"rootUri,omitempty"`
-	InitializationOptions InitializationOptions `json:"initializationOptions,omitempty"`
+	ProcessID             *int               `json:"processId"`
+	RootURI               string             `json:"rootUri,omitempty"`
+	Capabilities          ClientCapabilities `json:"capabilities"`
+	InitializationOptions json.RawMessage    `json:"initializationOptions,omitempty"`
+	WorkspaceFolders      []WorkspaceFolder  `json:"workspaceFolders,omitempty"`
+}
+
+type WorkspaceFolder struct {
+	URI  string `json:"uri"`
+	Name string `json:"name"`
+}
+
+type DidChangeWorkspaceFoldersParams struct {
+	Event WorkspaceFoldersChangeEvent `json:"event"`
+}
+
+type WorkspaceFoldersChangeEvent struct {
+	Added   []WorkspaceFolder `json:"added"`
+	Removed []WorkspaceFolder `json:"removed"`
+}
+
+type ClientCapabilities struct {
+	Workspace    WorkspaceClientCapabilities    `json:"workspace,omitempty"`
+	TextDocument TextDocumentClientCapabilities `json:"textDocument,omitempty"`
+	Window       WindowClientCapabilities       `json:"window,omitempty"`
+}
+
+type TextDocumentClientCapabilities struct {
+	// Diagnostic is set by clients supporting pull diagnostics.
+	Diagnostic *DiagnosticClientCapabilities `json:"diagnostic,omitempty"`
+
+	PublishDiagnostics PublishDiagnosticsClientCapabilities `json:"publishDiagnostics,omitempty"`
+
+	Formatting *DynamicRegistrationClientCapabilities `json:"formatting,omitempty"`
+}
+
+type DynamicRegistrationClientCapabilities struct {
+	DynamicRegistration bool `json:"dynamicRegistration,omitempty"`
+}
+
+// PublishDiagnosticsClientCapabilities are the optional fields of diagnostics the client
+// supports.
+type PublishDiagnosticsClientCapabilities struct {
+	RelatedInformation     bool `json:"relatedInformation,omitempty"`
+	CodeDescriptionSupport bool `json:"codeDescriptionSupport,omitempty"`
+
+	// TagSupport lists the diagnostic tags the client supports.
+	TagSupport *TagSupportClientCapabilities `json:"tagSupport,omitempty"`
+}
+
+type TagSupportClientCapabilities struct {
+	ValueSet []DiagnosticTag `json:"valueSet"`
+}
+
+type DiagnosticClientCapabilities struct {
+	DynamicRegistration    bool `json:"dynamicRegistration,omitempty"`
+	RelatedDocumentSupport bool `json:"relatedDocumentSupport,omitempty"`
+}
+
+type WorkspaceClientCapabilities struct {
+	ApplyEdit     bool `json:"applyEdit,omitempty"`
+	Configuration bool `json:"configuration,omitempty"`
+}
+
+type WindowClientCapabilities struct {
+	WorkDoneProgress bool                            `json:"workDoneProgress,omitempty"`
+	ShowDocument     *ShowDocumentClientCapabilities `json:"showDocument,omitempty"`
+}
+
+type ShowDocumentClientCapabilities struct {
+	Support bool `json:"support"`
 }
 
 type InitializationOptions struct {
-	Command []string
+	Command    []string `json:"command"`
+	LintTarget string   `json:"lintTarget,omitempty"`
+
+	// RetryOnTimeout defaults to true when unset.
+	RetryOnTimeout *bool `json:"retryOnTimeout,omitempty"`
+
+	LockWorkspace bool `json:"lockWorkspace,omitempty"`
+
+	OnlyTouchedLines bool `json:"onlyTouchedLines,omitempty"`
+
+	AlsoRunGoVet bool `json:"alsoRunGoVet,omitempty"`
+
+	AllowExternalPaths bool `json:"allowExternalPaths,omitempty"`
+
+	// LowPriority runs the lint processes at reduced CPU and I/O priority.
+	LowPriority bool `json:"lowPriority,omitempty"`
+
+	// LintByImportPath passes the import path of the package instead of its directory.
+	LintByImportPath bool `json:"lintByImportPath,omitempty"`
+
+	PublishBatchSize int `json:"publishBatchSize,omitempty"`
+
+	// StderrPattern selects the stderr lines forwarded while golangci-lint runs.
+	// It defaults to the log lines; an empty string disables forwarding.
+	StderrPattern *string `json:"stderrPattern,omitempty"`
+
+	// StartPaused defers all linting until the golangci-lint.resume command.
+	StartPaused bool `json:"startPaused,omitempty"`
+
+	// TypecheckOnly withholds the other issues of a run reporting typecheck errors.
+	// It defaults to true when unset.
+	TypecheckOnly *bool `json:"typecheckOnly,omitempty"`
+
+	// LintOnOpen defaults to true when unset.
+	LintOnOpen *bool `json:"lintOnOpen,omitempty"`
+
+	// ClearOnClose defaults to true when unset.
+	ClearOnClose *bool `json:"clearOnClose,omitempty"`
+
+	// DebounceMs is the window, in milliseconds, within which the lints requested for the
+	// files of a directory are coalesced. It defaults to 200 when unset; zero disables it.
+	DebounceMs *int `json:"debounceMs,omitempty"`
+
+	// MaxMessageLength is the number of characters messages are truncated to.
+	// It defaults to 1000 when unset; zero keeps messages whole.
+	MaxMessageLength *int `json:"maxMessageLength,omitempty"`
+
+	// CacheMaxEntries and CacheMaxBytes bound the diagnostics kept for closed documents.
+	// They default to 10000 documents and 64 MiB when unset; zero removes the bound.
+	CacheMaxEntries *int `json:"cacheMaxEntries,omitempty"`
+	CacheMaxBytes   *int `json:"cacheMaxBytes,omitempty"`
+
+	// Concurrency is the number of directories linted at the same time, overriding the
+	// -concurrency flag.
+	Concurrency *int `json:"concurrency,omitempty"`
+
+	// MaxOutputBytes is the amount of golangci-lint output read before the run is killed.
+	// It defaults to 64 MiB when unset; zero removes the bound.
+	MaxOutputBytes *int `json:"maxOutputBytes,omitempty"`
+
+	// ExcludeMessages are regexps dropping the issues whose text matches.
+	ExcludeMessages []string `json:"excludeMessages,omitempty"`
+
+	// MessageRewrites rewrite the text of matching issues. The first matching rule wins.
+	MessageRewrites []MessageRewrite `json:"messageRewrites,omitempty"`
+
+	// PathSeverities override the severity of issues in matching files. The first match wins.
+	PathSeverities []PathSeverity `json:"pathSeverities,omitempty"`
+
+	// SeverityGrades override the severities that grades like "HIGH" or "LOW", reported
+	// by gosec and similar linters, map onto.
+	SeverityGrades map[string]string `json:"severityGrades,omitempty"`
+
+	// Severities override the severity of the issues of each linter, by linter name.
+	Severities map[string]string `json:"severities,omitempty"`
+
+	// DiagnosticTags override the tags of the issues of a linter, or of a rule named like
+	// "staticcheck SA1019", by tag names. An empty list disables the default tags.
+	DiagnosticTags map[string][]string `json:"diagnosticTags,omitempty"`
+
+	// MergeSamePosition combines the diagnostics of different linters sharing a range
+	// into one.
+	MergeSamePosition bool `json:"mergeSamePosition,omitempty"`
+
+	// MinimumSeverity hides the diagnostics below a severity, such as "warning".
+	MinimumSeverity string `json:"minimumSeverity,omitempty"`
+
+	// LogLevel sets the level of the server logs, overriding the -debug flag.
+	LogLevel string `json:"logLevel,omitempty"`
+
+	// Subprojects are workspace-relative directories, globs allowed, linted as independent
+	// roots: golangci-lint runs in the nearest matching directory of a file.
+	Subprojects []string `json:"subprojects,omitempty"`
+
+	// Languages are the languageIds of the documents to lint. Defaults to ["go"].
+	Languages []string `json:"languages,omitempty"`
+}
+
+type MessageRewrite struct {
+	// Linter restricts the rule to the issues of one linter; empty matches every linter.
+	Linter     string `json:"linter,omitempty"`
+	MatchRegex string `json:"matchRegex"`
+	// ReplaceTemplate replaces the matched text; $1 or ${name} refer to capture groups.
+	ReplaceTemplate string `json:"replaceTemplate"`
+}
+
+type PathSeverity struct {
+	// Glob is a doublestar glob matched against the workspace-relative slash path of the file.
+	Glob     string `json:"glob"`
+	Severity string `json:"severity"`
 }
 
 type InitializeResult struct {
@@ -37,13 +217,111 @@ type TextDocumentSyncOptions struct {
 }
 
 type ServerCapabilities struct {
-	TextDocumentSync           TextDocumentSyncOptions `json:"textDocumentSync,omitempty"`
-	CompletionProvider         *CompletionProvider     `json:"completionProvider,omitempty"`
-	DocumentSymbolProvider     bool                    `json:"documentSymbolProvider,omitempty"`
-	DefinitionProvider         bool                    `json:"definitionProvider,omitempty"`
-	DocumentFormattingProvider bool                    `json:"documentFormattingProvider,omitempty"`
-	HoverProvider              bool                    `json:"hoverProvider,omitempty"`
-	CodeActionProvider         bool                    `json:"codeActionProvider,omitempty"`
+	TextDocumentSync           TextDocumentSyncOptions      `json:"textDocumentSync,omitempty"`
+	CompletionProvider         *CompletionProvider          `json:"completionProvider,omitempty"`
+	DocumentSymbolProvider     bool                         `json:"documentSymbolProvider,omitempty"`
+	DefinitionProvider         bool                         `json:"definitionProvider,omitempty"`
+	DocumentFormattingProvider bool                         `json:"documentFormattingProvider,omitempty"`
+	HoverProvider              bool                         `json:"hoverProvider,omitempty"`
+	CodeActionProvider         bool                         `json:"codeActionProvider,omitempty"`
+	ExecuteCommandProvider     *ExecuteCommandOptions       `json:"executeCommandProvider,omitempty"`
+	DiagnosticProvider         *DiagnosticOptions           `json:"diagnosticProvider,omitempty"`
+	Workspace                  *WorkspaceServerCapabilities `json:"workspace,omitempty"`
+}
+
+type WorkspaceServerCapabilities struct {
+	WorkspaceFolders *WorkspaceFoldersServerCapabilities `json:"workspaceFolders,omitempty"`
+}
+
+type WorkspaceFoldersServerCapabilities struct {
+	Supported           bool `json:"supported"`
+	ChangeNotifications bool `json:"changeNotifications"`
+}
+
+type DiagnosticOptions struct {
+	Identifier            string `json:"identifier,omitempty"`
+	InterFileDependencies bool   `json:"interFileDependencies"`
+	WorkspaceDiagnostics  bool   `json:"workspaceDiagnostics"`
+}
+
+type DocumentDiagnosticParams struct {
+	TextDocument     TextDocumentIdentifier `json:"textDocument"`
+	Identifier       string                 `json:"identifier,omitempty"`
+	PreviousResultID string                 `json:"previousResultId,omitempty"`
+}
+
+// Kinds of DocumentDiagnosticReport.
+const (
+	DocumentDiagnosticReportKindFull      = "full"
+	DocumentDiagnosticReportKindUnchanged = "unchanged"
+)
+
+type FullDocumentDiagnosticReport struct {
+	Kind     string       `json:"kind"`
+	ResultID string       `json:"resultId,omitempty"`
+	Items    []Diagnostic `json:"items"`
+}
+
+type UnchangedDocumentDiagnosticReport struct {
+	Kind     string `json:"kind"`
+	ResultID string `json:"resultId"`
+}
+
+type WorkspaceDiagnosticParams struct {
+	Identifier         string             `json:"identifier,omitempty"`
+	PreviousResultIDs  []PreviousResultID `json:"previousResultIds"`
+	PartialResultToken ProgressToken      `json:"partialResultToken,omitempty"`
+}
+
+type PreviousResultID struct {
+	URI   DocumentURI `json:"uri"`
+	Value string      `json:"value"`
+}
+
+type WorkspaceFullDocumentDiagnosticReport struct {
+	FullDocumentDiagnosticReport
+	URI DocumentURI `json:"uri"`
+	// Version is the version of the document the diagnostics were computed for, null as
+	// golangci-lint lints the files on disk.
+	Version *int `json:"version"`
+}
+
+type WorkspaceDiagnosticReport struct {
+	Items []WorkspaceFullDocumentDiagnosticReport `json:"items"`
+}
+
+// WorkspaceDiagnosticReportPartialResult is the value of the $/progress notifications
+// streaming the reports of a workspace/diagnostic request with a partialResultToken.
+type WorkspaceDiagnosticReportPartialResult struct {
+	Items []WorkspaceFullDocumentDiagnosticReport `json:"items"`
+}
+
+// RelatedFullDocumentDiagnosticReport is a full report of a document along with those of
+// other documents the lint reported on.
+type RelatedFullDocumentDiagnosticReport struct {
+	FullDocumentDiagnosticReport
+	RelatedDocuments map[DocumentURI]FullDocumentDiagnosticReport `json:"relatedDocuments,omitempty"`
+}
+
+type ExecuteCommandOptions struct {
+	Commands []string `json:"commands"`
+}
+
+type ExecuteCommandParams struct {
+	Command   string            `json:"command"`
+	Arguments []json.RawMessage `json:"arguments,omitempty"`
+}
+
+// StatusResult is the result of the golangci-lint.status command.
+type StatusResult struct {
+	Paused        bool `json:"paused"`
+	OpenDocuments int  `json:"openDocuments"`
+	QueuedLints   int  `json:"queuedLints"`
+	// HiddenDiagnostics is the number of diagnostics below minimumSeverity in the
+	// last lint of each document.
+	HiddenDiagnostics int `json:"hiddenDiagnostics"`
+	// Dismissed is the number of diagnostics dismissed for the session.
+	Dismissed int `json:"dismissed"`
 }
 
 type TextDocumentItem struct {
@@ -61,11 +339,60 @@ type DidOpenTextDocumentParams struct {
 	TextDocument TextDocumentItem `json:"textDocument"`
 }
 
+type VersionedTextDocumentIdentifier struct {
+	URI     DocumentURI `json:"uri"`
+	Version int         `json:"version"`
+}
+
+type TextDocumentContentChangeEvent struct {
+	Range *Range `json:"range,omitempty"`
+	Text  string `json:"text"`
+}
+
+type DidChangeTextDocumentParams struct {
+	TextDocument   VersionedTextDocumentIdentifier  `json:"textDocument"`
+	ContentChanges []TextDocumentContentChangeEvent `json:"contentChanges"`
+}
+
+type DidCloseTextDocumentParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
 type DidSaveTextDocumentParams struct {
 	Text         *string                `json:"text"`
 	TextDocument TextDocumentIdentifier `json:"textDocument"`
 }
 
+type DidChangeConfigurationParams struct {
+	Settings json.RawMessage `json:"settings"`
+}
+
+type ConfigurationItem struct {
+	ScopeURI string `json:"scopeUri,omitempty"`
+	Section  string `json:"section,omitempty"`
+}
+
+type ConfigurationParams struct {
+	Items []ConfigurationItem `json:"items"`
+}
+
+type FileChangeType int
+
+const (
+	FCTCreated FileChangeType = iota + 1
+	FCTChanged
+	FCTDeleted
+)
+
+type FileEvent struct {
+	URI  DocumentURI    `json:"uri"`
+	Type FileChangeType `json:"type"`
+}
+
+type DidChangeWatchedFilesParams struct {
+	Changes []FileEvent `json:"changes"`
+}
+
 type Location struct {
 	URI   string `json:"uri"`
 	Range Range  `json:"range"`
@@ -95,13 +422,199 @@ const (
 	DSHint
 )
 
+type DiagnosticTag int
+
+const (
+	// DTUnnecessary marks unused or unnecessary code, which clients fade out.
+	DTUnnecessary DiagnosticTag = iota + 1
+	// DTDeprecated marks deprecated code, which clients strike through.
+	DTDeprecated
+)
+
 type Diagnostic struct {
 	Range              Range                          `json:"range"`
 	Severity           DiagnosticSeverity             `json:"severity,omitempty"`
 	Code               *string                        `json:"code,omitempty"`
+	CodeDescription    *CodeDescription               `json:"codeDescription,omitempty"`
 	Source             *string                        `json:"source,omitempty"`
 	Message            string                         `json:"message"`
+	Tags               []DiagnosticTag                `json:"tags,omitempty"`
 	RelatedInformation []DiagnosticRelatedInformation `json:"relatedInformation,omitempty"`
+	Data               *DiagnosticData                `json:"data,omitempty"`
+}
+
+// CodeDescription links the code of a diagnostic to its documentation.
+type CodeDescription struct {
+	Href string `json:"href"`
+}
+
+// DiagnosticData is kept by the client with a diagnostic and sent back with it, for
+// instance in code action requests.
+type DiagnosticData struct {
+	// FullMessage is the message of a diagnostic whose message was truncated.
+	FullMessage string `json:"fullMessage,omitempty"`
+
+	// Hunks are the changes fixing the issue, from a formatting linter or a Replacement.
+	Hunks []DiffHunk `json:"hunks,omitempty"`
+
+	// UnusedNolint is the directive reported as unused by nolintlint.
+	UnusedNolint *UnusedNolint `json:"unusedNolint,omitempty"`
+
+	// Merged are the diagnostics of the same range combined into this one by
+	// mergeSamePosition.
+	Merged []Diagnostic `json:"merged,omitempty"`
+}
+
+// UnusedNolint is a //nolint directive reported as unused, as a whole or, when Linter
+// is set, for one of its linters.
+type UnusedNolint struct {
+	Directive string `json:"directive"`
+	Linter    string `json:"linter,omitempty"`
+}
+
+// DiffHunk replaces the Old lines of a document, starting at the 0-based Line, with the New lines.
+type DiffHunk struct {
+	Line int      `json:"line"`
+	Old  []string `json:"old"`
+	New  []string `json:"new"`
+}
+
+type CodeActionContext struct {
+	Diagnostics []Diagnostic `json:"diagnostics"`
+	Only        []string     `json:"only,omitempty"`
+}
+
+type CodeActionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+	Context      CodeActionContext      `json:"context"`
+}
+
+type Command struct {
+	Title     string `json:"title"`
+	Command   string `json:"command"`
+	Arguments []any  `json:"arguments,omitempty"`
+}
+
+type CodeAction struct {
+	Title       string         `json:"title"`
+	Kind        string         `json:"kind,omitempty"`
+	Diagnostics []Diagnostic   `json:"diagnostics,omitempty"`
+	Edit        *WorkspaceEdit `json:"edit,omitempty"`
+	Command     *Command       `json:"command,omitempty"`
+}
+
+type MessageActionItem struct {
+	Title string `json:"title"`
+}
+
+type ShowMessageRequestParams struct {
+	Type    MessageType         `json:"type"`
+	Message string              `json:"message"`
+	Actions []MessageActionItem `json:"actions,omitempty"`
+}
+
+type ShowDocumentParams struct {
+	URI       string `json:"uri"`
+	External  bool   `json:"external,omitempty"`
+	TakeFocus bool   `json:"takeFocus,omitempty"`
+	Selection *Range `json:"selection,omitempty"`
+}
+
+type MessageType int
+
+const (
+	MTError MessageType = iota + 1
+	MTWarning
+	MTInfo
+	MTLog
+)
+
+type ShowMessageParams struct {
+	Type    MessageType `json:"type"`
+	Message string      `json:"message"`
+}
+
+type LogMessageParams struct {
+	Type    MessageType `json:"type"`
+	Message string      `json:"message"`
+}
+
+type ProgressToken string
+
+type WorkDoneProgressCreateParams struct {
+	Token ProgressToken `json:"token"`
+}
+
+type WorkDoneProgressCancelParams struct {
+	Token ProgressToken `json:"token"`
+}
+
+type ProgressParams struct {
+	Token ProgressToken `json:"token"`
+	Value any           `json:"value"`
+}
+
+type WorkDoneProgressBegin struct {
+	Kind        string `json:"kind"`
+	Title       string `json:"title"`
+	Cancellable bool   `json:"cancellable,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+type WorkDoneProgressReport struct {
+	Kind       string `json:"kind"`
+	Message    string `json:"message,omitempty"`
+	Percentage int    `json:"percentage"`
+}
+
+type WorkDoneProgressEnd struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message,omitempty"`
+}
+
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+type DocumentFormattingParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Options      FormattingOptions      `json:"options"`
+}
+
+type FormattingOptions struct {
+	TabSize      int  `json:"tabSize"`
+	InsertSpaces bool `json:"insertSpaces"`
+}
+
+type RegistrationParams struct {
+	Registrations []Registration `json:"registrations"`
+}
+
+type Registration struct {
+	ID              string `json:"id"`
+	Method          string `json:"method"`
+	RegisterOptions any    `json:"registerOptions,omitempty"`
+}
+
+type TextDocumentRegistrationOptions struct {
+	DocumentSelector []DocumentFilter `json:"documentSelector"`
+}
+
+type DocumentFilter struct {
+	Language string `json:"language,omitempty"`
+	Scheme   string `json:"scheme,omitempty"`
+	Pattern  string `json:"pattern,omitempty"`
+}
+
+type WorkspaceEdit struct {
+	Changes map[DocumentURI][]TextEdit `json:"changes,omitempty"`
+}
+
+type ApplyWorkspaceEditParams struct {
+	Label string        `json:"label,omitempty"`
+	Edit  WorkspaceEdit `json:"edit"`
 }
 
 type PublishDiagnosticsParams struct {
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDocumentDiagnosticReport_marshal(t *testing.T) {
+	tests := []struct {
+		name   string
+		report any
+		want   string
+	}{
+		{
+			name:   "full",
+			report: FullDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindFull, ResultID: "1", Items: []Diagnostic{}},
+			want:   `{"kind":"full","resultId":"1","items":[]}`,
+		},
+		{
+			name:   "unchanged",
+			report: UnchangedDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindUnchanged, ResultID: "1"},
+			want:   `{"kind":"unchanged","resultId":"1"}`,
+		},
+		{
+			name: "related without other documents",
+			report: RelatedFullDocumentDiagnosticReport{
+				FullDocumentDiagnosticReport: FullDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindFull, ResultID: "1", Items: []Diagnostic{}},
+			},
+			want: `{"kind":"full","resultId":"1","items":[]}`,
+		},
+		{
+			name: "related",
+			report: RelatedFullDocumentDiagnosticReport{
+				FullDocumentDiagnosticReport: FullDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindFull, ResultID: "1", Items: []Diagnostic{}},
+				RelatedDocuments: map[DocumentURI]FullDocumentDiagnosticReport{
+					"file:///p/b.go": {Kind: DocumentDiagnosticReportKindFull, ResultID: "1", Items: []Diagnostic{}},
+				},
+			},
+			want: `{"kind":"full","resultId":"1","items":[],"relatedDocuments":{"file:///p/b.go":{"kind":"full","resultId":"1","items":[]}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.report)
+			if err != nil {
+				t.Fatalf("json.Marshal() returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, string(b)); diff != "" {
+				t.Errorf("report mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClientCapabilities_unmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want *DiagnosticClientCapabilities
+	}{
+		{name: "no pull diagnostics", raw: `{"textDocument": {}}`},
+		{name: "pull diagnostics", raw: `{"textDocument": {"diagnostic": {}}}`, want: &DiagnosticClientCapabilities{}},
+		{
+			name: "related documents",
+			raw:  `{"textDocument": {"diagnostic": {"dynamicRegistration": true, "relatedDocumentSupport": true}}}`,
+			want: &DiagnosticClientCapabilities{DynamicRegistration: true, RelatedDocumentSupport: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var capabilities ClientCapabilities
+			if err := json.Unmarshal([]byte(tt.raw), &capabilities); err != nil {
+				t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, capabilities.TextDocument.Diagnostic); diff != "" {
+				t.Errorf("diagnostic capabilities mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDiagnostic_marshal(t *testing.T) {
+	tests := []struct {
+		name       string
+		diagnostic Diagnostic
+		want       string
+	}{
+		{
+			name:       "without code",
+			diagnostic: Diagnostic{Severity: DSWarning, Message: "m"},
+			want:       `{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":0}},"severity":2,"message":"m"}`,
+		},
+		{
+			name: "code description",
+			diagnostic: Diagnostic{
+				Severity:        DSWarning,
+				Code:            pt("SA4006"),
+				CodeDescription: &CodeDescription{Href: "https://staticcheck.dev/docs/checks/#SA4006"},
+				Message:         "m",
+			},
+			want: `{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":0}},"severity":2,"code":"SA4006",` +
+				`"codeDescription":{"href":"https://staticcheck.dev/docs/checks/#SA4006"},"message":"m"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.diagnostic)
+			if err != nil {
+				t.Fatalf("json.Marshal() returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, string(b)); diff != "" {
+				t.Errorf("diagnostic mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPublishDiagnosticsClientCapabilities_unmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want PublishDiagnosticsClientCapabilities
+	}{
+		{name: "absent", raw: `{}`},
+		{
+			name: "related information",
+			raw:  `{"textDocument": {"publishDiagnostics": {"relatedInformation": true}}}`,
+			want: PublishDiagnosticsClientCapabilities{RelatedInformation: true},
+		},
+		{
+			name: "code description",
+			raw:  `{"textDocument": {"publishDiagnostics": {"codeDescriptionSupport": true}}}`,
+			want: PublishDiagnosticsClientCapabilities{CodeDescriptionSupport: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var capabilities ClientCapabilities
+			if err := json.Unmarshal([]byte(tt.raw), &capabilities); err != nil {
+				t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, capabilities.TextDocument.PublishDiagnostics); diff != "" {
+				t.Errorf("publishDiagnostics capabilities mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -3,47 +3,254 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/sourcegraph/jsonrpc2"
 )
 
 var defaultSeverity = "Warn"
 
+// replayIdleTimeout is how long -replay waits for the server to go quiet after the last message.
+const replayIdleTimeout = 5 * time.Second
+
+const (
+	// exitStartupTimeout is the exit code used when no client initialized the server in time.
+	exitStartupTimeout = 3
+	// exitClientGone is the exit code used when the client process given in initialize exited.
+	exitClientGone = 4
+)
+
 func main() {
-	debug := flag.Bool("debug", false, "output debug log")
-	noLinterName := flag.Bool("nolintername", false, "don't show a linter name in message")
-	flag.StringVar(&defaultSeverity, "severity", defaultSeverity, "Default severity to use. Choices are: Err(or), Warn(ing), Info(rmation) or Hint")
+	name, args := subcommand(os.Args[1:], stdinIsTerminal())
+
+	switch name {
+	case "serve":
+		serve(args)
+	case "check":
+		os.Exit(runCheck(args, os.Stdout, os.Stderr))
+	case "version":
+		fs := flag.NewFlagSet("version", flag.ExitOnError)
+		// ExitOnError exits on errors.
+		_ = fs.Parse(args)
+		printVersion(os.Stdout)
+	case "help":
+		usage(os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "golangci-lint-langserver: unknown command %q\n\n", name)
+		usage(os.Stderr)
+		os.Exit(exitUsage)
+	}
+}
+
+// subcommands are the commands of the binary, see usage.
+var subcommands = []string{"serve", "check", "version", "help"}
+
+// subcommand returns the subcommand named by args and its arguments. Editors launch
+// the server with flags only, or with no argument at all, which both mean serve;
+// without arguments on a terminal, where serving would just wait for input, the help
+// is shown instead.
+func subcommand(args []string, terminal bool) (string, []string) {
+	if len(args) == 0 {
+		if terminal {
+			return "help", nil
+		}
+
+		return "serve", nil
+	}
+
+	switch {
+	case slices.Contains(subcommands, args[0]):
+		return args[0], args[1:]
+	case args[0] == "-h" || args[0] == "-help" || args[0] == "--help":
+		return "help", nil
+	case strings.HasPrefix(args[0], "-"):
+		return "serve", args
+	default:
+		return args[0], args[1:]
+	}
+}
+
+// stdinIsTerminal reports whether stdin is a terminal rather than a pipe from an editor.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// usage prints the help of the binary.
+func usage(w io.Writer) {
+	fmt.Fprint(w, `golangci-lint-langserver is a language server for golangci-lint.
+
+It is normally launched by an editor, which talks to it over stdio: see the
+configuration examples at https://github.com/nametake/golangci-lint-langserver.
+Run from a terminal without arguments, it prints this help.
+
+Usage:
+
+	golangci-lint-langserver [serve] [flags]            run the language server (the default)
+	golangci-lint-langserver check [flags] [dir] [-- command...]
+	                                                    lint dir the way the server does and print the issues
+	golangci-lint-langserver version                    print the version
+	golangci-lint-langserver help                       print this help
+
+Run "golangci-lint-langserver <command> -h" for the flags of a command.
+`)
+}
+
+// printVersion prints the version the binary was built from, as recorded by go install.
+func printVersion(w io.Writer) {
+	version := "(devel)"
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		version = info.Main.Version
+	}
+
+	fmt.Fprintf(w, "golangci-lint-langserver %s %s %s/%s\n", version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
 
-	flag.Parse()
+// serve runs the language server until the client disconnects.
+func serve(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	debug := fs.Bool("debug", false, "output debug log")
+	noLinterName := fs.Bool("nolintername", false, "don't show a linter name in message")
+	fs.StringVar(&defaultSeverity, "severity", defaultSeverity, "Default severity to use. Choices are: Err(or), Warn(ing), Info(rmation) or Hint")
+	logFile := fs.String("log-file", "", "write logs to this file instead of stderr")
+	logMaxSizeMB := fs.Int("log-max-size-mb", 50, "rotate the log file once it exceeds this size in megabytes (0 disables rotation)")
+	logMaxBackups := fs.Int("log-max-backups", 3, "number of rotated log files to keep")
+	record := fs.String("record", "", "append every JSON-RPC message exchanged with the client to this file")
+	replay := fs.String("replay", "", "replay the client messages of a recorded session and print the server messages")
+	pipe := fs.String("pipe", "", "connect to the named pipe (Windows) or unix socket created by the client at this path instead of using stdio")
+	allowedCommands := fs.String("allowed-commands", defaultAllowedCommands, "comma-separated commands the client may configure: executable basenames looked up in PATH or absolute paths, optionally followed by leading arguments like \"go tool golangci-lint\"")
+	noTrustPrompt := fs.Bool("no-trust-prompt", false, "run the configured command without asking the user to allow executables other than golangci-lint from PATH")
+	pprofAddr := fs.String("pprof-addr", "", "serve net/http/pprof profiles and metrics over HTTP at this address, such as localhost:6060")
+	concurrency := fs.Int("concurrency", defaultConcurrency, "number of directories linted at the same time; the lints of a directory run one after the other")
+	startupTimeout := fs.Duration("startup-timeout", time.Minute, "exit if no initialize request arrives within this duration (0 disables)")
 
-	level := slog.LevelInfo
+	// ExitOnError exits on errors.
+	_ = fs.Parse(args)
+
+	// The logLevel option changes the level at runtime, from the one of -debug.
+	level := new(slog.LevelVar)
 	if *debug {
-		level = slog.LevelDebug
+		level.Set(slog.LevelDebug)
+	}
+
+	var logOutput io.Writer = os.Stderr
+	if *logFile != "" {
+		f, err := newRotatingFile(*logFile, int64(*logMaxSizeMB)*1024*1024, *logMaxBackups)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to open log file: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+
+		logOutput = f
 	}
 
-	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+	logger := slog.New(slog.NewTextHandler(logOutput, &slog.HandlerOptions{
 		Level: level,
 	}))
 	slog.SetDefault(logger)
 
-	handler := NewHandler(*noLinterName)
+	handler := newLangHandler(*noLinterName)
+	handler.logLevel, handler.startupLogLevel = level, level.Level()
+	handler.allowedCommands = parseAllowedCommands(*allowedCommands)
+	handler.startupConcurrency = max(*concurrency, 1)
+	handler.concurrency.Store(int32(handler.startupConcurrency))
+
+	if !*noTrustPrompt {
+		path, err := defaultTrustStorePath()
+		if err != nil {
+			slog.Warn("golangci-lint-langserver: command decisions will not be remembered", "error", err)
+		}
+
+		handler.trust = newTrustStore(path)
+	}
+
+	if *pprofAddr != "" {
+		if err := serveDebug(*pprofAddr, handler); err != nil {
+			slog.Error("golangci-lint-langserver: failed to start the debug HTTP listener", "addr", *pprofAddr, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	if *replay != "" {
+		if err := replaySession(*replay, handler, os.Stdout, replayIdleTimeout); err != nil {
+			slog.Error("golangci-lint-langserver: replay failed", "error", err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	if *startupTimeout > 0 {
+		go handler.exitUnlessInitialized(*startupTimeout)
+	}
 
 	var connOpt []jsonrpc2.ConnOpt
 
+	var codec jsonrpc2.ObjectCodec = jsonrpc2.VSCodeObjectCodec{}
+	if *record != "" {
+		f, err := os.OpenFile(*record, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			slog.Error("golangci-lint-langserver: failed to open the session recording", "error", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+
+		codec = newRecordingCodec(codec, f)
+	}
+
+	var rwc io.ReadWriteCloser = stdrwc{}
+	if *pipe != "" {
+		// Editors like VS Code pass --pipe=<path> and own the endpoint: the server connects to it.
+		conn, err := dialPipe(*pipe)
+		if err != nil {
+			slog.Error("golangci-lint-langserver: failed to connect to the pipe", "path", *pipe, "error", err)
+			os.Exit(1)
+		}
+
+		rwc = conn
+	}
+
 	slog.Info("golangci-lint-langserver: connections opened")
 
 	<-jsonrpc2.NewConn(
 		context.Background(),
-		jsonrpc2.NewBufferedStream(stdrwc{}, jsonrpc2.VSCodeObjectCodec{}),
+		jsonrpc2.NewBufferedStream(rwc, codec),
 		handler,
 		connOpt...,
 	).DisconnectNotify()
 
+	// The running lint is cancelled before exiting, so that golangci-lint does not
+	// outlive the server.
+	handler.connectionClosed()
+	handler.stop()
+
 	slog.Info("golangci-lint-langserver: connections closed")
 }
 
+// exitUnlessInitialized exits the process if no initialize request arrives within timeout,
+// so that servers orphaned by a client crashing before speaking LSP do not pile up.
+func (h *langHandler) exitUnlessInitialized(timeout time.Duration) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-h.initialized:
+	case <-timer.C:
+		slog.Error("golangci-lint-langserver: no initialize request received, exiting", "timeout", timeout)
+		h.exitProcess(exitStartupTimeout)
+	}
+}
+
 type stdrwc struct{}
 
 func (stdrwc) Read(p []byte) (int, error) {
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mergeByRange combines the diagnostics sharing the exact range of another into a
+// single diagnostic listing their messages, with the most severe of their severities.
+// The merged diagnostics are kept in its data, where expandMerged finds them again
+// for the code actions.
+func mergeByRange(diagnostics []Diagnostic) []Diagnostic {
+	groups := make(map[Range][]Diagnostic)
+	for _, d := range diagnostics {
+		groups[d.Range] = append(groups[d.Range], d)
+	}
+
+	if len(groups) == len(diagnostics) {
+		return diagnostics
+	}
+
+	merged := make([]Diagnostic, 0, len(groups))
+	for _, d := range diagnostics {
+		group, ok := groups[d.Range]
+		if !ok {
+			continue
+		}
+		// The group is added at the position of its first diagnostic.
+		delete(groups, d.Range)
+
+		if len(group) == 1 {
+			merged = append(merged, d)
+
+			continue
+		}
+
+		merged = append(merged, mergeDiagnostics(group))
+	}
+
+	return merged
+}
+
+// mergeDiagnostics returns the diagnostic standing for group, such as
+// "2 findings: declaration of "err" shadows declaration (govet); SA4006: this value is never used (staticcheck)".
+func mergeDiagnostics(group []Diagnostic) Diagnostic {
+	merged := Diagnostic{
+		Range: group[0].Range,
+		Data:  &DiagnosticData{Merged: group},
+	}
+
+	findings := make([]string, len(group))
+	for i, d := range group {
+		findings[i] = d.Message
+		if d.Source != nil {
+			findings[i] = fmt.Sprintf("%s (%s)", strings.TrimPrefix(d.Message, *d.Source+": "), *d.Source)
+		}
+
+		// Lower values are more severe.
+		if d.Severity != 0 && (merged.Severity == 0 || d.Severity < merged.Severity) {
+			merged.Severity = d.Severity
+		}
+
+		merged.RelatedInformation = append(merged.RelatedInformation, d.RelatedInformation...)
+	}
+
+	merged.Message = fmt.Sprintf("%d findings: %s", len(group), strings.Join(findings, "; "))
+
+	return merged
+}
+
+// expandMerged replaces the diagnostics merged by mergeByRange with the
+// diagnostics they stand for.
+func expandMerged(diagnostics []Diagnostic) []Diagnostic {
+	var expanded []Diagnostic
+
+	for i, d := range diagnostics {
+		if d.Data == nil || len(d.Data.Merged) == 0 {
+			if expanded != nil {
+				expanded = append(expanded, d)
+			}
+
+			continue
+		}
+
+		if expanded == nil {
+			expanded = append(make([]Diagnostic, 0, len(diagnostics)), diagnostics[:i]...)
+		}
+		expanded = append(expanded, d.Data.Merged...)
+	}
+
+	if expanded == nil {
+		return diagnostics
+	}
+
+	return expanded
+}
+
+// mergeSameRange merges the diagnostics of a document sharing a range when
+// mergeSamePosition is enabled.
+func (h *langHandler) mergeSameRange(diagnostics []Diagnostic) []Diagnostic {
+	if !h.mergeSamePosition {
+		return diagnostics
+	}
+
+	return mergeByRange(diagnostics)
+}
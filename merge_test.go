@@ -0,0 +1,106 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeByRange(t *testing.T) {
+	at := func(line int) Range {
+		return Range{Start: Position{Line: line}, End: Position{Line: line, Character: 3}}
+	}
+
+	shadow := Diagnostic{Range: at(4), Severity: DSWarning, Source: pt("govet"), Message: "govet: shadow: declaration of \"err\" shadows declaration"}
+	unused := Diagnostic{Range: at(4), Severity: DSError, Source: pt("staticcheck"), Message: "staticcheck: SA4006: this value of err is never used"}
+	comment := Diagnostic{Range: at(4), Severity: DSHint, Source: pt("revive"), Message: "exported: comment on exported function"}
+	other := Diagnostic{Range: at(9), Severity: DSWarning, Source: pt("errcheck"), Message: "errcheck: Error return value is not checked"}
+
+	tests := []struct {
+		name        string
+		diagnostics []Diagnostic
+		want        []Diagnostic
+	}{
+		{
+			name:        "distinct ranges",
+			diagnostics: []Diagnostic{shadow, other},
+			want:        []Diagnostic{shadow, other},
+		},
+		{
+			name:        "same range",
+			diagnostics: []Diagnostic{shadow, other, unused, comment},
+			want: []Diagnostic{
+				{
+					Range:    at(4),
+					Severity: DSError,
+					Message:  "3 findings: shadow: declaration of \"err\" shadows declaration (govet); SA4006: this value of err is never used (staticcheck); exported: comment on exported function (revive)",
+					Data:     &DiagnosticData{Merged: []Diagnostic{shadow, unused, comment}},
+				},
+				other,
+			},
+		},
+		{
+			name:        "highest severity",
+			diagnostics: []Diagnostic{comment, shadow},
+			want: []Diagnostic{
+				{
+					Range:    at(4),
+					Severity: DSWarning,
+					Message:  "2 findings: exported: comment on exported function (revive); shadow: declaration of \"err\" shadows declaration (govet)",
+					Data:     &DiagnosticData{Merged: []Diagnostic{comment, shadow}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeByRange(tt.diagnostics)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeByRange() = %+v, want %+v", got, tt.want)
+			}
+
+			if expanded := expandMerged(got); len(expanded) != len(tt.diagnostics) {
+				t.Errorf("expandMerged() returned %d diagnostics, want %d", len(expanded), len(tt.diagnostics))
+			}
+		})
+	}
+}
+
+func TestLangHandler_codeActionMergedDiagnostic(t *testing.T) {
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{"capabilities": map[string]any{}}, nil)
+
+	merged := mergeByRange([]Diagnostic{
+		{Source: pt("govet"), Message: "govet: shadow: declaration of \"err\" shadows declaration"},
+		{Source: pt("staticcheck"), Message: "staticcheck: SA4006: this value of err is never used"},
+	})
+	if len(merged) != 1 {
+		t.Fatalf("mergeByRange() returned %d diagnostics, want 1", len(merged))
+	}
+
+	var actions []CodeAction
+	client.call("textDocument/codeAction", map[string]any{
+		"textDocument": map[string]any{"uri": "file:///project/main.go"},
+		"context":      map[string]any{"diagnostics": merged},
+	}, &actions)
+
+	want := map[string]bool{
+		"Re-run govet only on this file":             false,
+		"Re-run staticcheck only on this file":       false,
+		"Dismiss govet issue for this session":       false,
+		"Dismiss staticcheck issue for this session": false,
+	}
+	for _, a := range actions {
+		if _, ok := want[a.Title]; ok {
+			want[a.Title] = true
+		}
+	}
+
+	for title, found := range want {
+		if !found {
+			t.Errorf("codeAction did not return %q: %+v", title, actions)
+		}
+	}
+}
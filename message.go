@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+// defaultMaxMessageLength is the number of characters diagnostic messages are truncated to by default.
+const defaultMaxMessageLength = 1000
+
+// truncateMessage cuts message after limit characters, at a rune boundary, and tells
+// how many characters were cut. A limit of zero or less keeps the message whole.
+func truncateMessage(message string, limit int) (string, bool) {
+	if limit <= 0 || utf8.RuneCountInString(message) <= limit {
+		return message, false
+	}
+
+	cut := 0
+	for range limit {
+		_, size := utf8.DecodeRuneInString(message[cut:])
+		cut += size
+	}
+
+	rest := utf8.RuneCountInString(message[cut:])
+
+	return fmt.Sprintf("%s… (truncated, %d more chars)", message[:cut], rest), true
+}
+
+// limitMessage truncates the message of the diagnostic to maxMessageLength characters.
+// The full message is kept in the data of the diagnostic.
+func (h *langHandler) limitMessage(d *Diagnostic) {
+	message, truncated := truncateMessage(d.Message, h.maxMessageLength)
+	if !truncated {
+		return
+	}
+
+	if d.Data == nil {
+		d.Data = &DiagnosticData{}
+	}
+	d.Data.FullMessage = d.Message
+	d.Message = message
+}
+
+// messageRewrite is a compiled MessageRewrite.
+type messageRewrite struct {
+	linter   string
+	re       *regexp.Regexp
+	template string
+}
+
+// rewriteMessage applies the first of rewrites matching the issue to its text.
+func rewriteMessage(rewrites []messageRewrite, linter, text string) string {
+	for _, r := range rewrites {
+		if r.linter != "" && r.linter != linter {
+			continue
+		}
+
+		if r.re.MatchString(text) {
+			return r.re.ReplaceAllString(text, r.template)
+		}
+	}
+
+	return text
+}
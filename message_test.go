@@ -0,0 +1,139 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestTruncateMessage tests that messages are cut at rune boundaries.
+func TestTruncateMessage(t *testing.T) {
+	tests := []struct {
+		name          string
+		message       string
+		limit         int
+		want          string
+		wantTruncated bool
+	}{
+		{
+			name:    "short message",
+			message: "hugeParam: cfg is heavy (80 bytes)",
+			limit:   100,
+			want:    "hugeParam: cfg is heavy (80 bytes)",
+		},
+		{
+			name:    "exactly the limit",
+			message: "abcdef",
+			limit:   6,
+			want:    "abcdef",
+		},
+		{
+			name:          "ascii",
+			message:       "abcdefghij",
+			limit:         4,
+			want:          "abcd… (truncated, 6 more chars)",
+			wantTruncated: true,
+		},
+		{
+			name:          "multibyte rune at the cut point",
+			message:       "ab日本語cd",
+			limit:         3,
+			want:          "ab日… (truncated, 4 more chars)",
+			wantTruncated: true,
+		},
+		{
+			name:          "four byte runes",
+			message:       "😀😀😀😀",
+			limit:         1,
+			want:          "😀… (truncated, 3 more chars)",
+			wantTruncated: true,
+		},
+		{
+			name:    "no limit",
+			message: strings.Repeat("x", 5000),
+			limit:   0,
+			want:    strings.Repeat("x", 5000),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := truncateMessage(tt.message, tt.limit)
+			if got != tt.want || truncated != tt.wantTruncated {
+				t.Errorf("truncateMessage() = %q, %v, want %q, %v", got, truncated, tt.want, tt.wantTruncated)
+			}
+
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateMessage() returned invalid UTF-8: %q", got)
+			}
+		})
+	}
+}
+
+// TestLangHandler_limitMessage tests that the full message is kept in the diagnostic data.
+func TestLangHandler_limitMessage(t *testing.T) {
+	h := &langHandler{maxMessageLength: 5}
+
+	d := Diagnostic{Message: "typecheck: cannot infer T"}
+	h.limitMessage(&d)
+
+	if d.Message != "typec… (truncated, 20 more chars)" {
+		t.Errorf("Message = %q", d.Message)
+	}
+
+	if d.Data == nil || d.Data.FullMessage != "typecheck: cannot infer T" {
+		t.Errorf("Data = %+v, want the full message", d.Data)
+	}
+}
+
+// TestRewriteMessage tests that the first matching rule rewrites the issue text.
+func TestRewriteMessage(t *testing.T) {
+	rewrites, err := compileMessageRewrites([]MessageRewrite{
+		{Linter: "gosec", MatchRegex: `^(G\d+) \(CWE-(\d+)\): (.*)$`, ReplaceTemplate: "$3 [$1, CWE-$2] → see the error handling guide"},
+		{MatchRegex: `^(?P<name>\w+) is unused$`, ReplaceTemplate: "remove ${name}, it is unused"},
+		{MatchRegex: `unused`, ReplaceTemplate: "never reached"},
+	})
+	if err != nil {
+		t.Fatalf("compileMessageRewrites() returned unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		linter string
+		text   string
+		want   string
+	}{
+		{
+			name:   "capture groups",
+			linter: "gosec",
+			text:   "G104 (CWE-703): Errors unhandled",
+			want:   "Errors unhandled [G104, CWE-703] → see the error handling guide",
+		},
+		{
+			name:   "other linter",
+			linter: "errcheck",
+			text:   "G104 (CWE-703): Errors unhandled",
+			want:   "G104 (CWE-703): Errors unhandled",
+		},
+		{
+			name:   "named group and first match wins",
+			linter: "unused",
+			text:   "helper is unused",
+			want:   "remove helper, it is unused",
+		},
+		{
+			name:   "no match",
+			linter: "revive",
+			text:   "exported function Foo should have comment or be unexported",
+			want:   "exported function Foo should have comment or be unexported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rewriteMessage(rewrites, tt.linter, tt.text); got != tt.want {
+				t.Errorf("rewriteMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
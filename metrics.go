@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// metricsPrefix prefixes the names of the metrics in the Prometheus text format.
+const metricsPrefix = "golangci_lint_langserver_"
+
+var (
+	// runDurationBuckets are the upper bounds, in seconds, of the run duration histogram.
+	runDurationBuckets = []float64{0.25, 0.5, 1, 2, 5, 10, 30, 60, 120}
+	// issueCountBuckets are the upper bounds of the histogram of issues per successful run.
+	issueCountBuckets = []float64{0, 1, 5, 10, 25, 50, 100, 500}
+)
+
+// lintMetrics counts the lint runs of documents and the use of the result cache.
+// A nil *lintMetrics records nothing.
+type lintMetrics struct {
+	started   atomic.Int64
+	succeeded atomic.Int64
+	failed    atomic.Int64
+	cancelled atomic.Int64
+
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
+
+	runDuration *histogram
+	issueCount  *histogram
+}
+
+func newLintMetrics() *lintMetrics {
+	return &lintMetrics{
+		runDuration: newHistogram(runDurationBuckets),
+		issueCount:  newHistogram(issueCountBuckets),
+	}
+}
+
+func (m *lintMetrics) runStarted() {
+	if m == nil {
+		return
+	}
+
+	m.started.Add(1)
+}
+
+// runFinished records the outcome of a run: cancelled when ctx was, failed when it
+// returned an error or a single error diagnostic for a failed golangci-lint run.
+func (m *lintMetrics) runFinished(ctx context.Context, diagnostics []Diagnostic, err error, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+
+	if ctx.Err() != nil {
+		m.cancelled.Add(1)
+
+		return
+	}
+
+	m.runDuration.observe(elapsed.Seconds())
+
+	if _, failed := failureSignature(diagnostics); err != nil || failed {
+		m.failed.Add(1)
+
+		return
+	}
+
+	m.succeeded.Add(1)
+	m.issueCount.observe(float64(len(diagnostics)))
+}
+
+// cacheLookup records whether a cached result could be served instead of a lint.
+func (m *lintMetrics) cacheLookup(hit bool) {
+	if m == nil {
+		return
+	}
+
+	if hit {
+		m.cacheHits.Add(1)
+	} else {
+		m.cacheMisses.Add(1)
+	}
+}
+
+// histogram counts observations in buckets of increasing upper bounds.
+type histogram struct {
+	bounds []float64
+
+	mu sync.Mutex
+	// counts holds the observations of each bucket, not cumulative, and of +Inf last.
+	counts []int64
+	sum    float64
+	count  int64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]int64, len(bounds)+1)}
+}
+
+func (h *histogram) observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := 0
+	for i < len(h.bounds) && v > h.bounds[i] {
+		i++
+	}
+
+	h.counts[i]++
+	h.sum += v
+	h.count++
+}
+
+// histogramSnapshot is the expvar form of a histogram. Buckets are cumulative and keyed
+// by their upper bound, like the Prometheus le label.
+type histogramSnapshot struct {
+	Buckets map[string]int64 `json:"buckets"`
+	Sum     float64          `json:"sum"`
+	Count   int64            `json:"count"`
+}
+
+func (h *histogram) snapshot() histogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := histogramSnapshot{Buckets: make(map[string]int64, len(h.counts)), Sum: h.sum, Count: h.count}
+
+	var cumulative int64
+	for i, n := range h.counts {
+		cumulative += n
+		s.Buckets[bucketLabel(h.bounds, i)] = cumulative
+	}
+
+	return s
+}
+
+func bucketLabel(bounds []float64, i int) string {
+	if i == len(bounds) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(bounds[i], 'g', -1, 64)
+}
+
+// MetricsSnapshot is the expvar form of the metrics of the server.
+type MetricsSnapshot struct {
+	RunsStarted   int64             `json:"runsStarted"`
+	RunsSucceeded int64             `json:"runsSucceeded"`
+	RunsFailed    int64             `json:"runsFailed"`
+	RunsCancelled int64             `json:"runsCancelled"`
+	RunDuration   histogramSnapshot `json:"runDurationSeconds"`
+	IssueCount    histogramSnapshot `json:"issueCount"`
+	QueueDepth    int               `json:"queueDepth"`
+	CacheHits     int64             `json:"cacheHits"`
+	CacheMisses   int64             `json:"cacheMisses"`
+}
+
+// metricsSnapshot returns the current metrics, along with the number of queued lints.
+func (h *langHandler) metricsSnapshot() MetricsSnapshot {
+	h.mu.Lock()
+	depth := len(h.request)
+	h.mu.Unlock()
+
+	m := h.metrics
+
+	return MetricsSnapshot{
+		RunsStarted:   m.started.Load(),
+		RunsSucceeded: m.succeeded.Load(),
+		RunsFailed:    m.failed.Load(),
+		RunsCancelled: m.cancelled.Load(),
+		RunDuration:   m.runDuration.snapshot(),
+		IssueCount:    m.issueCount.snapshot(),
+		QueueDepth:    depth,
+		CacheHits:     m.cacheHits.Load(),
+		CacheMisses:   m.cacheMisses.Load(),
+	}
+}
+
+// writePrometheus writes the metrics in the Prometheus text exposition format.
+func (h *langHandler) writePrometheus(w io.Writer) {
+	m := h.metrics
+	s := h.metricsSnapshot()
+
+	counter := func(name, help string, value int64) {
+		fmt.Fprintf(w, "# HELP %s%s %s\n# TYPE %s%s counter\n%s%s %d\n", metricsPrefix, name, help, metricsPrefix, name, metricsPrefix, name, value)
+	}
+
+	counter("lint_runs_started_total", "Lint runs of documents started.", s.RunsStarted)
+	fmt.Fprintf(w, "# HELP %slint_runs_total Lint runs of documents finished, by result.\n# TYPE %slint_runs_total counter\n", metricsPrefix, metricsPrefix)
+	for _, r := range []struct {
+		result string
+		value  int64
+	}{{"succeeded", s.RunsSucceeded}, {"failed", s.RunsFailed}, {"cancelled", s.RunsCancelled}} {
+		fmt.Fprintf(w, "%slint_runs_total{result=%q} %d\n", metricsPrefix, r.result, r.value)
+	}
+
+	writeHistogram(w, "lint_run_duration_seconds", "Duration of the lint runs that were not cancelled.", m.runDuration)
+	writeHistogram(w, "lint_issues", "Diagnostics of the successful lint runs.", m.issueCount)
+
+	fmt.Fprintf(w, "# HELP %slint_queue_depth Lints waiting for the linter.\n# TYPE %slint_queue_depth gauge\n%slint_queue_depth %d\n", metricsPrefix, metricsPrefix, metricsPrefix, s.QueueDepth)
+
+	counter("cache_hits_total", "Cached results served without linting.", s.CacheHits)
+	counter("cache_misses_total", "Results that were not cached or out of date.", s.CacheMisses)
+}
+
+func writeHistogram(w io.Writer, name, help string, h *histogram) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s%s %s\n# TYPE %s%s histogram\n", metricsPrefix, name, help, metricsPrefix, name)
+
+	var cumulative int64
+	for i, n := range h.counts {
+		cumulative += n
+		fmt.Fprintf(w, "%s%s_bucket{le=%q} %d\n", metricsPrefix, name, bucketLabel(h.bounds, i), cumulative)
+	}
+
+	fmt.Fprintf(w, "%s%s_sum %s\n%s%s_count %d\n", metricsPrefix, name, strconv.FormatFloat(h.sum, 'g', -1, 64), metricsPrefix, name, h.count)
+}
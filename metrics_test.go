@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHistogram_observe(t *testing.T) {
+	h := newHistogram([]float64{1, 5})
+	for _, v := range []float64{0, 1, 3, 10} {
+		h.observe(v)
+	}
+
+	want := histogramSnapshot{Buckets: map[string]int64{"1": 2, "5": 3, "+Inf": 4}, Sum: 14, Count: 4}
+	if diff := cmp.Diff(want, h.snapshot()); diff != "" {
+		t.Errorf("snapshot() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestLangHandler_lintMetrics tests that the run counters move when a run completes,
+// fails or is cancelled.
+func TestLangHandler_lintMetrics(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	issue := Issue{FromLinter: "revive", Text: "exported function should have comment"}
+	issue.Pos.Filename, issue.Pos.Line, issue.Pos.Column = "main.go", 1, 1
+
+	tests := []struct {
+		name       string
+		command    []string
+		cancel     bool
+		want       MetricsSnapshot
+		wantIssues int64
+	}{
+		{
+			name:       "succeeded",
+			command:    fakeLinter(t, GolangCILintResult{Issues: []Issue{issue}}),
+			want:       MetricsSnapshot{RunsStarted: 1, RunsSucceeded: 1},
+			wantIssues: 1,
+		},
+		{
+			name:    "failed",
+			command: []string{"sh", "-c", "echo broken >&2; exit 3"},
+			want:    MetricsSnapshot{RunsStarted: 1, RunsFailed: 1},
+		},
+		{
+			name:    "cancelled",
+			command: fakeLinter(t, GolangCILintResult{}),
+			cancel:  true,
+			want:    MetricsSnapshot{RunsStarted: 1, RunsCancelled: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &langHandler{rootDir: root, command: tt.command, metrics: newLintMetrics()}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			_, _ = h.lint(ctx, pathToURI(path))
+
+			got := h.metricsSnapshot()
+			if got.IssueCount.Count != tt.wantIssues || (got.RunDuration.Count == 0) != tt.cancel {
+				t.Errorf("histograms = %+v, %+v, want %d successful runs and durations unless cancelled", got.IssueCount, got.RunDuration, tt.wantIssues)
+			}
+
+			got.RunDuration, got.IssueCount = histogramSnapshot{}, histogramSnapshot{}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("metricsSnapshot() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDebugMux(t *testing.T) {
+	h := newLangHandler(false)
+	t.Cleanup(h.stop)
+
+	h.metrics.runStarted()
+	h.metrics.cacheLookup(true)
+
+	server := httptest.NewServer(debugMux(h))
+	t.Cleanup(server.Close)
+
+	get := func(path string) string {
+		t.Helper()
+
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s returned unexpected error: %v", path, err)
+		}
+		defer resp.Body.Close()
+
+		b, err := io.ReadAll(resp.Body)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s = %d, %v", path, resp.StatusCode, err)
+		}
+
+		return string(b)
+	}
+
+	metrics := get("/metrics")
+	for _, want := range []string{
+		"golangci_lint_langserver_lint_runs_started_total 1\n",
+		"golangci_lint_langserver_lint_runs_total{result=\"succeeded\"} 0\n",
+		"golangci_lint_langserver_lint_run_duration_seconds_bucket{le=\"+Inf\"} 0\n",
+		"golangci_lint_langserver_lint_queue_depth 0\n",
+		"golangci_lint_langserver_cache_hits_total 1\n",
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("/metrics does not contain %q:\n%s", want, metrics)
+		}
+	}
+
+	get("/debug/pprof/")
+}
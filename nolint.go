@@ -0,0 +1,128 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// unusedNolintRe matches the nolintlint issue of an unused directive, capturing the
+// directive and, when only one of its linters is unused, that linter.
+var unusedNolintRe = regexp.MustCompile("^directive `(//\\s*nolint[^`]*)` is unused(?: for linter \"([\\w-]+)\")?")
+
+// nolintListRe matches the linter list of a //nolint directive.
+var nolintListRe = regexp.MustCompile(`^//\s*nolint:([\w-]+(?:\s*,\s*[\w-]+)*)`)
+
+// unusedNolint returns the unused directive reported by a nolintlint issue.
+func unusedNolint(issue *Issue) *UnusedNolint {
+	if issue.FromLinter != "nolintlint" {
+		return nil
+	}
+
+	m := unusedNolintRe.FindStringSubmatch(issue.Text)
+	if m == nil {
+		return nil
+	}
+
+	return &UnusedNolint{Directive: m[1], Linter: m[2]}
+}
+
+// nolintEdit returns the edit removing an unused directive from the 0-based line of
+// text: the linter alone when the directive lists others, otherwise the whole comment,
+// along with its line when nothing else is on it. It fails when the line no longer
+// holds the directive.
+func nolintEdit(text string, line int, unused *UnusedNolint) (TextEdit, bool) {
+	lines := strings.SplitAfter(text, "\n")
+	if line < 0 || line >= len(lines) {
+		return TextEdit{}, false
+	}
+
+	content := strings.TrimRight(lines[line], "\r\n")
+
+	start := strings.Index(content, unused.Directive)
+	if start < 0 {
+		return TextEdit{}, false
+	}
+	comment := content[start:]
+
+	if unused.Linter != "" {
+		if m := nolintListRe.FindStringSubmatchIndex(comment); m != nil {
+			linters := strings.Split(comment[m[2]:m[3]], ",")
+			for i := range linters {
+				linters[i] = strings.TrimSpace(linters[i])
+			}
+			if i := slices.Index(linters, unused.Linter); i >= 0 && len(linters) > 1 {
+				listStart, listEnd := start+m[2], start+m[3]
+
+				return TextEdit{
+					Range: Range{
+						Start: Position{Line: line, Character: utf16Len(content[:listStart])},
+						End:   Position{Line: line, Character: utf16Len(content[:listEnd])},
+					},
+					NewText: strings.Join(slices.Delete(linters, i, i+1), ","),
+				}, true
+			}
+		}
+	}
+
+	code := strings.TrimRight(content[:start], " \t")
+	if code == "" {
+		// The comment stands alone: its line goes with it.
+		return TextEdit{
+			Range:   Range{Start: Position{Line: line}, End: Position{Line: line + 1}},
+			NewText: "",
+		}, true
+	}
+
+	// A trailing comment goes with the blanks separating it from the code.
+	return TextEdit{
+		Range: Range{
+			Start: Position{Line: line, Character: utf16Len(code)},
+			End:   Position{Line: line, Character: utf16Len(content)},
+		},
+		NewText: "",
+	}, true
+}
+
+// nolintActions returns a quick fix removing the directive of each unused-directive
+// diagnostic, validated against the current content of the document.
+func (h *langHandler) nolintActions(uri DocumentURI, diagnostics []Diagnostic, only []string) []CodeAction {
+	if !codeActionKindRequested(only, "quickfix") {
+		return nil
+	}
+
+	var text string
+	var actions []CodeAction
+
+	for _, d := range diagnostics {
+		if d.Data == nil || d.Data.UnusedNolint == nil {
+			continue
+		}
+
+		if text == "" {
+			var ok bool
+			if text, ok = h.documentText(uri); !ok {
+				return nil
+			}
+		}
+
+		edit, ok := nolintEdit(text, d.Range.Start.Line, d.Data.UnusedNolint)
+		if !ok {
+			continue
+		}
+
+		title := "Remove unused " + d.Data.UnusedNolint.Directive
+		if edit.NewText != "" {
+			title = "Remove " + d.Data.UnusedNolint.Linter + " from " + d.Data.UnusedNolint.Directive
+		}
+
+		actions = append(actions, CodeAction{
+			Title:       title,
+			Kind:        "quickfix",
+			Diagnostics: []Diagnostic{d},
+			Edit:        &WorkspaceEdit{Changes: map[DocumentURI][]TextEdit{uri: {edit}}},
+		})
+	}
+
+	return actions
+}
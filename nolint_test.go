@@ -0,0 +1,173 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnusedNolint(t *testing.T) {
+	tests := []struct {
+		name  string
+		issue Issue
+		want  *UnusedNolint
+	}{
+		{
+			name:  "directive",
+			issue: Issue{FromLinter: "nolintlint", Text: "directive `//nolint:errcheck // ok` is unused"},
+			want:  &UnusedNolint{Directive: "//nolint:errcheck // ok"},
+		},
+		{
+			name:  "linter",
+			issue: Issue{FromLinter: "nolintlint", Text: "directive `//nolint:errcheck,unused` is unused for linter \"unused\""},
+			want:  &UnusedNolint{Directive: "//nolint:errcheck,unused", Linter: "unused"},
+		},
+		{
+			name:  "other nolintlint issue",
+			issue: Issue{FromLinter: "nolintlint", Text: "directive `//nolint` should mention specific linter such as `//nolint:my-linter`"},
+		},
+		{
+			name:  "other linter",
+			issue: Issue{FromLinter: "revive", Text: "directive `//nolint` is unused"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, unusedNolint(&tt.issue)); diff != "" {
+				t.Errorf("unusedNolint() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNolintEdit(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		line   int
+		unused UnusedNolint
+		want   TextEdit
+		wantOK bool
+	}{
+		{
+			name:   "standalone comment",
+			text:   "package main\n\n\t//nolint:errcheck // cleanup\n\tf()\n",
+			line:   2,
+			unused: UnusedNolint{Directive: "//nolint:errcheck // cleanup"},
+			want:   TextEdit{Range: Range{Start: Position{Line: 2}, End: Position{Line: 3}}},
+			wantOK: true,
+		},
+		{
+			name:   "inline trailing comment",
+			text:   "package main\n\n\tf() //nolint:errcheck\n",
+			line:   2,
+			unused: UnusedNolint{Directive: "//nolint:errcheck"},
+			want:   TextEdit{Range: Range{Start: Position{Line: 2, Character: 4}, End: Position{Line: 2, Character: 22}}},
+			wantOK: true,
+		},
+		{
+			name:   "bare directive",
+			text:   "\tf()\t//nolint\r\n",
+			unused: UnusedNolint{Directive: "//nolint"},
+			want:   TextEdit{Range: Range{Start: Position{Character: 4}, End: Position{Character: 13}}},
+			wantOK: true,
+		},
+		{
+			name:   "multi-linter list",
+			text:   "\tf() //nolint:errcheck,unused,gosec // why\n",
+			unused: UnusedNolint{Directive: "//nolint:errcheck,unused,gosec // why", Linter: "unused"},
+			want:   TextEdit{Range: Range{Start: Position{Character: 14}, End: Position{Character: 35}}, NewText: "errcheck,gosec"},
+			wantOK: true,
+		},
+		{
+			name:   "multi-linter list with spaces",
+			text:   "\tf() //nolint:errcheck, unused\n",
+			unused: UnusedNolint{Directive: "//nolint:errcheck, unused", Linter: "errcheck"},
+			want:   TextEdit{Range: Range{Start: Position{Character: 14}, End: Position{Character: 30}}, NewText: "unused"},
+			wantOK: true,
+		},
+		{
+			name:   "last linter of the list",
+			text:   "\tf() //nolint:errcheck\n",
+			unused: UnusedNolint{Directive: "//nolint:errcheck", Linter: "errcheck"},
+			want:   TextEdit{Range: Range{Start: Position{Character: 4}, End: Position{Character: 22}}},
+			wantOK: true,
+		},
+		{
+			name:   "directive no longer there",
+			text:   "\tf() //nolint:gosec\n",
+			unused: UnusedNolint{Directive: "//nolint:errcheck"},
+		},
+		{
+			name:   "line past the end",
+			text:   "package main\n",
+			line:   3,
+			unused: UnusedNolint{Directive: "//nolint"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := nolintEdit(tt.text, tt.line, &tt.unused)
+			if ok != tt.wantOK {
+				t.Fatalf("nolintEdit() ok = %v, want %v", ok, tt.wantOK)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("nolintEdit() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLangHandler_nolintActions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\nfunc f() {\n\tg() //nolint:errcheck,unused\n}\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	uri := pathToURI(path)
+	d := Diagnostic{
+		Range:   Range{Start: Position{Line: 3, Character: 5}, End: Position{Line: 3, Character: 5}},
+		Source:  pt("nolintlint"),
+		Message: "nolintlint: directive `//nolint:errcheck,unused` is unused for linter \"unused\"",
+		Data:    &DiagnosticData{UnusedNolint: &UnusedNolint{Directive: "//nolint:errcheck,unused", Linter: "unused"}},
+	}
+	stale := d
+	stale.Range = Range{Start: Position{Line: 1}, End: Position{Line: 1}}
+	other := Diagnostic{Source: pt("errcheck"), Message: "errcheck: Error return value is not checked"}
+
+	h := &langHandler{}
+
+	tests := []struct {
+		name       string
+		context    []Diagnostic
+		only       []string
+		wantTitles []string
+	}{
+		{name: "quick fix", context: []Diagnostic{d, other}, wantTitles: []string{"Remove unused from //nolint:errcheck,unused"}},
+		{name: "stale diagnostic", context: []Diagnostic{stale}},
+		{name: "other kinds", context: []Diagnostic{d}, only: []string{"source.fixAll"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var titles []string
+			for _, a := range h.nolintActions(uri, tt.context, tt.only) {
+				titles = append(titles, a.Title)
+
+				want := []TextEdit{{Range: Range{Start: Position{Line: 3, Character: 14}, End: Position{Line: 3, Character: 29}}, NewText: "errcheck"}}
+				if diff := cmp.Diff(want, a.Edit.Changes[uri]); diff != "" {
+					t.Errorf("%s edits mismatch (-want +got):\n%s", a.Title, diff)
+				}
+			}
+
+			if diff := cmp.Diff(tt.wantTitles, titles); diff != "" {
+				t.Errorf("nolintActions() titles mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// parseInitializationOptions decodes the initializationOptions of the initialize request.
+// Each option is decoded on its own so that one misspelled key or wrong type does not
+// discard the others; the problems found are returned as human readable messages.
+func parseInitializationOptions(raw json.RawMessage) (InitializationOptions, []string) {
+	var opts InitializationOptions
+
+	if len(raw) == 0 || string(raw) == "null" {
+		return opts, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return opts, []string{"initializationOptions must be an object"}
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var problems []string
+	for _, key := range keys {
+		b, err := json.Marshal(map[string]json.RawMessage{key: fields[key]})
+		if err != nil {
+			return opts, []string{err.Error()}
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+
+		decoded := opts
+		if err := dec.Decode(&decoded); err != nil {
+			problems = append(problems, optionProblem(key, err))
+
+			continue
+		}
+
+		opts = decoded
+	}
+
+	return opts, problems
+}
+
+func optionProblem(key string, err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return fmt.Sprintf("%q must be %s, got %s", key, jsonTypeName(typeErr.Type), typeErr.Value)
+	}
+
+	if strings.HasPrefix(err.Error(), "json: unknown field") {
+		return fmt.Sprintf("unknown option %q", key)
+	}
+
+	return fmt.Sprintf("%q: %v", key, err)
+}
+
+// jsonTypeName describes a Go type in JSON terms.
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonTypeName(t.Elem())
+	case reflect.Slice, reflect.Array:
+		return "an array of " + strings.TrimPrefix(strings.TrimPrefix(jsonTypeName(t.Elem()), "a "), "an ") + "s"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	default:
+		return "a number"
+	}
+}
+
+// initializationOptionNames returns the JSON names of every known option.
+func initializationOptionNames() []string {
+	t := reflect.TypeFor[InitializationOptions]()
+
+	names := make([]string, 0, t.NumField())
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// initializationOptionsMessage formats the problems for window/showMessage.
+func initializationOptionsMessage(problems []string) string {
+	return fmt.Sprintf("golangci-lint-langserver: invalid initializationOptions: %s (valid options: %s)",
+		strings.Join(problems, "; "), strings.Join(initializationOptionNames(), ", "))
+}
+
+// rejectedSettingsMessage formats the problems of settings changed after initialize,
+// which are not applied, for window/showMessage.
+func rejectedSettingsMessage(problems []string) string {
+	return fmt.Sprintf("golangci-lint-langserver: invalid settings, keeping the previous ones: %s (valid options: %s)",
+		strings.Join(problems, "; "), strings.Join(initializationOptionNames(), ", "))
+}
+
+// isOptions reports whether every field of the settings is an option, as when a client
+// sends the options themselves rather than under configurationSection.
+func isOptions(settings map[string]json.RawMessage) bool {
+	if len(settings) == 0 {
+		return false
+	}
+
+	names := initializationOptionNames()
+	for key := range settings {
+		if !slices.Contains(names, key) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateOptions returns the problems applyOptions would report for the options,
+// without applying them.
+func (h *langHandler) validateOptions(opts InitializationOptions) []string {
+	var problems []string
+
+	if _, err := compileExcludeMessages(opts.ExcludeMessages); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if _, err := compileMessageRewrites(opts.MessageRewrites); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if err := h.checkAllowedCommand(opts.Command); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if _, ok := parseSeverity(opts.MinimumSeverity); opts.MinimumSeverity != "" && !ok {
+		problems = append(problems, fmt.Sprintf("%q must be one of error, warning, info or hint, got %q", "minimumSeverity", opts.MinimumSeverity))
+	}
+
+	if _, ok := parseLogLevel(opts.LogLevel); opts.LogLevel != "" && !ok {
+		problems = append(problems, fmt.Sprintf("%q must be one of debug, info, warn or error, got %q", "logLevel", opts.LogLevel))
+	}
+
+	if opts.Concurrency != nil && *opts.Concurrency < 1 {
+		problems = append(problems, fmt.Sprintf("%q must be at least 1, got %d", "concurrency", *opts.Concurrency))
+	}
+
+	if _, err := compileStderrPattern(opts.StderrPattern); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if _, err := compilePathSeverities(opts.PathSeverities); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if _, err := compileLinterSeverities(opts.Severities); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if _, err := compileSubprojects(opts.Subprojects); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	return problems
+}
+
+// compileExcludeMessages compiles the excludeMessages option. All invalid patterns are
+// reported in the returned error.
+func compileExcludeMessages(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+
+	var invalid []string
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%q", p))
+
+			continue
+		}
+
+		compiled = append(compiled, re)
+	}
+
+	if len(invalid) > 0 {
+		return compiled, fmt.Errorf("\"excludeMessages\" has invalid regular expressions: %s", strings.Join(invalid, ", "))
+	}
+
+	return compiled, nil
+}
+
+// compileMessageRewrites compiles the messageRewrites option. All invalid patterns are
+// reported in the returned error.
+func compileMessageRewrites(rules []MessageRewrite) ([]messageRewrite, error) {
+	compiled := make([]messageRewrite, 0, len(rules))
+
+	var invalid []string
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.MatchRegex)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%q", rule.MatchRegex))
+
+			continue
+		}
+
+		compiled = append(compiled, messageRewrite{linter: rule.Linter, re: re, template: rule.ReplaceTemplate})
+	}
+
+	if len(invalid) > 0 {
+		return compiled, fmt.Errorf("\"messageRewrites\" has invalid regular expressions: %s", strings.Join(invalid, ", "))
+	}
+
+	return compiled, nil
+}
+
+// parseLogLevel returns the level named by the logLevel option.
+func parseLogLevel(name string) (slog.Level, bool) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, true
+	case "info":
+		return slog.LevelInfo, true
+	case "warn", "warning":
+		return slog.LevelWarn, true
+	case "error":
+		return slog.LevelError, true
+	default:
+		return 0, false
+	}
+}
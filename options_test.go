@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseInitializationOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		raw          string
+		want         InitializationOptions
+		wantProblems []string
+	}{
+		{
+			name: "absent",
+			raw:  "",
+		},
+		{
+			name: "valid options",
+			raw:  `{"command": ["golangci-lint", "run"], "lintTarget": "file"}`,
+			want: InitializationOptions{
+				Command:    []string{"golangci-lint", "run"},
+				LintTarget: "file",
+			},
+		},
+		{
+			name: "misspelled key",
+			raw:  `{"comand": ["golangci-lint", "run"], "lintTarget": "file"}`,
+			want: InitializationOptions{
+				LintTarget: "file",
+			},
+			wantProblems: []string{`unknown option "comand"`},
+		},
+		{
+			name: "string where array expected",
+			raw:  `{"command": "golangci-lint run", "onlyTouchedLines": true}`,
+			want: InitializationOptions{
+				OnlyTouchedLines: true,
+			},
+			wantProblems: []string{`"command" must be an array of strings, got string`},
+		},
+		{
+			name: "several problems",
+			raw:  `{"severety": "error", "retryOnTimeout": "no", "command": ["golangci-lint"]}`,
+			want: InitializationOptions{
+				Command: []string{"golangci-lint"},
+			},
+			wantProblems: []string{
+				`"retryOnTimeout" must be a boolean, got string`,
+				`unknown option "severety"`,
+			},
+		},
+		{
+			name:         "not an object",
+			raw:          `["golangci-lint"]`,
+			wantProblems: []string{"initializationOptions must be an object"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, problems := parseInitializationOptions(json.RawMessage(tt.raw))
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("parseInitializationOptions() options mismatch (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tt.wantProblems, problems); diff != "" {
+				t.Errorf("parseInitializationOptions() problems mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestCompileExcludeMessages tests that every invalid pattern is reported.
+func TestCompileExcludeMessages(t *testing.T) {
+	compiled, err := compileExcludeMessages([]string{"should have comment", "(unclosed", "[z-a]"})
+	if len(compiled) != 1 {
+		t.Errorf("compileExcludeMessages() compiled %d patterns, want 1", len(compiled))
+	}
+
+	want := `"excludeMessages" has invalid regular expressions: "(unclosed", "[z-a]"`
+	if err == nil || err.Error() != want {
+		t.Errorf("compileExcludeMessages() error = %v, want %q", err, want)
+	}
+}
+
+// TestCompileMessageRewrites tests that every invalid pattern is reported.
+func TestCompileMessageRewrites(t *testing.T) {
+	compiled, err := compileMessageRewrites([]MessageRewrite{
+		{MatchRegex: "(unclosed", ReplaceTemplate: "x"},
+		{Linter: "gosec", MatchRegex: "^G104", ReplaceTemplate: "y"},
+	})
+	if len(compiled) != 1 {
+		t.Errorf("compileMessageRewrites() compiled %d rules, want 1", len(compiled))
+	}
+
+	want := `"messageRewrites" has invalid regular expressions: "(unclosed"`
+	if err == nil || err.Error() != want {
+		t.Errorf("compileMessageRewrites() error = %v, want %q", err, want)
+	}
+}
+
+// TestLangHandler_validateOptions tests that the invalid patterns, globs and severities of
+// the options are reported before the settings apply.
+func TestLangHandler_validateOptions(t *testing.T) {
+	invalidPattern := "(unclosed"
+
+	tests := []struct {
+		name string
+		opts InitializationOptions
+		want []string
+	}{
+		{
+			name: "valid",
+			opts: InitializationOptions{
+				StderrPattern:  pt("level=(warn|error)"),
+				PathSeverities: []PathSeverity{{Glob: "internal/**", Severity: "hint"}},
+				Severities:     map[string]string{"errcheck": "error"},
+				Subprojects:    []string{"services/*"},
+			},
+		},
+		{
+			name: "stderrPattern",
+			opts: InitializationOptions{StderrPattern: &invalidPattern},
+			want: []string{`"stderrPattern" is an invalid regular expression: "(unclosed"`},
+		},
+		{
+			name: "pathSeverities",
+			opts: InitializationOptions{PathSeverities: []PathSeverity{
+				{Glob: "a**", Severity: "hint"},
+				{Glob: "internal/**", Severity: "severe"},
+				{Glob: "cmd/**", Severity: "info"},
+			}},
+			want: []string{`"pathSeverities" has invalid entries: invalid glob "a**": ** must be a whole path segment, "internal/**": unknown severity "severe"`},
+		},
+		{
+			name: "severities",
+			opts: InitializationOptions{Severities: map[string]string{"gosec": "severe", "errcheck": "error", "dupl": "low"}},
+			want: []string{`"severities" has unknown severities: dupl: "low", gosec: "severe"`},
+		},
+		{
+			name: "subprojects",
+			opts: InitializationOptions{Subprojects: []string{"services/*", "tools/[a"}},
+			want: []string{`"subprojects" has invalid entries: invalid glob "tools/[a": unterminated character class`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newLangHandler(false)
+
+			if diff := cmp.Diff(tt.want, h.validateOptions(tt.opts)); diff != "" {
+				t.Errorf("validateOptions() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// outputFormatAdvice tells how to configure the command for the JSON output the server needs.
+const outputFormatAdvice = "use `--output.json.path stdout` with golangci-lint v2 or `--out-format json` with v1"
+
+// outputPathFlag recognizes the v2 --output.<format>.path flag in arg, with the flag prefix
+// already removed. rest are the arguments following arg, for the separate-arg form.
+func outputPathFlag(arg string, rest []string) (format, path string, ok bool) {
+	name, value, hasValue := strings.Cut(arg, "=")
+
+	format, ok = strings.CutPrefix(name, "output.")
+	if !ok {
+		return "", "", false
+	}
+
+	format, ok = strings.CutSuffix(format, ".path")
+	if !ok {
+		return "", "", false
+	}
+
+	if hasValue {
+		return format, value, true
+	}
+
+	if len(rest) == 0 {
+		return "", "", false
+	}
+
+	return format, rest[0], true
+}
+
+// hasStdoutFormat reports whether the output format value writes the format to stdout.
+// v1 accepts a comma separated list of format[:path] entries.
+func hasStdoutFormat(outFormat, want string) bool {
+	for _, entry := range strings.Split(outFormat, ",") {
+		format, path, _ := strings.Cut(entry, ":")
+		if format == want && (path == "" || path == "stdout") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// jsonOutputCommand returns a copy of command writing JSON to stdout in place of the
+// output format it configures, and whether anything was replaced. Only the output
+// flags are touched, so the rest of the command behaves as configured. SARIF, which
+// the server reads as well, is kept.
+func jsonOutputCommand(command []string) ([]string, bool) {
+	pc := parseCommandFlags(command)
+	if pc.outFormat == "" || hasStdoutFormat(pc.outFormat, "json") || hasStdoutFormat(pc.outFormat, "sarif") {
+		return command, false
+	}
+
+	replaced := slices.Clone(command)
+	for i := 0; i < len(replaced); i++ {
+		if !strings.HasPrefix(replaced[i], "-") {
+			continue
+		}
+
+		prefix := "-"
+		if strings.HasPrefix(replaced[i], "--") {
+			prefix = "--"
+		}
+		arg := strings.TrimPrefix(replaced[i], prefix)
+
+		switch {
+		case strings.HasPrefix(arg, "out-format="):
+			replaced[i] = prefix + "out-format=json"
+		case arg == "out-format" && i+1 < len(replaced):
+			replaced[i+1] = "json"
+			i++
+		default:
+			_, path, ok := outputPathFlag(arg, replaced[i+1:])
+			if !ok || path != "stdout" {
+				continue
+			}
+
+			if strings.Contains(arg, "=") {
+				replaced[i] = prefix + "output.json.path=stdout"
+			} else {
+				replaced[i] = prefix + "output.json.path"
+				i++
+			}
+		}
+	}
+
+	return replaced, true
+}
+
+// outputSnippetLength is the number of bytes of unparseable output shown to the user.
+const outputSnippetLength = 500
+
+// lineNumberRe matches an issue line of the line-number format: path:line[:col]: message (linter).
+var lineNumberRe = regexp.MustCompile(`^(.+?):(\d+)(?::(\d+))?: (.*) \(([\w-]+)\)$`)
+
+// parseLineNumberOutput parses the issues of the line-number and colored-line-number
+// output formats. The source lines printed below issues and summaries are skipped.
+// It reports false when no issue was found.
+func parseLineNumberOutput(b []byte) (GolangCILintResult, bool) {
+	var result GolangCILintResult
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimRight(stripANSI(scanner.Text()), "\r")
+
+		m := lineNumberRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		var issue Issue
+		issue.Pos.Filename = m[1]
+		issue.Pos.Line, _ = strconv.Atoi(m[2])
+		issue.Pos.Column, _ = strconv.Atoi(m[3])
+		issue.Text = m[4]
+		issue.FromLinter = m[5]
+
+		result.Issues = append(result.Issues, issue)
+	}
+
+	return result, len(result.Issues) > 0
+}
+
+// outputSnippet returns the start of output for display: color escape sequences,
+// invalid UTF-8 and control characters other than newlines and tabs are removed.
+func outputSnippet(b []byte) string {
+	text := strings.ToValidUTF8(stripANSI(string(b)), "")
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+
+		return r
+	}, text)
+
+	if len(text) <= outputSnippetLength {
+		return text
+	}
+
+	cut := outputSnippetLength
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+
+	return text[:cut] + "…"
+}
+
+// exitCode returns the exit code of a failed command, or -1 when it did not exit.
+func exitCode(err error) int {
+	var e *exec.ExitError
+	if errors.As(err, &e) {
+		return e.ExitCode()
+	}
+
+	return -1
+}
+
+// unparseableOutputDiagnostics reports output that is neither JSON nor the line-number
+// format, with the start of it, since the parse error alone tells nothing about it.
+func unparseableOutputDiagnostics(parseErr error, code int, b []byte) []Diagnostic {
+	snippet := outputSnippet(b)
+
+	slog.Debug("unparseable golangci-lint output", "error", parseErr, "exitCode", code, "output", snippet)
+
+	message := fmt.Sprintf("failed to parse golangci-lint output (exit code %d): %v\n"+
+		"The command probably lacks the JSON output flag: %s.\n\nOutput:\n%s",
+		code, parseErr, outputFormatAdvice, snippet)
+
+	return []Diagnostic{{Severity: DSError, Message: message}}
+}
+
+// warnOutputFormat tells the user once that golangci-lint does not write JSON.
+func (h *langHandler) warnOutputFormat(message string) {
+	if h.conn == nil || h.outputFormatWarned.Swap(true) {
+		return
+	}
+
+	slog.Warn(message)
+
+	if err := h.conn.Notify(context.Background(), "window/showMessage", &ShowMessageParams{
+		Type:    MTWarning,
+		Message: fmt.Sprintf("golangci-lint-langserver: %s; %s", message, outputFormatAdvice),
+	}); err != nil {
+		slog.Error("failed to show message", "error", err)
+	}
+}
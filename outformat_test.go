@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseLineNumberOutput(t *testing.T) {
+	type issue struct {
+		path   string
+		line   int
+		column int
+		linter string
+		text   string
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		want   []issue
+		wantOK bool
+	}{
+		{
+			name: "line-number with source lines",
+			output: "main.go:6:2: printf: fmt.Printf format %d has arg \"x\" of wrong type string (govet)\n" +
+				"\tfmt.Printf(\"%d\", \"x\")\n" +
+				"\t^\n" +
+				"internal/store/db.go:41:15: Error return value of `rows.Close` is not checked (errcheck)\n" +
+				"\tdefer rows.Close()\n" +
+				"\t             ^\n",
+			want: []issue{
+				{path: "main.go", line: 6, column: 2, linter: "govet", text: `printf: fmt.Printf format %d has arg "x" of wrong type string`},
+				{path: "internal/store/db.go", line: 41, column: 15, linter: "errcheck", text: "Error return value of `rows.Close` is not checked"},
+			},
+			wantOK: true,
+		},
+		{
+			name:   "issue without column",
+			output: "pkg/foo.go:12: File is not `gofmt`-ed with `-s` (gofmt)\n",
+			want:   []issue{{path: "pkg/foo.go", line: 12, linter: "gofmt", text: "File is not `gofmt`-ed with `-s`"}},
+			wantOK: true,
+		},
+		{
+			name: "colored-line-number",
+			output: "\x1b[1mmain.go:3:6\x1b[0m: \x1b[1m\x1b[31mfunc `unused` is unused\x1b[0m (unused)\n" +
+				"func unused() {}\n" +
+				"     ^\n",
+			want:   []issue{{path: "main.go", line: 3, column: 6, linter: "unused", text: "func `unused` is unused"}},
+			wantOK: true,
+		},
+		{
+			name:   "windows path",
+			output: "C:\\project\\main.go:10:1: exported function Foo should have comment or be unexported (revive)\r\n",
+			want:   []issue{{path: `C:\project\main.go`, line: 10, column: 1, linter: "revive", text: "exported function Foo should have comment or be unexported"}},
+			wantOK: true,
+		},
+		{
+			name:   "checkstyle",
+			output: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<checkstyle version=\"5.0\"></checkstyle>\n",
+		},
+		{
+			name:   "not an issue",
+			output: "level=error msg=\"Running error: context loading failed: no go files to analyze\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := parseLineNumberOutput([]byte(tt.output))
+			if ok != tt.wantOK {
+				t.Errorf("parseLineNumberOutput() ok = %v, want %v", ok, tt.wantOK)
+			}
+
+			var got []issue
+			for _, i := range result.Issues {
+				got = append(got, issue{path: i.Pos.Filename, line: i.Pos.Line, column: i.Pos.Column, linter: i.FromLinter, text: i.Text})
+			}
+
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(issue{})); diff != "" {
+				t.Errorf("parseLineNumberOutput() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJSONOutputCommand(t *testing.T) {
+	tests := []struct {
+		name         string
+		command      string
+		want         string
+		wantReplaced bool
+	}{
+		{
+			name:    "v2 json",
+			command: "golangci-lint run --output.json.path stdout --show-stats=false",
+			want:    "golangci-lint run --output.json.path stdout --show-stats=false",
+		},
+		{
+			name:    "v1 json",
+			command: "golangci-lint run --out-format json",
+			want:    "golangci-lint run --out-format json",
+		},
+		{
+			name:    "v1 json among several formats",
+			command: "golangci-lint run --out-format=json,checkstyle:report.xml",
+			want:    "golangci-lint run --out-format=json,checkstyle:report.xml",
+		},
+		{
+			name:         "v1 separate",
+			command:      "golangci-lint run --out-format colored-line-number --issues-exit-code=1",
+			want:         "golangci-lint run --out-format json --issues-exit-code=1",
+			wantReplaced: true,
+		},
+		{
+			name:         "v1 with equals",
+			command:      "golangci-lint run --out-format=checkstyle",
+			want:         "golangci-lint run --out-format=json",
+			wantReplaced: true,
+		},
+		{
+			name:         "v2 text on stdout",
+			command:      "golangci-lint run --output.text.path stdout --show-stats=false",
+			want:         "golangci-lint run --output.json.path stdout --show-stats=false",
+			wantReplaced: true,
+		},
+		{
+			name:    "v2 text on stdout next to json",
+			command: "golangci-lint run --output.text.path=stdout --output.json.path=stdout",
+			want:    "golangci-lint run --output.text.path=stdout --output.json.path=stdout",
+		},
+		{
+			name:    "v2 sarif on stdout",
+			command: "golangci-lint run --output.sarif.path=stdout",
+			want:    "golangci-lint run --output.sarif.path=stdout",
+		},
+		{
+			name:    "v1 sarif",
+			command: "golangci-lint run --out-format sarif",
+			want:    "golangci-lint run --out-format sarif",
+		},
+		{
+			name:    "no output flag",
+			command: "./scripts/lint.sh",
+			want:    "./scripts/lint.sh",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, replaced := jsonOutputCommand(strings.Fields(tt.command))
+			if replaced != tt.wantReplaced {
+				t.Errorf("jsonOutputCommand() replaced = %v, want %v", replaced, tt.wantReplaced)
+			}
+
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("jsonOutputCommand() = %q, want %q", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputSnippet(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{
+			name:   "banner",
+			output: "\x1b[1mlint.sh\x1b[0m v1.2\r\nrunning\tgolangci-lint\x07\n",
+			want:   "lint.sh v1.2\nrunning\tgolangci-lint\n",
+		},
+		{
+			name:   "invalid UTF-8",
+			output: "a\xffb",
+			want:   "ab",
+		},
+		{
+			name:   "long output is cut at a rune boundary",
+			output: strings.Repeat("a", outputSnippetLength-1) + "é and more",
+			want:   strings.Repeat("a", outputSnippetLength-1) + "…",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := outputSnippet([]byte(tt.output)); got != tt.want {
+				t.Errorf("outputSnippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLangHandler_unparseableOutput tests that output which cannot be parsed is shown in the error diagnostic.
+func TestLangHandler_unparseableOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake golangci-lint needs sh")
+	}
+
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	h := &langHandler{
+		rootDir: root,
+		command: []string{"sh", "-c", "echo 'lint wrapper v1.0'; exit 1"},
+	}
+
+	results, err := h.lint(context.Background(), pathToURI(path))
+	if err != nil {
+		t.Fatalf("lint() returned unexpected error: %v", err)
+	}
+
+	diagnostics := results[pathToURI(path)]
+
+	if len(diagnostics) != 1 {
+		t.Fatalf("lint() = %+v, want one error diagnostic", diagnostics)
+	}
+
+	for _, want := range []string{"exit code 1", "lint wrapper v1.0", "--output.json.path stdout"} {
+		if !strings.Contains(diagnostics[0].Message, want) {
+			t.Errorf("diagnostic message %q does not contain %q", diagnostics[0].Message, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+)
+
+// defaultMaxOutputBytes is the amount of golangci-lint output read by default.
+const defaultMaxOutputBytes = 64 << 20
+
+// limitedBuffer captures the output of a process up to limit bytes, a limit of zero
+// capturing everything. Once the limit is exceeded, the rest is discarded and onExceed,
+// which kills the process, is called, so that a runaway run cannot exhaust the memory
+// of the server.
+type limitedBuffer struct {
+	limit    int
+	onExceed func()
+
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	exceeded bool
+}
+
+func (w *limitedBuffer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.exceeded {
+		return len(p), nil
+	}
+
+	if w.limit > 0 && w.buf.Len()+len(p) > w.limit {
+		w.exceeded = true
+		w.buf.Reset()
+
+		if w.onExceed != nil {
+			w.onExceed()
+		}
+
+		return len(p), nil
+	}
+
+	return w.buf.Write(p)
+}
+
+// Bytes returns the output captured, which is empty once the limit was exceeded.
+func (w *limitedBuffer) Bytes() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.Bytes()
+}
+
+// Len returns the number of bytes captured.
+func (w *limitedBuffer) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.Len()
+}
+
+// Exceeded reports whether the output went over the limit.
+func (w *limitedBuffer) Exceeded() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.exceeded
+}
+
+// outputLimitMessage explains a run killed for writing more than limit bytes.
+func outputLimitMessage(limit int) string {
+	return fmt.Sprintf("golangci-lint was stopped after writing more than %d bytes of output (maxOutputBytes). "+
+		"Reduce the number of reported issues, for instance with --max-issues-per-linter and --max-same-issues, "+
+		"or by excluding generated code and noisy linters in the config.", limit)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimitedBuffer(t *testing.T) {
+	tests := []struct {
+		name         string
+		limit        int
+		writes       []string
+		want         string
+		wantExceeded bool
+	}{
+		{name: "under the limit", limit: 10, writes: []string{"abc", "def"}, want: "abcdef"},
+		{name: "at the limit", limit: 6, writes: []string{"abc", "def"}, want: "abcdef"},
+		{name: "over the limit", limit: 5, writes: []string{"abc", "def", "ghi"}, wantExceeded: true},
+		{name: "no limit", writes: []string{"abc", "def"}, want: "abcdef"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			w := &limitedBuffer{limit: tt.limit, onExceed: func() { calls++ }}
+
+			for _, s := range tt.writes {
+				if n, err := w.Write([]byte(s)); n != len(s) || err != nil {
+					t.Fatalf("Write(%q) = %d, %v, want %d, nil", s, n, err, len(s))
+				}
+			}
+
+			if got := string(w.Bytes()); got != tt.want {
+				t.Errorf("Bytes() = %q, want %q", got, tt.want)
+			}
+
+			if w.Exceeded() != tt.wantExceeded {
+				t.Errorf("Exceeded() = %v, want %v", w.Exceeded(), tt.wantExceeded)
+			}
+
+			if wantCalls := map[bool]int{true: 1}[tt.wantExceeded]; calls != wantCalls {
+				t.Errorf("onExceed called %d times, want %d", calls, wantCalls)
+			}
+		})
+	}
+}
+
+// TestLangHandler_lintOutputLimit tests that a run writing more than maxOutputBytes
+// is killed and reported with a single error diagnostic.
+func TestLangHandler_lintOutputLimit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake golangci-lint needs sh")
+	}
+
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	h := &langHandler{
+		rootDir:        root,
+		command:        []string{"sh", "-c", `while :; do echo '{"Issues":[]}'; done`},
+		maxOutputBytes: 1 << 10,
+	}
+
+	start := time.Now()
+
+	results, err := h.lint(context.Background(), pathToURI(path))
+	if err != nil {
+		t.Fatalf("lint() returned unexpected error: %v", err)
+	}
+
+	diagnostics := results[pathToURI(path)]
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("lint() took %v, want the run killed", elapsed)
+	}
+
+	if len(diagnostics) != 1 || diagnostics[0].Severity != DSError {
+		t.Fatalf("lint() = %+v, want one error diagnostic", diagnostics)
+	}
+
+	for _, want := range []string{"1024 bytes", "maxOutputBytes", "--max-issues-per-linter"} {
+		if !strings.Contains(diagnostics[0].Message, want) {
+			t.Errorf("diagnostic message %q does not contain %q", diagnostics[0].Message, want)
+		}
+	}
+}
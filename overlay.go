@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// overlayLinters are the linters run on documents that exist only in the editor.
+// They are linted outside of their module, so linters relying on the rest of the
+// package or module would mostly report noise.
+var overlayLinters = []string{"govet", "ineffassign", "misspell", "staticcheck"}
+
+// overlayFileName is the file name of untitled documents in the overlay.
+const overlayFileName = "untitled.go"
+
+// overlayDirPrefix starts the name of the temporary directories of overlays.
+const overlayDirPrefix = "golangci-lint-langserver-overlay-"
+
+func isUntitled(uri DocumentURI) bool {
+	return strings.HasPrefix(string(uri), "untitled:")
+}
+
+// needsOverlay reports whether the document only exists in the editor: an untitled
+// document, or an open document whose file has not been saved yet.
+func (h *langHandler) needsOverlay(uri DocumentURI) bool {
+	if isUntitled(uri) {
+		return true
+	}
+
+	if !h.documents.isOpen(uri) {
+		return false
+	}
+
+	_, err := os.Stat(uriToPath(string(uri)))
+
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// lintOverlay lints a document that only exists in the editor. The content is written to
+// a temporary directory, next to copies of the other Go files of its package and of the
+// nearest go.mod and go.sum when the document has a path, or in a module of its own otherwise.
+// Only overlayLinters run, and the diagnostics are reported for the document.
+func (h *langHandler) lintOverlay(ctx context.Context, uri DocumentURI, extraArgs ...string) ([]Diagnostic, error) {
+	text, ok := h.documents.GetText(uri)
+	if !ok {
+		return []Diagnostic{}, nil
+	}
+
+	tmp, err := os.MkdirTemp("", overlayDirPrefix)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := os.RemoveAll(tmp); err != nil {
+			slog.Warn("failed to remove the overlay", "dir", tmp, "error", err)
+		}
+	}()
+
+	name := overlayFileName
+	if !isUntitled(uri) {
+		path := uriToPath(string(uri))
+		name = filepath.Base(path)
+
+		if err := copyPackage(filepath.Dir(path), tmp, name); err != nil {
+			slog.Debug("linting the document without its package", "uri", uri, "error", err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(tmp, "go.mod")); err != nil {
+		if err := os.WriteFile(filepath.Join(tmp, "go.mod"), []byte("module overlay\n"), 0o600); err != nil {
+			return nil, err
+		}
+	}
+
+	path := filepath.Join(tmp, name)
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"--enable-only=" + strings.Join(overlayLinters, ",")}, extraArgs...)
+
+	slog.Debug("linting the document in an overlay", "uri", uri, "dir", tmp)
+
+	results, err := h.lintPath(ctx, path, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Locations in the overlay mean nothing to the client, nor do the copies of the
+	// other files of the package.
+	diagnostics := results[path]
+	for i := range diagnostics {
+		diagnostics[i].Message = strings.ReplaceAll(diagnostics[i].Message, tmp+string(filepath.Separator), "")
+		diagnostics[i].RelatedInformation = nil
+	}
+
+	return diagnostics, nil
+}
+
+// copyPackage copies the Go files of dir, except skip, and the nearest go.mod to dst,
+// along with the go.sum next to it so that dependencies still resolve.
+func copyPackage(dir, dst, skip string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if filepath.Base(file) == skip {
+			continue
+		}
+
+		if err := copyFile(file, filepath.Join(dst, filepath.Base(file))); err != nil {
+			return err
+		}
+	}
+
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return copyModule(d, dst)
+		}
+
+		if filepath.Dir(d) == d {
+			return fmt.Errorf("no go.mod above %s", dir)
+		}
+	}
+}
+
+// copyModule copies go.mod, and go.sum when present, from the module directory to dst.
+// go.work is left out: the other modules it uses are not copied.
+func copyModule(dir, dst string) error {
+	for _, name := range []string{"go.mod", "go.sum"} {
+		err := copyFile(filepath.Join(dir, name), filepath.Join(dst, name))
+		if err != nil && (name == "go.mod" || !errors.Is(err, fs.ErrNotExist)) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// overlayEnv sets GOWORK=off for cmd when it runs in an overlay, so that a go.work
+// above the temporary directory does not take the copy into its workspace.
+func overlayEnv(cmd *exec.Cmd) {
+	if !strings.HasPrefix(filepath.Base(cmd.Dir), overlayDirPrefix) {
+		return
+	}
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+
+	cmd.Env = append(env, "GOWORK=off")
+}
+
+func copyFile(src, dst string) error {
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(dst, b, 0o600)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestLangHandler_lintUntitled tests that untitled documents are linted from their
+// current content in a temporary directory that is removed afterwards.
+func TestLangHandler_lintUntitled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake linter needs sh")
+	}
+
+	issue := Issue{FromLinter: "govet", Text: "unreachable code"}
+	issue.Pos.Filename = overlayFileName
+	issue.Pos.Line = 3
+	issue.Pos.Column = 15
+
+	result, err := json.Marshal(GolangCILintResult{Issues: []Issue{issue}})
+	if err != nil {
+		t.Fatalf("json.Marshal() returned unexpected error: %v", err)
+	}
+
+	dir := t.TempDir()
+	resultPath := filepath.Join(dir, "result.json")
+	if err := os.WriteFile(resultPath, result, 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	// The fake linter records the linted directory and the content of the document in it.
+	record := filepath.Join(dir, "record")
+	script := `for a; do target=$a; done; echo "$target" > "$0.dir"; cp "$target/` + overlayFileName + `" "$0"; cat "$1"; exit 1`
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri": string(pathToURI(t.TempDir())),
+		"initializationOptions": map[string]any{
+			"command":    []string{"sh", "-c", script, record, resultPath},
+			"lintOnOpen": false,
+		},
+	}, nil)
+
+	uri := "untitled:Untitled-1"
+	client.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": "package main\n\nfunc main() {}\n"},
+	})
+	client.notify("textDocument/didChange", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "version": 2},
+		"contentChanges": []map[string]any{{
+			"range": map[string]any{"start": map[string]any{"line": 2, "character": 13}, "end": map[string]any{"line": 2, "character": 13}},
+			"text":  " return ",
+		}},
+	})
+
+	var report FullDocumentDiagnosticReport
+	client.call("textDocument/diagnostic", map[string]any{"textDocument": map[string]any{"uri": uri}}, &report)
+
+	if len(report.Items) != 1 || report.Items[0].Message != "govet: unreachable code" || report.Items[0].Range.Start.Line != 2 {
+		t.Errorf("unexpected diagnostics: %+v", report.Items)
+	}
+
+	content, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned unexpected error: %v", err)
+	}
+
+	if want := "package main\n\nfunc main() { return }\n"; string(content) != want {
+		t.Errorf("linted content = %q, want %q", content, want)
+	}
+
+	target, err := os.ReadFile(record + ".dir")
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned unexpected error: %v", err)
+	}
+
+	overlay := strings.TrimSpace(string(target))
+	if _, err := os.Stat(overlay); !os.IsNotExist(err) {
+		t.Errorf("the overlay %s was not removed: %v", overlay, err)
+	}
+}
+
+// TestCopyPackage_dependency tests that the copy of a package importing a dependency
+// still builds with the go.sum of its module, and outside the go.work of its repository.
+func TestCopyPackage_dependency(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+
+	sum, err := os.ReadFile("go.sum")
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned unexpected error: %v", err)
+	}
+
+	dir := t.TempDir()
+	for name, text := range map[string]string{
+		"go.mod":       "module example.com/dep\n\ngo 1.23\n\nrequire github.com/google/go-cmp v0.6.0\n",
+		"go.sum":       string(sum),
+		"go.work":      "go 1.23\n\nuse (\n\t.\n\t./tools\n)\n",
+		"tools/go.mod": "module example.com/tools\n\ngo 1.23\n",
+		"a.go":         "package dep\n\nimport \"github.com/google/go-cmp/cmp\"\n\nvar Diff = cmp.Diff\n",
+		"b.go":         "package dep\n\nvar Unsaved = 1\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	// The temporary directory may itself be below a workspace.
+	tmp := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmp, "go.work"), []byte("go 1.23\n\nuse ./tools\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	dst, err := os.MkdirTemp(tmp, overlayDirPrefix)
+	if err != nil {
+		t.Fatalf("os.MkdirTemp() returned unexpected error: %v", err)
+	}
+
+	if err := copyPackage(dir, dst, "b.go"); err != nil {
+		t.Fatalf("copyPackage() returned unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"go.mod", "go.sum", "a.go"} {
+		if _, err := os.Stat(filepath.Join(dst, name)); err != nil {
+			t.Errorf("%s was not copied: %v", name, err)
+		}
+	}
+	for _, name := range []string{"b.go", "go.work"} {
+		if _, err := os.Stat(filepath.Join(dst, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was copied: %v", name, err)
+		}
+	}
+
+	// Without go.sum, the dependency would be reported missing from it, and without
+	// GOWORK=off the copy would not be part of the workspace above it.
+	cmd := exec.Command("go", "vet", ".")
+	cmd.Dir = dst
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+	overlayEnv(cmd)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("go vet in the copy failed: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// LSP error codes.
+const (
+	// codeServerNotInitialized is the error of requests sent before initialize.
+	codeServerNotInitialized = -32002
+	// codeRequestCancelled is the error of requests cancelled by the server or the client.
+	codeRequestCancelled = -32800
+)
+
+func invalidParams(format string, args ...any) *jsonrpc2.Error {
+	return &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// decodeParams unmarshals the params of the request into v.
+// Missing or malformed params are reported as InvalidParams errors.
+func decodeParams(req *jsonrpc2.Request, v any) error {
+	if req.Params == nil {
+		return invalidParams("%s: missing params", req.Method)
+	}
+
+	if err := json.Unmarshal(*req.Params, v); err != nil {
+		return invalidParams("%s: invalid params: %v", req.Method, err)
+	}
+
+	return nil
+}
+
+// validateDocumentURI checks that uri refers to a local file or an untitled document the server can lint.
+func validateDocumentURI(uri DocumentURI) error {
+	if uri == "" {
+		return invalidParams("missing textDocument.uri")
+	}
+
+	u, err := url.Parse(string(uri))
+	if err != nil {
+		return invalidParams("invalid textDocument.uri %q: %v", uri, err)
+	}
+
+	if u.Scheme == "untitled" && u.Opaque != "" {
+		return nil
+	}
+
+	if u.Scheme != "file" {
+		return invalidParams("unsupported textDocument.uri scheme %q: only file and untitled URIs are supported", u.Scheme)
+	}
+
+	if u.Path == "" {
+		return invalidParams("invalid textDocument.uri %q: missing path", uri)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+func TestLangHandler_handleInvalidParams(t *testing.T) {
+	raw := func(s string) *json.RawMessage {
+		m := json.RawMessage(s)
+
+		return &m
+	}
+
+	methods := []string{
+		"textDocument/didOpen",
+		"textDocument/didClose",
+		"textDocument/didChange",
+		"textDocument/didSave",
+	}
+
+	params := []struct {
+		name   string
+		params *json.RawMessage
+	}{
+		{name: "nil params"},
+		{name: "not an object", params: raw(`[]`)},
+		{name: "empty object", params: raw(`{}`)},
+		{name: "missing uri", params: raw(`{"textDocument": {}}`)},
+		{name: "non-file scheme", params: raw(`{"textDocument": {"uri": "http://example.com/main.go"}}`)},
+		{name: "unparseable uri", params: raw(`{"textDocument": {"uri": "file://%zz/main.go"}}`)},
+		{name: "uri without path", params: raw(`{"textDocument": {"uri": "file://"}}`)},
+	}
+
+	type testCase struct {
+		method string
+		name   string
+		params *json.RawMessage
+	}
+
+	var tests []testCase
+	for _, method := range methods {
+		for _, p := range params {
+			tests = append(tests, testCase{method: method, name: p.name, params: p.params})
+		}
+	}
+	tests = append(tests,
+		testCase{method: "initialize", name: "nil params"},
+		testCase{method: "initialize", name: "not an object", params: raw(`"golangci-lint"`)},
+		testCase{method: "window/workDoneProgress/cancel", name: "nil params"},
+		testCase{method: "window/workDoneProgress/cancel", name: "not an object", params: raw(`[]`)},
+	)
+
+	for _, tt := range tests {
+		t.Run(tt.method+"/"+tt.name, func(t *testing.T) {
+			h := &langHandler{request: make(chan DocumentURI, 1)}
+
+			_, err := h.handle(context.Background(), nil, &jsonrpc2.Request{Method: tt.method, Params: tt.params})
+
+			var rpcErr *jsonrpc2.Error
+			if !errors.As(err, &rpcErr) || rpcErr.Code != jsonrpc2.CodeInvalidParams {
+				t.Errorf("request: expected an InvalidParams error, got %v", err)
+			}
+
+			if _, err := h.handle(context.Background(), nil, &jsonrpc2.Request{Method: tt.method, Params: tt.params, Notif: true}); err != nil {
+				t.Errorf("notification: expected the error to be dropped, got %v", err)
+			}
+
+			if len(h.request) != 0 {
+				t.Errorf("a lint was requested for invalid params")
+			}
+		})
+	}
+}
+
+func TestValidateDocumentURI(t *testing.T) {
+	if err := validateDocumentURI("file:///project/main.go"); err != nil {
+		t.Errorf("validateDocumentURI() returned unexpected error: %v", err)
+	}
+
+	if err := validateDocumentURI("file:///c%3A/project/main.go"); err != nil {
+		t.Errorf("validateDocumentURI() returned unexpected error for an escaped Windows URI: %v", err)
+	}
+}
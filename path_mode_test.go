@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -41,6 +44,46 @@ func TestParseCommandFlags(t *testing.T) {
 				configDir: filepath.Dir("/path/to/.golangci.yml"),
 			},
 		},
+		{
+			name:     "path-prefix with equals",
+			command:  []string{"golangci-lint", "run", "--path-prefix=services/api"},
+			expected: pathConfig{pathPrefix: "services/api"},
+		},
+		{
+			name:     "path-prefix separate",
+			command:  []string{"golangci-lint", "run", "--path-prefix", "services/api"},
+			expected: pathConfig{pathPrefix: "services/api"},
+		},
+		{
+			name:     "relative-path-mode with equals",
+			command:  []string{"golangci-lint", "run", "--relative-path-mode=gitroot"},
+			expected: pathConfig{relativePathMode: "gitroot"},
+		},
+		{
+			name:     "relative-path-mode separate",
+			command:  []string{"golangci-lint", "run", "--relative-path-mode", "wd"},
+			expected: pathConfig{relativePathMode: "wd"},
+		},
+		{
+			name:     "out-format with equals",
+			command:  []string{"golangci-lint", "run", "--out-format=line-number"},
+			expected: pathConfig{outFormat: "line-number"},
+		},
+		{
+			name:     "out-format separate",
+			command:  []string{"golangci-lint", "run", "--out-format", "checkstyle"},
+			expected: pathConfig{outFormat: "checkstyle"},
+		},
+		{
+			name:     "output path to stdout",
+			command:  []string{"golangci-lint", "run", "--output.tab.path", "stdout"},
+			expected: pathConfig{outFormat: "tab"},
+		},
+		{
+			name:     "output path to a file",
+			command:  []string{"golangci-lint", "run", "--output.text.path=report.txt"},
+			expected: pathConfig{},
+		},
 		{
 			name:     "no-config flag",
 			command:  []string{"golangci-lint", "run", "--no-config"},
@@ -68,12 +111,21 @@ func TestParseCommandFlags(t *testing.T) {
 			if result.pathMode != tt.expected.pathMode {
 				t.Errorf("pathMode: expected %q, got %q", tt.expected.pathMode, result.pathMode)
 			}
+			if result.pathPrefix != tt.expected.pathPrefix {
+				t.Errorf("pathPrefix: expected %q, got %q", tt.expected.pathPrefix, result.pathPrefix)
+			}
+			if result.outFormat != tt.expected.outFormat {
+				t.Errorf("outFormat: expected %q, got %q", tt.expected.outFormat, result.outFormat)
+			}
 			if result.configDir != tt.expected.configDir {
 				t.Errorf("configDir: expected %q, got %q", tt.expected.configDir, result.configDir)
 			}
 			if result.noConfig != tt.expected.noConfig {
 				t.Errorf("noConfig: expected %v, got %v", tt.expected.noConfig, result.noConfig)
 			}
+			if result.relativePathMode != tt.expected.relativePathMode {
+				t.Errorf("relativePathMode: expected %q, got %q", tt.expected.relativePathMode, result.relativePathMode)
+			}
 		})
 	}
 }
@@ -127,6 +179,114 @@ func TestPathConfigGetBaseDir(t *testing.T) {
 	}
 }
 
+// relativePathLayout creates a repository holding a module, whose lint directory holds
+// a config with the given run.relative-path-mode, above the package of main.go:
+//
+//	repo/.git
+//	repo/svc/go.mod
+//	repo/svc/lint/.golangci.yml
+//	repo/svc/lint/pkg/main.go
+func relativePathLayout(t *testing.T, mode string) (repo string) {
+	t.Helper()
+
+	repo = t.TempDir()
+	pkg := filepath.Join(repo, "svc", "lint", "pkg")
+	for _, d := range []string{filepath.Join(repo, ".git"), pkg} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+		}
+	}
+
+	files := map[string]string{
+		filepath.Join(repo, "svc", "go.mod"):                "module example.com/svc\n",
+		filepath.Join(repo, "svc", "lint", ".golangci.yml"): "version: \"2\"\n",
+		filepath.Join(pkg, "main.go"):                       "package main\n",
+	}
+	if mode != "" {
+		files[filepath.Join(repo, "svc", "lint", ".golangci.yml")] = "version: \"2\"\nrun:\n  relative-path-mode: " + mode + "\n"
+	}
+
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	return repo
+}
+
+func TestLangHandler_relativePathBase(t *testing.T) {
+	tests := []struct {
+		name       string
+		configMode string
+		flagMode   string
+		want       string
+	}{
+		{name: "gomod", configMode: "gomod", want: "svc"},
+		{name: "gitroot", configMode: "gitroot", want: "."},
+		{name: "cfg", configMode: "cfg", want: "svc/lint"},
+		{name: "wd", configMode: "wd", want: "svc/lint/pkg"},
+		{name: "flag wins over the config", configMode: "cfg", flagMode: "gomod", want: "svc"},
+		{name: "unset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := relativePathLayout(t, tt.configMode)
+			pkg := filepath.Join(repo, "svc", "lint", "pkg")
+
+			h := &langHandler{rootDir: pkg, pathConfig: pathConfig{relativePathMode: tt.flagMode}}
+
+			want := ""
+			if tt.want != "" {
+				want = filepath.Join(repo, filepath.FromSlash(tt.want))
+			}
+
+			if got := h.relativePathBase(pkg, pkg); got != want {
+				t.Errorf("relativePathBase() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// TestLangHandler_lintRelativePathMode tests that issue paths relative to the directory
+// of each relative path mode are resolved to the linted file.
+func TestLangHandler_lintRelativePathMode(t *testing.T) {
+	tests := []struct {
+		mode     string
+		filename string
+	}{
+		{mode: "gomod", filename: "lint/pkg/main.go"},
+		{mode: "gitroot", filename: "svc/lint/pkg/main.go"},
+		{mode: "cfg", filename: "pkg/main.go"},
+		{mode: "wd", filename: "main.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			repo := relativePathLayout(t, tt.mode)
+			pkg := filepath.Join(repo, "svc", "lint", "pkg")
+			path := filepath.Join(pkg, "main.go")
+
+			issue := Issue{FromLinter: "revive", Text: "package comment should be of the form"}
+			issue.Pos.Filename, issue.Pos.Line, issue.Pos.Column = tt.filename, 1, 1
+
+			h := &langHandler{rootDir: pkg, command: fakeLinter(t, GolangCILintResult{Issues: []Issue{issue}})}
+
+			results, err := h.lint(context.Background(), pathToURI(path))
+			if err != nil {
+				t.Fatalf("lint() returned unexpected error: %v", err)
+			}
+
+			diagnostics := results[pathToURI(path)]
+
+			if len(diagnostics) != 1 {
+				t.Errorf("lint() returned %d diagnostics, want 1: %+v", len(diagnostics), diagnostics)
+			}
+		})
+	}
+}
+
 // TestPathComparisonLogic tests the path comparison logic used for handling
 // different golangci-lint path-mode settings.
 func TestPathComparisonLogic(t *testing.T) {
@@ -135,6 +295,7 @@ func TestPathComparisonLogic(t *testing.T) {
 		targetPath  string
 		issuePath   string
 		baseDir     string
+		pathPrefix  string
 		shouldMatch bool
 	}{
 		{
@@ -179,6 +340,38 @@ func TestPathComparisonLogic(t *testing.T) {
 			baseDir:     "/project",
 			shouldMatch: false,
 		},
+		{
+			name:        "path prefix stripped",
+			targetPath:  "/project/src/main.go",
+			issuePath:   "services/api/src/main.go",
+			baseDir:     "/project",
+			pathPrefix:  "services/api",
+			shouldMatch: true,
+		},
+		{
+			name:        "path prefix with trailing slash",
+			targetPath:  "/project/src/main.go",
+			issuePath:   "services/api/src/main.go",
+			baseDir:     "/project",
+			pathPrefix:  "services/api/",
+			shouldMatch: true,
+		},
+		{
+			name:        "path prefix not present",
+			targetPath:  "/project/src/main.go",
+			issuePath:   "src/main.go",
+			baseDir:     "/project",
+			pathPrefix:  "services/api",
+			shouldMatch: true,
+		},
+		{
+			name:        "path prefix only partially matching",
+			targetPath:  "/project/src/main.go",
+			issuePath:   "services/apis/src/main.go",
+			baseDir:     "/project",
+			pathPrefix:  "services/api",
+			shouldMatch: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -189,7 +382,7 @@ func TestPathComparisonLogic(t *testing.T) {
 			}
 			absPath = filepath.Clean(absPath)
 
-			issuePath := tt.issuePath
+			issuePath := pathConfig{pathPrefix: tt.pathPrefix}.stripPathPrefix(filepath.FromSlash(tt.issuePath))
 			match := false
 
 			if !filepath.IsAbs(issuePath) {
@@ -211,3 +404,93 @@ func TestPathComparisonLogic(t *testing.T) {
 		})
 	}
 }
+
+// TestIssueMatchesPath tests resolving issue paths against several base directories,
+// as needed when a single file is passed to golangci-lint.
+func TestIssueMatchesPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		issuePath string
+		baseDirs  []string
+		want      bool
+	}{
+		{
+			name:      "relative to first base",
+			issuePath: "src/main.go",
+			baseDirs:  []string{"/project", "/project/src"},
+			want:      true,
+		},
+		{
+			name:      "relative to file directory",
+			issuePath: "main.go",
+			baseDirs:  []string{"/elsewhere", "/project/src"},
+			want:      true,
+		},
+		{
+			name:      "suffix fallback",
+			issuePath: "src/main.go",
+			baseDirs:  []string{"/elsewhere"},
+			want:      true,
+		},
+		{
+			name:      "other file",
+			issuePath: "other.go",
+			baseDirs:  []string{"/project", "/project/src"},
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			absPath, err := filepath.Abs("/project/src/main.go")
+			if err != nil {
+				t.Fatalf("filepath.Abs error: %v", err)
+			}
+
+			issuePath := filepath.FromSlash(tt.issuePath)
+			if got := issueMatchesPath(issuePath, filepath.Clean(absPath), tt.baseDirs); got != tt.want {
+				t.Errorf("issueMatchesPath(%q) = %v, want %v", tt.issuePath, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExpandCommand tests the substitution of placeholders in the command.
+func TestExpandCommand(t *testing.T) {
+	tests := []struct {
+		name         string
+		command      []string
+		want         []string
+		wantExpanded bool
+	}{
+		{
+			name:    "no placeholder",
+			command: []string{"golangci-lint", "run"},
+			want:    []string{"golangci-lint", "run"},
+		},
+		{
+			name:         "dir in the middle",
+			command:      []string{"./scripts/lint.sh", "--target", "{dir}", "--format", "json"},
+			want:         []string{"./scripts/lint.sh", "--target", "/project/src", "--format", "json"},
+			wantExpanded: true,
+		},
+		{
+			name:         "composite arguments",
+			command:      []string{"lint", "--target={dir}", "--file={path}", "--root={root}"},
+			want:         []string{"lint", "--target=/project/src", "--file=/project/src/main.go", "--root=/project"},
+			wantExpanded: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, expanded := expandCommand(tt.command, "/project/src/main.go", "/project/src", "/project")
+			if expanded != tt.wantExpanded {
+				t.Errorf("expanded: expected %v, got %v", tt.wantExpanded, expanded)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+// samePath reports whether the clean paths a and b name the same file.
+func samePath(a, b string) bool {
+	return a == b
+}
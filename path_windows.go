@@ -0,0 +1,12 @@
+//go:build windows
+
+package main
+
+import "strings"
+
+// samePath reports whether the clean paths a and b name the same file. Windows paths
+// are case-insensitive, and their drive letter may come in either case from the client
+// and from golangci-lint.
+func samePath(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
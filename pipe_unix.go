@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+import (
+	"io"
+	"net"
+)
+
+// dialPipe connects to the unix socket the client listens on, as given by --pipe.
+func dialPipe(path string) (io.ReadWriteCloser, error) {
+	return net.Dial("unix", path)
+}
@@ -0,0 +1,66 @@
+//go:build !windows
+
+package main
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDialPipe tests that the server connects to the unix socket created by the client.
+func TestDialPipe(t *testing.T) {
+	// Unix socket paths are limited to about 100 bytes, which t.TempDir may exceed.
+	dir, err := os.MkdirTemp("", "pipe")
+	if err != nil {
+		t.Fatalf("os.MkdirTemp() returned unexpected error: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "lsp.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("net.Listen() returned unexpected error: %v", err)
+	}
+	defer l.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			t.Errorf("Accept() returned unexpected error: %v", err)
+		}
+		accepted <- conn
+	}()
+
+	rwc, err := dialPipe(path)
+	if err != nil {
+		t.Fatalf("dialPipe() returned unexpected error: %v", err)
+	}
+	defer rwc.Close()
+
+	client := <-accepted
+	if client == nil {
+		return
+	}
+	defer client.Close()
+
+	if _, err := client.Write([]byte("Content-Length: 2\r\n\r\n{}")); err != nil {
+		t.Fatalf("Write() returned unexpected error: %v", err)
+	}
+
+	got := make([]byte, 23)
+	if _, err := io.ReadFull(rwc, got); err != nil {
+		t.Fatalf("ReadFull() returned unexpected error: %v", err)
+	}
+
+	if string(got) != "Content-Length: 2\r\n\r\n{}" {
+		t.Errorf("read %q from the pipe", got)
+	}
+
+	if _, err := dialPipe(filepath.Join(dir, "missing.sock")); err == nil {
+		t.Error("dialPipe() returned no error for a missing socket")
+	}
+}
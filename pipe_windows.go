@@ -0,0 +1,93 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// namedPipe is the client end of a named pipe. The handle is opened for overlapped
+// I/O: reads and writes on a synchronous handle are serialized, so the write of a
+// reply would wait for the pending read of the next message.
+type namedPipe struct {
+	handle    syscall.Handle
+	closeOnce sync.Once
+}
+
+// dialPipe connects to the named pipe the client listens on, as given by --pipe,
+// such as \\.\pipe\vscode-lsp-1234.
+func dialPipe(path string) (io.ReadWriteCloser, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	handle, err := syscall.CreateFile(name,
+		syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil,
+		syscall.OPEN_EXISTING, syscall.FILE_FLAG_OVERLAPPED, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &namedPipe{handle: handle}, nil
+}
+
+func (p *namedPipe) Read(b []byte) (int, error) {
+	n, err := p.overlapped(syscall.ReadFile, b)
+	if errors.Is(err, syscall.ERROR_BROKEN_PIPE) || (err == nil && n == 0 && len(b) > 0) {
+		return n, io.EOF
+	}
+
+	return n, err
+}
+
+func (p *namedPipe) Write(b []byte) (int, error) {
+	written := 0
+	for written < len(b) {
+		n, err := p.overlapped(syscall.WriteFile, b[written:])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+func (p *namedPipe) Close() error {
+	var err error
+	p.closeOnce.Do(func() {
+		// Pending reads and writes fail once their handle is closed.
+		_ = syscall.CancelIoEx(p.handle, nil)
+		err = syscall.CloseHandle(p.handle)
+	})
+
+	return err
+}
+
+// overlapped runs a read or write on the pipe and waits for its completion.
+func (p *namedPipe) overlapped(op func(syscall.Handle, []byte, *uint32, *syscall.Overlapped) error, b []byte) (int, error) {
+	event, _, err := procCreateEventW.Call(0, 1, 0, 0)
+	if event == 0 {
+		return 0, err
+	}
+	defer syscall.CloseHandle(syscall.Handle(event))
+
+	o := syscall.Overlapped{HEvent: syscall.Handle(event)}
+
+	var n uint32
+	err = op(p.handle, b, &n, &o)
+	if errors.Is(err, syscall.ERROR_IO_PENDING) {
+		r, _, callErr := procGetOverlappedResult.Call(uintptr(p.handle), uintptr(unsafe.Pointer(&o)), uintptr(unsafe.Pointer(&n)), 1)
+		err = nil
+		if r == 0 {
+			err = callErr
+		}
+	}
+
+	return int(n), err
+}
@@ -0,0 +1,33 @@
+package main
+
+import "os/exec"
+
+// lowPriorityNice is the nice level lint processes run at when lowPriority is set.
+const lowPriorityNice = 10
+
+// run starts cmd, at reduced priority when lowPriority is set, and waits for it.
+// Vendored modules get GOFLAGS=-mod=vendor, and overlays GOWORK=off.
+func (h *langHandler) run(cmd *exec.Cmd) error {
+	h.vendorEnv(cmd)
+	h.gopathEnv(cmd)
+	overlayEnv(cmd)
+
+	start := cmd.Start
+	if h.lowPriority {
+		start = func() error { return startLowPriority(cmd) }
+	}
+
+	if err := start(); err != nil {
+		releaseProcessGroup(cmd)
+
+		return err
+	}
+
+	h.processes.Add(1)
+	defer h.processes.Done()
+
+	joinProcessGroup(cmd)
+	defer releaseProcessGroup(cmd)
+
+	return cmd.Wait()
+}
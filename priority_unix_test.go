@@ -0,0 +1,72 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestLangHandler_run tests that processes run at the lowered nice level with lowPriority.
+func TestLangHandler_run(t *testing.T) {
+	tests := []struct {
+		name        string
+		lowPriority bool
+		want        int
+	}{
+		{name: "normal", want: 0},
+		{name: "low priority", lowPriority: true, want: lowPriorityNice},
+	}
+
+	base := niceness(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := filepath.Join(t.TempDir(), "nice")
+
+			// The priority is lowered right after the start, so the script waits before reading it.
+			cmd := exec.CommandContext(context.Background(), "sh", "-c", `sleep 0.2; nice > "$0"`, record)
+			setProcessGroup(cmd)
+
+			h := &langHandler{lowPriority: tt.lowPriority}
+			if err := h.run(cmd); err != nil {
+				t.Fatalf("run() returned unexpected error: %v", err)
+			}
+
+			b, err := os.ReadFile(record)
+			if err != nil {
+				t.Fatalf("os.ReadFile() returned unexpected error: %v", err)
+			}
+
+			got, err := strconv.Atoi(strings.TrimSpace(string(b)))
+			if err != nil {
+				t.Fatalf("unexpected nice output %q", b)
+			}
+
+			if want := min(max(base, tt.want), 19); got != want {
+				t.Errorf("process ran at nice level %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func niceness(t *testing.T) int {
+	t.Helper()
+
+	b, err := exec.Command("nice").Output()
+	if err != nil {
+		t.Skipf("nice is not available: %v", err)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		t.Skipf("unexpected nice output %q", b)
+	}
+
+	return n
+}
@@ -0,0 +1,63 @@
+//go:build !windows
+
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// processExists reports whether a process with the given pid is running.
+func processExists(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	err = p.Signal(syscall.Signal(0))
+
+	// EPERM means the process exists but belongs to another user.
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// setProcessGroup starts cmd in a process group of its own and makes cancellation
+// kill the whole group, so that the processes it spawned do not outlive it.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// joinProcessGroup does nothing: the process group is set up when cmd starts.
+func joinProcessGroup(_ *exec.Cmd) {}
+
+// releaseProcessGroup does nothing: the process group goes away with its processes.
+func releaseProcessGroup(_ *exec.Cmd) {}
+
+// startLowPriority starts cmd and lowers the CPU and, where available, I/O priority
+// of its process group, or of the process itself when it has no group of its own.
+func startLowPriority(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	which := syscall.PRIO_PROCESS
+	if cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid {
+		which = syscall.PRIO_PGRP
+	}
+
+	pid := cmd.Process.Pid
+	if err := syscall.Setpriority(which, pid, lowPriorityNice); err != nil {
+		slog.Debug("failed to lower the CPU priority", "pid", pid, "error", err)
+	} else {
+		slog.Debug("lowered the CPU priority", "pid", pid, "nice", lowPriorityNice)
+	}
+
+	lowerIOPriority(which == syscall.PRIO_PGRP, pid)
+
+	return nil
+}
@@ -0,0 +1,173 @@
+//go:build windows
+
+package main
+
+import (
+	"log/slog"
+	"os/exec"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modkernel32                  = syscall.NewLazyDLL("kernel32.dll")
+	procCreateEventW             = modkernel32.NewProc("CreateEventW")
+	procGetOverlappedResult      = modkernel32.NewProc("GetOverlappedResult")
+	procCreateJobObjectW         = modkernel32.NewProc("CreateJobObjectW")
+	procSetInformationJobObject  = modkernel32.NewProc("SetInformationJobObject")
+	procAssignProcessToJobObject = modkernel32.NewProc("AssignProcessToJobObject")
+	procTerminateJobObject       = modkernel32.NewProc("TerminateJobObject")
+)
+
+// jobs holds the job object of each command prepared by setProcessGroup until it exits.
+var jobs sync.Map
+
+const (
+	// processSetQuota (PROCESS_SET_QUOTA) is the access right AssignProcessToJobObject needs.
+	processSetQuota = 0x0100
+	// jobObjectExtendedLimitInformationClass (JobObjectExtendedLimitInformation) is the
+	// information class of jobObjectExtendedLimitInformation.
+	jobObjectExtendedLimitInformationClass = 9
+	// jobObjectLimitKillOnJobClose (JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE) kills the processes
+	// of a job when its last handle is closed.
+	jobObjectLimitKillOnJobClose = 0x2000
+)
+
+// jobObjectExtendedLimitInformation is JOBOBJECT_EXTENDED_LIMIT_INFORMATION.
+type jobObjectExtendedLimitInformation struct {
+	BasicLimitInformation struct {
+		PerProcessUserTimeLimit int64
+		PerJobUserTimeLimit     int64
+		LimitFlags              uint32
+		MinimumWorkingSetSize   uintptr
+		MaximumWorkingSetSize   uintptr
+		ActiveProcessLimit      uint32
+		Affinity                uintptr
+		PriorityClass           uint32
+		SchedulingClass         uint32
+	}
+	IoInfo struct {
+		ReadOperationCount  uint64
+		WriteOperationCount uint64
+		OtherOperationCount uint64
+		ReadTransferCount   uint64
+		WriteTransferCount  uint64
+		OtherTransferCount  uint64
+	}
+	ProcessMemoryLimit    uintptr
+	JobMemoryLimit        uintptr
+	PeakProcessMemoryUsed uintptr
+	PeakJobMemoryUsed     uintptr
+}
+
+// belowNormalPriorityClass (BELOW_NORMAL_PRIORITY_CLASS) is the process creation flag lowering the priority class.
+const belowNormalPriorityClass = 0x00004000
+
+// stillActive (STILL_ACTIVE) is the exit code GetExitCodeProcess reports for a running process.
+const stillActive = 259
+
+// processExists reports whether a process with the given pid is running.
+func processExists(pid int) bool {
+	h, err := syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// ERROR_ACCESS_DENIED means the process exists but cannot be queried.
+		return err == syscall.ERROR_ACCESS_DENIED
+	}
+	defer syscall.CloseHandle(h)
+
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return true
+	}
+
+	return code == stillActive
+}
+
+// setProcessGroup makes cmd run in a job object of its own and cancellation terminate
+// the job, so that the processes it spawned, such as go.exe compiling packages, do not
+// outlive it. The process joins the job with joinProcessGroup once started: processes
+// it spawns before that escape the job.
+func setProcessGroup(cmd *exec.Cmd) {
+	job, err := newKillOnCloseJob()
+	if err != nil {
+		slog.Debug("failed to create a job object, cancellation only kills the process", "error", err)
+
+		return
+	}
+
+	jobs.Store(cmd, job)
+	cmd.Cancel = func() error {
+		if r, _, err := procTerminateJobObject.Call(uintptr(job), 1); r == 0 {
+			slog.Debug("failed to terminate the job object", "error", err)
+		}
+
+		return cmd.Process.Kill()
+	}
+}
+
+// joinProcessGroup assigns the started process of cmd to the job object of setProcessGroup.
+func joinProcessGroup(cmd *exec.Cmd) {
+	v, ok := jobs.Load(cmd)
+	if !ok {
+		return
+	}
+
+	process, err := syscall.OpenProcess(processSetQuota|syscall.PROCESS_TERMINATE, false, uint32(cmd.Process.Pid))
+	if err != nil {
+		slog.Debug("failed to open the process to assign it to the job object", "pid", cmd.Process.Pid, "error", err)
+
+		return
+	}
+	defer syscall.CloseHandle(process)
+
+	if r, _, err := procAssignProcessToJobObject.Call(uintptr(v.(syscall.Handle)), uintptr(process)); r == 0 {
+		slog.Debug("failed to assign the process to the job object", "pid", cmd.Process.Pid, "error", err)
+	}
+}
+
+// releaseProcessGroup closes the job object of cmd, which kills the processes left in it.
+func releaseProcessGroup(cmd *exec.Cmd) {
+	if v, ok := jobs.LoadAndDelete(cmd); ok {
+		syscall.CloseHandle(v.(syscall.Handle))
+	}
+}
+
+// newKillOnCloseJob creates a job object whose processes are killed when its last
+// handle is closed.
+func newKillOnCloseJob() (syscall.Handle, error) {
+	r, _, err := procCreateJobObjectW.Call(0, 0)
+	if r == 0 {
+		return 0, err
+	}
+	job := syscall.Handle(r)
+
+	var info jobObjectExtendedLimitInformation
+	info.BasicLimitInformation.LimitFlags = jobObjectLimitKillOnJobClose
+
+	if r, _, err := procSetInformationJobObject.Call(uintptr(job), jobObjectExtendedLimitInformationClass,
+		uintptr(unsafe.Pointer(&info)), unsafe.Sizeof(info)); r == 0 {
+		syscall.CloseHandle(job)
+
+		return 0, err
+	}
+
+	return job, nil
+}
+
+// startLowPriority starts cmd in the below normal priority class, inherited by the
+// processes it spawns.
+func startLowPriority(cmd *exec.Cmd) error {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= belowNormalPriorityClass
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	slog.Debug("started in the below normal priority class", "pid", cmd.Process.Pid)
+
+	return nil
+}
@@ -0,0 +1,93 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// helperProcessEnv makes the test binary act as the process tree of a lint: with
+// "parent", it spawns a "child" and writes its pid to the given file; with "child",
+// it sleeps.
+const helperProcessEnv = "GOLANGCI_LINT_LANGSERVER_HELPER"
+
+func TestHelperProcess(t *testing.T) {
+	role, pidFile, _ := strings.Cut(os.Getenv(helperProcessEnv), ":")
+
+	switch role {
+	case "parent":
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+		cmd.Env = append(os.Environ(), helperProcessEnv+"=child:")
+		if err := cmd.Start(); err != nil {
+			os.Exit(1)
+		}
+
+		if err := os.WriteFile(pidFile, []byte(strconv.Itoa(cmd.Process.Pid)), 0o600); err != nil {
+			os.Exit(1)
+		}
+
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	case "child":
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	}
+}
+
+// TestSetProcessGroup tests that cancelling a command kills the processes it spawned.
+func TestSetProcessGroup(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, os.Args[0], "-test.run=^TestHelperProcess$")
+	cmd.Env = append(os.Environ(), helperProcessEnv+"=parent:"+pidFile)
+	setProcessGroup(cmd)
+
+	h := &langHandler{}
+	done := make(chan error, 1)
+	go func() { done <- h.run(cmd) }()
+
+	var pid int
+	deadline := time.Now().Add(30 * time.Second)
+	for pid == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the helper process did not spawn its child")
+		}
+
+		time.Sleep(50 * time.Millisecond)
+
+		if b, err := os.ReadFile(pidFile); err == nil {
+			pid, _ = strconv.Atoi(string(b))
+		}
+	}
+
+	if !processExists(pid) {
+		t.Fatalf("child process %d is not running", pid)
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("run() did not return after cancellation")
+	}
+
+	deadline = time.Now().Add(10 * time.Second)
+	for processExists(pid) {
+		if time.Now().After(deadline) {
+			t.Fatalf("child process %d outlived the cancelled command", pid)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+}
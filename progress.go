@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// beginProgress reports the start of a lint of the document as cancellable work done progress.
+// cancel is called when the client cancels the progress. It returns the progress token,
+// or an empty token when the client does not support server initiated progress.
+func (h *langHandler) beginProgress(ctx context.Context, uri DocumentURI, cancel context.CancelFunc) ProgressToken {
+	if !h.workDoneProgress || h.conn == nil {
+		return ""
+	}
+
+	h.progressMu.Lock()
+	h.nextProgressID++
+	token := ProgressToken(fmt.Sprintf("golangci-lint-langserver/lint/%d", h.nextProgressID))
+	if h.progressCancels == nil {
+		h.progressCancels = make(map[ProgressToken]context.CancelFunc)
+	}
+	h.progressCancels[token] = cancel
+	h.progressMu.Unlock()
+
+	// The response is not awaited: the linter must not wait on the client, and the
+	// create request is delivered before the progress notifications that follow it.
+	if _, err := h.conn.DispatchCall(ctx, "window/workDoneProgress/create", &WorkDoneProgressCreateParams{Token: token}); err != nil {
+		slog.Error("failed to create progress", "error", err)
+	}
+
+	h.notifyProgress(token, &WorkDoneProgressBegin{
+		Kind:        "begin",
+		Title:       "golangci-lint",
+		Cancellable: true,
+		Message:     h.progressTarget(uri),
+	})
+
+	return token
+}
+
+// progressTarget names what the lint of the document runs golangci-lint on in progress
+// messages: its directory, or the document in file mode and in an overlay, relative to
+// the workspace root when below it.
+func (h *langHandler) progressTarget(uri DocumentURI) string {
+	target := uriToPath(string(uri))
+	if h.lintTarget != lintTargetFile && !h.needsOverlay(uri) {
+		target = filepath.Dir(target)
+	}
+
+	return h.workspaceRelative(target)
+}
+
+// workspaceRelative returns target relative to its workspace root, like ./internal/auth,
+// or as it is when outside the workspace.
+func (h *langHandler) workspaceRelative(target string) string {
+	root := h.workspaceRoot(target)
+	if root == "" {
+		return target
+	}
+
+	rel, err := filepath.Rel(root, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return target
+	}
+
+	if rel == "." {
+		return "."
+	}
+
+	return "./" + filepath.ToSlash(rel)
+}
+
+// issueCount describes the number of issues golangci-lint reported in the results of a lint.
+func issueCount(results map[DocumentURI][]Diagnostic) string {
+	n := 0
+	for _, diagnostics := range results {
+		n += len(diagnostics)
+	}
+
+	if n == 1 {
+		return "1 issue"
+	}
+
+	return fmt.Sprintf("%d issues", n)
+}
+
+// endProgress reports the end of the lint started with beginProgress, with an optional
+// message such as "cancelled".
+func (h *langHandler) endProgress(token ProgressToken, message string) {
+	if token == "" {
+		return
+	}
+
+	h.progressMu.Lock()
+	delete(h.progressCancels, token)
+	h.progressMu.Unlock()
+
+	h.notifyProgress(token, &WorkDoneProgressEnd{Kind: "end", Message: message})
+}
+
+// reportProgress reports intermediate progress of the lint started with beginProgress.
+func (h *langHandler) reportProgress(token ProgressToken, message string, percentage int) {
+	if token == "" {
+		return
+	}
+
+	h.notifyProgress(token, &WorkDoneProgressReport{
+		Kind:       "report",
+		Message:    message,
+		Percentage: percentage,
+	})
+}
+
+func (h *langHandler) notifyProgress(token ProgressToken, value any) {
+	if err := h.conn.Notify(context.Background(), "$/progress", &ProgressParams{Token: token, Value: value}); err != nil {
+		slog.Error("failed to report progress", "error", err)
+	}
+}
+
+func (h *langHandler) handleWorkDoneProgressCancel(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	var params WorkDoneProgressCancelParams
+	if err := decodeParams(req, &params); err != nil {
+		return nil, err
+	}
+
+	h.progressMu.Lock()
+	cancel, ok := h.progressCancels[params.Token]
+	h.progressMu.Unlock()
+
+	if ok {
+		slog.Debug("cancelling lint", "token", params.Token)
+		cancel()
+	}
+
+	return nil, nil
+}
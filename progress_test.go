@@ -0,0 +1,33 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLangHandler_progressTarget(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	tests := []struct {
+		name       string
+		lintTarget string
+		path       string
+		want       string
+	}{
+		{name: "root package", path: filepath.Join(root, "main.go"), want: "."},
+		{name: "package below the root", path: filepath.Join(root, "internal", "auth", "auth.go"), want: "./internal/auth"},
+		{name: "file mode", lintTarget: lintTargetFile, path: filepath.Join(root, "internal", "auth.go"), want: "./internal/auth.go"},
+		{name: "outside the workspace", path: filepath.Join(outside, "main.go"), want: outside},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &langHandler{rootDir: root, lintTarget: tt.lintTarget}
+
+			if got := h.progressTarget(pathToURI(tt.path)); got != tt.want {
+				t.Errorf("progressTarget() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+//go:build !windows
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLangHandler_workDoneProgressCancel(t *testing.T) {
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "pid")
+	mainPath := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(mainPath, []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":      string(pathToURI(dir)),
+		"capabilities": map[string]any{"window": map[string]any{"workDoneProgress": true}},
+		"initializationOptions": map[string]any{
+			// A stand-in for golangci-lint that never finishes on its own.
+			"command": []string{"sh", "-c", "echo $$ > " + pidFile + "; exec sleep 30"},
+		},
+	}, nil)
+
+	client.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": string(pathToURI(mainPath)), "languageId": "go", "text": "package main\n"},
+	})
+
+	var progress struct {
+		Token string `json:"token"`
+		Value struct {
+			Kind    string `json:"kind"`
+			Message string `json:"message"`
+		} `json:"value"`
+	}
+	begin := client.waitFor("$/progress", func(params json.RawMessage) bool {
+		return json.Unmarshal(params, &progress) == nil && progress.Value.Kind == "begin"
+	}, 5*time.Second)
+	if err := json.Unmarshal(begin, &progress); err != nil {
+		t.Fatalf("invalid progress: %v", err)
+	}
+
+	var pid int
+	for deadline := time.Now().Add(5 * time.Second); pid == 0; {
+		if b, err := os.ReadFile(pidFile); err == nil {
+			pid, _ = strconv.Atoi(strings.TrimSpace(string(b)))
+		}
+		if pid == 0 && time.Now().After(deadline) {
+			t.Fatal("the lint command did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	client.notify("window/workDoneProgress/cancel", map[string]any{"token": progress.Token})
+
+	client.waitFor("$/progress", func(params json.RawMessage) bool {
+		return json.Unmarshal(params, &progress) == nil && progress.Value.Kind == "end" && progress.Value.Message == "cancelled"
+	}, 5*time.Second)
+
+	if processExists(pid) {
+		t.Errorf("the lint command (pid %d) is still running after cancellation", pid)
+	}
+
+	// Give a late publication the chance to show up.
+	time.Sleep(100 * time.Millisecond)
+	if got := client.received("textDocument/publishDiagnostics"); len(got) != 0 {
+		t.Errorf("diagnostics were published for a cancelled run: %s", got)
+	}
+}
+
+// TestLangHandler_workDoneProgress tests that each lint gets its own progress token,
+// reported from begin to end with the linted directory in the messages.
+func TestLangHandler_workDoneProgress(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "sub", "main.go")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	issue := Issue{FromLinter: "errcheck", Text: "Error return value is not checked"}
+	issue.Pos.Filename, issue.Pos.Line, issue.Pos.Column = "sub/main.go", 1, 1
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":      string(pathToURI(root)),
+		"capabilities": map[string]any{"window": map[string]any{"workDoneProgress": true}},
+		"initializationOptions": map[string]any{
+			"command":    fakeLinter(t, GolangCILintResult{Issues: []Issue{issue}}),
+			"lintOnOpen": false,
+		},
+	}, nil)
+
+	uri := pathToURI(path)
+	h.lintDocument(uri)
+	h.lintDocument(uri)
+
+	type progress struct {
+		Token string `json:"token"`
+		Value struct {
+			Kind    string `json:"kind"`
+			Message string `json:"message"`
+		} `json:"value"`
+	}
+
+	client.waitFor("$/progress", func(json.RawMessage) bool {
+		ends := 0
+		for _, raw := range client.received("$/progress") {
+			var p progress
+			if json.Unmarshal(raw, &p) == nil && p.Value.Kind == "end" {
+				ends++
+			}
+		}
+
+		return ends == 2
+	}, 5*time.Second)
+
+	var tokens []string
+	got := make(map[string][]string)
+	for _, raw := range client.received("$/progress") {
+		var p progress
+		if err := json.Unmarshal(raw, &p); err != nil {
+			t.Fatalf("invalid progress: %v", err)
+		}
+		if _, ok := got[p.Token]; !ok {
+			tokens = append(tokens, p.Token)
+		}
+		got[p.Token] = append(got[p.Token], p.Value.Kind+" "+p.Value.Message)
+	}
+
+	if len(tokens) != 2 {
+		t.Fatalf("got progress tokens %v, want one per lint", tokens)
+	}
+
+	want := []string{"begin ./sub", "report ./sub: 1 issue", "end "}
+	for _, token := range tokens {
+		if diff := cmp.Diff(want, got[token]); diff != "" {
+			t.Errorf("progress of %s mismatch (-want +got):\n%s", token, diff)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLangHandler_publishAll(t *testing.T) {
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"capabilities":          map[string]any{"window": map[string]any{"workDoneProgress": true}},
+		"initializationOptions": map[string]any{"command": []string{"golangci-lint", "run"}, "publishBatchSize": 2},
+	}, nil)
+
+	results := make(map[DocumentURI][]Diagnostic)
+	for i := range 5 {
+		results[DocumentURI(fmt.Sprintf("file:///project/%d.go", i))] = []Diagnostic{}
+	}
+	// The server reads the open documents for its status once initialized.
+	h.openMu.Lock()
+	h.open = map[DocumentURI]string{"file:///project/3.go": languageGo}
+	h.openMu.Unlock()
+
+	h.publishAll("token", results)
+
+	client.waitFor("textDocument/publishDiagnostics", func(params json.RawMessage) bool {
+		return len(client.received("textDocument/publishDiagnostics")) == 5
+	}, 5*time.Second)
+
+	var got []DocumentURI
+	for _, params := range client.received("textDocument/publishDiagnostics") {
+		var p PublishDiagnosticsParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			t.Fatalf("invalid publishDiagnostics params: %v", err)
+		}
+		got = append(got, p.URI)
+	}
+
+	want := []DocumentURI{
+		"file:///project/3.go",
+		"file:///project/0.go",
+		"file:///project/1.go",
+		"file:///project/2.go",
+		"file:///project/4.go",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("publish order mismatch (-want +got):\n%s", diff)
+	}
+
+	if reports := len(client.received("$/progress")); reports != 3 {
+		t.Errorf("expected a progress report per batch (3), got %d", reports)
+	}
+}
+
+// TestLangHandler_publishAllClamps tests that positions past the end of the document are clamped.
+func TestLangHandler_publishAllClamps(t *testing.T) {
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{}, nil)
+
+	uri := DocumentURI("untitled:Untitled-1")
+	h.documents.open(uri, "package main\n")
+
+	pos := Position{Line: 12, Character: 3}
+	h.publishAll("token", map[DocumentURI][]Diagnostic{uri: {{Range: Range{Start: pos, End: pos}, Message: "stale"}}})
+
+	raw := client.waitFor("textDocument/publishDiagnostics", func(json.RawMessage) bool { return true }, 5*time.Second)
+
+	var params PublishDiagnosticsParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		t.Fatalf("invalid publishDiagnostics params: %v", err)
+	}
+
+	want := Position{Line: 1}
+	if len(params.Diagnostics) != 1 || params.Diagnostics[0].Range != (Range{Start: want, End: want}) {
+		t.Errorf("published %+v, want the diagnostic clamped to %+v", params.Diagnostics, want)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// resultID identifies the inputs of a lint of the document: the command, the settings
+// and dismissals shaping its diagnostics, the modification times of the Go files of its
+// directory and of the config. A lint result is still valid as long as the resultID it
+// was computed for is unchanged.
+func (h *langHandler) resultID(uri DocumentURI) string {
+	path := uriToPath(string(uri))
+	dir := filepath.Dir(path)
+
+	hash := sha256.New()
+	for _, arg := range h.command {
+		fmt.Fprintf(hash, "%s\x00", arg)
+	}
+	fmt.Fprintf(hash, "%s\x00%s\x00%d\x00", h.lintTarget, h.effectiveOptions, h.dismissed.version())
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	if h.lintTarget == lintTargetFile {
+		files = []string{path}
+	}
+
+	if config := h.configFileFor(dir); config != "" {
+		files = append(files, config)
+	}
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			fmt.Fprintf(hash, "%s\x00missing\x00", file)
+
+			continue
+		}
+
+		fmt.Fprintf(hash, "%s\x00%d\x00%d\x00", file, info.ModTime().UnixNano(), info.Size())
+	}
+
+	// Documents that only exist in the editor are identified by their content.
+	if h.needsOverlay(uri) {
+		text, _ := h.documents.GetText(uri)
+		fmt.Fprintf(hash, "buffer\x00%s", text)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// handleTextDocumentDiagnostic answers pull diagnostic requests. golangci-lint only runs
+// when the inputs of the cached result of the document changed.
+func (h *langHandler) handleTextDocumentDiagnostic(ctx context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	var params DocumentDiagnosticParams
+	if err := decodeParams(req, &params); err != nil {
+		return nil, err
+	}
+
+	uri := params.TextDocument.URI
+	if err := validateDocumentURI(uri); err != nil {
+		return nil, err
+	}
+
+	if !h.lintable(uri) || h.excluded(uri) {
+		return FullDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindFull, Items: []Diagnostic{}}, nil
+	}
+
+	if path, err := filepath.Abs(uriToPath(string(uri))); err == nil && !isUntitled(uri) && (!h.allowExternalPaths && !h.inWorkspace(path) || h.inTestdata(path)) {
+		return FullDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindFull, Items: []Diagnostic{}}, nil
+	}
+
+	if h.paused.Load() {
+		// The last result stands while paused; its result ID no longer matches once resumed.
+		cached, ok := h.results.get(uri)
+		if !ok {
+			return FullDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindFull, Items: []Diagnostic{}}, nil
+		}
+
+		return FullDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindFull, ResultID: cached.resultID, Items: cached.diagnostics}, nil
+	}
+
+	resultID := h.resultID(uri)
+
+	cached, ok := h.results.get(uri)
+	hit := ok && cached.resultID == resultID
+	h.metrics.cacheLookup(hit)
+
+	if hit {
+		if params.PreviousResultID == resultID {
+			return UnchangedDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindUnchanged, ResultID: resultID}, nil
+		}
+
+		return FullDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindFull, ResultID: resultID, Items: cached.diagnostics}, nil
+	}
+
+	lintCtx := h.lintContext()
+
+	results, err := h.lintWithVet(lintCtx, uri)
+	if lintCtx.Err() != nil {
+		return nil, &jsonrpc2.Error{Code: codeRequestCancelled, Message: "lint cancelled"}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	diagnostics := results[uri]
+	_, failed := failureSignature(diagnostics)
+
+	if h.onlyTouchedLines {
+		diagnostics = h.filterTouched(uri, diagnostics)
+	}
+
+	diagnostics = h.dismissed.filter(uri, diagnostics)
+	diagnostics, _ = h.filterSeverity(uri, diagnostics)
+	diagnostics = h.mergeSameRange(diagnostics)
+
+	h.results.set(uri, diagnostics, resultID)
+
+	report := RelatedFullDocumentDiagnosticReport{
+		FullDocumentDiagnosticReport: FullDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindFull, ResultID: resultID, Items: diagnostics},
+	}
+
+	// The diagnostics of the other files of the package are only reported to clients
+	// accepting related documents; the others request each document.
+	if h.relatedDocuments && !failed {
+		related := make(map[DocumentURI][]Diagnostic)
+		h.addSiblingResults(uri, resultID, results, related)
+
+		for sibling, items := range related {
+			if report.RelatedDocuments == nil {
+				report.RelatedDocuments = make(map[DocumentURI]FullDocumentDiagnosticReport)
+			}
+			report.RelatedDocuments[sibling] = FullDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindFull, ResultID: resultID, Items: items}
+		}
+	}
+
+	return report, nil
+}
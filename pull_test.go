@@ -0,0 +1,194 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLangHandler_pullDiagnostics tests that pull requests only run golangci-lint when the result is stale.
+func TestLangHandler_pullDiagnostics(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake linter needs sh")
+	}
+
+	root := t.TempDir()
+	source := filepath.Join(root, "main.go")
+	config := filepath.Join(root, ".golangci.yml")
+	runs := filepath.Join(t.TempDir(), "runs")
+
+	for _, path := range []string{source, config} {
+		if err := os.WriteFile(path, []byte("\n"), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(root)),
+		"initializationOptions": map[string]any{"command": []string{"sh", "-c", `echo run >> "$0"`, runs}},
+	}, nil)
+
+	countRuns := func() int {
+		b, _ := os.ReadFile(runs)
+
+		return strings.Count(string(b), "run")
+	}
+
+	touch := func(path string, offset time.Duration) {
+		at := time.Now().Add(offset)
+		if err := os.Chtimes(path, at, at); err != nil {
+			t.Fatalf("os.Chtimes() returned unexpected error: %v", err)
+		}
+	}
+
+	var resultID string
+	pull := func() (string, string) {
+		var report struct {
+			Kind     string `json:"kind"`
+			ResultID string `json:"resultId"`
+		}
+		client.call("textDocument/diagnostic", map[string]any{
+			"textDocument":     map[string]any{"uri": pathToURI(source)},
+			"previousResultId": resultID,
+		}, &report)
+
+		return report.Kind, report.ResultID
+	}
+
+	steps := []struct {
+		name     string
+		change   func()
+		wantKind string
+		wantRuns int
+	}{
+		{name: "first pull", change: func() {}, wantKind: "full", wantRuns: 1},
+		{name: "hit", change: func() {}, wantKind: "unchanged", wantRuns: 1},
+		{name: "miss due to edit", change: func() { touch(source, time.Hour) }, wantKind: "full", wantRuns: 2},
+		{name: "hit after edit", change: func() {}, wantKind: "unchanged", wantRuns: 2},
+		{name: "miss due to config change", change: func() { touch(config, 2*time.Hour) }, wantKind: "full", wantRuns: 3},
+		{name: "miss due to settings change", change: func() {
+			client.notify("workspace/didChangeConfiguration", map[string]any{
+				"settings": map[string]any{configurationSection: map[string]any{"excludeMessages": []string{"generated"}}},
+			})
+
+			// The settings apply once the running handlers released settingsMu.
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				h.settingsMu.RLock()
+				applied := strings.Contains(string(h.effectiveOptions), "generated")
+				h.settingsMu.RUnlock()
+
+				if applied || time.Now().After(deadline) {
+					break
+				}
+
+				time.Sleep(10 * time.Millisecond)
+			}
+		}, wantKind: "full", wantRuns: 4},
+		{name: "hit after settings change", change: func() {}, wantKind: "unchanged", wantRuns: 4},
+		{name: "full after dismissal", change: func() {
+			client.call("workspace/executeCommand", map[string]any{
+				"command":   commandDismiss,
+				"arguments": []any{DismissArguments{URI: pathToURI(source), Diagnostic: Diagnostic{Source: pt("errcheck"), Message: "unchecked"}}},
+			}, nil)
+		}, wantKind: "full", wantRuns: 4},
+		{name: "hit after dismissal", change: func() {}, wantKind: "unchanged", wantRuns: 4},
+	}
+
+	for _, step := range steps {
+		step.change()
+
+		kind, id := pull()
+		if kind != step.wantKind {
+			t.Errorf("%s: kind = %q, want %q", step.name, kind, step.wantKind)
+		}
+
+		if id == "" {
+			t.Errorf("%s: empty resultId", step.name)
+		}
+
+		if got := countRuns(); got != step.wantRuns {
+			t.Errorf("%s: golangci-lint ran %d times, want %d", step.name, got, step.wantRuns)
+		}
+
+		resultID = id
+	}
+}
+
+// TestLangHandler_pullRelatedDocuments tests that pull diagnostics are only advertised to
+// clients supporting them, and that the issues of the other files of the package are
+// reported as related documents to clients accepting them.
+func TestLangHandler_pullRelatedDocuments(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities map[string]any
+		wantProvider bool
+		wantRelated  bool
+	}{
+		{name: "push only", capabilities: map[string]any{}},
+		{
+			name:         "pull",
+			capabilities: map[string]any{"textDocument": map[string]any{"diagnostic": map[string]any{}}},
+			wantProvider: true,
+		},
+		{
+			name:         "related documents",
+			capabilities: map[string]any{"textDocument": map[string]any{"diagnostic": map[string]any{"relatedDocumentSupport": true}}},
+			wantProvider: true,
+			wantRelated:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for _, name := range []string{"a.go", "b.go"} {
+				if err := os.WriteFile(filepath.Join(root, name), []byte("package main\n"), 0o600); err != nil {
+					t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+				}
+			}
+
+			issue := Issue{FromLinter: "errcheck", Text: "Error return value is not checked"}
+			issue.Pos.Filename, issue.Pos.Line, issue.Pos.Column = "b.go", 1, 1
+
+			command := fakeLinter(t, GolangCILintResult{Issues: []Issue{issue}})
+
+			h := newLangHandler(false)
+			client := newTestClient(t, h)
+
+			var result InitializeResult
+			client.call("initialize", map[string]any{
+				"rootUri":               string(pathToURI(root)),
+				"capabilities":          tt.capabilities,
+				"initializationOptions": map[string]any{"command": command},
+			}, &result)
+
+			if got := result.Capabilities.DiagnosticProvider != nil; got != tt.wantProvider {
+				t.Errorf("diagnosticProvider advertised = %v, want %v", got, tt.wantProvider)
+			}
+
+			var report RelatedFullDocumentDiagnosticReport
+			client.call("textDocument/diagnostic", map[string]any{
+				"textDocument": map[string]any{"uri": pathToURI(filepath.Join(root, "a.go"))},
+			}, &report)
+
+			if len(report.Items) != 0 {
+				t.Errorf("items = %+v, want none", report.Items)
+			}
+
+			related, ok := report.RelatedDocuments[pathToURI(filepath.Join(root, "b.go"))]
+			if ok != tt.wantRelated {
+				t.Fatalf("related documents = %+v, want b.go reported: %v", report.RelatedDocuments, tt.wantRelated)
+			}
+			if ok && (len(related.Items) != 1 || related.ResultID != report.ResultID) {
+				t.Errorf("related report = %+v, want the issue of b.go with resultId %q", related, report.ResultID)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// commandRecheckLinter lints a document again with a single linter enabled and replaces
+// the diagnostics of that linter with the fresh ones.
+const commandRecheckLinter = "golangci-lint.recheckLinter"
+
+// linterNameRe matches the linter names the command accepts, keeping flags out of the
+// golangci-lint arguments.
+var linterNameRe = regexp.MustCompile(`^\w[\w-]*$`)
+
+// RecheckLinterArguments is the argument of the golangci-lint.recheckLinter command.
+type RecheckLinterArguments struct {
+	URI    DocumentURI `json:"uri"`
+	Linter string      `json:"linter"`
+}
+
+// recheckActions returns an action re-running each linter reported by the diagnostics
+// on the document alone. typecheck is not a linter that can be enabled by itself.
+func recheckActions(uri DocumentURI, diagnostics []Diagnostic) []CodeAction {
+	var actions []CodeAction
+
+	seen := make(map[string]bool)
+	for _, d := range diagnostics {
+		if d.Source == nil || *d.Source == "typecheck" || seen[*d.Source] {
+			continue
+		}
+		seen[*d.Source] = true
+
+		title := "Re-run " + *d.Source + " only on this file"
+		actions = append(actions, CodeAction{
+			Title:       title,
+			Diagnostics: []Diagnostic{d},
+			Command: &Command{
+				Title:     title,
+				Command:   commandRecheckLinter,
+				Arguments: []any{RecheckLinterArguments{URI: uri, Linter: *d.Source}},
+			},
+		})
+	}
+
+	return actions
+}
+
+func (h *langHandler) executeRecheckLinter(ctx context.Context, params ExecuteCommandParams) error {
+	if len(params.Arguments) != 1 {
+		return invalidParams("%s: expected one argument", commandRecheckLinter)
+	}
+
+	var args RecheckLinterArguments
+	if err := json.Unmarshal(params.Arguments[0], &args); err != nil {
+		return invalidParams("%s: invalid argument: %v", commandRecheckLinter, err)
+	}
+
+	if err := validateDocumentURI(args.URI); err != nil {
+		return err
+	}
+
+	if !linterNameRe.MatchString(args.Linter) {
+		return invalidParams("%s: invalid linter %q", commandRecheckLinter, args.Linter)
+	}
+
+	// The lint may take long, its outcome is published when it completes.
+	go h.recheckLinter(h.lintContext(), args.URI, args.Linter)
+
+	return nil
+}
+
+// linterOnlyArgs returns the arguments restricting a run of command to linter:
+// --enable-only with golangci-lint v2, --disable-all --enable with v1, which is
+// recognized by its --out-format flag or by the detected version of the binary.
+func (h *langHandler) linterOnlyArgs(command []string, linter string) []string {
+	v1 := hasOutFormatFlag(command)
+	if args, ok := versionCommand(command); ok {
+		if v, ok := h.binaryVersions.Load(strings.Join(args, "\x00")); ok {
+			v1 = v.(golangciVersion).major == 1
+		}
+	}
+
+	if v1 {
+		return []string{"--disable-all", "--enable=" + linter}
+	}
+
+	return []string{"--enable-only=" + linter}
+}
+
+// hasOutFormatFlag reports whether command sets the v1 --out-format flag.
+func hasOutFormatFlag(command []string) bool {
+	for _, arg := range command {
+		arg = strings.TrimLeft(arg, "-")
+		if arg == "out-format" || strings.HasPrefix(arg, "out-format=") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// recheckLinter lints the document with linter alone and publishes its diagnostics with
+// those of linter replaced by the fresh ones. Failures are shown with window/showMessage
+// and leave the published diagnostics in place.
+func (h *langHandler) recheckLinter(ctx context.Context, uri DocumentURI, linter string) {
+	h.settingsMu.RLock()
+	defer h.settingsMu.RUnlock()
+
+	// The inputs are identified before the run, so that edits made meanwhile invalidate the result.
+	resultID := h.resultID(uri)
+
+	slog.Info("re-checking a single linter", "uri", uri, "linter", linter)
+
+	results, err := h.lint(ctx, uri, h.linterOnlyArgs(h.command, linter)...)
+	if ctx.Err() != nil {
+		slog.Info("re-check cancelled", "uri", uri, "linter", linter)
+
+		return
+	}
+
+	if err != nil {
+		h.showMessage(ctx, MTError, fmt.Sprintf("golangci-lint-langserver: cannot re-run %s: %v", linter, err))
+
+		return
+	}
+
+	fresh := results[uri]
+	if signature, failed := failureSignature(fresh); failed {
+		h.showMessage(ctx, MTError, fmt.Sprintf("golangci-lint failed re-running %s: %s", linter, signature))
+
+		return
+	}
+
+	if h.onlyTouchedLines {
+		fresh = h.filterTouched(uri, fresh)
+	}
+
+	current, _ := h.results.get(uri)
+	diagnostics := h.dismissed.filter(uri, mergeLinterDiagnostics(expandMerged(current.diagnostics), fresh, linter))
+	diagnostics, _ = h.filterSeverity(uri, diagnostics)
+	diagnostics = h.mergeSameRange(diagnostics)
+
+	h.results.set(uri, diagnostics, resultID)
+	h.publishAll("", map[DocumentURI][]Diagnostic{uri: diagnostics})
+}
+
+// mergeLinterDiagnostics returns the current diagnostics with those of linter replaced
+// by the ones of linter in fresh. The diagnostics of other linters are kept as they are;
+// those a run restricted to linter still reports, such as typecheck errors, are dropped.
+func mergeLinterDiagnostics(current, fresh []Diagnostic, linter string) []Diagnostic {
+	merged := make([]Diagnostic, 0, len(current)+len(fresh))
+
+	for _, d := range current {
+		if d.Source == nil || *d.Source != linter {
+			merged = append(merged, d)
+		}
+	}
+
+	for _, d := range fresh {
+		if d.Source != nil && *d.Source == linter {
+			merged = append(merged, d)
+		}
+	}
+
+	return merged
+}
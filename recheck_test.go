@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMergeLinterDiagnostics(t *testing.T) {
+	at := func(source string, line int) Diagnostic {
+		d := Diagnostic{Range: Range{Start: Position{Line: line}, End: Position{Line: line}}, Message: "issue"}
+		if source != "" {
+			d.Source = pt(source)
+		}
+
+		return d
+	}
+
+	tests := []struct {
+		name    string
+		current []Diagnostic
+		fresh   []Diagnostic
+		want    []Diagnostic
+	}{
+		{
+			name:    "replaces the linter subset",
+			current: []Diagnostic{at("errcheck", 1), at("govet", 2), at("errcheck", 3)},
+			fresh:   []Diagnostic{at("errcheck", 3), at("errcheck", 4)},
+			want:    []Diagnostic{at("govet", 2), at("errcheck", 3), at("errcheck", 4)},
+		},
+		{
+			name:    "linter no longer reporting",
+			current: []Diagnostic{at("errcheck", 1), at("govet", 2)},
+			fresh:   []Diagnostic{},
+			want:    []Diagnostic{at("govet", 2)},
+		},
+		{
+			name:    "linter newly reporting",
+			current: []Diagnostic{at("govet", 2)},
+			fresh:   []Diagnostic{at("errcheck", 1)},
+			want:    []Diagnostic{at("govet", 2), at("errcheck", 1)},
+		},
+		{
+			name:    "other diagnostics of the run",
+			current: []Diagnostic{at("", 0), at("typecheck", 5)},
+			fresh:   []Diagnostic{at("typecheck", 6), at("errcheck", 1)},
+			want:    []Diagnostic{at("", 0), at("typecheck", 5), at("errcheck", 1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeLinterDiagnostics(tt.current, tt.fresh, "errcheck")
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("mergeLinterDiagnostics() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLangHandler_linterOnlyArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+		version *golangciVersion
+		want    []string
+	}{
+		{name: "v2", command: []string{"golangci-lint", "run", "--output.json.path", "stdout"}, want: []string{"--enable-only=errcheck"}},
+		{name: "v1", command: []string{"golangci-lint", "run", "--out-format=json"}, want: []string{"--disable-all", "--enable=errcheck"}},
+		{name: "v1 separate value", command: []string{"golangci-lint", "run", "--out-format", "json"}, want: []string{"--disable-all", "--enable=errcheck"}},
+		{
+			name:    "detected v1 binary",
+			command: []string{"golangci-lint", "run"},
+			version: &golangciVersion{full: "1.64.8", major: 1},
+			want:    []string{"--disable-all", "--enable=errcheck"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &langHandler{}
+			if tt.version != nil {
+				h.binaryVersions.Store("golangci-lint\x00version", *tt.version)
+			}
+
+			if diff := cmp.Diff(tt.want, h.linterOnlyArgs(tt.command, "errcheck")); diff != "" {
+				t.Errorf("linterOnlyArgs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestLangHandler_recheckLinter tests that re-running a linter replaces its diagnostics
+// and leaves those of the other linters published.
+func TestLangHandler_recheckLinter(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	text := "package main\n\nfunc main() {\n\tf()\n\tg()\n}\n"
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	issue := func(linter string, line int) Issue {
+		i := Issue{FromLinter: linter, Text: "issue"}
+		i.Pos.Filename, i.Pos.Line, i.Pos.Column = "main.go", line, 2
+
+		return i
+	}
+
+	// The full run reports both linters; the run restricted to errcheck only reports g().
+	full := fakeLinter(t, GolangCILintResult{Issues: []Issue{issue("errcheck", 4), issue("errcheck", 5), issue("govet", 3)}})[3]
+	only := fakeLinter(t, GolangCILintResult{Issues: []Issue{issue("errcheck", 5)}})[3]
+	command := []string{"sh", "-c", `for a; do [ "$a" = --enable-only=errcheck ] && { cat "$1"; exit 1; }; done; cat "$0"; exit 1`, full, only}
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(root)),
+		"initializationOptions": map[string]any{"command": command},
+	}, nil)
+
+	published := func(want map[string][]int) func(json.RawMessage) bool {
+		return func(raw json.RawMessage) bool {
+			var params PublishDiagnosticsParams
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return false
+			}
+
+			got := make(map[string][]int)
+			for _, d := range params.Diagnostics {
+				got[*d.Source] = append(got[*d.Source], d.Range.Start.Line)
+			}
+
+			return cmp.Equal(want, got)
+		}
+	}
+
+	uri := pathToURI(path)
+	client.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": text},
+	})
+
+	client.waitFor("textDocument/publishDiagnostics", published(map[string][]int{"errcheck": {3, 4}, "govet": {2}}), 5*time.Second)
+
+	client.call("workspace/executeCommand", map[string]any{
+		"command":   commandRecheckLinter,
+		"arguments": []any{RecheckLinterArguments{URI: uri, Linter: "errcheck"}},
+	}, nil)
+
+	client.waitFor("textDocument/publishDiagnostics", published(map[string][]int{"govet": {2}, "errcheck": {4}}), 5*time.Second)
+}
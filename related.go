@@ -0,0 +1,150 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// duplRe matches dupl messages such as "18-25 lines are duplicate of `foo/bar.go:40-47`".
+	duplRe = regexp.MustCompile("lines are duplicate of `(.+):(\\d+)-(\\d+)`")
+	// goconstRe matches goconst messages such as "string `foo` has 3 occurrences, make it a constant".
+	goconstRe = regexp.MustCompile("^string `(.*)` has \\d+ occurrences")
+)
+
+// relatedInformation extracts the secondary locations referenced by multi-location issues.
+// absPath is the file the issue belongs to and baseDirs are used to resolve relative paths.
+// References that cannot be resolved, or messages in another format, are left to the
+// message text.
+func relatedInformation(issue *Issue, absPath string, baseDirs []string) []DiagnosticRelatedInformation {
+	switch issue.FromLinter {
+	case "dupl":
+		return duplRelatedInformation(issue, baseDirs)
+	case "goconst":
+		return goconstRelatedInformation(issue, absPath)
+	}
+
+	return nil
+}
+
+func duplRelatedInformation(issue *Issue, baseDirs []string) []DiagnosticRelatedInformation {
+	m := duplRe.FindStringSubmatch(issue.Text)
+	if m == nil {
+		return nil
+	}
+
+	path, ok := resolveIssuePath(filepath.FromSlash(m[1]), baseDirs)
+	if !ok {
+		return nil
+	}
+
+	from, errFrom := strconv.Atoi(m[2])
+	to, errTo := strconv.Atoi(m[3])
+	if errFrom != nil || errTo != nil || from < 1 || to < from {
+		return nil
+	}
+
+	// The range covers the last line too, like the range of the issue itself.
+	src, _ := readSourceLines(path)
+
+	return []DiagnosticRelatedInformation{
+		{
+			Location: Location{
+				URI: string(pathToURI(path)),
+				Range: Range{
+					Start: Position{Line: from - 1},
+					End:   src.lineEnd(to),
+				},
+			},
+			Message: "duplicate code",
+		},
+	}
+}
+
+// goconstRelatedInformation locates the other occurrences of the repeated string in the package directory.
+func goconstRelatedInformation(issue *Issue, absPath string) []DiagnosticRelatedInformation {
+	m := goconstRe.FindStringSubmatch(issue.Text)
+	if m == nil {
+		return nil
+	}
+
+	literal := strconv.Quote(m[1])
+
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(absPath), "*.go"))
+	if err != nil {
+		return nil
+	}
+
+	src, _ := readSourceLines(absPath)
+	issuePos := src.position(issue.Pos.Line, issue.Pos.Column)
+
+	var related []DiagnosticRelatedInformation
+
+	for _, file := range files {
+		for _, pos := range findLiteral(file, literal) {
+			if file == absPath && pos == issuePos {
+				continue
+			}
+
+			related = append(related, DiagnosticRelatedInformation{
+				Location: Location{
+					URI:   string(pathToURI(file)),
+					Range: Range{Start: pos, End: Position{Line: pos.Line, Character: pos.Character + utf16Len(literal)}},
+				},
+				Message: "other occurrence of " + literal,
+			})
+		}
+	}
+
+	return related
+}
+
+// findLiteral returns the positions of literal in the file.
+func findLiteral(path, literal string) []Position {
+	src, err := readSourceLines(path)
+	if err != nil {
+		return nil
+	}
+
+	var positions []Position
+
+	for line, text := range src.lines {
+		offset := 0
+		for {
+			i := strings.Index(text[offset:], literal)
+			if i < 0 {
+				break
+			}
+
+			positions = append(positions, Position{Line: line, Character: src.character(line, offset+i)})
+			offset += i + len(literal)
+		}
+	}
+
+	return positions
+}
+
+// resolveIssuePath returns the first existing file that issuePath refers to.
+func resolveIssuePath(issuePath string, baseDirs []string) (string, bool) {
+	if filepath.IsAbs(issuePath) {
+		_, err := os.Stat(issuePath)
+
+		return filepath.Clean(issuePath), err == nil
+	}
+
+	for _, baseDir := range baseDirs {
+		path, err := filepath.Abs(filepath.Join(baseDir, issuePath))
+		if err != nil {
+			continue
+		}
+
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+
+	return "", false
+}
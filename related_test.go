@@ -0,0 +1,197 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRelatedInformation(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"main.go":  "package main\n\nvar a = \"foo\"\nvar b = \"foo\"\n",
+		"other.go": "package main\n\nfunc f() string {\n\treturn \"foo\"\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	mainPath := filepath.Join(dir, "main.go")
+	otherPath := filepath.Join(dir, "other.go")
+
+	tests := []struct {
+		name  string
+		issue Issue
+		want  []DiagnosticRelatedInformation
+	}{
+		{
+			name: "dupl resolved against base directory",
+			issue: Issue{
+				FromLinter: "dupl",
+				Text:       "3-4 lines are duplicate of `other.go:3-4`",
+			},
+			want: []DiagnosticRelatedInformation{
+				{
+					Location: Location{
+						URI: string(pathToURI(otherPath)),
+						Range: Range{
+							Start: Position{Line: 2},
+							End:   Position{Line: 3, Character: 13},
+						},
+					},
+					Message: "duplicate code",
+				},
+			},
+		},
+		{
+			name: "dupl up to the last line",
+			issue: Issue{
+				FromLinter: "dupl",
+				Text:       "3-5 lines are duplicate of `other.go:3-5`",
+			},
+			want: []DiagnosticRelatedInformation{
+				{
+					Location: Location{
+						URI: string(pathToURI(otherPath)),
+						Range: Range{
+							Start: Position{Line: 2},
+							End:   Position{Line: 4, Character: 1},
+						},
+					},
+					Message: "duplicate code",
+				},
+			},
+		},
+		{
+			name: "dupl past the end of the file",
+			issue: Issue{
+				FromLinter: "dupl",
+				Text:       "3-8 lines are duplicate of `other.go:3-8`",
+			},
+			want: []DiagnosticRelatedInformation{
+				{
+					Location: Location{
+						URI: string(pathToURI(otherPath)),
+						Range: Range{
+							Start: Position{Line: 2},
+							End:   Position{Line: 8},
+						},
+					},
+					Message: "duplicate code",
+				},
+			},
+		},
+		{
+			name: "dupl with unresolvable file",
+			issue: Issue{
+				FromLinter: "dupl",
+				Text:       "3-4 lines are duplicate of `missing.go:3-5`",
+			},
+			want: nil,
+		},
+		{
+			name: "dupl with a reversed line range",
+			issue: Issue{
+				FromLinter: "dupl",
+				Text:       "3-4 lines are duplicate of `other.go:5-3`",
+			},
+			want: nil,
+		},
+		{
+			name: "dupl in another format",
+			issue: Issue{
+				FromLinter: "dupl",
+				Text:       "3-4 lines are duplicate of other.go",
+			},
+			want: nil,
+		},
+		{
+			name: "goconst in another format",
+			issue: Issue{
+				FromLinter: "goconst",
+				Text:       "repeated string `foo`, make it a constant",
+			},
+			want: nil,
+		},
+		{
+			name: "goconst other occurrences",
+			issue: Issue{
+				FromLinter: "goconst",
+				Text:       "string `foo` has 3 occurrences, make it a constant",
+			},
+			want: []DiagnosticRelatedInformation{
+				{
+					Location: Location{
+						URI: string(pathToURI(mainPath)),
+						Range: Range{
+							Start: Position{Line: 3, Character: 8},
+							End:   Position{Line: 3, Character: 13},
+						},
+					},
+					Message: "other occurrence of \"foo\"",
+				},
+				{
+					Location: Location{
+						URI: string(pathToURI(otherPath)),
+						Range: Range{
+							Start: Position{Line: 3, Character: 8},
+							End:   Position{Line: 3, Character: 13},
+						},
+					},
+					Message: "other occurrence of \"foo\"",
+				},
+			},
+		},
+		{
+			name: "other linters",
+			issue: Issue{
+				FromLinter: "unused",
+				Text:       "var a is unused",
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.issue.Pos.Filename = "main.go"
+			tt.issue.Pos.Line = 3
+			tt.issue.Pos.Column = 9
+
+			got := relatedInformation(&tt.issue, mainPath, []string{filepath.Join(dir, "missing"), dir})
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("relatedInformation() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestLangHandler_issueDiagnosticRelatedInformation tests that related information is
+// only attached for clients supporting it.
+func TestLangHandler_issueDiagnosticRelatedInformation(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"main.go", "other.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("package main\n"), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	mainPath := filepath.Join(dir, "main.go")
+
+	issue := Issue{FromLinter: "dupl", Text: "1-1 lines are duplicate of `other.go:1-1`"}
+	issue.Pos.Filename, issue.Pos.Line, issue.Pos.Column = "main.go", 1, 1
+
+	for _, supported := range []bool{false, true} {
+		h := &langHandler{relatedInformation: supported}
+
+		d := h.issueDiagnostic(&issue, mainPath, sourceLines{}, []string{dir})
+		if got := len(d.RelatedInformation) > 0; got != supported {
+			t.Errorf("relatedInformation %v: related information = %+v", supported, d.RelatedInformation)
+		}
+	}
+}
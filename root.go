@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// moduleRoot returns the directory of the go.mod closest above dir, or dir without one.
+func moduleRoot(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return d
+		}
+
+		if filepath.Dir(d) == d {
+			return dir
+		}
+	}
+}
+
+// gitRoot returns the root of the git repository containing dir, where .git is a
+// directory or, in worktrees and submodules, a file. It returns "" outside repositories.
+func gitRoot(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+
+		if filepath.Dir(d) == d {
+			return ""
+		}
+	}
+}
+
+// resolveRoot returns the workspace root for the rootUri of the initialize request and
+// whether the server runs without a workspace. Clients opening a single file send no
+// rootUri or the file itself; the module of the file stands in for the workspace then.
+func resolveRoot(rootURI string) (string, bool) {
+	if rootURI == "" {
+		return "", true
+	}
+
+	path := uriToPath(rootURI)
+
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return path, false
+	}
+
+	return moduleRoot(filepath.Dir(path)), true
+}
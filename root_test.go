@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResolveRoot(t *testing.T) {
+	module := t.TempDir()
+	dir := filepath.Join(module, "cmd", "app")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+	}
+
+	for path, content := range map[string]string{
+		filepath.Join(module, "go.mod"): "module example.com/app\n",
+		filepath.Join(dir, "main.go"):   "package main\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	outside := t.TempDir()
+	loose := filepath.Join(outside, "scratch.go")
+	if err := os.WriteFile(loose, []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		rootURI        string
+		wantRoot       string
+		wantSingleFile bool
+	}{
+		{name: "directory", rootURI: string(pathToURI(module)), wantRoot: module},
+		{name: "missing", wantSingleFile: true},
+		{name: "file in a module", rootURI: string(pathToURI(filepath.Join(dir, "main.go"))), wantRoot: module, wantSingleFile: true},
+		{name: "file outside a module", rootURI: string(pathToURI(loose)), wantRoot: outside, wantSingleFile: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, singleFile := resolveRoot(tt.rootURI)
+			if root != tt.wantRoot || singleFile != tt.wantSingleFile {
+				t.Errorf("resolveRoot() = (%q, %v), want (%q, %v)", root, singleFile, tt.wantRoot, tt.wantSingleFile)
+			}
+		})
+	}
+}
+
+// TestLangHandler_fileRootURI tests that a file opened as the workspace is linted from its module.
+func TestLangHandler_fileRootURI(t *testing.T) {
+	module := t.TempDir()
+	dir := filepath.Join(module, "cmd", "app")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+	}
+
+	path := filepath.Join(dir, "main.go")
+	for p, content := range map[string]string{
+		filepath.Join(module, "go.mod"): "module example.com/app\n",
+		path:                            "package main\n\nfunc main() {}\n",
+	} {
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	// Issue paths are relative to the module, where golangci-lint runs.
+	issue := Issue{FromLinter: "unused", Text: "func `main` is unused"}
+	issue.Pos.Filename = "cmd/app/main.go"
+	issue.Pos.Line = 3
+	issue.Pos.Column = 6
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	uri := pathToURI(path)
+	client.call("initialize", map[string]any{
+		"rootUri":               string(uri),
+		"initializationOptions": map[string]any{"command": fakeLinter(t, GolangCILintResult{Issues: []Issue{issue}})},
+	}, nil)
+
+	client.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": "package main\n\nfunc main() {}\n"},
+	})
+
+	raw := client.waitFor("textDocument/publishDiagnostics", func(json.RawMessage) bool { return true }, 5*time.Second)
+
+	var params PublishDiagnosticsParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+	}
+
+	if params.URI != uri || len(params.Diagnostics) != 1 || params.Diagnostics[0].Range.Start.Line != 2 {
+		t.Errorf("unexpected diagnostics for %s: %+v", params.URI, params.Diagnostics)
+	}
+}
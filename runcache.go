@@ -0,0 +1,126 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// maxCachedRuns is the number of lint targets whose last results are kept.
+const maxCachedRuns = 64
+
+// runCache holds the results of the last successful golangci-lint run of each target,
+// as returned by lintKey, so that opening several files of a package runs golangci-lint
+// once. The results are those of lint, before the filters of the document are applied.
+type runCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// lru orders the entries from the most to the least recently used.
+	lru list.List
+}
+
+type cachedRun struct {
+	key string
+	// resultID identifies the inputs of the run, see langHandler.resultID.
+	resultID string
+	results  map[DocumentURI][]Diagnostic
+}
+
+// get returns the results of the run of key if its inputs are still those of resultID.
+func (c *runCache) get(key, resultID string) (map[DocumentURI][]Diagnostic, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || e.Value.(*cachedRun).resultID != resultID {
+		return nil, false
+	}
+
+	c.lru.MoveToFront(e)
+
+	// The callers append to the diagnostics, which must not reach the cached arrays.
+	results := maps.Clone(e.Value.(*cachedRun).results)
+	for uri, diagnostics := range results {
+		results[uri] = slices.Clip(diagnostics)
+	}
+
+	return results, true
+}
+
+func (c *runCache) set(key, resultID string, results map[DocumentURI][]Diagnostic) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]*list.Element)
+	}
+
+	run := &cachedRun{key: key, resultID: resultID, results: results}
+	if e, ok := c.entries[key]; ok {
+		e.Value = run
+		c.lru.MoveToFront(e)
+	} else {
+		c.entries[key] = c.lru.PushFront(run)
+	}
+
+	for len(c.entries) > maxCachedRuns {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.entries, e.Value.(*cachedRun).key)
+	}
+}
+
+// invalidate drops the results of key.
+func (c *runCache) invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.lru.Remove(e)
+		delete(c.entries, key)
+	}
+}
+
+// clear drops every entry.
+func (c *runCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = nil
+	c.lru.Init()
+}
+
+// lintCached returns the results of the last run of the target of the document when
+// its inputs, identified by resultID, did not change since. Otherwise golangci-lint
+// runs, and its results are kept unless it failed.
+func (h *langHandler) lintCached(ctx context.Context, uri DocumentURI, resultID string) (map[DocumentURI][]Diagnostic, error) {
+	key := h.lintKey(uri)
+
+	if results, ok := h.runs.get(key, resultID); ok {
+		slog.Debug("lint cache hit, not running golangci-lint", "uri", uri, "target", key)
+		h.metrics.cacheLookup(true)
+
+		if _, ok := results[uri]; !ok {
+			results[uri] = []Diagnostic{}
+		}
+
+		return results, nil
+	}
+
+	slog.Debug("lint cache miss", "uri", uri, "target", key)
+	h.metrics.cacheLookup(false)
+
+	results, err := h.lintWithVet(ctx, uri)
+	if err != nil || ctx.Err() != nil {
+		return results, err
+	}
+
+	if _, failed := failureSignature(results[uri]); !failed {
+		h.runs.set(key, resultID, results)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunCache(t *testing.T) {
+	results := func() map[DocumentURI][]Diagnostic {
+		return map[DocumentURI][]Diagnostic{"file:///p/a.go": {{Message: "a"}}}
+	}
+
+	tests := []struct {
+		name string
+		// change is applied to the cache holding the results of "/p" for resultID "1".
+		change  func(c *runCache)
+		key     string
+		id      string
+		wantHit bool
+	}{
+		{name: "hit", change: func(*runCache) {}, key: "/p", id: "1", wantHit: true},
+		{name: "inputs changed", change: func(*runCache) {}, key: "/p", id: "2"},
+		{name: "other target", change: func(*runCache) {}, key: "/q", id: "1"},
+		{name: "invalidated", change: func(c *runCache) { c.invalidate("/p") }, key: "/p", id: "1"},
+		{name: "cleared", change: func(c *runCache) { c.clear() }, key: "/p", id: "1"},
+		{
+			name: "evicted",
+			change: func(c *runCache) {
+				for i := range maxCachedRuns {
+					c.set(fmt.Sprintf("/d%d", i), "1", results())
+				}
+			},
+			key: "/p",
+			id:  "1",
+		},
+		{
+			name: "used entries are kept",
+			change: func(c *runCache) {
+				for i := range maxCachedRuns {
+					c.get("/p", "1")
+					c.set(fmt.Sprintf("/d%d", i), "1", results())
+				}
+			},
+			key:     "/p",
+			id:      "1",
+			wantHit: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c runCache
+			c.set("/p", "1", results())
+			tt.change(&c)
+
+			got, ok := c.get(tt.key, tt.id)
+			if ok != tt.wantHit {
+				t.Fatalf("get() hit = %v, want %v", ok, tt.wantHit)
+			}
+
+			if !ok {
+				return
+			}
+
+			// Appending to the returned diagnostics leaves the cached ones alone.
+			got["file:///p/a.go"] = append(got["file:///p/a.go"], Diagnostic{Message: "vet"})
+			if again, _ := c.get(tt.key, tt.id); len(again["file:///p/a.go"]) != 1 {
+				t.Errorf("cached results changed to %+v", again)
+			}
+		})
+	}
+}
+
+// TestLangHandler_runCache tests that lints of the files of a package whose inputs did
+// not change run golangci-lint once, and that a save runs it again.
+func TestLangHandler_runCache(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake linter needs sh")
+	}
+
+	root := t.TempDir()
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("package main\n"), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	runs := filepath.Join(t.TempDir(), "runs")
+	command := []string{"sh", "-c", `echo run >> "$0"`, runs}
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(root)),
+		"initializationOptions": map[string]any{"command": command, "lintOnOpen": false, "debounceMs": 0},
+	}, nil)
+
+	countRuns := func() int {
+		b, _ := os.ReadFile(runs)
+
+		return strings.Count(string(b), "run")
+	}
+
+	a, b := pathToURI(filepath.Join(root, "a.go")), pathToURI(filepath.Join(root, "b.go"))
+	h.lintDocument(a)
+	h.lintDocument(b)
+
+	if got := countRuns(); got != 1 {
+		t.Errorf("golangci-lint ran %d times for two files of a package, want once", got)
+	}
+
+	before := len(client.received("textDocument/publishDiagnostics"))
+	client.notify("textDocument/didSave", map[string]any{"textDocument": map[string]any{"uri": b}})
+	client.waitFor("textDocument/publishDiagnostics", func(json.RawMessage) bool {
+		return len(client.received("textDocument/publishDiagnostics")) > before
+	}, 5*time.Second)
+
+	if got := countRuns(); got != 2 {
+		t.Errorf("golangci-lint ran %d times after a save, want twice", got)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// sarifLog is the subset of a SARIF 2.1.0 log, as written by the sarif output format of
+// golangci-lint, the server reads.
+type sarifLog struct {
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Results []sarifResult `json:"results"`
+}
+
+type sarifResult struct {
+	// RuleID is the name of the linter reporting the result.
+	RuleID  string `json:"ruleId"`
+	Level   string `json:"level"`
+	Message struct {
+		Text string `json:"text"`
+	} `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region struct {
+			StartLine   int `json:"startLine"`
+			StartColumn int `json:"startColumn"`
+		} `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+// sarifLevels map the levels of SARIF results onto the severity names of -severity.
+// A result without a level is a warning.
+var sarifLevels = map[string]string{
+	"":        "warning",
+	"error":   "error",
+	"warning": "warning",
+	"note":    "info",
+	"none":    "hint",
+}
+
+// parseSARIFOutput reads the results of a SARIF 2.1.0 log as issues. It reports false
+// when the output is not a SARIF log, such as the JSON output of golangci-lint.
+func parseSARIFOutput(b []byte) (GolangCILintResult, bool) {
+	var log sarifLog
+	if err := json.Unmarshal(b, &log); err != nil || !strings.HasPrefix(log.Version, "2.1") {
+		return GolangCILintResult{}, false
+	}
+
+	var result GolangCILintResult
+	for _, run := range log.Runs {
+		for _, r := range run.Results {
+			var issue Issue
+			issue.FromLinter = r.RuleID
+			issue.Text = r.Message.Text
+			issue.Severity = sarifLevels[r.Level]
+
+			if len(r.Locations) > 0 {
+				loc := r.Locations[0].PhysicalLocation
+				// Artifacts are file URIs or paths relative to the working directory.
+				issue.Pos.Filename = uriToPath(loc.ArtifactLocation.URI)
+				issue.Pos.Line = loc.Region.StartLine
+				issue.Pos.Column = loc.Region.StartColumn
+			}
+
+			result.Issues = append(result.Issues, issue)
+		}
+	}
+
+	return result, true
+}
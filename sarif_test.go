@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseSARIFOutput(t *testing.T) {
+	type issue struct {
+		path     string
+		line     int
+		column   int
+		linter   string
+		severity string
+		text     string
+	}
+
+	report, err := os.ReadFile(filepath.Join("testdata", "sarif", "report.sarif"))
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		want   []issue
+		wantOK bool
+	}{
+		{
+			name:   "golangci-lint report",
+			output: string(report),
+			want: []issue{
+				{path: "main.go", line: 13, column: 15, linter: "errcheck", severity: "error", text: "Error return value of `f.Close` is not checked"},
+				{path: filepath.FromSlash("internal/store/db.go"), line: 7, column: 9, linter: "gosec", severity: "warning", text: "G304: Potential file inclusion via variable"},
+				{path: filepath.FromSlash("internal/store/db.go"), line: 6, column: 1, linter: "revive", severity: "info", text: "exported: exported function Open should have comment or be unexported"},
+			},
+			wantOK: true,
+		},
+		{
+			name:   "no results",
+			output: `{"version":"2.1.0","runs":[{"tool":{"driver":{"name":"golangci-lint"}},"results":[]}]}`,
+			wantOK: true,
+		},
+		{
+			name:   "file URI without level",
+			output: `{"version":"2.1.0","runs":[{"results":[{"ruleId":"unused","message":{"text":"func f is unused"},"locations":[{"physicalLocation":{"artifactLocation":{"uri":"file:///src/app/main%20file.go"},"region":{"startLine":3}}}]}]}]}`,
+			want:   []issue{{path: filepath.FromSlash("/src/app/main file.go"), line: 3, linter: "unused", severity: "warning", text: "func f is unused"}},
+			wantOK: true,
+		},
+		{
+			name:   "golangci-lint JSON",
+			output: `{"Issues":[{"FromLinter":"errcheck","Text":"unchecked","Pos":{"Filename":"main.go","Line":1,"Column":1}}]}`,
+		},
+		{
+			name:   "not JSON",
+			output: "main.go:1:1: unchecked (errcheck)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := parseSARIFOutput([]byte(tt.output))
+			if ok != tt.wantOK {
+				t.Fatalf("parseSARIFOutput() ok = %v, want %v", ok, tt.wantOK)
+			}
+
+			var got []issue
+			for _, i := range result.Issues {
+				got = append(got, issue{path: i.Pos.Filename, line: i.Pos.Line, column: i.Pos.Column, linter: i.FromLinter, severity: i.Severity, text: i.Text})
+			}
+
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(issue{})); diff != "" {
+				t.Errorf("parseSARIFOutput() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestLangHandler_lintSARIF tests that SARIF written by golangci-lint becomes the
+// diagnostics of the linted file, with the severities of the result levels.
+func TestLangHandler_lintSARIF(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake golangci-lint needs sh")
+	}
+
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	report, err := filepath.Abs(filepath.Join("testdata", "sarif", "report.sarif"))
+	if err != nil {
+		t.Fatalf("filepath.Abs() returned unexpected error: %v", err)
+	}
+
+	h := &langHandler{
+		rootDir: root,
+		command: []string{"sh", "-c", `cat "$0"; exit 1`, report, "--output.sarif.path=stdout"},
+	}
+
+	results, err := h.lint(context.Background(), pathToURI(path))
+	if err != nil {
+		t.Fatalf("lint() returned unexpected error: %v", err)
+	}
+
+	diagnostics := results[pathToURI(path)]
+
+	want := []Diagnostic{{
+		Range:    Range{Start: Position{Line: 12, Character: 14}, End: Position{Line: 12, Character: 14}},
+		Severity: DSError,
+		Source:   pt("errcheck"),
+		Message:  "errcheck: Error return value of `f.Close` is not checked",
+	}}
+	if diff := cmp.Diff(want, diagnostics); diff != "" {
+		t.Errorf("lint() mismatch (-want +got):\n%s", diff)
+	}
+}
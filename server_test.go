@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// testClient is an LSP client connected to a langHandler through an in-memory pipe.
+// It records every notification and request the server sends.
+type testClient struct {
+	t    *testing.T
+	conn *jsonrpc2.Conn
+
+	mu            sync.Mutex
+	notifications []*jsonrpc2.Request
+	notified      chan struct{}
+	replies       sync.WaitGroup
+	// results are the replies to server requests by method; other requests get null.
+	results map[string]any
+}
+
+func newTestClient(t *testing.T, h *langHandler) *testClient {
+	t.Helper()
+
+	serverSide, clientSide := net.Pipe()
+
+	ctx := context.Background()
+	serverConn := jsonrpc2.NewConn(ctx, jsonrpc2.NewBufferedStream(serverSide, jsonrpc2.VSCodeObjectCodec{}), h)
+
+	c := &testClient{t: t, notified: make(chan struct{}, 1)}
+	c.conn = jsonrpc2.NewConn(ctx, jsonrpc2.NewBufferedStream(clientSide, jsonrpc2.VSCodeObjectCodec{}), c)
+
+	t.Cleanup(func() {
+		// A reply processed after the connection closed would complete an already
+		// closed call, so the replies are flushed with a round trip first: the server
+		// reads messages in order.
+		c.replies.Wait()
+		syncCtx, cancel := context.WithTimeout(ctx, time.Second)
+		_ = c.conn.Call(syncCtx, "$/sync", nil, nil)
+		cancel()
+
+		c.conn.Close()
+		serverConn.Close()
+	})
+
+	return c
+}
+
+// Handle records the message. Server requests, such as window/workDoneProgress/create,
+// are recorded along with notifications and accepted as is. Replies are sent from
+// another goroutine: net.Pipe is unbuffered, so replying from the read loop would
+// deadlock with a server that sends a request while handling one of the client's.
+func (c *testClient) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	c.mu.Lock()
+	// The reply is counted before the request is visible to waitFor, so that a test
+	// returning right after seeing it is still waited for on cleanup.
+	if !req.Notif {
+		c.replies.Add(1)
+	}
+	c.notifications = append(c.notifications, req)
+	result := c.results[req.Method]
+	c.mu.Unlock()
+
+	select {
+	case c.notified <- struct{}{}:
+	default:
+	}
+
+	if !req.Notif {
+		go func() {
+			defer c.replies.Done()
+
+			if err := conn.Reply(ctx, req.ID, result); err != nil {
+				c.t.Logf("failed to reply to %s: %v", req.Method, err)
+			}
+		}()
+	}
+}
+
+// call sends a request and waits for its result. Like clients do, initialize is
+// followed by the initialized notification.
+func (c *testClient) call(method string, params, result any) {
+	c.t.Helper()
+
+	if err := c.conn.Call(context.Background(), method, params, result); err != nil {
+		c.t.Fatalf("%s returned unexpected error: %v", method, err)
+	}
+
+	if method == "initialize" {
+		c.notify("initialized", map[string]any{})
+	}
+}
+
+func (c *testClient) notify(method string, params any) {
+	c.t.Helper()
+
+	if err := c.conn.Notify(context.Background(), method, params); err != nil {
+		c.t.Fatalf("%s returned unexpected error: %v", method, err)
+	}
+}
+
+// respond sets the reply to the server requests for method.
+func (c *testClient) respond(method string, result any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.results == nil {
+		c.results = make(map[string]any)
+	}
+	c.results[method] = result
+}
+
+// waitFor waits for a notification for which match returns true and returns it.
+func (c *testClient) waitFor(method string, match func(params json.RawMessage) bool, timeout time.Duration) json.RawMessage {
+	c.t.Helper()
+
+	deadline := time.After(timeout)
+	seen := 0
+	for {
+		c.mu.Lock()
+		pending := c.notifications[seen:]
+		seen = len(c.notifications)
+		c.mu.Unlock()
+
+		for _, n := range pending {
+			if n.Method == method && n.Params != nil && match(*n.Params) {
+				return *n.Params
+			}
+		}
+
+		select {
+		case <-c.notified:
+		case <-deadline:
+			c.t.Fatalf("no %s notification received within %s", method, timeout)
+		}
+	}
+}
+
+// received returns the notifications received so far for method.
+func (c *testClient) received(method string) []json.RawMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var params []json.RawMessage
+	for _, n := range c.notifications {
+		if n.Method == method && n.Params != nil {
+			params = append(params, *n.Params)
+		}
+	}
+
+	return params
+}
+
+// fakeLinter returns a command that prints result like golangci-lint does when it finds issues.
+func fakeLinter(t *testing.T, result GolangCILintResult) []string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake linter needs sh")
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned unexpected error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "result.json")
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	return []string{"sh", "-c", `cat "$0"; exit 1`, path}
+}
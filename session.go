@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+const (
+	// maxRecordedMessageSize caps the size of a single message in a session recording.
+	maxRecordedMessageSize = 1 << 20
+	// replayResponseTimeout bounds how long a replayed request waits for its response.
+	replayResponseTimeout = time.Minute
+)
+
+// sessionEntry is one line of a session recording.
+type sessionEntry struct {
+	Time time.Time `json:"time"`
+	// Direction is "in" for messages from the client and "out" for messages to the client.
+	Direction string          `json:"direction"`
+	Message   json.RawMessage `json:"message,omitempty"`
+	// Truncated is the size of a message too large to be recorded.
+	Truncated int `json:"truncated,omitempty"`
+}
+
+// recordingCodec wraps a codec and appends every message it reads or writes to a session recording.
+type recordingCodec struct {
+	codec jsonrpc2.ObjectCodec
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newRecordingCodec(codec jsonrpc2.ObjectCodec, w io.Writer) *recordingCodec {
+	return &recordingCodec{codec: codec, w: w}
+}
+
+func (c *recordingCodec) WriteObject(stream io.Writer, obj any) error {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	c.record("out", b)
+
+	return c.codec.WriteObject(stream, json.RawMessage(b))
+}
+
+func (c *recordingCodec) ReadObject(stream *bufio.Reader, v any) error {
+	var raw json.RawMessage
+	if err := c.codec.ReadObject(stream, &raw); err != nil {
+		return err
+	}
+
+	c.record("in", raw)
+
+	return json.Unmarshal(raw, v)
+}
+
+func (c *recordingCodec) record(direction string, message []byte) {
+	entry := sessionEntry{Time: time.Now(), Direction: direction}
+	if len(message) > maxRecordedMessageSize {
+		entry.Truncated = len(message)
+	} else {
+		entry.Message = message
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		slog.Error("failed to record message", "error", err)
+
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.w.Write(append(b, '\n')); err != nil {
+		slog.Error("failed to record message", "error", err)
+	}
+}
+
+// replaySession feeds the client messages of the recording at path to handler, in order,
+// and writes every message the server sends back to out in the recording format.
+// Requests wait for their response before the next message is sent. Once every message
+// is sent, the replay ends when the server has been quiet for idle, so that diagnostics
+// of lints triggered by the last messages are still written.
+func replaySession(path string, handler jsonrpc2.Handler, out io.Writer, idle time.Duration) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var inbound []json.RawMessage
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 2*maxRecordedMessageSize)
+	for scanner.Scan() {
+		var entry sessionEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("invalid session entry: %w", err)
+		}
+
+		if entry.Direction != "in" {
+			continue
+		}
+
+		if entry.Truncated > 0 {
+			slog.Warn("skipping a message too large to have been recorded", "size", entry.Truncated)
+
+			continue
+		}
+
+		message, err := withoutProcessID(entry.Message)
+		if err != nil {
+			return err
+		}
+
+		inbound = append(inbound, message)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	serverSide, clientSide := net.Pipe()
+
+	jsonrpc2.NewConn(context.Background(), jsonrpc2.NewBufferedStream(serverSide, jsonrpc2.VSCodeObjectCodec{}), handler)
+
+	responses := make(chan json.RawMessage, 16)
+	received := make(chan struct{}, 1)
+	go readReplayOutput(clientSide, out, responses, received)
+
+	// out is only written by the reader, which ends once the pipe is closed.
+	defer func() {
+		clientSide.Close()
+		for range responses {
+		}
+	}()
+
+	codec := jsonrpc2.VSCodeObjectCodec{}
+	for _, message := range inbound {
+		if err := codec.WriteObject(clientSide, message); err != nil {
+			return err
+		}
+
+		// Notifications and the replies of the client to server requests get no response.
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.Unmarshal(message, &req); err != nil || req.ID == nil || req.Method == "" {
+			continue
+		}
+
+		if err := waitForResponse(responses, req.ID); err != nil {
+			return err
+		}
+	}
+
+	// Wait for the server to go quiet before ending the replay.
+	for {
+		select {
+		case <-received:
+		case <-time.After(idle):
+			return nil
+		}
+	}
+}
+
+// withoutProcessID clears the processId of a recorded initialize request,
+// as the recorded client process is long gone when the session is replayed.
+func withoutProcessID(message json.RawMessage) (json.RawMessage, error) {
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, fmt.Errorf("invalid recorded message: %w", err)
+	}
+
+	if string(msg["method"]) != `"initialize"` || msg["params"] == nil {
+		return message, nil
+	}
+
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(msg["params"], &params); err != nil {
+		return nil, fmt.Errorf("invalid initialize params: %w", err)
+	}
+
+	params["processId"] = json.RawMessage("null")
+
+	b, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	msg["params"] = b
+
+	return json.Marshal(msg)
+}
+
+// readReplayOutput writes the server messages to out, sending the IDs of responses on responses.
+func readReplayOutput(conn io.Reader, out io.Writer, responses chan<- json.RawMessage, received chan<- struct{}) {
+	r := bufio.NewReader(conn)
+	enc := json.NewEncoder(out)
+
+	for {
+		var raw json.RawMessage
+		if err := (jsonrpc2.VSCodeObjectCodec{}).ReadObject(r, &raw); err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrClosedPipe) {
+				slog.Error("failed to read server message", "error", err)
+			}
+
+			close(responses)
+
+			return
+		}
+
+		if err := enc.Encode(sessionEntry{Time: time.Now(), Direction: "out", Message: raw}); err != nil {
+			slog.Error("failed to write server message", "error", err)
+		}
+
+		select {
+		case received <- struct{}{}:
+		default:
+		}
+
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.Unmarshal(raw, &msg); err == nil && msg.ID != nil && msg.Method == "" {
+			responses <- msg.ID
+		}
+	}
+}
+
+func waitForResponse(responses <-chan json.RawMessage, id json.RawMessage) error {
+	timeout := time.After(replayResponseTimeout)
+
+	for {
+		select {
+		case got, ok := <-responses:
+			if !ok {
+				return errors.New("connection closed before the response was received")
+			}
+
+			if bytes.Equal(got, id) {
+				return nil
+			}
+		case <-timeout:
+			return fmt.Errorf("no response received for request %s", id)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+func TestRecordingCodec(t *testing.T) {
+	var recording bytes.Buffer
+	codec := newRecordingCodec(jsonrpc2.VSCodeObjectCodec{}, &recording)
+
+	var stream bytes.Buffer
+	if err := codec.WriteObject(&stream, map[string]string{"method": "ping"}); err != nil {
+		t.Fatalf("WriteObject() returned unexpected error: %v", err)
+	}
+
+	var got map[string]string
+	if err := codec.ReadObject(bufio.NewReader(&stream), &got); err != nil {
+		t.Fatalf("ReadObject() returned unexpected error: %v", err)
+	}
+
+	if got["method"] != "ping" {
+		t.Errorf("ReadObject() = %v, want the written object", got)
+	}
+
+	if err := codec.WriteObject(&stream, strings.Repeat("x", maxRecordedMessageSize)); err != nil {
+		t.Fatalf("WriteObject() returned unexpected error: %v", err)
+	}
+
+	var entries []sessionEntry
+	for _, line := range strings.Split(strings.TrimSpace(recording.String()), "\n") {
+		var entry sessionEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid recorded line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("recorded %d entries, want 3", len(entries))
+	}
+
+	for i, want := range []sessionEntry{
+		{Direction: "out", Message: json.RawMessage(`{"method":"ping"}`)},
+		{Direction: "in", Message: json.RawMessage(`{"method":"ping"}`)},
+		{Direction: "out", Truncated: maxRecordedMessageSize + 2},
+	} {
+		entries[i].Time = time.Time{}
+		if diff := cmp.Diff(want, entries[i]); diff != "" {
+			t.Errorf("entry %d mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+}
+
+func TestReplaySession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	session := strings.Join([]string{
+		`{"time":"2024-01-01T00:00:00Z","direction":"in","message":{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"processId":4242}}}`,
+		`{"time":"2024-01-01T00:00:01Z","direction":"out","message":{"jsonrpc":"2.0","id":1,"result":{}}}`,
+		`{"time":"2024-01-01T00:00:02Z","direction":"in","message":{"jsonrpc":"2.0","method":"textDocument/didSave","params":{}}}`,
+		`{"time":"2024-01-01T00:00:03Z","direction":"in","truncated":2000000}`,
+	}, "\n")
+	if err := os.WriteFile(path, []byte(session), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	handler := jsonrpc2.HandlerWithError(func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (any, error) {
+		switch req.Method {
+		case "initialize":
+			return req.Params, nil
+		case "textDocument/didSave":
+			return nil, conn.Notify(ctx, "textDocument/publishDiagnostics", map[string]any{"diagnostics": []any{}})
+		}
+
+		return nil, nil
+	})
+
+	var out bytes.Buffer
+	if err := replaySession(path, handler, &out, 100*time.Millisecond); err != nil {
+		t.Fatalf("replaySession() returned unexpected error: %v", err)
+	}
+
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var entry sessionEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid output line %q: %v", line, err)
+		}
+		messages = append(messages, string(entry.Message))
+	}
+
+	want := []string{
+		`{"id":1,"result":{"processId":null},"jsonrpc":"2.0"}`,
+		`{"jsonrpc":"2.0","method":"textDocument/publishDiagnostics","params":{"diagnostics":[]}}`,
+	}
+	if diff := cmp.Diff(want, messages); diff != "" {
+		t.Errorf("replayed messages mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestReplaySession_clientReply tests that the replies of the client to server requests
+// are replayed without waiting for a response to them.
+func TestReplaySession_clientReply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	session := strings.Join([]string{
+		`{"time":"2024-01-01T00:00:00Z","direction":"in","message":{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}}`,
+		`{"time":"2024-01-01T00:00:01Z","direction":"out","message":{"jsonrpc":"2.0","id":1,"result":{}}}`,
+		`{"time":"2024-01-01T00:00:02Z","direction":"out","message":{"jsonrpc":"2.0","id":0,"method":"window/workDoneProgress/create","params":{"token":"lint"}}}`,
+		`{"time":"2024-01-01T00:00:03Z","direction":"in","message":{"jsonrpc":"2.0","id":0,"result":null}}`,
+		`{"time":"2024-01-01T00:00:04Z","direction":"in","message":{"jsonrpc":"2.0","id":2,"method":"shutdown"}}`,
+	}, "\n")
+	if err := os.WriteFile(path, []byte(session), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	handler := jsonrpc2.HandlerWithError(func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (any, error) {
+		return req.Method, nil
+	})
+
+	start := time.Now()
+
+	var out bytes.Buffer
+	if err := replaySession(path, handler, &out, 100*time.Millisecond); err != nil {
+		t.Fatalf("replaySession() returned unexpected error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed >= replayResponseTimeout {
+		t.Errorf("replaySession() took %v, waiting for a response to the client reply", elapsed)
+	}
+
+	if got := out.String(); !strings.Contains(got, `"result":"shutdown"`) {
+		t.Errorf("the request after the client reply was not answered, got %s", got)
+	}
+}
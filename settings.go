@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// configurationSection is the section of the client configuration holding the options.
+const configurationSection = "golangci-lint-langserver"
+
+// configurationTimeout is how long the client has to answer workspace/configuration.
+const configurationTimeout = 10 * time.Second
+
+// settingsLockPollInterval is how often applying new settings retries to take settingsMu.
+const settingsLockPollInterval = 10 * time.Millisecond
+
+// mergeOptions returns the options of base with the ones set in overlay replacing them,
+// as a JSON object. Either may be empty or null.
+func mergeOptions(base, overlay json.RawMessage) (json.RawMessage, error) {
+	merged := make(map[string]json.RawMessage)
+
+	for _, raw := range []json.RawMessage{base, overlay} {
+		if len(bytes.TrimSpace(raw)) == 0 || string(bytes.TrimSpace(raw)) == "null" {
+			continue
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, errors.New("settings must be an object")
+		}
+
+		for key, value := range fields {
+			merged[key] = value
+		}
+	}
+
+	// Keys are sorted, so equal options marshal equally.
+	return json.Marshal(merged)
+}
+
+// showOptionProblems reports invalid options to the user.
+func (h *langHandler) showOptionProblems(ctx context.Context, problems []string) {
+	if len(problems) == 0 {
+		return
+	}
+
+	message := initializationOptionsMessage(problems)
+	slog.Warn(message)
+
+	if h.conn == nil {
+		return
+	}
+
+	if err := h.conn.Notify(ctx, "window/showMessage", &ShowMessageParams{Type: MTError, Message: message}); err != nil {
+		slog.Error("failed to show message", "error", err)
+	}
+}
+
+// rejectSettings reports the problems of settings that are not applied.
+func (h *langHandler) rejectSettings(ctx context.Context, problems []string) {
+	message := rejectedSettingsMessage(problems)
+	slog.Warn(message)
+
+	h.showMessage(ctx, MTWarning, message)
+}
+
+// pullConfiguration requests the options section of the client configuration, when the
+// client supports it, and applies it over the initializationOptions. The request is
+// made from another goroutine, as its reply is read by the goroutine handling messages.
+// When the open documents were waiting for it, they are linted once it completes, even
+// if the client answers null or fails to answer.
+func (h *langHandler) pullConfiguration() {
+	if !h.configuration || h.conn == nil {
+		return
+	}
+
+	conn, scope := h.conn, h.rootURI
+	ctx := h.serverContext()
+
+	go func() {
+		callCtx, cancel := context.WithTimeout(ctx, configurationTimeout)
+		defer cancel()
+
+		var sections []json.RawMessage
+		err := conn.Call(callCtx, "workspace/configuration", &ConfigurationParams{
+			Items: []ConfigurationItem{{ScopeURI: scope, Section: configurationSection}},
+		}, &sections)
+
+		awaiting := h.awaitingConfiguration.Swap(false)
+
+		applied := false
+		if err != nil {
+			slog.Warn("failed to pull the configuration", "error", err)
+		} else {
+			var section json.RawMessage
+			if len(sections) > 0 {
+				section = sections[0]
+			}
+
+			applied = h.updateSettings(ctx, section)
+		}
+
+		if applied || !awaiting {
+			return
+		}
+
+		h.settingsMu.RLock()
+		defer h.settingsMu.RUnlock()
+
+		if err := h.lintOpenDocuments(ctx); err != nil {
+			slog.Error("failed to lint open documents", "error", err)
+		}
+	}()
+}
+
+// updateSettings applies the section of the client configuration over the
+// initializationOptions. When the options in effect change, running lints are
+// aborted and the open documents are linted again with the new settings. Invalid
+// settings are reported and not applied, leaving the previous ones in effect.
+// updateSettings reports whether the new settings were applied.
+func (h *langHandler) updateSettings(ctx context.Context, section json.RawMessage) bool {
+	h.settingsMu.RLock()
+	merged, err := mergeOptions(h.initOptions, section)
+	unchanged := err == nil && bytes.Equal(merged, h.effectiveOptions)
+	h.settingsMu.RUnlock()
+
+	if err != nil {
+		h.rejectSettings(ctx, []string{configurationSection + " " + err.Error()})
+
+		return false
+	}
+
+	if unchanged {
+		slog.Debug("settings unchanged")
+
+		return false
+	}
+
+	opts, problems := parseInitializationOptions(merged)
+
+	h.settingsMu.RLock()
+	problems = append(problems, h.validateOptions(opts)...)
+	h.settingsMu.RUnlock()
+
+	if len(problems) > 0 {
+		h.rejectSettings(ctx, problems)
+
+		return false
+	}
+
+	// Running lints hold settingsMu for reading until they return. Waiting for them with
+	// Lock would also block new readers, such as the message handlers queueing lints for
+	// the linter, so the lock is polled for instead.
+	h.cancelRunningLints()
+	for !h.settingsMu.TryLock() {
+		time.Sleep(settingsLockPollInterval)
+	}
+	h.effectiveOptions = merged
+	problems = append(problems, h.applyOptions(opts)...)
+	h.resetLintConfigs()
+	h.settingsMu.Unlock()
+
+	slog.Info("settings changed, linting open documents again")
+
+	h.showOptionProblems(ctx, problems)
+
+	h.settingsMu.RLock()
+	defer h.settingsMu.RUnlock()
+
+	if err := h.lintOpenDocuments(ctx); err != nil {
+		slog.Error("failed to lint open documents", "error", err)
+	}
+
+	return true
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergeOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		overlay string
+		want    string
+		wantErr bool
+	}{
+		{name: "both empty", want: `{}`},
+		{name: "null overlay", base: `{"lintTarget": "file"}`, overlay: `null`, want: `{"lintTarget":"file"}`},
+		{
+			name:    "overlay wins per key",
+			base:    `{"command": ["golangci-lint", "run"], "lintTarget": "file"}`,
+			overlay: `{"lintTarget": "package", "onlyTouchedLines": true}`,
+			want:    `{"command":["golangci-lint","run"],"lintTarget":"package","onlyTouchedLines":true}`,
+		},
+		{name: "overlay not an object", base: `{}`, overlay: `"golangci-lint"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeOptions(json.RawMessage(tt.base), json.RawMessage(tt.overlay))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mergeOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("mergeOptions() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLangHandler_pullConfiguration tests that settings pulled from the client apply over
+// the initializationOptions, after initialized and after an empty configuration change.
+func TestLangHandler_pullConfiguration(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	command := func(text string) []string {
+		issue := Issue{FromLinter: "revive", Text: text}
+		issue.Pos.Filename = "main.go"
+		issue.Pos.Line = 1
+
+		return fakeLinter(t, GolangCILintResult{Issues: []Issue{issue}})
+	}
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	// The configuration is pulled once initialized arrives, right after initialize.
+	client.respond("workspace/configuration", []any{map[string]any{"command": command("pulled")}})
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(root)),
+		"capabilities":          map[string]any{"workspace": map[string]any{"configuration": true}},
+		"initializationOptions": map[string]any{"command": command("from initializationOptions")},
+	}, nil)
+
+	uri := pathToURI(path)
+	client.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": "package main\n"},
+	})
+
+	waitForMessage := func(message string) {
+		t.Helper()
+
+		client.waitFor("textDocument/publishDiagnostics", func(raw json.RawMessage) bool {
+			var params PublishDiagnosticsParams
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return false
+			}
+
+			return len(params.Diagnostics) == 1 && params.Diagnostics[0].Message == message
+		}, 5*time.Second)
+	}
+
+	// Whether the first lint ran before the settings were pulled or not, the document
+	// ends up linted with them.
+	waitForMessage("revive: pulled")
+
+	client.respond("workspace/configuration", []any{map[string]any{"command": command("pulled again")}})
+	client.notify("workspace/didChangeConfiguration", map[string]any{"settings": nil})
+	waitForMessage("revive: pulled again")
+
+	requests := client.received("workspace/configuration")
+	if len(requests) != 2 {
+		t.Fatalf("got %d workspace/configuration requests, want 2", len(requests))
+	}
+
+	var params ConfigurationParams
+	if err := json.Unmarshal(requests[0], &params); err != nil {
+		t.Fatalf("invalid workspace/configuration params: %v", err)
+	}
+
+	if len(params.Items) != 1 || params.Items[0].Section != configurationSection {
+		t.Errorf("unexpected workspace/configuration items: %+v", params.Items)
+	}
+}
+
+// TestLangHandler_pullConfigurationWithoutCommand tests that, without a command in the
+// initializationOptions, the open documents are linted once the configuration is pulled,
+// with the command it sets or the default one when the client answers null.
+func TestLangHandler_pullConfigurationWithoutCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		section any
+		want    func(params PublishDiagnosticsParams) bool
+	}{
+		{
+			name:    "pulled command",
+			section: "pulled",
+			want: func(params PublishDiagnosticsParams) bool {
+				return len(params.Diagnostics) == 1 && params.Diagnostics[0].Message == "revive: pulled"
+			},
+		},
+		{
+			name: "null",
+			want: func(PublishDiagnosticsParams) bool { return true },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			path := filepath.Join(root, "main.go")
+			if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+				t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+			}
+
+			var section any
+			if text, ok := tt.section.(string); ok {
+				issue := Issue{FromLinter: "revive", Text: text}
+				issue.Pos.Filename = "main.go"
+				issue.Pos.Line = 1
+
+				section = map[string]any{"command": fakeLinter(t, GolangCILintResult{Issues: []Issue{issue}})}
+			}
+
+			h := newLangHandler(false)
+			client := newTestClient(t, h)
+
+			client.respond("workspace/configuration", []any{section})
+			client.call("initialize", map[string]any{
+				"rootUri":      string(pathToURI(root)),
+				"capabilities": map[string]any{"workspace": map[string]any{"configuration": true}},
+			}, nil)
+
+			client.notify("textDocument/didOpen", map[string]any{
+				"textDocument": map[string]any{"uri": pathToURI(path), "languageId": "go", "version": 1, "text": "package main\n"},
+			})
+
+			raw := client.waitFor("textDocument/publishDiagnostics", func(json.RawMessage) bool { return true }, 5*time.Second)
+
+			var params PublishDiagnosticsParams
+			if err := json.Unmarshal(raw, &params); err != nil {
+				t.Fatalf("invalid textDocument/publishDiagnostics params: %v", err)
+			}
+
+			if !tt.want(params) {
+				t.Errorf("first diagnostics = %+v", params.Diagnostics)
+			}
+		})
+	}
+}
+
+// TestLangHandler_didChangeConfiguration tests that settings pushed with
+// workspace/didChangeConfiguration apply at runtime, under configurationSection or in the
+// shape of initializationOptions, and that invalid ones are rejected.
+func TestLangHandler_didChangeConfiguration(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	command := func(text string) []string {
+		issue := Issue{FromLinter: "revive", Text: text}
+		issue.Pos.Filename = "main.go"
+		issue.Pos.Line = 1
+
+		return fakeLinter(t, GolangCILintResult{Issues: []Issue{issue}})
+	}
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(root)),
+		"initializationOptions": map[string]any{"command": command("initial")},
+	}, nil)
+
+	uri := pathToURI(path)
+	client.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": "package main\n"},
+	})
+
+	waitForMessage := func(message string) {
+		t.Helper()
+
+		client.waitFor("textDocument/publishDiagnostics", func(raw json.RawMessage) bool {
+			var params PublishDiagnosticsParams
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return false
+			}
+
+			return len(params.Diagnostics) == 1 && params.Diagnostics[0].Message == message
+		}, 5*time.Second)
+	}
+
+	waitForMessage("revive: initial")
+
+	client.notify("workspace/didChangeConfiguration", map[string]any{
+		"settings": map[string]any{configurationSection: map[string]any{"command": command("section")}},
+	})
+	waitForMessage("revive: section")
+
+	client.notify("workspace/didChangeConfiguration", map[string]any{
+		"settings": map[string]any{"command": command("flat")},
+	})
+	waitForMessage("revive: flat")
+
+	client.notify("workspace/didChangeConfiguration", map[string]any{
+		"settings": map[string]any{"command": command("rejected"), "minimumSeverity": "fatal"},
+	})
+
+	raw := client.waitFor("window/showMessage", func(json.RawMessage) bool { return true }, 5*time.Second)
+
+	var message ShowMessageParams
+	if err := json.Unmarshal(raw, &message); err != nil {
+		t.Fatalf("invalid window/showMessage params: %v", err)
+	}
+
+	if message.Type != MTWarning || !strings.Contains(message.Message, "minimumSeverity") {
+		t.Errorf("window/showMessage = %+v, want a warning about minimumSeverity", message)
+	}
+
+	// The document is linted with the previous command.
+	client.notify("textDocument/didSave", map[string]any{"textDocument": map[string]any{"uri": uri}})
+	waitForMessage("revive: flat")
+}
+
+func TestIsOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		want     bool
+	}{
+		{name: "empty", settings: `{}`},
+		{name: "options", settings: `{"command": [], "lintTarget": "file"}`, want: true},
+		{name: "section", settings: `{"golangci-lint-langserver": {}}`},
+		{name: "other keys", settings: `{"command": [], "gopls": {}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var settings map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.settings), &settings); err != nil {
+				t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+			}
+
+			if got := isOptions(settings); got != tt.want {
+				t.Errorf("isOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLangHandler_applyOptionsLogLevel tests that the logLevel option sets the level of
+// the logger, and that the level of -debug applies again once it is unset.
+func TestLangHandler_applyOptionsLogLevel(t *testing.T) {
+	tests := []struct {
+		name        string
+		logLevel    string
+		want        slog.Level
+		wantProblem bool
+	}{
+		{name: "unset", want: slog.LevelInfo},
+		{name: "debug", logLevel: "debug", want: slog.LevelDebug},
+		{name: "case insensitive", logLevel: "WARN", want: slog.LevelWarn},
+		{name: "error", logLevel: "error", want: slog.LevelError},
+		{name: "invalid", logLevel: "verbose", want: slog.LevelInfo, wantProblem: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &langHandler{logLevel: new(slog.LevelVar), startupLogLevel: slog.LevelInfo}
+			h.logLevel.Set(slog.LevelDebug)
+
+			problems := h.applyOptions(InitializationOptions{LogLevel: tt.logLevel})
+			if (len(problems) > 0) != tt.wantProblem {
+				t.Errorf("applyOptions() problems = %v, wantProblem %v", problems, tt.wantProblem)
+			}
+
+			if got := h.logLevel.Level(); got != tt.want {
+				t.Errorf("log level = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// defaultSeverityGrades map the severity grades of gosec and similar linters onto
+// LSP severities.
+var defaultSeverityGrades = map[string]DiagnosticSeverity{
+	"critical": DSError,
+	"high":     DSError,
+	"medium":   DSWarning,
+	"low":      DSInformation,
+}
+
+// embeddedSeverityRe matches the severity gosec embeds in its messages, like
+// "G304 (CWE-22): Potential file inclusion via variable (Confidence: HIGH, Severity: MEDIUM)".
+var embeddedSeverityRe = regexp.MustCompile(`\bSeverity: ([A-Za-z]+)\)`)
+
+// compileSeverityGrades merges the severityGrades option into the default grades,
+// skipping entries with an unknown severity.
+func compileSeverityGrades(overrides map[string]string) map[string]DiagnosticSeverity {
+	grades := maps.Clone(defaultSeverityGrades)
+
+	for grade, name := range overrides {
+		severity, ok := parseSeverity(name)
+		if !ok {
+			slog.Warn("ignoring severityGrades entry with an unknown severity", "grade", grade, "severity", name)
+
+			continue
+		}
+
+		grades[strings.ToLower(grade)] = severity
+	}
+
+	return grades
+}
+
+// compileLinterSeverities compiles the severities option. Linters need not be enabled,
+// as a shared configuration may name linters other projects run; entries with an
+// unknown severity are skipped and all reported in the returned error.
+func compileLinterSeverities(severities map[string]string) (map[string]DiagnosticSeverity, error) {
+	compiled := make(map[string]DiagnosticSeverity, len(severities))
+
+	var invalid []string
+	for linter, name := range severities {
+		severity, ok := parseSeverity(name)
+		if !ok {
+			invalid = append(invalid, fmt.Sprintf("%s: %q", linter, name))
+
+			continue
+		}
+
+		compiled[strings.ToLower(linter)] = severity
+	}
+
+	if len(invalid) > 0 {
+		slices.Sort(invalid)
+
+		return compiled, fmt.Errorf("\"severities\" has unknown severities: %s", strings.Join(invalid, ", "))
+	}
+
+	return compiled, nil
+}
+
+// issueSeverity returns the severity of an issue. The severities option wins for the
+// linter of the issue. Grades like "HIGH", from the Severity field or, when it is
+// empty, embedded in the text by gosec, map through severityGrades; other severities
+// are those of -severity.
+func (h *langHandler) issueSeverity(issue *Issue) DiagnosticSeverity {
+	if s, ok := h.linterSeverities[strings.ToLower(issue.FromLinter)]; ok {
+		return s
+	}
+
+	grade := issue.Severity
+	if grade == "" {
+		if m := embeddedSeverityRe.FindStringSubmatch(issue.Text); m != nil {
+			grade = m[1]
+		}
+	}
+
+	grades := h.severityGrades
+	if grades == nil {
+		grades = defaultSeverityGrades
+	}
+
+	if s, ok := grades[strings.ToLower(grade)]; ok {
+		return s
+	}
+
+	return issue.DiagSeverity()
+}
+
+// pathSeverity is a compiled entry of the pathSeverities option.
+type pathSeverity struct {
+	glob     *regexp.Regexp
+	severity DiagnosticSeverity
+}
+
+// compilePathSeverities compiles the pathSeverities option, skipping invalid entries.
+// All invalid entries are reported in the returned error.
+func compilePathSeverities(entries []PathSeverity) ([]pathSeverity, error) {
+	compiled := make([]pathSeverity, 0, len(entries))
+
+	var invalid []string
+	for _, e := range entries {
+		glob, err := compileGlob(e.Glob)
+		if err != nil {
+			invalid = append(invalid, err.Error())
+
+			continue
+		}
+
+		severity, ok := parseSeverity(e.Severity)
+		if !ok {
+			invalid = append(invalid, fmt.Sprintf("%q: unknown severity %q", e.Glob, e.Severity))
+
+			continue
+		}
+
+		compiled = append(compiled, pathSeverity{glob: glob, severity: severity})
+	}
+
+	if len(invalid) > 0 {
+		return compiled, fmt.Errorf("\"pathSeverities\" has invalid entries: %s", strings.Join(invalid, ", "))
+	}
+
+	return compiled, nil
+}
+
+// pathSeverity returns the severity of the first pathSeverities entry matching the file.
+// Files outside the workspace never match.
+func (h *langHandler) pathSeverity(absPath string) (DiagnosticSeverity, bool) {
+	if len(h.pathSeverities) == 0 {
+		return 0, false
+	}
+
+	rel, err := filepath.Rel(h.workspaceRoot(absPath), absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return 0, false
+	}
+
+	rel = filepath.ToSlash(rel)
+	for _, ps := range h.pathSeverities {
+		if ps.glob.MatchString(rel) {
+			return ps.severity, true
+		}
+	}
+
+	return 0, false
+}
+
+// filterSeverity drops the diagnostics below minimumSeverity, once every severity
+// mapping has applied, and records how many were dropped for the document.
+func (h *langHandler) filterSeverity(uri DocumentURI, diagnostics []Diagnostic) ([]Diagnostic, int) {
+	if h.minimumSeverity == 0 {
+		return diagnostics, 0
+	}
+
+	kept := make([]Diagnostic, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		// Lower values are more severe.
+		if d.Severity == 0 || d.Severity <= h.minimumSeverity {
+			kept = append(kept, d)
+		}
+	}
+
+	hidden := len(diagnostics) - len(kept)
+	if hidden > 0 {
+		slog.Info("hiding diagnostics below minimumSeverity", "uri", uri, "count", hidden)
+	}
+
+	h.hiddenMu.Lock()
+	if h.hidden == nil {
+		h.hidden = make(map[DocumentURI]int)
+	}
+	if hidden > 0 {
+		h.hidden[uri] = hidden
+	} else {
+		delete(h.hidden, uri)
+	}
+	h.hiddenMu.Unlock()
+
+	return kept, hidden
+}
+
+// hiddenDiagnostics returns the number of diagnostics below minimumSeverity in the
+// last lint of every document.
+func (h *langHandler) hiddenDiagnostics() int {
+	h.hiddenMu.Lock()
+	defer h.hiddenMu.Unlock()
+
+	total := 0
+	for _, n := range h.hidden {
+		total += n
+	}
+
+	return total
+}
+
+// hiddenMessage summarizes the diagnostics hidden by minimumSeverity for the end of
+// the lint progress.
+func hiddenMessage(hidden int) string {
+	switch hidden {
+	case 0:
+		return ""
+	case 1:
+		return "1 diagnostic below minimumSeverity hidden"
+	default:
+		return fmt.Sprintf("%d diagnostics below minimumSeverity hidden", hidden)
+	}
+}
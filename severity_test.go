@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIssue_DiagSeverity(t *testing.T) {
+	tests := []struct {
+		name            string
+		severity        string
+		defaultSeverity string
+		want            DiagnosticSeverity
+	}{
+		{name: "error", severity: "error", defaultSeverity: "Warn", want: DSError},
+		{name: "warning", severity: "warning", defaultSeverity: "Hint", want: DSWarning},
+		{name: "info", severity: "info", defaultSeverity: "Warn", want: DSInformation},
+		{name: "hint", severity: "hint", defaultSeverity: "Warn", want: DSHint},
+		{name: "case insensitive", severity: "ERROR", defaultSeverity: "Warn", want: DSError},
+		{name: "empty uses the default", defaultSeverity: "Info", want: DSInformation},
+		{name: "unknown uses the default", severity: "blocker", defaultSeverity: "Hint", want: DSHint},
+		{name: "invalid default", severity: "blocker", defaultSeverity: "loud", want: DSWarning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := defaultSeverity
+			defaultSeverity = tt.defaultSeverity
+			t.Cleanup(func() { defaultSeverity = saved })
+
+			if got := (Issue{Severity: tt.severity}).DiagSeverity(); got != tt.want {
+				t.Errorf("DiagSeverity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLangHandler_pathSeverity tests the precedence of pathSeverities against the other severity sources.
+func TestLangHandler_pathSeverity(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "internal", "experimental")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+	}
+
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package experimental\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		pathSeverities []PathSeverity
+		lintTarget     string
+		issue          Issue
+		want           DiagnosticSeverity
+	}{
+		{
+			name:  "linter severity without path severities",
+			issue: Issue{FromLinter: "gosec", Severity: "error", Text: "G104"},
+			want:  DSError,
+		},
+		{
+			name:           "path severity overrides the linter severity",
+			pathSeverities: []PathSeverity{{Glob: "internal/experimental/**", Severity: "hint"}},
+			issue:          Issue{FromLinter: "gosec", Severity: "error", Text: "G104"},
+			want:           DSHint,
+		},
+		{
+			name: "first matching entry wins",
+			pathSeverities: []PathSeverity{
+				{Glob: "internal/**", Severity: "info"},
+				{Glob: "internal/experimental/**", Severity: "hint"},
+			},
+			issue: Issue{FromLinter: "errcheck", Text: "unchecked"},
+			want:  DSInformation,
+		},
+		{
+			name:           "non-matching entry keeps the linter severity",
+			pathSeverities: []PathSeverity{{Glob: "cmd/**", Severity: "hint"}},
+			issue:          Issue{FromLinter: "errcheck", Severity: "warning", Text: "unchecked"},
+			want:           DSWarning,
+		},
+		{
+			name:           "invalid entries are ignored",
+			pathSeverities: []PathSeverity{{Glob: "internal/**", Severity: "critical"}, {Glob: "[", Severity: "hint"}},
+			issue:          Issue{FromLinter: "errcheck", Severity: "warning", Text: "unchecked"},
+			want:           DSWarning,
+		},
+		{
+			name:           "single file noise stays a hint",
+			pathSeverities: []PathSeverity{{Glob: "**", Severity: "error"}},
+			lintTarget:     lintTargetFile,
+			issue:          Issue{FromLinter: "typecheck", Severity: "error", Text: "undefined: foo"},
+			want:           DSHint,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.issue.Pos.Filename = "internal/experimental/main.go"
+			tt.issue.Pos.Line = 1
+			tt.issue.Pos.Column = 1
+
+			pathSeverities, _ := compilePathSeverities(tt.pathSeverities)
+
+			h := &langHandler{
+				rootDir:        root,
+				command:        fakeLinter(t, GolangCILintResult{Issues: []Issue{tt.issue}}),
+				lintTarget:     tt.lintTarget,
+				pathSeverities: pathSeverities,
+			}
+
+			results, err := h.lint(context.Background(), pathToURI(path))
+			if err != nil {
+				t.Fatalf("lint() returned unexpected error: %v", err)
+			}
+
+			diagnostics := results[pathToURI(path)]
+
+			if len(diagnostics) != 1 {
+				t.Fatalf("lint() returned %d diagnostics, want 1: %+v", len(diagnostics), diagnostics)
+			}
+
+			if diagnostics[0].Severity != tt.want {
+				t.Errorf("severity = %v, want %v", diagnostics[0].Severity, tt.want)
+			}
+		})
+	}
+}
+
+func TestLangHandler_issueSeverity(t *testing.T) {
+	tests := []struct {
+		name       string
+		grades     map[string]string
+		severities map[string]string
+		issue      Issue
+		want       DiagnosticSeverity
+	}{
+		{name: "high grade", issue: Issue{FromLinter: "gosec", Severity: "HIGH"}, want: DSError},
+		{name: "critical grade", issue: Issue{FromLinter: "custom", Severity: "critical"}, want: DSError},
+		{name: "medium grade", issue: Issue{FromLinter: "gosec", Severity: "MEDIUM"}, want: DSWarning},
+		{name: "low grade", issue: Issue{FromLinter: "gosec", Severity: "Low"}, want: DSInformation},
+		{name: "LSP severity", issue: Issue{FromLinter: "errcheck", Severity: "hint"}, want: DSHint},
+		{
+			name:  "grade embedded in the text",
+			issue: Issue{FromLinter: "gosec", Text: "G304 (CWE-22): Potential file inclusion via variable (Confidence: HIGH, Severity: MEDIUM)"},
+			want:  DSWarning,
+		},
+		{
+			name:  "field wins over the text",
+			issue: Issue{FromLinter: "gosec", Severity: "low", Text: "G104 (CWE-703): Errors unhandled (Confidence: HIGH, Severity: HIGH)"},
+			want:  DSInformation,
+		},
+		{
+			name:   "overridden grade",
+			grades: map[string]string{"MEDIUM": "error", "low": "hint", "high": "severe"},
+			issue:  Issue{FromLinter: "gosec", Severity: "medium"},
+			want:   DSError,
+		},
+		{
+			name:   "invalid override keeps the default",
+			grades: map[string]string{"high": "severe"},
+			issue:  Issue{FromLinter: "gosec", Severity: "high"},
+			want:   DSError,
+		},
+		{
+			name:       "linter severity wins over the issue severity",
+			severities: map[string]string{"errcheck": "error", "lll": "hint"},
+			issue:      Issue{FromLinter: "errcheck", Severity: "warning"},
+			want:       DSError,
+		},
+		{
+			name:       "linter severity wins over the grade",
+			severities: map[string]string{"GoSec": "info"},
+			issue:      Issue{FromLinter: "gosec", Severity: "HIGH"},
+			want:       DSInformation,
+		},
+		{
+			name:       "other linter keeps the issue severity",
+			severities: map[string]string{"lll": "hint", "notenabled": "error"},
+			issue:      Issue{FromLinter: "errcheck", Severity: "warning"},
+			want:       DSWarning,
+		},
+		{
+			name:       "invalid linter severity is ignored",
+			severities: map[string]string{"errcheck": "severe"},
+			issue:      Issue{FromLinter: "errcheck", Severity: "info"},
+			want:       DSInformation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			severities, _ := compileLinterSeverities(tt.severities)
+			h := &langHandler{severityGrades: compileSeverityGrades(tt.grades), linterSeverities: severities}
+
+			if got := h.issueSeverity(&tt.issue); got != tt.want {
+				t.Errorf("issueSeverity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLangHandler_filterSeverity(t *testing.T) {
+	diagnostics := []Diagnostic{
+		{Severity: DSError, Message: "error"},
+		{Severity: DSWarning, Message: "warning"},
+		{Severity: DSInformation, Message: "information"},
+		{Severity: DSHint, Message: "hint"},
+	}
+
+	tests := []struct {
+		name         string
+		minimum      DiagnosticSeverity
+		wantMessages []string
+	}{
+		{name: "no threshold", wantMessages: []string{"error", "warning", "information", "hint"}},
+		{name: "warning", minimum: DSWarning, wantMessages: []string{"error", "warning"}},
+		{name: "error", minimum: DSError, wantMessages: []string{"error"}},
+		{name: "hint", minimum: DSHint, wantMessages: []string{"error", "warning", "information", "hint"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &langHandler{minimumSeverity: tt.minimum}
+
+			kept, hidden := h.filterSeverity("file:///a.go", diagnostics)
+
+			var messages []string
+			for _, d := range kept {
+				messages = append(messages, d.Message)
+			}
+
+			if diff := cmp.Diff(tt.wantMessages, messages); diff != "" {
+				t.Errorf("filterSeverity() mismatch (-want +got):\n%s", diff)
+			}
+
+			if want := len(diagnostics) - len(tt.wantMessages); hidden != want || h.hiddenDiagnostics() != want {
+				t.Errorf("filterSeverity() hid %d, hiddenDiagnostics() = %d, want %d", hidden, h.hiddenDiagnostics(), want)
+			}
+		})
+	}
+}
+
+// TestLangHandler_filterSeverityCount tests that the hidden diagnostics of a document
+// are replaced by those of its next lint.
+func TestLangHandler_filterSeverityCount(t *testing.T) {
+	h := &langHandler{minimumSeverity: DSWarning}
+
+	hints := []Diagnostic{{Severity: DSHint}, {Severity: DSHint}}
+	h.filterSeverity("file:///a.go", hints)
+	h.filterSeverity("file:///b.go", hints[:1])
+
+	if got := h.hiddenDiagnostics(); got != 3 {
+		t.Errorf("hiddenDiagnostics() = %d, want 3", got)
+	}
+
+	h.filterSeverity("file:///a.go", nil)
+
+	if got := h.hiddenDiagnostics(); got != 1 {
+		t.Errorf("hiddenDiagnostics() = %d after a clean lint, want 1", got)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"log/slog"
+	"path/filepath"
+	"slices"
+)
+
+// addSiblingResults adds to published the diagnostics golangci-lint reported for the
+// other files of the directory of uri, and clears those of the open files and of the
+// files whose cached diagnostics the lint no longer reports. It is skipped when a
+// file of the directory changed during the run, as the issues of the other files may
+// be out of date then.
+func (h *langHandler) addSiblingResults(uri DocumentURI, resultID string, results, published map[DocumentURI][]Diagnostic) {
+	// A file lint or an overlay does not report the other files.
+	if h.lintTarget == lintTargetFile || h.needsOverlay(uri) {
+		return
+	}
+
+	if h.resultID(uri) != resultID {
+		slog.Debug("files changed during the lint, not publishing the other files of the package", "uri", uri)
+
+		return
+	}
+
+	siblings := make(map[DocumentURI][]Diagnostic)
+	dir := filepath.Dir(uriToPath(string(uri)))
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		sibling := h.documentURI(file)
+		if cached, ok := h.results.get(sibling); ok && len(cached.diagnostics) > 0 {
+			siblings[sibling] = []Diagnostic{}
+		}
+	}
+
+	// The open documents of the directory, whose lints may have been coalesced into
+	// this one, are published even without issues.
+	var open []DocumentURI
+	h.openMu.Lock()
+	for u, id := range h.open {
+		if slices.Contains(h.languages, id) && samePath(filepath.Dir(uriToPath(string(u))), dir) {
+			open = append(open, u)
+		}
+	}
+	h.openMu.Unlock()
+
+	for _, u := range open {
+		if !h.needsOverlay(u) {
+			siblings[u] = []Diagnostic{}
+		}
+	}
+
+	for sibling, diagnostics := range results {
+		siblings[sibling] = diagnostics
+	}
+	delete(siblings, uri)
+
+	for sibling, diagnostics := range siblings {
+		if h.excluded(sibling) {
+			continue
+		}
+
+		if h.onlyTouchedLines {
+			diagnostics = h.filterTouched(sibling, diagnostics)
+		}
+
+		diagnostics = h.dismissed.filter(sibling, diagnostics)
+		diagnostics, _ = h.filterSeverity(sibling, diagnostics)
+		diagnostics = h.mergeSameRange(diagnostics)
+
+		// The files of a directory share their resultID in package mode.
+		h.results.set(sibling, diagnostics, resultID)
+		published[sibling] = diagnostics
+	}
+}
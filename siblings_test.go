@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestLangHandler_siblingDiagnostics tests that the lint of a document publishes the
+// issues of the other files of its package, skips those of other directories and
+// clears the files whose issues are gone.
+func TestLangHandler_siblingDiagnostics(t *testing.T) {
+	root := t.TempDir()
+	for name, text := range map[string]string{
+		"go.mod":   "module example.com/sibling\n",
+		"a.go":     "package main\n\nfunc main() {\n\tf()\n}\n",
+		"b.go":     "package main\n\nfunc f() error {\n\treturn nil\n}\n",
+		"sub/c.go": "package sub\n",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	issue := func(filename string, line int) Issue {
+		i := Issue{FromLinter: "errcheck", Text: "Error return value is not checked"}
+		i.Pos.Filename, i.Pos.Line, i.Pos.Column = filename, line, 2
+
+		return i
+	}
+
+	command := fakeLinter(t, GolangCILintResult{Issues: []Issue{issue("a.go", 4), issue("b.go", 4), issue("sub/c.go", 1)}})
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(root)),
+		"initializationOptions": map[string]any{"command": command},
+	}, nil)
+
+	published := func() map[DocumentURI][]Diagnostic {
+		got := make(map[DocumentURI][]Diagnostic)
+		for _, raw := range client.received("textDocument/publishDiagnostics") {
+			var params PublishDiagnosticsParams
+			if err := json.Unmarshal(raw, &params); err != nil {
+				t.Fatalf("invalid publishDiagnostics params: %v", err)
+			}
+			got[params.URI] = params.Diagnostics
+		}
+
+		return got
+	}
+
+	a, b, c := pathToURI(filepath.Join(root, "a.go")), pathToURI(filepath.Join(root, "b.go")), pathToURI(filepath.Join(root, "sub", "c.go"))
+
+	h.lintDocument(a)
+	client.waitFor("textDocument/publishDiagnostics", func(json.RawMessage) bool {
+		return len(client.received("textDocument/publishDiagnostics")) >= 2
+	}, 5*time.Second)
+
+	got := published()
+	if len(got[a]) != 1 || len(got[b]) != 1 {
+		t.Errorf("published %+v, want a diagnostic for %s and %s", got, a, b)
+	}
+	if _, ok := got[c]; ok {
+		t.Errorf("published diagnostics for %s, which is outside the linted directory", c)
+	}
+
+	// The issue of b.go is fixed.
+	b2, err := json.Marshal(GolangCILintResult{Issues: []Issue{issue("a.go", 4)}})
+	if err != nil {
+		t.Fatalf("json.Marshal() returned unexpected error: %v", err)
+	}
+	if err := os.WriteFile(command[3], b2, 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "b.go"), []byte("package main\n\nfunc f() {}\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	before := len(client.received("textDocument/publishDiagnostics"))
+	h.lintDocument(a)
+	client.waitFor("textDocument/publishDiagnostics", func(json.RawMessage) bool {
+		return len(client.received("textDocument/publishDiagnostics")) >= before+2
+	}, 5*time.Second)
+
+	if got := published(); len(got[a]) != 1 || len(got[b]) != 0 {
+		t.Errorf("published %+v, want the diagnostics of %s cleared", got, b)
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// utf8BOM is the byte order mark some editors put at the start of UTF-8 files.
+const utf8BOM = "\xef\xbb\xbf"
+
+// sourceLines holds the lines of a source file without their line terminators,
+// used to convert golangci-lint positions into LSP positions.
+type sourceLines struct {
+	lines []string
+	// bom reports whether the file starts with a byte order mark, which is
+	// stripped from the first line.
+	bom bool
+}
+
+// readSourceLines reads the file at path and splits it into lines.
+// Both LF and CRLF line endings are handled, so a trailing '\r' is never part of a line.
+func readSourceLines(path string) (sourceLines, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return sourceLines{}, err
+	}
+
+	return newSourceLines(string(b)), nil
+}
+
+func newSourceLines(content string) sourceLines {
+	var src sourceLines
+
+	content, src.bom = strings.CutPrefix(content, utf8BOM)
+
+	src.lines = strings.Split(content, "\n")
+	for i, line := range src.lines {
+		src.lines[i] = strings.TrimSuffix(line, "\r")
+	}
+
+	return src
+}
+
+// position converts a golangci-lint position (1-based line, 1-based byte column)
+// into an LSP position (0-based line, UTF-16 character offset).
+// Columns past the end of the line are clamped to the line length.
+func (s sourceLines) position(line, column int) Position {
+	pos := Position{
+		Line:      max(line-1, 0),
+		Character: max(column-1, 0),
+	}
+
+	if pos.Line >= len(s.lines) {
+		return pos
+	}
+
+	// Go counts the byte order mark in the columns of the first line,
+	// while clients do not show it as part of the document.
+	offset := pos.Character
+	if pos.Line == 0 && s.bom {
+		offset = max(offset-len(utf8BOM), 0)
+	}
+
+	pos.Character = s.character(pos.Line, offset)
+
+	return pos
+}
+
+// character converts a byte offset in the 0-based line into a UTF-16 character offset.
+func (s sourceLines) character(line, offset int) int {
+	text := s.lines[line]
+
+	return utf16Len(text[:min(offset, len(text))])
+}
+
+// operatorChars are the characters of Go operators and punctuation spanning several
+// characters, like := or &&.
+const operatorChars = "+-*/%&|^<>=!:.~"
+
+// tokenEnd returns the end of the token at a golangci-lint position: an identifier or
+// number, a run of operator characters or a single other character. Without a column,
+// the token is the rest of the line. Whitespace, and lines or columns past the end of
+// the source, as when the file changed since golangci-lint ran, give an empty token.
+// When the source could not be read, the token ends at the start of the next line.
+func (s sourceLines) tokenEnd(line, column int) Position {
+	start := s.position(line, column)
+	if len(s.lines) == 0 {
+		return Position{Line: start.Line + 1}
+	}
+	if start.Line >= len(s.lines) || line < 1 {
+		return start
+	}
+
+	text := s.lines[start.Line]
+	if column < 1 {
+		return Position{Line: start.Line, Character: utf16Len(text)}
+	}
+
+	offset := column - 1
+	if start.Line == 0 && s.bom {
+		offset = max(offset-len(utf8BOM), 0)
+	}
+	if offset >= len(text) {
+		return start
+	}
+
+	r, size := utf8.DecodeRuneInString(text[offset:])
+	var in func(rune) bool
+	switch {
+	case isIdentRune(r):
+		in = isIdentRune
+	case strings.ContainsRune(operatorChars, r):
+		in = func(r rune) bool { return strings.ContainsRune(operatorChars, r) }
+	case unicode.IsSpace(r):
+		return start
+	default:
+		return Position{Line: start.Line, Character: s.character(start.Line, offset+size)}
+	}
+
+	end := offset
+	for end < len(text) {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if !in(r) {
+			break
+		}
+		end += size
+	}
+
+	return Position{Line: start.Line, Character: s.character(start.Line, end)}
+}
+
+// isIdentRune reports whether r may be part of a Go identifier or number.
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// utf16Len returns the number of UTF-16 code units needed to encode s.
+func utf16Len(s string) int {
+	n := 0
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		n += utf16.RuneLen(r)
+		s = s[size:]
+	}
+
+	return n
+}
+
+// fullRange returns the range covering the whole source.
+func (s sourceLines) fullRange() Range {
+	if len(s.lines) == 0 {
+		return Range{}
+	}
+
+	last := len(s.lines) - 1
+
+	return Range{End: Position{Line: last, Character: utf16Len(s.lines[last])}}
+}
+
+// lineRange returns the range covering the 1-based lines from to to, when they span
+// more than one line. The range ends at the end of the last line, or at the start of
+// the next one when the source does not have it.
+func (s sourceLines) lineRange(from, to int) (Range, bool) {
+	if from < 1 || to <= from {
+		return Range{}, false
+	}
+
+	return Range{Start: Position{Line: from - 1}, End: s.lineEnd(to)}, true
+}
+
+// lineEnd returns the end of the 1-based line, or the start of the next one when the
+// source does not have it.
+func (s sourceLines) lineEnd(line int) Position {
+	if line-1 < len(s.lines) {
+		return Position{Line: line - 1, Character: utf16Len(s.lines[line-1])}
+	}
+
+	return Position{Line: line}
+}
+
+// clamp moves pos inside the source: lines past the end go to the end of the last
+// line and characters past the end of their line to the end of that line.
+func (s sourceLines) clamp(pos Position) (Position, bool) {
+	if len(s.lines) == 0 {
+		return pos, false
+	}
+
+	clamped := pos
+	if last := len(s.lines) - 1; clamped.Line > last {
+		clamped = Position{Line: last, Character: utf16Len(s.lines[last])}
+	} else if n := utf16Len(s.lines[clamped.Line]); clamped.Character > n {
+		clamped.Character = n
+	}
+
+	return clamped, clamped != pos
+}
+
+// clampDiagnostics returns the diagnostics with their ranges clamped to the source,
+// and how many of them were out of bounds. The given slice is left untouched.
+func (s sourceLines) clampDiagnostics(diagnostics []Diagnostic) ([]Diagnostic, int) {
+	var clamped []Diagnostic
+	count := 0
+
+	for i, d := range diagnostics {
+		start, startClamped := s.clamp(d.Range.Start)
+		end, endClamped := s.clamp(d.Range.End)
+		if !startClamped && !endClamped {
+			continue
+		}
+
+		if clamped == nil {
+			clamped = slices.Clone(diagnostics)
+		}
+		clamped[i].Range = Range{Start: start, End: end}
+		count++
+	}
+
+	if clamped == nil {
+		return diagnostics, 0
+	}
+
+	return clamped, count
+}
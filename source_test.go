@@ -0,0 +1,224 @@
+package main
+
+import "testing"
+
+func TestSourceLinesPosition(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		line   int
+		column int
+		want   Position
+	}{
+		{
+			name:   "crlf column within the line",
+			file:   "./testdata/positions/crlf.go",
+			line:   3,
+			column: 5,
+			want:   Position{Line: 2, Character: 4},
+		},
+		{
+			name:   "crlf column at the carriage return is clamped to the line end",
+			file:   "./testdata/positions/crlf.go",
+			line:   3,
+			column: 16,
+			want:   Position{Line: 2, Character: 15},
+		},
+		{
+			name:   "crlf column past the line end",
+			file:   "./testdata/positions/crlf.go",
+			line:   3,
+			column: 40,
+			want:   Position{Line: 2, Character: 15},
+		},
+		{
+			name: "crlf multi-byte characters are counted in UTF-16 code units",
+			file: "./testdata/positions/crlf.go",
+			line: 5,
+			// "// café 😀 " is 14 bytes, 11 UTF-16 code units.
+			column: 15,
+			want:   Position{Line: 4, Character: 11},
+		},
+		{
+			name:   "bom issue at 1:1",
+			file:   "./testdata/positions/bom.go",
+			line:   1,
+			column: 1,
+			want:   Position{Line: 0, Character: 0},
+		},
+		{
+			name: "bom bytes are not counted on the first line",
+			file: "./testdata/positions/bom.go",
+			line: 1,
+			// "positions" starts after the 3 byte BOM and "package ".
+			column: 12,
+			want:   Position{Line: 0, Character: 8},
+		},
+		{
+			name:   "bom does not affect later lines",
+			file:   "./testdata/positions/bom.go",
+			line:   3,
+			column: 5,
+			want:   Position{Line: 2, Character: 4},
+		},
+		{
+			name:   "line past the end of the file",
+			file:   "./testdata/positions/crlf.go",
+			line:   40,
+			column: 3,
+			want:   Position{Line: 39, Character: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := readSourceLines(tt.file)
+			if err != nil {
+				t.Fatalf("readSourceLines() returned unexpected error: %v", err)
+			}
+
+			if got := src.position(tt.line, tt.column); got != tt.want {
+				t.Errorf("position(%d, %d) = %+v, want %+v", tt.line, tt.column, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadSourceLinesStripsCarriageReturns(t *testing.T) {
+	src, err := readSourceLines("./testdata/positions/crlf.go")
+	if err != nil {
+		t.Fatalf("readSourceLines() returned unexpected error: %v", err)
+	}
+
+	for i, line := range src.lines {
+		if len(line) > 0 && line[len(line)-1] == '\r' {
+			t.Errorf("line %d ends with a carriage return", i+1)
+		}
+	}
+}
+
+func TestReadSourceLinesStripsBOM(t *testing.T) {
+	src, err := readSourceLines("./testdata/positions/bom.go")
+	if err != nil {
+		t.Fatalf("readSourceLines() returned unexpected error: %v", err)
+	}
+
+	if !src.bom {
+		t.Error("bom = false, want true")
+	}
+
+	if want := "package positions"; src.lines[0] != want {
+		t.Errorf("first line = %q, want %q", src.lines[0], want)
+	}
+}
+
+func TestSourceLinesClampDiagnostics(t *testing.T) {
+	src := newSourceLines("package main\n\nfunc main() {}\n")
+
+	at := func(line, character int) Range {
+		pos := Position{Line: line, Character: character}
+
+		return Range{Start: pos, End: pos}
+	}
+
+	diagnostics := []Diagnostic{
+		{Range: at(2, 5), Message: "within bounds"},
+		{Range: at(2, 40), Message: "past the end of the line"},
+		{Range: at(7, 1), Message: "past the end of the file"},
+	}
+
+	got, clamped := src.clampDiagnostics(diagnostics)
+	if clamped != 2 {
+		t.Errorf("clampDiagnostics() clamped %d diagnostics, want 2", clamped)
+	}
+
+	want := []Range{at(2, 5), at(2, 14), at(3, 0)}
+	for i, d := range got {
+		if d.Range != want[i] {
+			t.Errorf("%s: range = %+v, want %+v", d.Message, d.Range, want[i])
+		}
+	}
+
+	if diagnostics[2].Range != at(7, 1) {
+		t.Error("clampDiagnostics() modified the given diagnostics")
+	}
+}
+
+func TestSourceLinesLineRange(t *testing.T) {
+	src := newSourceLines("package main\n\nfunc main() {\n\t_ = \"café\"\n}\n")
+
+	tests := []struct {
+		name     string
+		from, to int
+		want     Range
+		wantOK   bool
+	}{
+		{name: "missing"},
+		{name: "single line", from: 3, to: 3},
+		{name: "reversed", from: 5, to: 3},
+		{name: "missing from", to: 3},
+		{
+			name:   "block",
+			from:   3,
+			to:     4,
+			want:   Range{Start: Position{Line: 2}, End: Position{Line: 3, Character: 11}},
+			wantOK: true,
+		},
+		{
+			name:   "past the end of the file",
+			from:   3,
+			to:     9,
+			want:   Range{Start: Position{Line: 2}, End: Position{Line: 9}},
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := src.lineRange(tt.from, tt.to)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("lineRange(%d, %d) = %+v, %v, want %+v, %v", tt.from, tt.to, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSourceLinesTokenEnd(t *testing.T) {
+	// "\tcafé := naïve + 😀x" has café at the 0-based bytes 1-5, := at 7-8, naïve at 10-15, + at 17
+	// and 😀 at 19-22.
+	src := newSourceLines("package main\n\tcafé := naïve + 😀x\n")
+
+	tests := []struct {
+		name   string
+		src    sourceLines
+		line   int
+		column int
+		want   Position
+	}{
+		{name: "identifier with a multi-byte character", src: src, line: 2, column: 2, want: Position{Line: 1, Character: 5}},
+		{name: "operator", src: src, line: 2, column: 8, want: Position{Line: 1, Character: 8}},
+		{name: "identifier after multi-byte characters", src: src, line: 2, column: 11, want: Position{Line: 1, Character: 14}},
+		{name: "single operator character", src: src, line: 2, column: 18, want: Position{Line: 1, Character: 16}},
+		{name: "surrogate pair", src: src, line: 2, column: 20, want: Position{Line: 1, Character: 19}},
+		{name: "whitespace", src: src, line: 2, column: 7, want: Position{Line: 1, Character: 5}},
+		{name: "no column", src: src, line: 2, want: Position{Line: 1, Character: 20}},
+		{name: "column past the end of the line", src: src, line: 2, column: 40, want: Position{Line: 1, Character: 20}},
+		{name: "line past the end of the file", src: src, line: 9, column: 3, want: Position{Line: 8, Character: 2}},
+		{name: "unreadable source", line: 2, column: 2, want: Position{Line: 2}},
+		{
+			name:   "bom bytes are not counted on the first line",
+			src:    newSourceLines(utf8BOM + "package main\n"),
+			line:   1,
+			column: 4,
+			want:   Position{Line: 0, Character: 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.src.tokenEnd(tt.line, tt.column); got != tt.want {
+				t.Errorf("tokenEnd(%d, %d) = %+v, want %+v", tt.line, tt.column, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sync"
+)
+
+// defaultStderrPattern selects the golangci-lint log lines forwarded to the client.
+const defaultStderrPattern = `level=`
+
+// lineWriter captures everything written to it and calls onLine for each complete line
+// as it arrives. It lets stderr be streamed while the process runs and still be
+// available in full afterwards.
+type lineWriter struct {
+	onLine func(line string)
+
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	pending []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	w.pending = append(w.pending, p...)
+
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+
+		line := bytes.TrimSuffix(w.pending[:i], []byte("\r"))
+		w.pending = w.pending[i+1:]
+
+		if w.onLine != nil {
+			w.onLine(string(line))
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush passes a trailing line without newline to onLine.
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.pending) > 0 && w.onLine != nil {
+		w.onLine(string(w.pending))
+	}
+	w.pending = nil
+}
+
+// Bytes returns everything written so far.
+func (w *lineWriter) Bytes() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return bytes.Clone(w.buf.Bytes())
+}
+
+// compileStderrPattern returns the pattern of the stderrPattern option. Unset selects
+// the default and an empty string disables forwarding.
+func compileStderrPattern(pattern *string) (*regexp.Regexp, error) {
+	if pattern == nil {
+		return regexp.MustCompile(defaultStderrPattern), nil
+	}
+
+	if *pattern == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(*pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%q is an invalid regular expression: %q", "stderrPattern", *pattern)
+	}
+
+	return re, nil
+}
+
+// logStderrLine forwards a golangci-lint stderr line matching the stderr pattern as a
+// window/logMessage, without its colors. Complaints about deprecated flags are shown
+// to the user.
+func (h *langHandler) logStderrLine(line string) {
+	line = stripANSI(line)
+
+	if f, ok := stderrDeprecatedFlag(line); ok {
+		h.warnDeprecatedFlag(f)
+	}
+
+	if h.stderrPattern == nil || h.conn == nil || !h.stderrPattern.MatchString(line) {
+		return
+	}
+
+	if err := h.conn.Notify(context.Background(), "window/logMessage", &LogMessageParams{Type: MTLog, Message: line}); err != nil {
+		slog.Debug("failed to forward golangci-lint output", "error", err)
+	}
+}
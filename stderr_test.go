@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestLineWriter tests that lines are reported as they complete and the output is kept whole.
+func TestLineWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   []string
+	}{
+		{
+			name:   "one line per write",
+			writes: []string{"level=info msg=\"[loader] Go packages loading\"\n", "level=info msg=\"[runner] ran\"\n"},
+			want:   []string{`level=info msg="[loader] Go packages loading"`, `level=info msg="[runner] ran"`},
+		},
+		{
+			name:   "line split across writes",
+			writes: []string{"level=in", "fo msg=a\nlevel=", "info msg=b\n"},
+			want:   []string{"level=info msg=a", "level=info msg=b"},
+		},
+		{
+			name:   "CRLF",
+			writes: []string{"a\r\nb\r\n"},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:   "trailing line without newline",
+			writes: []string{"a\nError: can't load config"},
+			want:   []string{"a", "Error: can't load config"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			w := &lineWriter{onLine: func(line string) { got = append(got, line) }}
+
+			var all string
+			for _, s := range tt.writes {
+				if _, err := w.Write([]byte(s)); err != nil {
+					t.Fatalf("Write() returned unexpected error: %v", err)
+				}
+				all += s
+			}
+			w.Flush()
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("lines = %q, want %q", got, tt.want)
+			}
+
+			if string(w.Bytes()) != all {
+				t.Errorf("Bytes() = %q, want %q", w.Bytes(), all)
+			}
+		})
+	}
+}
+
+// TestCompileStderrPattern tests the default and disabled stderr patterns.
+func TestCompileStderrPattern(t *testing.T) {
+	empty := ""
+	custom := `\[runner\]`
+
+	tests := []struct {
+		name    string
+		pattern *string
+		line    string
+		want    bool
+	}{
+		{name: "default", pattern: nil, line: `level=info msg="[runner] ran"`, want: true},
+		{name: "default skips other lines", pattern: nil, line: "panic: oops", want: false},
+		{name: "disabled", pattern: &empty, line: `level=info msg="[runner] ran"`, want: false},
+		{name: "custom", pattern: &custom, line: `level=info msg="[runner] ran"`, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := compileStderrPattern(tt.pattern)
+			if err != nil {
+				t.Fatalf("compileStderrPattern() returned unexpected error: %v", err)
+			}
+
+			if got := re != nil && re.MatchString(tt.line); got != tt.want {
+				t.Errorf("match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLangHandler_logStderrLine tests that forwarded stderr lines lose their colors.
+func TestLangHandler_logStderrLine(t *testing.T) {
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"initializationOptions": map[string]any{"command": []string{"golangci-lint", "run"}, "stderrPattern": "."},
+	}, nil)
+
+	h.logStderrLine("\x1b[36mINFO\x1b[0m [runner] linters took 1.2s")
+
+	raw := client.waitFor("window/logMessage", func(json.RawMessage) bool { return true }, 5*time.Second)
+
+	var params LogMessageParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+	}
+
+	if want := "INFO [runner] linters took 1.2s"; params.Message != want {
+		t.Errorf("logMessage = %q, want %q", params.Message, want)
+	}
+}
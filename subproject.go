@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// compileSubprojects compiles the subprojects option, skipping invalid globs. All invalid
+// globs are reported in the returned error.
+func compileSubprojects(globs []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(globs))
+
+	var invalid []string
+	for _, g := range globs {
+		re, err := compileGlob(strings.TrimSuffix(filepath.ToSlash(g), "/"))
+		if err != nil {
+			invalid = append(invalid, err.Error())
+
+			continue
+		}
+
+		compiled = append(compiled, re)
+	}
+
+	if len(invalid) > 0 {
+		return compiled, fmt.Errorf("\"subprojects\" has invalid entries: %s", strings.Join(invalid, ", "))
+	}
+
+	return compiled, nil
+}
+
+// projectRoot returns the root golangci-lint runs in for files in dir: the nearest
+// ancestor matching a subproject, or the root of the workspace folder of dir.
+func (h *langHandler) projectRoot(dir string) string {
+	root := h.workspaceRoot(dir)
+	if len(h.subprojects) == 0 || root == "" {
+		return root
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return root
+	}
+
+	for ; rel != "."; rel = filepath.Dir(rel) {
+		slashed := filepath.ToSlash(rel)
+		for _, re := range h.subprojects {
+			if re.MatchString(slashed) {
+				return filepath.Join(root, rel)
+			}
+		}
+	}
+
+	return root
+}
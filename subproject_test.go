@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLangHandler_projectRoot(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "workspace")
+
+	tests := []struct {
+		name        string
+		subprojects []string
+		dir         string
+		want        string
+	}{
+		{name: "no subprojects", dir: filepath.Join(root, "services", "api"), want: root},
+		{name: "glob", subprojects: []string{"services/*"}, dir: filepath.Join(root, "services", "api"), want: filepath.Join(root, "services", "api")},
+		{name: "below a subproject", subprojects: []string{"services/*"}, dir: filepath.Join(root, "services", "api", "internal"), want: filepath.Join(root, "services", "api")},
+		{name: "nearest wins", subprojects: []string{"tools", "tools/gen"}, dir: filepath.Join(root, "tools", "gen", "cmd"), want: filepath.Join(root, "tools", "gen")},
+		{name: "trailing slash", subprojects: []string{"tools/"}, dir: filepath.Join(root, "tools"), want: filepath.Join(root, "tools")},
+		{name: "not under a subproject", subprojects: []string{"services/*"}, dir: filepath.Join(root, "cmd"), want: root},
+		{name: "outside the workspace", subprojects: []string{"*"}, dir: filepath.Join(string(filepath.Separator), "elsewhere"), want: root},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subprojects, _ := compileSubprojects(tt.subprojects)
+			h := &langHandler{rootDir: root, subprojects: subprojects}
+			if got := h.projectRoot(tt.dir); got != tt.want {
+				t.Errorf("projectRoot(%q) = %q, want %q", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLangHandler_subprojects tests that files in a subproject are linted from it.
+func TestLangHandler_subprojects(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "services", "api")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+	}
+
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	// Issue paths are relative to the subproject, where golangci-lint runs.
+	issue := Issue{FromLinter: "unused", Text: "func `main` is unused"}
+	issue.Pos.Filename = "main.go"
+	issue.Pos.Line = 3
+	issue.Pos.Column = 6
+
+	command := fakeLinter(t, GolangCILintResult{Issues: []Issue{issue}})
+	record := filepath.Join(t.TempDir(), "pwd")
+	command = []string{"sh", "-c", `pwd > "$1"; cat "$0"; exit 1`, command[3], record}
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	uri := pathToURI(path)
+	client.call("initialize", map[string]any{
+		"rootUri": string(pathToURI(root)),
+		"initializationOptions": map[string]any{
+			"command":     command,
+			"subprojects": []string{"services/*"},
+		},
+	}, nil)
+
+	client.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "go", "version": 1, "text": "package main\n\nfunc main() {}\n"},
+	})
+
+	raw := client.waitFor("textDocument/publishDiagnostics", func(json.RawMessage) bool { return true }, 5*time.Second)
+
+	var params PublishDiagnosticsParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		t.Fatalf("json.Unmarshal() returned unexpected error: %v", err)
+	}
+
+	if params.URI != uri || len(params.Diagnostics) != 1 || params.Diagnostics[0].Range.Start.Line != 2 {
+		t.Errorf("unexpected diagnostics for %s: %+v", params.URI, params.Diagnostics)
+	}
+
+	b, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned unexpected error: %v", err)
+	}
+
+	got, _ := filepath.EvalSymlinks(strings.TrimSpace(string(b)))
+	if want, _ := filepath.EvalSymlinks(dir); got != want {
+		t.Errorf("golangci-lint ran in %q, want %q", got, want)
+	}
+}
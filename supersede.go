@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"sync"
+)
+
+// runningLint is a lint of the linter goroutine that a newer request for the same
+// target may supersede.
+type runningLint struct {
+	cancel     context.CancelFunc
+	superseded bool
+}
+
+// runningLints are the running lints by target, as returned by lintKey.
+type runningLints struct {
+	mu    sync.Mutex
+	lints map[string]*runningLint
+}
+
+// start records the lint of key, which cancel aborts.
+func (r *runningLints) start(key string, cancel context.CancelFunc) *runningLint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l := &runningLint{cancel: cancel}
+	if r.lints == nil {
+		r.lints = make(map[string]*runningLint)
+	}
+	r.lints[key] = l
+
+	return l
+}
+
+// supersede cancels the running lint of key, if any, and reports whether there was one.
+func (r *runningLints) supersede(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l, ok := r.lints[key]
+	if !ok {
+		return false
+	}
+
+	l.superseded = true
+	l.cancel()
+	delete(r.lints, key)
+
+	return true
+}
+
+// finish forgets the lint of key and reports whether it was superseded, in which case
+// its results must be dropped. A lint that was not superseded by then no longer can be:
+// a newer request is linted after it, and publishes after it.
+func (r *runningLints) finish(key string, l *runningLint) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lints[key] == l {
+		delete(r.lints, key)
+	}
+
+	return l.superseded
+}
+
+// lintKey returns the target golangci-lint lints for the document: its directory, or
+// the document itself in file mode and in an overlay.
+func (h *langHandler) lintKey(uri DocumentURI) string {
+	if h.lintTarget == lintTargetFile || h.needsOverlay(uri) {
+		return string(uri)
+	}
+
+	return filepath.Dir(uriToPath(string(uri)))
+}
+
+// supersedeRunningLint aborts the running lint of the target of the document, whose
+// results a newer lint is about to replace.
+func (h *langHandler) supersedeRunningLint(uri DocumentURI) {
+	if h.running.supersede(h.lintKey(uri)) {
+		slog.Info("cancelling the lint superseded by a newer request", "uri", uri)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRunningLints(t *testing.T) {
+	tests := []struct {
+		name string
+		// supersede is called for these keys before the lint of "/p" finishes.
+		supersede      []string
+		wantSuperseded bool
+	}{
+		{name: "not superseded"},
+		{name: "other target", supersede: []string{"/q"}},
+		// The process exited, but the results were not published yet.
+		{name: "superseded after the run", supersede: []string{"/p"}, wantSuperseded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r runningLints
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			l := r.start("/p", cancel)
+			for _, key := range tt.supersede {
+				r.supersede(key)
+			}
+
+			if got := r.finish("/p", l); got != tt.wantSuperseded {
+				t.Errorf("finish() = %v, want %v", got, tt.wantSuperseded)
+			}
+			if (ctx.Err() != nil) != tt.wantSuperseded {
+				t.Errorf("context cancelled = %v, want %v", ctx.Err() != nil, tt.wantSuperseded)
+			}
+
+			// A finished lint can no longer be superseded.
+			if r.supersede("/p") {
+				t.Errorf("supersede() found the finished lint")
+			}
+		})
+	}
+}
+
+// TestLangHandler_supersedeLint tests that a newer request for the directory of a running
+// lint kills golangci-lint and that the killed run publishes nothing.
+func TestLangHandler_supersedeLint(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake linter needs sh")
+	}
+
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	// The first run hangs until it is killed, the next ones report nothing.
+	marker := filepath.Join(t.TempDir(), "started")
+	command := []string{"sh", "-c", `if [ -e "$0" ]; then exit 0; fi; touch "$0"; exec sleep 30`, marker}
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(root)),
+		"initializationOptions": map[string]any{"command": command, "lintOnOpen": false, "debounceMs": 0},
+	}, nil)
+
+	uri := pathToURI(path)
+	start := time.Now()
+
+	h.queue(uri)
+	for {
+		if _, err := os.Stat(marker); err == nil {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("golangci-lint did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	h.queue(uri)
+
+	client.waitFor("textDocument/publishDiagnostics", func(json.RawMessage) bool { return true }, 5*time.Second)
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("lints took %v, want the first run killed", elapsed)
+	}
+
+	// Give a stale publication of the killed run the time to arrive.
+	time.Sleep(100 * time.Millisecond)
+
+	published := client.received("textDocument/publishDiagnostics")
+	if len(published) != 1 {
+		t.Fatalf("published %d times, want once by the newer run", len(published))
+	}
+
+	var params PublishDiagnosticsParams
+	if err := json.Unmarshal(published[0], &params); err != nil {
+		t.Fatalf("invalid publishDiagnostics params: %v", err)
+	}
+	if len(params.Diagnostics) != 0 {
+		t.Errorf("published %+v, want no diagnostics", params.Diagnostics)
+	}
+}
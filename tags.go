@@ -0,0 +1,84 @@
+package main
+
+import (
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// defaultDiagnosticTags map the issues of linters, or of their rules named as in
+// lintDocumentation, onto diagnostic tags.
+var defaultDiagnosticTags = map[string][]DiagnosticTag{
+	"unused":             {DTUnnecessary},
+	"unparam":            {DTUnnecessary},
+	"ineffassign":        {DTUnnecessary},
+	"staticcheck SA1019": {DTDeprecated},
+}
+
+// deprecatedRe matches the messages of revive reporting the use of deprecated code.
+var deprecatedRe = regexp.MustCompile(`(?i)\bdeprecated\b`)
+
+// parseDiagnosticTag parses the tag names accepted by the diagnosticTags option.
+func parseDiagnosticTag(name string) (DiagnosticTag, bool) {
+	switch strings.ToLower(name) {
+	case "unnecessary":
+		return DTUnnecessary, true
+	case "deprecated":
+		return DTDeprecated, true
+	default:
+		return 0, false
+	}
+}
+
+// compileDiagnosticTags merges the diagnosticTags option into the default tags,
+// skipping unknown tag names.
+func compileDiagnosticTags(overrides map[string][]string) map[string][]DiagnosticTag {
+	tags := maps.Clone(defaultDiagnosticTags)
+
+	for key, names := range overrides {
+		compiled := make([]DiagnosticTag, 0, len(names))
+		for _, name := range names {
+			tag, ok := parseDiagnosticTag(name)
+			if !ok {
+				slog.Warn("ignoring unknown diagnostic tag", "key", key, "tag", name)
+
+				continue
+			}
+
+			compiled = append(compiled, tag)
+		}
+
+		tags[key] = compiled
+	}
+
+	return tags
+}
+
+// tagDiagnostic sets the tags of the diagnostic of an issue that the client supports.
+// An entry for the rule of the issue wins over one for its linter.
+func (h *langHandler) tagDiagnostic(d *Diagnostic, linter, text string) {
+	if len(h.tagSupport) == 0 || linter == "" {
+		return
+	}
+
+	tags := h.diagnosticTags
+	if tags == nil {
+		tags = defaultDiagnosticTags
+	}
+
+	issueTags, ok := tags[issueDocumentation(linter, text).name]
+	if !ok {
+		issueTags, ok = tags[linter]
+	}
+	if !ok && linter == "revive" && deprecatedRe.MatchString(text) {
+		issueTags = []DiagnosticTag{DTDeprecated}
+	}
+
+	for _, tag := range issueTags {
+		if slices.Contains(h.tagSupport, tag) {
+			d.Tags = append(d.Tags, tag)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLangHandler_tagDiagnostic(t *testing.T) {
+	both := []DiagnosticTag{DTUnnecessary, DTDeprecated}
+
+	tests := []struct {
+		name       string
+		tagSupport []DiagnosticTag
+		overrides  map[string][]string
+		linter     string
+		text       string
+		want       []DiagnosticTag
+	}{
+		{name: "unsupported", linter: "unused", text: "func `f` is unused"},
+		{name: "unused", tagSupport: both, linter: "unused", text: "func `f` is unused", want: []DiagnosticTag{DTUnnecessary}},
+		{name: "unparam", tagSupport: both, linter: "unparam", text: "`f` - `x` is unused", want: []DiagnosticTag{DTUnnecessary}},
+		{name: "ineffassign", tagSupport: both, linter: "ineffassign", text: "ineffectual assignment to err", want: []DiagnosticTag{DTUnnecessary}},
+		{
+			name:       "staticcheck SA1019",
+			tagSupport: both,
+			linter:     "staticcheck",
+			text:       "SA1019: ioutil.ReadFile has been deprecated since Go 1.19",
+			want:       []DiagnosticTag{DTDeprecated},
+		},
+		{name: "other staticcheck check", tagSupport: both, linter: "staticcheck", text: "SA4006: this value of err is never used"},
+		{
+			name:       "revive deprecation",
+			tagSupport: both,
+			linter:     "revive",
+			text:       "use of deprecated function Foo",
+			want:       []DiagnosticTag{DTDeprecated},
+		},
+		{name: "other revive rule", tagSupport: both, linter: "revive", text: "exported: exported function Foo should have comment"},
+		{name: "tag the client lacks", tagSupport: []DiagnosticTag{DTDeprecated}, linter: "unused", text: "func `f` is unused"},
+		{
+			name:       "extended",
+			tagSupport: both,
+			overrides:  map[string][]string{"wastedassign": {"Unnecessary", "bogus"}},
+			linter:     "wastedassign",
+			text:       "reassigned, but never used afterwards",
+			want:       []DiagnosticTag{DTUnnecessary},
+		},
+		{
+			name:       "disabled",
+			tagSupport: both,
+			overrides:  map[string][]string{"unused": {}},
+			linter:     "unused",
+			text:       "func `f` is unused",
+		},
+		{
+			name:       "rule wins over the linter",
+			tagSupport: both,
+			overrides:  map[string][]string{"staticcheck": {"unnecessary"}},
+			linter:     "staticcheck",
+			text:       "SA1019: ioutil.ReadFile has been deprecated since Go 1.19",
+			want:       []DiagnosticTag{DTDeprecated},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &langHandler{tagSupport: tt.tagSupport, diagnosticTags: compileDiagnosticTags(tt.overrides)}
+
+			var d Diagnostic
+			h.tagDiagnostic(&d, tt.linter, tt.text)
+
+			if diff := cmp.Diff(tt.want, d.Tags); diff != "" {
+				t.Errorf("tags mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// hasTestdataElement reports whether a directory of path is named testdata, which the
+// go tool and golangci-lint ignore.
+func hasTestdataElement(path string) bool {
+	dir := filepath.ToSlash(filepath.Dir(filepath.Clean(path)))
+
+	return slices.Contains(strings.Split(dir, "/"), "testdata")
+}
+
+// inTestdata reports whether the file at path is under a testdata directory. Inside the
+// workspace, only the directories below the root count, so that a workspace opened in a
+// testdata directory is still linted.
+func (h *langHandler) inTestdata(path string) bool {
+	if root := h.workspaceRoot(path); root != "" && h.inWorkspace(path) {
+		if rel, err := filepath.Rel(root, path); err == nil {
+			path = rel
+		}
+	}
+
+	return hasTestdataElement(path)
+}
+
+// testdataBelowBase reports whether absPath is under a testdata directory below its
+// base directory while the issue path does not name one, in which case a suffix match
+// of the issue path must not attach the issue to the testdata file.
+func testdataBelowBase(issuePath, absPath string, baseDirs []string) bool {
+	if hasTestdataElement(issuePath) {
+		return false
+	}
+
+	for _, baseDir := range baseDirs {
+		base, err := filepath.Abs(baseDir)
+		if err != nil {
+			continue
+		}
+
+		rel, err := filepath.Rel(base, absPath)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		return hasTestdataElement(rel)
+	}
+
+	return false
+}
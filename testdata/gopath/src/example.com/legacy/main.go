@@ -0,0 +1,7 @@
+package main
+
+import "example.com/legacy/util"
+
+func main() {
+	util.Close()
+}
@@ -0,0 +1,8 @@
+package util
+
+import "os"
+
+func Close() {
+	f, _ := os.Open("legacy.txt")
+	f.Close()
+}
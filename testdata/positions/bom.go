@@ -0,0 +1,3 @@
+﻿package positions
+
+var foo = "bar"
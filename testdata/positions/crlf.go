@@ -0,0 +1,6 @@
+package positions
+
+var foo = "bar"
+
+// café 😀 x
+var baz = 1
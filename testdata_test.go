@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLangHandler_inTestdata(t *testing.T) {
+	root := filepath.FromSlash("/work/project")
+
+	tests := []struct {
+		name string
+		root string
+		path string
+		want bool
+	}{
+		{name: "fixture", root: root, path: "/work/project/parser/testdata/input.go", want: true},
+		{name: "nested fixture", root: root, path: "/work/project/testdata/a/b/input.go", want: true},
+		{name: "package", root: root, path: "/work/project/parser/parser.go"},
+		{name: "testdata file name", root: root, path: "/work/project/parser/testdata.go"},
+		{name: "similar directory", root: root, path: "/work/project/mytestdata/input.go"},
+		{name: "workspace in testdata", root: filepath.FromSlash("/work/testdata/project"), path: "/work/testdata/project/main.go"},
+		{name: "no workspace", path: "/work/testdata/input.go", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &langHandler{rootDir: tt.root}
+
+			if got := h.inTestdata(filepath.FromSlash(tt.path)); got != tt.want {
+				t.Errorf("inTestdata(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssueMatchesPath_testdata(t *testing.T) {
+	tests := []struct {
+		name      string
+		issuePath string
+		absPath   string
+		want      bool
+	}{
+		{name: "package file", issuePath: "parser/input.go", absPath: "/project/parser/input.go", want: true},
+		{name: "suffix of a fixture", issuePath: "parser/input.go", absPath: "/project/internal/testdata/parser/input.go"},
+		{name: "fixture named by the issue", issuePath: "testdata/parser/input.go", absPath: "/project/internal/testdata/parser/input.go", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			absPath, err := filepath.Abs(filepath.FromSlash(tt.absPath))
+			if err != nil {
+				t.Fatalf("filepath.Abs error: %v", err)
+			}
+
+			if got := issueMatchesPath(filepath.FromSlash(tt.issuePath), absPath, []string{"/elsewhere", "/project"}); got != tt.want {
+				t.Errorf("issueMatchesPath(%q, %q) = %v, want %v", tt.issuePath, tt.absPath, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLangHandler_testdataSkipped tests that saving a testdata file publishes empty
+// diagnostics without running golangci-lint.
+func TestLangHandler_testdataSkipped(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "parser", "testdata", "input.go")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("package broken\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	ran := filepath.Join(t.TempDir(), "ran")
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(root)),
+		"initializationOptions": map[string]any{"command": []string{"sh", "-c", `touch "$0"; exit 1`, ran}},
+	}, nil)
+
+	client.notify("textDocument/didSave", map[string]any{"textDocument": map[string]any{"uri": string(pathToURI(path))}})
+
+	client.waitFor("textDocument/publishDiagnostics", func(raw json.RawMessage) bool {
+		var params PublishDiagnosticsParams
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return false
+		}
+
+		return params.URI == pathToURI(path) && len(params.Diagnostics) == 0
+	}, 5*time.Second)
+
+	if _, err := os.Stat(ran); err == nil {
+		t.Error("golangci-lint ran for a testdata file")
+	}
+}
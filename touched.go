@@ -0,0 +1,76 @@
+package main
+
+import (
+	"slices"
+	"strings"
+)
+
+// lineRange is an inclusive range of zero-based lines.
+type lineRange struct {
+	start, end int
+}
+
+// touchedLines tracks the lines of a document modified since it was opened.
+// Ranges are kept sorted and merged, and shift along with later edits.
+type touchedLines struct {
+	ranges []lineRange
+}
+
+// apply records a content change that replaced r with text.
+// A nil range means the whole document was replaced.
+func (t *touchedLines) apply(r *Range, text string) {
+	added := strings.Count(text, "\n")
+
+	if r == nil {
+		t.ranges = []lineRange{{start: 0, end: added}}
+
+		return
+	}
+
+	start, end := r.Start.Line, r.End.Line
+	delta := added - (end - start)
+
+	ranges := make([]lineRange, 0, len(t.ranges)+1)
+	for _, lr := range t.ranges {
+		switch {
+		case lr.end < start:
+			ranges = append(ranges, lr)
+		case lr.start > end:
+			ranges = append(ranges, lineRange{start: lr.start + delta, end: lr.end + delta})
+		default:
+			// Keep the parts outside of the replaced lines; the replaced lines are added below.
+			if lr.start < start {
+				ranges = append(ranges, lineRange{start: lr.start, end: start - 1})
+			}
+			if lr.end > end {
+				ranges = append(ranges, lineRange{start: end + 1 + delta, end: lr.end + delta})
+			}
+		}
+	}
+	ranges = append(ranges, lineRange{start: start, end: start + added})
+
+	slices.SortFunc(ranges, func(a, b lineRange) int { return a.start - b.start })
+
+	merged := ranges[:0]
+	for _, lr := range ranges {
+		if n := len(merged); n > 0 && lr.start <= merged[n-1].end+1 {
+			merged[n-1].end = max(merged[n-1].end, lr.end)
+
+			continue
+		}
+
+		merged = append(merged, lr)
+	}
+	t.ranges = merged
+}
+
+// overlaps reports whether any line from start to end (inclusive) was touched.
+func (t *touchedLines) overlaps(start, end int) bool {
+	for _, lr := range t.ranges {
+		if lr.start <= end && start <= lr.end {
+			return true
+		}
+	}
+
+	return false
+}
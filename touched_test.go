@@ -0,0 +1,106 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func rng(startLine, endLine int) *Range {
+	return &Range{Start: Position{Line: startLine}, End: Position{Line: endLine}}
+}
+
+func TestTouchedLinesApply(t *testing.T) {
+	type change struct {
+		r    *Range
+		text string
+	}
+
+	tests := []struct {
+		name    string
+		changes []change
+		want    []lineRange
+	}{
+		{
+			name:    "edit within a line",
+			changes: []change{{r: rng(3, 3), text: "x"}},
+			want:    []lineRange{{start: 3, end: 3}},
+		},
+		{
+			name:    "inserted lines",
+			changes: []change{{r: rng(3, 3), text: "a\nb\n"}},
+			want:    []lineRange{{start: 3, end: 5}},
+		},
+		{
+			name: "later ranges shift down on insertion",
+			changes: []change{
+				{r: rng(10, 10), text: "x"},
+				{r: rng(2, 2), text: "a\nb\n"},
+			},
+			want: []lineRange{{start: 2, end: 4}, {start: 12, end: 12}},
+		},
+		{
+			name: "later ranges shift up on deletion",
+			changes: []change{
+				{r: rng(10, 10), text: "x"},
+				{r: rng(2, 5), text: ""},
+			},
+			want: []lineRange{{start: 2, end: 2}, {start: 7, end: 7}},
+		},
+		{
+			name: "adjacent ranges merge",
+			changes: []change{
+				{r: rng(4, 4), text: "x"},
+				{r: rng(5, 5), text: "y"},
+			},
+			want: []lineRange{{start: 4, end: 5}},
+		},
+		{
+			name: "deleting part of a touched range",
+			changes: []change{
+				{r: rng(2, 6), text: "a\nb\nc\nd\ne"},
+				{r: rng(3, 5), text: ""},
+			},
+			want: []lineRange{{start: 2, end: 4}},
+		},
+		{
+			name:    "full document replacement",
+			changes: []change{{text: "a\nb\nc\n"}},
+			want:    []lineRange{{start: 0, end: 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tl touchedLines
+			for _, c := range tt.changes {
+				tl.apply(c.r, c.text)
+			}
+
+			if diff := cmp.Diff(tt.want, tl.ranges, cmp.AllowUnexported(lineRange{})); diff != "" {
+				t.Errorf("apply() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLangHandlerFilterTouched(t *testing.T) {
+	uri := DocumentURI("file:///project/main.go")
+
+	var tl touchedLines
+	tl.apply(rng(5, 5), "x")
+
+	h := &langHandler{touched: map[DocumentURI]*touchedLines{uri: &tl}}
+
+	diagnostics := []Diagnostic{
+		{Range: Range{Start: Position{Line: 5}, End: Position{Line: 5}}, Source: pt("unused")},
+		{Range: Range{Start: Position{Line: 8}, End: Position{Line: 8}}, Source: pt("unused")},
+		{Range: Range{Start: Position{Line: 9}, End: Position{Line: 9}}, Source: pt("typecheck")},
+		{Message: "golangci-lint failed"},
+	}
+
+	want := []Diagnostic{diagnostics[0], diagnostics[2], diagnostics[3]}
+	if diff := cmp.Diff(want, h.filterTouched(uri, diagnostics)); diff != "" {
+		t.Errorf("filterTouched() mismatch (-want +got):\n%s", diff)
+	}
+}
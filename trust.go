@@ -0,0 +1,229 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// trustActionAllow and trustActionReject are the choices of the trust prompt.
+	trustActionAllow  = "Allow"
+	trustActionReject = "Reject"
+)
+
+// trustStore remembers which executables the user allowed or rejected as the lint
+// command. The command usually comes from workspace settings, so a repository could
+// otherwise make the editor run any binary as soon as it is opened. Decisions are
+// keyed by the SHA-256 of the executable, so a replaced binary is asked about again,
+// and saved in a per-user state file.
+type trustStore struct {
+	path string
+
+	// mu serializes prompts and access to the state file.
+	mu sync.Mutex
+	// session holds the decisions of this session, including dismissed prompts,
+	// which are not saved.
+	session map[string]bool
+}
+
+// trustDecision is a decision of the state file.
+type trustDecision struct {
+	Path    string `json:"path"`
+	Trusted bool   `json:"trusted"`
+}
+
+// defaultTrustStorePath returns the state file under the user config directory.
+func defaultTrustStorePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "golangci-lint-langserver", "trusted-commands.json"), nil
+}
+
+func newTrustStore(path string) *trustStore {
+	return &trustStore{path: path, session: make(map[string]bool)}
+}
+
+// load reads the saved decisions. A missing or unreadable file has none.
+func (s *trustStore) load() map[string]trustDecision {
+	decisions := make(map[string]trustDecision)
+	if s.path == "" {
+		return decisions
+	}
+
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("failed to read the trusted commands", "path", s.path, "error", err)
+		}
+
+		return decisions
+	}
+
+	if err := json.Unmarshal(b, &decisions); err != nil {
+		slog.Warn("ignoring invalid trusted commands file", "path", s.path, "error", err)
+	}
+
+	return decisions
+}
+
+// save adds a decision to the state file.
+func (s *trustStore) save(hash string, decision trustDecision) error {
+	if s.path == "" {
+		return nil
+	}
+
+	decisions := s.load()
+	decisions[hash] = decision
+
+	b, err := json.MarshalIndent(decisions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, b, 0o600)
+}
+
+// executableHash returns the hex SHA-256 of the file at path.
+func executableHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// isSafeExecutable reports whether path is the golangci-lint found on PATH, which
+// runs without asking.
+func isSafeExecutable(path string) bool {
+	safe, err := exec.LookPath("golangci-lint")
+	if err != nil {
+		return false
+	}
+
+	return sameFile(safe, path)
+}
+
+func sameFile(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+
+	return os.SameFile(ai, bi)
+}
+
+// approveCommand asks the user, unless they already decided, whether the executable
+// of cmd may run. It fails when the executable is rejected.
+func (h *langHandler) approveCommand(cmd *exec.Cmd) error {
+	if h.trust == nil || cmd.Err != nil {
+		// Start reports executables that could not be found.
+		return nil
+	}
+
+	path := cmd.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cmd.Dir, path)
+	}
+
+	if isSafeExecutable(path) {
+		return nil
+	}
+
+	hash, err := executableHash(path)
+	if err != nil {
+		return err
+	}
+
+	s := h.trust
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trusted, ok := s.session[hash]
+	if !ok {
+		if d, saved := s.load()[hash]; saved {
+			trusted, ok = d.Trusted, true
+		}
+	}
+
+	if !ok {
+		trusted = h.promptTrust(path, hash)
+	}
+	s.session[hash] = trusted
+
+	if !trusted {
+		return fmt.Errorf("%s was not allowed to run as the lint command; decisions are kept in %s", path, s.path)
+	}
+
+	return nil
+}
+
+// promptTrust asks the user whether the executable may run and saves the answer.
+// A dismissed prompt rejects the executable for the session only.
+func (h *langHandler) promptTrust(path, hash string) bool {
+	slog.Info("asking whether the lint command may run", "path", path, "sha256", hash)
+
+	var action *MessageActionItem
+	err := h.conn.Call(h.serverContext(), "window/showMessageRequest", &ShowMessageRequestParams{
+		Type:    MTWarning,
+		Message: fmt.Sprintf("golangci-lint-langserver is configured to run %s, which is not golangci-lint from PATH. Allow it to run?", path),
+		Actions: []MessageActionItem{{Title: trustActionAllow}, {Title: trustActionReject}},
+	}, &action)
+	if err != nil {
+		slog.Warn("failed to ask whether the lint command may run", "error", err)
+
+		return false
+	}
+
+	if action == nil {
+		slog.Info("trust prompt dismissed, not running the lint command", "path", path)
+
+		return false
+	}
+
+	trusted := action.Title == trustActionAllow
+	if err := h.trust.save(hash, trustDecision{Path: path, Trusted: trusted}); err != nil {
+		slog.Warn("failed to save the lint command decision", "path", h.trust.path, "error", err)
+	}
+
+	slog.Info("lint command decision", "path", path, "trusted", trusted)
+
+	return trusted
+}
+
+// runLintCommand runs the configured lint command once its executable is allowed.
+func (h *langHandler) runLintCommand(cmd *exec.Cmd) error {
+	if err := h.approveCommand(cmd); err != nil {
+		return err
+	}
+
+	return h.run(cmd)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestLangHandler_approveCommand tests that an executable other than golangci-lint from
+// PATH only runs once allowed, and that the decision is remembered.
+func TestLangHandler_approveCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake lint command is a shell script")
+	}
+
+	tests := []struct {
+		name        string
+		action      any
+		wantErr     bool
+		wantSaved   bool
+		wantTrusted bool
+	}{
+		{name: "allowed", action: MessageActionItem{Title: trustActionAllow}, wantSaved: true, wantTrusted: true},
+		{name: "rejected", action: MessageActionItem{Title: trustActionReject}, wantErr: true, wantSaved: true},
+		{name: "dismissed", action: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script := filepath.Join(t.TempDir(), "lint.sh")
+			if err := os.WriteFile(script, []byte("#!/bin/sh\nexit 0\n"), 0o700); err != nil {
+				t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+			}
+
+			store := filepath.Join(t.TempDir(), "trusted-commands.json")
+
+			h := newLangHandler(false)
+			h.trust = newTrustStore(store)
+			client := newTestClient(t, h)
+			client.respond("window/showMessageRequest", tt.action)
+			client.call("initialize", map[string]any{
+				"rootUri":               string(pathToURI(t.TempDir())),
+				"initializationOptions": map[string]any{"command": []string{script}},
+			}, nil)
+
+			for range 2 {
+				if err := h.approveCommand(exec.Command(script)); (err != nil) != tt.wantErr {
+					t.Fatalf("approveCommand() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+
+			// The decision holds for the session, whatever the answer.
+			if n := len(client.received("window/showMessageRequest")); n != 1 {
+				t.Errorf("prompted %d times, want once", n)
+			}
+
+			hash, err := executableHash(script)
+			if err != nil {
+				t.Fatalf("executableHash() returned unexpected error: %v", err)
+			}
+
+			decision, saved := newTrustStore(store).load()[hash]
+			if saved != tt.wantSaved || decision.Trusted != tt.wantTrusted {
+				t.Errorf("saved decision = %+v, %v, want trusted %v, saved %v", decision, saved, tt.wantTrusted, tt.wantSaved)
+			}
+
+			// Another session uses the saved decision without asking.
+			if tt.wantSaved {
+				other := newLangHandler(false)
+				other.trust = newTrustStore(store)
+
+				if err := other.approveCommand(exec.Command(script)); (err != nil) != tt.wantErr {
+					t.Errorf("approveCommand() of another session error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestLangHandler_approveCommandWithoutTrust(t *testing.T) {
+	h := &langHandler{}
+
+	if err := h.approveCommand(exec.Command("sh", "-c", "exit 0")); err != nil {
+		t.Errorf("approveCommand() returned unexpected error: %v", err)
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"unicode"
 )
 
+// uriToPath returns the path of a file URI, with native separators. Windows drive
+// letters, which clients may send escaped and in lower case as in file:///c%3A/src,
+// are upper-cased.
 func uriToPath(uri string) string {
 	switch {
 	case strings.HasPrefix(uri, "file:///"):
@@ -26,10 +29,39 @@ func uriToPath(uri string) string {
 	return filepath.FromSlash(uri)
 }
 
+// isWindowsDriveURIPath reports whether the path of a URI starts with a drive, as in
+// /C:/src or /C:.
 func isWindowsDriveURIPath(uri string) bool {
-	if len(uri) < 4 {
+	if len(uri) < 3 {
 		return false
 	}
 
-	return uri[0] == '/' && unicode.IsLetter(rune(uri[1])) && uri[2] == ':'
+	return uri[0] == '/' && unicode.IsLetter(rune(uri[1])) && uri[2] == ':' && (len(uri) == 3 || uri[3] == '/')
+}
+
+// pathToURI returns the file URI of path, such as file:///C:/my%20project/main.go
+// on Windows, with the drive letter upper-cased like uriToPath does.
+func pathToURI(path string) DocumentURI {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows drive paths such as C:/foo need a leading slash.
+		path = "/" + path
+	}
+
+	if isWindowsDriveURIPath(path) {
+		path = "/" + strings.ToUpper(path[1:2]) + path[2:]
+	}
+
+	return DocumentURI((&url.URL{Scheme: "file", Path: path}).String())
+}
+
+// hasPathSuffix reports whether path ends with the elements of the relative path suffix,
+// whatever its separators.
+func hasPathSuffix(path, suffix string) bool {
+	suffix = filepath.Clean(suffix)
+	if len(suffix) > len(path) || !samePath(filepath.Base(path), filepath.Base(suffix)) {
+		return false
+	}
+
+	return samePath(path[len(path)-len(suffix):], suffix)
 }
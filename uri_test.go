@@ -0,0 +1,80 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestURIToPath(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		want string
+	}{
+		{name: "unix", uri: "file:///home/me/project/main.go", want: "/home/me/project/main.go"},
+		{name: "escaped space", uri: "file:///home/me/my%20project/main.go", want: "/home/me/my project/main.go"},
+		{name: "escaped drive", uri: "file:///c%3A/Users/me/project/main.go", want: "C:/Users/me/project/main.go"},
+		{name: "lower case drive", uri: "file:///d:/src/main.go", want: "D:/src/main.go"},
+		{name: "drive with space", uri: "file:///C:/Users/me/my%20project/main.go", want: "C:/Users/me/my project/main.go"},
+		{name: "drive root", uri: "file:///c%3A", want: "C:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, want := uriToPath(tt.uri), filepath.FromSlash(tt.want); got != want {
+				t.Errorf("uriToPath(%q) = %q, want %q", tt.uri, got, want)
+			}
+		})
+	}
+}
+
+func TestPathToURI(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want DocumentURI
+	}{
+		{name: "unix", path: "/home/me/project/main.go", want: "file:///home/me/project/main.go"},
+		{name: "space", path: "/home/me/my project/main.go", want: "file:///home/me/my%20project/main.go"},
+		{name: "drive", path: "C:/Users/me/project/main.go", want: "file:///C:/Users/me/project/main.go"},
+		{name: "lower case drive with space", path: "c:/Users/me/my project/main.go", want: "file:///C:/Users/me/my%20project/main.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pathToURI(filepath.FromSlash(tt.path))
+			if got != tt.want {
+				t.Errorf("pathToURI(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+
+			// The URIs of clients escaping the drive colon lead to the same path.
+			if back := uriToPath(string(got)); back != uriToPath(string(tt.want)) {
+				t.Errorf("uriToPath(pathToURI(%q)) = %q, want %q", tt.path, back, uriToPath(string(tt.want)))
+			}
+		})
+	}
+}
+
+func TestHasPathSuffix(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		suffix string
+		want   bool
+	}{
+		{name: "file", path: "/src/pkg/a.go", suffix: "a.go", want: true},
+		{name: "directory and file", path: "/src/pkg/a.go", suffix: "pkg/a.go", want: true},
+		{name: "dot prefix", path: "/src/pkg/a.go", suffix: "./pkg/a.go", want: true},
+		{name: "other directory", path: "/src/pkg/a.go", suffix: "other/a.go"},
+		{name: "partial name", path: "/src/pkg/data.go", suffix: "a.go"},
+		{name: "longer than the path", path: "/a.go", suffix: "src/pkg/a.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasPathSuffix(filepath.FromSlash(tt.path), tt.suffix); got != tt.want {
+				t.Errorf("hasPathSuffix(%q, %q) = %v, want %v", tt.path, tt.suffix, got, tt.want)
+			}
+		})
+	}
+}
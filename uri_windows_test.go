@@ -0,0 +1,83 @@
+//go:build windows
+
+package main
+
+import "testing"
+
+// TestURIToPath_windows tests that the URIs clients send for Windows files lead to their
+// native path, and back to the URI with an upper-case drive.
+func TestURIToPath_windows(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     string
+		want    string
+		wantURI DocumentURI
+	}{
+		{
+			name:    "escaped drive",
+			uri:     "file:///c%3A/Users/me/project/main.go",
+			want:    `C:\Users\me\project\main.go`,
+			wantURI: "file:///C:/Users/me/project/main.go",
+		},
+		{
+			name:    "space",
+			uri:     "file:///c%3A/Users/me/my%20project/main.go",
+			want:    `C:\Users\me\my project\main.go`,
+			wantURI: "file:///C:/Users/me/my%20project/main.go",
+		},
+		{
+			name:    "upper case drive",
+			uri:     "file:///C:/src/main.go",
+			want:    `C:\src\main.go`,
+			wantURI: "file:///C:/src/main.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := uriToPath(tt.uri)
+			if got != tt.want {
+				t.Errorf("uriToPath(%q) = %q, want %q", tt.uri, got, tt.want)
+			}
+
+			if uri := pathToURI(got); uri != tt.wantURI {
+				t.Errorf("pathToURI(%q) = %q, want %q", got, uri, tt.wantURI)
+			}
+		})
+	}
+}
+
+func TestSamePath_windows(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: `C:\src\main.go`, b: `c:\src\main.go`, want: true},
+		{a: `C:\Src\Main.go`, b: `C:\src\main.go`, want: true},
+		{a: `C:\src\main.go`, b: `D:\src\main.go`},
+	}
+
+	for _, tt := range tests {
+		if got := samePath(tt.a, tt.b); got != tt.want {
+			t.Errorf("samePath(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// TestIssueMatchesPath_windows tests that the issues golangci-lint reports with forward
+// slashes or another drive letter case are kept for the document.
+func TestIssueMatchesPath_windows(t *testing.T) {
+	absPath := `C:\Users\me\project\pkg\main.go`
+	baseDirs := []string{`C:\Users\me\project`}
+
+	for _, issuePath := range []string{
+		`pkg\main.go`,
+		"pkg/main.go",
+		`c:\Users\me\project\pkg\main.go`,
+		"c:/Users/me/project/pkg/main.go",
+	} {
+		if !issueMatchesPath(issuePath, absPath, baseDirs) {
+			t.Errorf("issueMatchesPath(%q, %q) = false, want true", issuePath, absPath)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// vendorEnv sets GOFLAGS=-mod=vendor for cmd when the module it runs in vendors its
+// dependencies: modules with a go directive before 1.14 do not use vendor/ on their
+// own and fail to typecheck without it. It leaves cmd alone when its environment or
+// arguments already choose a module mode.
+func (h *langHandler) vendorEnv(cmd *exec.Cmd) {
+	root := moduleRoot(cmd.Dir)
+	if _, err := os.Stat(filepath.Join(root, "vendor", "modules.txt")); err != nil {
+		return
+	}
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+
+	goflags := ""
+	for _, kv := range env {
+		if v, ok := strings.CutPrefix(kv, "GOFLAGS="); ok {
+			goflags = v
+		}
+	}
+
+	if modeChosen(goflags, cmd.Args) {
+		h.logVendorDecision(root, "the environment or command already sets the module mode")
+
+		return
+	}
+
+	cmd.Env = append(env, "GOFLAGS="+strings.TrimSpace(goflags+" -mod=vendor"))
+	h.logVendorDecision(root, "setting GOFLAGS=-mod=vendor")
+}
+
+// modeChosen reports whether goflags or args choose how modules are loaded.
+func modeChosen(goflags string, args []string) bool {
+	if strings.Contains(goflags, "-mod=") {
+		return true
+	}
+
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "GOFLAGS=") || strings.HasPrefix(arg, "-mod=") ||
+			strings.HasPrefix(arg, "--modules-download-mode") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// logVendorDecision logs the decision on -mod=vendor once per module root.
+func (h *langHandler) logVendorDecision(root, decision string) {
+	if _, logged := h.vendorLogged.LoadOrStore(root, true); !logged {
+		slog.Info("module vendors its dependencies", "root", root, "decision", decision)
+	}
+}
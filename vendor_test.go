@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestLangHandler_vendorEnv(t *testing.T) {
+	vendored := t.TempDir()
+	plain := t.TempDir()
+	for _, dir := range []string{vendored, plain} {
+		if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.13\n"), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(vendored, "vendor"), 0o755); err != nil {
+		t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(vendored, "vendor", "modules.txt"), nil, 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(vendored, "pkg"), 0o755); err != nil {
+		t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		dir     string
+		goflags string
+		args    []string
+		want    string
+	}{
+		{name: "vendored module", dir: vendored, want: "GOFLAGS=-mod=vendor"},
+		{name: "package of a vendored module", dir: filepath.Join(vendored, "pkg"), want: "GOFLAGS=-mod=vendor"},
+		{name: "other flags kept", dir: vendored, goflags: "-tags=integration", want: "GOFLAGS=-tags=integration -mod=vendor"},
+		{name: "mode in the environment", dir: vendored, goflags: "-mod=mod"},
+		{name: "mode in the command", dir: vendored, args: []string{"--modules-download-mode=readonly"}},
+		{name: "not vendored", dir: plain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOFLAGS", tt.goflags)
+
+			cmd := exec.Command("golangci-lint", append([]string{"run"}, tt.args...)...)
+			cmd.Dir = tt.dir
+
+			h := &langHandler{}
+			h.vendorEnv(cmd)
+
+			got := ""
+			if cmd.Env != nil {
+				got = cmd.Env[len(cmd.Env)-1]
+			}
+
+			if got != tt.want {
+				t.Errorf("vendorEnv() set %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// versionCheckTimeout bounds the golangci-lint version command, which may have to be
+// built first when run with `go run` or `go tool`.
+const versionCheckTimeout = 2 * time.Minute
+
+// golangciVersionRe matches the version printed by `golangci-lint version`, such as
+// "golangci-lint has version 1.64.8 built with go1.24.1 from ...".
+var golangciVersionRe = regexp.MustCompile(`version v?((\d+)\.\d+\.\d+\S*)`)
+
+// tomlVersionRe matches the top-level version key of a TOML config.
+var tomlVersionRe = regexp.MustCompile(`(?m)^version\s*=\s*"?(\w+)"?`)
+
+// golangciVersion is the version of a golangci-lint binary.
+type golangciVersion struct {
+	full  string
+	major int
+}
+
+// parseGolangciVersion reads the output of `golangci-lint version`.
+func parseGolangciVersion(output []byte) (golangciVersion, bool) {
+	m := golangciVersionRe.FindSubmatch(output)
+	if m == nil {
+		return golangciVersion{}, false
+	}
+
+	major, err := strconv.Atoi(string(m[2]))
+	if err != nil {
+		return golangciVersion{}, false
+	}
+
+	return golangciVersion{full: string(m[1]), major: major}, true
+}
+
+// versionCommand returns the command printing the version of the golangci-lint run by
+// command, like cacheCleanCommand. It fails when the command has no run subcommand,
+// as with custom scripts, whose binary is unknown.
+func versionCommand(command []string) ([]string, bool) {
+	base, ok := commandBinary(command)
+	if !ok {
+		return nil, false
+	}
+
+	return append(slices.Clone(base), "version"), true
+}
+
+// configVersion returns the major version of the config format declared by the file
+// at path: the version key of v2 configs, or 1 when there is none.
+func configVersion(path string) (int, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var declared string
+	if filepath.Ext(path) == ".toml" {
+		if m := tomlVersionRe.FindSubmatch(b); m != nil {
+			declared = string(m[1])
+		}
+	} else {
+		var raw struct {
+			Version string `yaml:"version"`
+		}
+		if err := yaml.Unmarshal(b, &raw); err != nil {
+			return 0, fmt.Errorf("parse %s: %w", path, err)
+		}
+
+		declared = raw.Version
+	}
+
+	if declared == "" {
+		return 1, nil
+	}
+
+	v, err := strconv.Atoi(declared)
+	if err != nil {
+		return 0, fmt.Errorf("invalid config version %q in %s", declared, path)
+	}
+
+	return v, nil
+}
+
+// versionMismatch returns the message telling which side to upgrade when the config
+// format is not the one of the binary, or "" when they agree.
+func versionMismatch(config string, configMajor int, binary golangciVersion) string {
+	switch {
+	case configMajor == binary.major:
+		return ""
+	case configMajor > binary.major:
+		return fmt.Sprintf("golangci-lint-langserver: %s is a version %d config, which golangci-lint %s cannot load; upgrade golangci-lint to v%d", config, configMajor, binary.full, configMajor)
+	case binary.major == 2:
+		return fmt.Sprintf("golangci-lint-langserver: %s is a version %d config, which golangci-lint %s no longer loads; upgrade the config with `golangci-lint migrate`", config, configMajor, binary.full)
+	default:
+		return fmt.Sprintf("golangci-lint-langserver: %s is a version %d config, which golangci-lint %s no longer loads; upgrade the config to version %d", config, configMajor, binary.full, binary.major)
+	}
+}
+
+// checkConfigVersion tells the user when the config of the workspace root is in a format
+// the golangci-lint binary cannot load, before lints fail with a generic config error.
+// The version of a command is detected once per session; a mismatch is reported once
+// until the config or the command changes.
+func (h *langHandler) checkConfigVersion(ctx context.Context, root, config string) {
+	h.settingsMu.RLock()
+	command := h.command
+	h.settingsMu.RUnlock()
+
+	args, ok := versionCommand(command)
+	if !ok {
+		return
+	}
+
+	configMajor, err := configVersion(config)
+	if err != nil {
+		// golangci-lint reports config errors of its own.
+		slog.Debug("failed to read the config version", "path", config, "error", err)
+
+		return
+	}
+
+	binary, ok := h.binaryVersion(ctx, root, args)
+	if !ok {
+		return
+	}
+
+	message := versionMismatch(config, configMajor, binary)
+	key := message + "\x00" + strings.Join(command, "\x00")
+
+	h.versionWarnedMu.Lock()
+	warned := h.versionWarned == key
+	h.versionWarned = key
+	h.versionWarnedMu.Unlock()
+
+	if message == "" || warned {
+		return
+	}
+
+	slog.Warn("golangci-lint version does not match the config", "config", config, "configVersion", configMajor, "version", binary.full)
+	h.showMessage(ctx, MTError, message)
+}
+
+// binaryVersion runs the version command, caching its result for the session.
+func (h *langHandler) binaryVersion(ctx context.Context, dir string, args []string) (golangciVersion, bool) {
+	key := strings.Join(args, "\x00")
+	if v, ok := h.binaryVersions.Load(key); ok {
+		return v.(golangciVersion), true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, versionCheckTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	setProcessGroup(cmd)
+	cmd.Dir = dir
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := h.runLintCommand(cmd); err != nil {
+		slog.Debug("failed to detect the golangci-lint version", "command", cmd.Args, "error", err)
+
+		return golangciVersion{}, false
+	}
+
+	v, ok := parseGolangciVersion(stdout.Bytes())
+	if !ok {
+		slog.Debug("unrecognized golangci-lint version", "output", stdout.String())
+
+		return golangciVersion{}, false
+	}
+
+	slog.Info("detected golangci-lint version", "version", v.full)
+	h.binaryVersions.Store(key, v)
+
+	return v, true
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseGolangciVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   golangciVersion
+		wantOK bool
+	}{
+		{
+			name:   "v1",
+			output: "golangci-lint has version 1.64.8 built with go1.24.1 from 8b37f141 on 2025-03-17T20:41:53Z\n",
+			want:   golangciVersion{full: "1.64.8", major: 1},
+			wantOK: true,
+		},
+		{
+			name:   "v2",
+			output: "golangci-lint has version v2.1.6 built with go1.24.2 from eabc2638 on 2025-05-04T15:41:19Z\n",
+			want:   golangciVersion{full: "2.1.6", major: 2},
+			wantOK: true,
+		},
+		{
+			name:   "development build",
+			output: "golangci-lint has version (devel) built with go1.24.1 from (unknown, modified: ?, mod sum: \"\") on (unknown)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseGolangciVersion([]byte(tt.output))
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("parseGolangciVersion() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestVersionCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+		want    []string
+	}{
+		{name: "golangci-lint", command: []string{"golangci-lint", "run", "--output.json.path", "stdout"}, want: []string{"golangci-lint", "version"}},
+		{name: "go tool", command: []string{"go", "tool", "golangci-lint", "run"}, want: []string{"go", "tool", "golangci-lint", "version"}},
+		{name: "go run", command: []string{"go", "run", "github.com/golangci/golangci-lint/v2/cmd/golangci-lint@v2.1.6", "run", "--fast-only"}, want: []string{"go", "run", "github.com/golangci/golangci-lint/v2/cmd/golangci-lint@v2.1.6", "version"}},
+		{name: "script", command: []string{"./lint.sh"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := versionCommand(tt.command)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("versionCommand() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    int
+		wantErr bool
+	}{
+		{name: "v2 yaml", file: ".golangci.yml", content: "version: \"2\"\nlinters:\n  default: standard\n", want: 2},
+		{name: "v1 yaml", file: ".golangci.yaml", content: "run:\n  timeout: 5m\n", want: 1},
+		{name: "v2 json", file: ".golangci.json", content: `{"version": "2"}`, want: 2},
+		{name: "v2 toml", file: ".golangci.toml", content: "version = \"2\"\n\n[linters]\ndefault = \"none\"\n", want: 2},
+		{name: "v1 toml", file: ".golangci.toml", content: "[run]\ntimeout = \"5m\"\n", want: 1},
+		{name: "invalid version", file: ".golangci.yml", content: "version: two\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+			}
+
+			got, err := configVersion(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("configVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("configVersion() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionMismatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		configMajor int
+		binary      golangciVersion
+		want        string
+	}{
+		{name: "v2 config, v1 binary", configMajor: 2, binary: golangciVersion{full: "1.64.8", major: 1}, want: "upgrade golangci-lint to v2"},
+		{name: "v1 config, v2 binary", configMajor: 1, binary: golangciVersion{full: "2.1.6", major: 2}, want: "golangci-lint migrate"},
+		{name: "matching", configMajor: 2, binary: golangciVersion{full: "2.1.6", major: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := versionMismatch(".golangci.yml", tt.configMajor, tt.binary)
+			if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+				t.Errorf("versionMismatch() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLangHandler_checkConfigVersion tests that a v2 config used with a v1 binary is
+// reported at initialize, and again once the config changes.
+func TestLangHandler_checkConfigVersion(t *testing.T) {
+	root := t.TempDir()
+	config := filepath.Join(root, ".golangci.yml")
+	if err := os.WriteFile(config, []byte("version: \"2\"\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	// The version command is the command up to its run argument: sh prints the version.
+	command := []string{"sh", "-c", "echo golangci-lint has version 1.64.8 built with go1.24.1", "run"}
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(root)),
+		"initializationOptions": map[string]any{"command": command},
+	}, nil)
+
+	mismatch := func(want string) func(json.RawMessage) bool {
+		return func(raw json.RawMessage) bool {
+			var params ShowMessageParams
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return false
+			}
+
+			return params.Type == MTError && strings.Contains(params.Message, want)
+		}
+	}
+
+	client.waitFor("window/showMessage", mismatch("upgrade golangci-lint to v2"), 5*time.Second)
+
+	if err := os.WriteFile(config, []byte("version: \"3\"\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	client.notify("workspace/didChangeWatchedFiles", map[string]any{
+		"changes": []map[string]any{{"uri": string(pathToURI(config)), "type": 2}},
+	})
+
+	client.waitFor("window/showMessage", mismatch("upgrade golangci-lint to v3"), 5*time.Second)
+}
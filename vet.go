@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// vetDiagnostic is a diagnostic as printed by `go vet -json`.
+type vetDiagnostic struct {
+	Posn    string `json:"posn"`
+	Message string `json:"message"`
+}
+
+// vetResult maps package paths to analyzer names to diagnostics.
+type vetResult map[string]map[string][]vetDiagnostic
+
+// parseVetOutput parses the output of `go vet -json`: one JSON object per package,
+// interleaved with "# package" comment lines.
+func parseVetOutput(b []byte) ([]Issue, error) {
+	var lines [][]byte
+	for _, line := range bytes.SplitAfter(b, []byte("\n")) {
+		if !bytes.HasPrefix(line, []byte("#")) {
+			lines = append(lines, line)
+		}
+	}
+
+	var issues []Issue
+
+	dec := json.NewDecoder(bytes.NewReader(bytes.Join(lines, nil)))
+	for {
+		var result vetResult
+		if err := dec.Decode(&result); err != nil {
+			if errors.Is(err, io.EOF) {
+				return issues, nil
+			}
+
+			return nil, err
+		}
+
+		for _, analyzers := range result {
+			for analyzer, diagnostics := range analyzers {
+				for _, d := range diagnostics {
+					issue := Issue{
+						FromLinter: "govet",
+						Text:       analyzer + ": " + d.Message,
+					}
+					issue.Pos.Filename, issue.Pos.Line, issue.Pos.Column = parsePosn(d.Posn)
+					issues = append(issues, issue)
+				}
+			}
+		}
+	}
+}
+
+// parsePosn splits a "file:line:col" position. The file may itself contain colons on Windows.
+func parsePosn(posn string) (string, int, int) {
+	rest, colStr, ok := cutLast(posn, ":")
+	if !ok {
+		return posn, 0, 0
+	}
+
+	file, lineStr, ok := cutLast(rest, ":")
+	if !ok {
+		return posn, 0, 0
+	}
+
+	line, err := strconv.Atoi(lineStr)
+	if err != nil {
+		return posn, 0, 0
+	}
+
+	col, _ := strconv.Atoi(colStr)
+
+	return file, line, col
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
+
+// vet runs `go vet -json` on the package of the document and returns its diagnostics for the document.
+// Failures are logged and yield no diagnostics, leaving the golangci-lint results untouched.
+func (h *langHandler) vet(ctx context.Context, uri DocumentURI) []Diagnostic {
+	path := uriToPath(string(uri))
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		slog.Warn("go vet skipped", "error", err)
+
+		return nil
+	}
+	absPath = filepath.Clean(absPath)
+
+	cmd := h.vetCommand(ctx, filepath.Dir(absPath))
+
+	slog.Debug("running go vet", "command", cmd.Args, "dir", cmd.Dir)
+
+	// go vet -json exits 0 unless the package fails to build. Older Go releases print its
+	// results to stderr, newer ones to stdout.
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := h.run(cmd); err != nil {
+		slog.Warn("go vet failed", "error", err, "stderr", stderr.String())
+
+		return nil
+	}
+
+	issues, err := parseVetOutput(append(stdout.Bytes(), stderr.Bytes()...))
+	if err != nil {
+		slog.Warn("failed to parse go vet output", "error", err)
+
+		return nil
+	}
+
+	src, _ := readSourceLines(absPath)
+	baseDirs := []string{cmd.Dir}
+
+	// The issues go through the same options as those of golangci-lint.
+	diagnostics := make([]Diagnostic, 0, len(issues))
+	for _, issue := range issues {
+		if h.excludesMessage(issue.Text) || !issueMatchesPath(issue.Pos.Filename, absPath, baseDirs) {
+			continue
+		}
+
+		diagnostics = append(diagnostics, h.issueDiagnostic(&issue, absPath, src, baseDirs))
+	}
+
+	return diagnostics
+}
+
+// vetCommand returns the `go vet -json` command for the package in dir, with the same
+// module mode as golangci-lint gets: -mod=vendor in vendored modules and GO111MODULE
+// outside modules.
+func (h *langHandler) vetCommand(ctx context.Context, dir string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "go", "vet", "-json", ".")
+	setProcessGroup(cmd)
+	cmd.Dir = dir
+	h.vendorEnv(cmd)
+	h.gopathEnv(cmd)
+
+	return cmd
+}
+
+// lintWithVet lints the document like lintWithRetry and, with alsoRunGoVet, merges the
+// go vet diagnostics of the document into its results, so that they are filtered and
+// cached along with those of golangci-lint.
+func (h *langHandler) lintWithVet(ctx context.Context, uri DocumentURI) (map[DocumentURI][]Diagnostic, error) {
+	if !h.alsoRunGoVet || h.needsOverlay(uri) {
+		return h.lintWithRetry(ctx, uri)
+	}
+
+	vetResults := make(chan []Diagnostic, 1)
+	go func() { vetResults <- h.vet(ctx, uri) }()
+
+	results, err := h.lintWithRetry(ctx, uri)
+	vet := <-vetResults
+	if err == nil {
+		results[uri] = mergeVetDiagnostics(results[uri], vet)
+	}
+
+	return results, err
+}
+
+// mergeVetDiagnostics appends the go vet diagnostics that golangci-lint's govet did not report already.
+func mergeVetDiagnostics(diagnostics, vet []Diagnostic) []Diagnostic {
+	for _, v := range vet {
+		duplicate := false
+		for _, d := range diagnostics {
+			if d.Source != nil && *d.Source == "govet" && d.Range.Start == v.Range.Start {
+				duplicate = true
+
+				break
+			}
+		}
+
+		if !duplicate {
+			diagnostics = append(diagnostics, v)
+		}
+	}
+
+	return diagnostics
+}
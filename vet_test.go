@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseVetOutput(t *testing.T) {
+	output := `# example.com/foo
+{
+	"example.com/foo": {
+		"printf": [
+			{
+				"posn": "/project/foo/main.go:10:2",
+				"message": "fmt.Sprintf format %d has arg s of wrong type string"
+			}
+		]
+	}
+}
+# example.com/foo/bar
+{
+	"example.com/foo/bar": {
+		"unreachable": [
+			{
+				"posn": "C:\\project\\foo\\bar\\bar.go:4:1",
+				"message": "unreachable code"
+			}
+		]
+	}
+}
+`
+
+	issues, err := parseVetOutput([]byte(output))
+	if err != nil {
+		t.Fatalf("parseVetOutput() returned unexpected error: %v", err)
+	}
+
+	type pos struct {
+		Filename     string
+		Line, Column int
+	}
+
+	var got []pos
+	var texts []string
+	for _, issue := range issues {
+		got = append(got, pos{issue.Pos.Filename, issue.Pos.Line, issue.Pos.Column})
+		texts = append(texts, issue.Text)
+	}
+
+	want := []pos{
+		{"/project/foo/main.go", 10, 2},
+		{`C:\project\foo\bar\bar.go`, 4, 1},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseVetOutput() positions mismatch (-want +got):\n%s", diff)
+	}
+
+	wantTexts := []string{
+		"printf: fmt.Sprintf format %d has arg s of wrong type string",
+		"unreachable: unreachable code",
+	}
+	if diff := cmp.Diff(wantTexts, texts); diff != "" {
+		t.Errorf("parseVetOutput() texts mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestLangHandler_vetCommand tests that go vet runs in the module mode golangci-lint gets.
+func TestLangHandler_vetCommand(t *testing.T) {
+	t.Setenv("GOFLAGS", "")
+
+	vendored := t.TempDir()
+	if err := os.WriteFile(filepath.Join(vendored, "go.mod"), []byte("module example.com/app\n\ngo 1.13\n"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(vendored, "vendor"), 0o755); err != nil {
+		t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(vendored, "vendor", "modules.txt"), nil, 0o600); err != nil {
+		t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+	}
+
+	project := gopathFixture(t)
+
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{name: "vendored module", dir: vendored, want: "GOFLAGS=-mod=vendor"},
+		{name: "GOPATH package", dir: filepath.Join(project, "util"), want: "GO111MODULE=off"},
+	}
+
+	h := &langHandler{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := h.vetCommand(context.Background(), tt.dir)
+
+			if cmd.Dir != tt.dir {
+				t.Errorf("vetCommand() runs in %q, want %q", cmd.Dir, tt.dir)
+			}
+
+			found := false
+			for _, kv := range cmd.Env {
+				found = found || kv == tt.want
+			}
+
+			if !found {
+				t.Errorf("vetCommand() environment lacks %q", tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeVetDiagnostics(t *testing.T) {
+	at := func(line, char int) Range {
+		return Range{Start: Position{Line: line, Character: char}, End: Position{Line: line, Character: char}}
+	}
+
+	diagnostics := []Diagnostic{
+		{Range: at(9, 1), Source: pt("govet"), Message: "govet: printf: bad format"},
+		{Range: at(3, 0), Source: pt("unused"), Message: "unused: var foo is unused"},
+	}
+	vet := []Diagnostic{
+		{Range: at(9, 1), Source: pt("govet"), Message: "govet: printf: bad format"},
+		{Range: at(3, 0), Source: pt("govet"), Message: "govet: unreachable: unreachable code"},
+	}
+
+	want := []Diagnostic{diagnostics[0], diagnostics[1], vet[1]}
+	if diff := cmp.Diff(want, mergeVetDiagnostics(diagnostics, vet)); diff != "" {
+		t.Errorf("mergeVetDiagnostics() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestLangHandler_pullVetDiagnostics tests that the go vet issues of a pulled document
+// go through excludeMessages and severities like those of golangci-lint.
+func TestLangHandler_pullVetDiagnostics(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+
+	root := t.TempDir()
+	for name, text := range map[string]string{
+		"go.mod":  "module example.com/vet\n\ngo 1.23\n",
+		"main.go": "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Printf(\"%d\\n\", \"s\")\n\treturn\n\tfmt.Println()\n}\n",
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(text), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri": string(pathToURI(root)),
+		"initializationOptions": map[string]any{
+			"command":         fakeLinter(t, GolangCILintResult{}),
+			"alsoRunGoVet":    true,
+			"excludeMessages": []string{"unreachable"},
+			"severities":      map[string]string{"govet": "hint"},
+			"lintOnOpen":      false,
+		},
+	}, nil)
+
+	var report FullDocumentDiagnosticReport
+	client.call("textDocument/diagnostic", map[string]any{
+		"textDocument": map[string]any{"uri": pathToURI(filepath.Join(root, "main.go"))},
+	}, &report)
+
+	if len(report.Items) != 1 {
+		t.Fatalf("expected the printf issue only, got %+v", report.Items)
+	}
+
+	if d := report.Items[0]; d.Range.Start.Line != 5 || d.Severity != DSHint || !strings.Contains(d.Message, "printf") {
+		t.Errorf("unexpected diagnostic: %+v", d)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// parentPollInterval is how often the client process is checked for existence.
+const parentPollInterval = 3 * time.Second
+
+// watchProcess calls onExit once the process with the given pid no longer exists.
+// It returns without calling onExit when ctx is done first.
+func watchProcess(ctx context.Context, pid int, interval time.Duration, onExit func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !processExists(pid) {
+				onExit()
+
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestWatchProcess(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to run a child process: %v", err)
+	}
+
+	t.Run("exited process", func(t *testing.T) {
+		exited := make(chan struct{})
+		go watchProcess(context.Background(), cmd.ProcessState.Pid(), time.Millisecond, func() { close(exited) })
+
+		select {
+		case <-exited:
+		case <-time.After(time.Second):
+			t.Error("onExit was not called for an exited process")
+		}
+	})
+
+	t.Run("running process", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		watchProcess(ctx, os.Getpid(), time.Millisecond, func() {
+			t.Error("onExit was called for a running process")
+		})
+	})
+}
+
+// TestLangHandler_exitUnlessInitialized tests that the server exits with exitStartupTimeout
+// when no initialize request arrives in time, and keeps running otherwise.
+func TestLangHandler_exitUnlessInitialized(t *testing.T) {
+	t.Run("timeout", func(t *testing.T) {
+		h := newLangHandler(false)
+		exited := make(chan int, 1)
+		h.exit = func(code int) { exited <- code }
+
+		go h.exitUnlessInitialized(10 * time.Millisecond)
+
+		select {
+		case code := <-exited:
+			if code != exitStartupTimeout {
+				t.Errorf("exit code = %d, want %d", code, exitStartupTimeout)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("the server did not exit")
+		}
+	})
+
+	t.Run("initialized", func(t *testing.T) {
+		h := newLangHandler(false)
+		h.exit = func(code int) { t.Errorf("the server exited with code %d", code) }
+		client := newTestClient(t, h)
+
+		client.call("initialize", map[string]any{}, nil)
+
+		h.exitUnlessInitialized(10 * time.Millisecond)
+	})
+}
+
+// TestLangHandler_exitClientGone tests that the server exits with exitClientGone once the
+// client process given in initialize is gone.
+func TestLangHandler_exitClientGone(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to run a child process: %v", err)
+	}
+
+	h := newLangHandler(false)
+	exited := make(chan int, 1)
+	h.exit = func(code int) { exited <- code }
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{"processId": cmd.ProcessState.Pid()}, nil)
+
+	select {
+	case code := <-exited:
+		if code != exitClientGone {
+			t.Errorf("exit code = %d, want %d", code, exitClientGone)
+		}
+	case <-time.After(2 * parentPollInterval):
+		t.Fatal("the server did not exit")
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// workspacePattern is the package pattern of workspace/diagnostic, whose previously
+// reported documents are tracked along those of the lintPackage command.
+const workspacePattern = "./..."
+
+// workspaceDiagnosticBatch is the number of documents per partial result of
+// workspace/diagnostic.
+const workspaceDiagnosticBatch = 100
+
+// handleWorkspaceDiagnostic answers workspace/diagnostic requests by linting the packages
+// of each workspace folder. The reports are streamed in batches of $/progress
+// notifications when the request has a partialResultToken, leaving the response empty.
+func (h *langHandler) handleWorkspaceDiagnostic(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	var params WorkspaceDiagnosticParams
+	if err := decodeParams(req, &params); err != nil {
+		return nil, err
+	}
+
+	// There is nothing to lint without a workspace, and the last results stand while paused.
+	roots := h.workspaceRoots()
+	if len(roots) == 0 || h.paused.Load() {
+		return WorkspaceDiagnosticReport{Items: []WorkspaceFullDocumentDiagnosticReport{}}, nil
+	}
+
+	lintCtx := h.lintContext()
+
+	// Each folder of a multi-root workspace is linted from its own root.
+	results := make(map[DocumentURI][]Diagnostic)
+	for _, root := range roots {
+		rootResults, err := h.packageDiagnostics(lintCtx, workspacePattern, root, true)
+		if lintCtx.Err() != nil {
+			return nil, &jsonrpc2.Error{Code: codeRequestCancelled, Message: "lint cancelled"}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		maps.Copy(results, rootResults)
+	}
+
+	h.recordPackageLint(workspacePattern, results)
+
+	// The client may know of documents reported before a restart of the server.
+	for _, previous := range params.PreviousResultIDs {
+		if _, ok := results[previous.URI]; ok || validateDocumentURI(previous.URI) != nil {
+			continue
+		}
+
+		if path, err := filepath.Abs(uriToPath(string(previous.URI))); err == nil && h.inWorkspace(path) {
+			results[previous.URI] = []Diagnostic{}
+		}
+	}
+
+	uris := make([]DocumentURI, 0, len(results))
+	for uri := range results {
+		uris = append(uris, uri)
+	}
+	slices.Sort(uris)
+
+	items := make([]WorkspaceFullDocumentDiagnosticReport, 0, len(uris))
+	for _, uri := range uris {
+		resultID := h.resultID(uri)
+		h.results.set(uri, results[uri], resultID)
+
+		items = append(items, WorkspaceFullDocumentDiagnosticReport{
+			FullDocumentDiagnosticReport: FullDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindFull, ResultID: resultID, Items: results[uri]},
+			URI:                          uri,
+		})
+	}
+
+	if params.PartialResultToken == "" || h.conn == nil {
+		return WorkspaceDiagnosticReport{Items: items}, nil
+	}
+
+	for batch := range slices.Chunk(items, workspaceDiagnosticBatch) {
+		h.notifyProgress(params.PartialResultToken, &WorkspaceDiagnosticReportPartialResult{Items: batch})
+	}
+
+	// The reports were all sent as partial results.
+	return WorkspaceDiagnosticReport{Items: []WorkspaceFullDocumentDiagnosticReport{}}, nil
+}
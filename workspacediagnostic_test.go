@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// workspaceLayout writes a module with a file in its root and one in a subpackage, and
+// returns the root.
+func workspaceLayout(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for name, text := range map[string]string{
+		"go.mod":   "module example.com/workspace\n",
+		"a.go":     "package main\n",
+		"sub/b.go": "package sub\n",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("os.MkdirAll() returned unexpected error: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+	}
+
+	return root
+}
+
+func workspaceIssue(filename string) Issue {
+	i := Issue{FromLinter: "errcheck", Text: "Error return value is not checked"}
+	i.Pos.Filename, i.Pos.Line, i.Pos.Column = filename, 1, 1
+
+	return i
+}
+
+// reportCounts returns the number of diagnostics of each document of the reports.
+func reportCounts(items []WorkspaceFullDocumentDiagnosticReport) map[DocumentURI]int {
+	counts := make(map[DocumentURI]int)
+	for _, item := range items {
+		counts[item.URI] = len(item.Items)
+	}
+
+	return counts
+}
+
+// TestLangHandler_workspaceDiagnostic tests that workspace/diagnostic reports the issues
+// of the packages of the workspace and clears the documents that are clean again.
+func TestLangHandler_workspaceDiagnostic(t *testing.T) {
+	root := workspaceLayout(t)
+	command := fakeLinter(t, GolangCILintResult{Issues: []Issue{workspaceIssue("a.go"), workspaceIssue("sub/b.go")}})
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(root)),
+		"initializationOptions": map[string]any{"command": command},
+	}, nil)
+
+	a, b := pathToURI(filepath.Join(root, "a.go")), pathToURI(filepath.Join(root, "sub", "b.go"))
+	outside := pathToURI(filepath.Join(t.TempDir(), "c.go"))
+	stale := pathToURI(filepath.Join(root, "gone.go"))
+
+	steps := []struct {
+		name     string
+		issues   []Issue
+		previous []PreviousResultID
+		want     map[DocumentURI]int
+	}{
+		{
+			name:   "first run",
+			issues: []Issue{workspaceIssue("a.go"), workspaceIssue("sub/b.go")},
+			want:   map[DocumentURI]int{a: 1, b: 1},
+		},
+		{
+			name:   "fixed file is cleared",
+			issues: []Issue{workspaceIssue("a.go")},
+			want:   map[DocumentURI]int{a: 1, b: 0},
+		},
+		{
+			name:     "previous results of the client are cleared",
+			issues:   []Issue{workspaceIssue("a.go")},
+			previous: []PreviousResultID{{URI: stale, Value: "1"}, {URI: outside, Value: "1"}},
+			want:     map[DocumentURI]int{a: 1, stale: 0},
+		},
+	}
+
+	for _, step := range steps {
+		out, err := json.Marshal(GolangCILintResult{Issues: step.issues})
+		if err != nil {
+			t.Fatalf("json.Marshal() returned unexpected error: %v", err)
+		}
+		if err := os.WriteFile(command[3], out, 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+
+		var report WorkspaceDiagnosticReport
+		client.call("workspace/diagnostic", map[string]any{"previousResultIds": step.previous}, &report)
+
+		if diff := cmp.Diff(step.want, reportCounts(report.Items)); diff != "" {
+			t.Errorf("%s: reports mismatch (-want +got):\n%s", step.name, diff)
+		}
+
+		for _, item := range report.Items {
+			if item.Kind != DocumentDiagnosticReportKindFull || item.ResultID == "" {
+				t.Errorf("%s: report of %s = %+v, want a full report with a resultId", step.name, item.URI, item)
+			}
+		}
+	}
+}
+
+// TestLangHandler_workspaceDiagnosticPartialResults tests that the reports are streamed
+// in batches with a partialResultToken, leaving the response empty.
+func TestLangHandler_workspaceDiagnosticPartialResults(t *testing.T) {
+	root := t.TempDir()
+
+	var issues []Issue
+	want := make(map[DocumentURI]int)
+	for i := range workspaceDiagnosticBatch + 1 {
+		name := filepath.Join(root, "p"+string(rune('a'+i/26))+string(rune('a'+i%26))+".go")
+		if err := os.WriteFile(name, []byte("package main\n"), 0o600); err != nil {
+			t.Fatalf("os.WriteFile() returned unexpected error: %v", err)
+		}
+
+		issues = append(issues, workspaceIssue(filepath.Base(name)))
+		want[pathToURI(name)] = 1
+	}
+
+	command := fakeLinter(t, GolangCILintResult{Issues: issues})
+
+	h := newLangHandler(false)
+	client := newTestClient(t, h)
+
+	client.call("initialize", map[string]any{
+		"rootUri":               string(pathToURI(root)),
+		"initializationOptions": map[string]any{"command": command},
+	}, nil)
+
+	var report WorkspaceDiagnosticReport
+	client.call("workspace/diagnostic", map[string]any{"previousResultIds": []any{}, "partialResultToken": "partial"}, &report)
+
+	if len(report.Items) != 0 {
+		t.Errorf("response has %d reports, want them all as partial results", len(report.Items))
+	}
+
+	client.waitFor("$/progress", func(json.RawMessage) bool {
+		return len(client.received("$/progress")) >= 2
+	}, 5*time.Second)
+
+	var batches []int
+	got := make(map[DocumentURI]int)
+	for _, raw := range client.received("$/progress") {
+		var params struct {
+			Token ProgressToken                          `json:"token"`
+			Value WorkspaceDiagnosticReportPartialResult `json:"value"`
+		}
+		if err := json.Unmarshal(raw, &params); err != nil {
+			t.Fatalf("invalid $/progress params: %v", err)
+		}
+		if params.Token != "partial" {
+			continue
+		}
+
+		batches = append(batches, len(params.Value.Items))
+		for uri, n := range reportCounts(params.Value.Items) {
+			got[uri] = n
+		}
+	}
+
+	if diff := cmp.Diff([]int{workspaceDiagnosticBatch, 1}, batches); diff != "" {
+		t.Errorf("batch sizes mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("partial results mismatch (-want +got):\n%s", diff)
+	}
+}